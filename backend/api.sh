@@ -0,0 +1,13 @@
+#!/bin/bash
+
+# Load environment variables from .env file
+if [ -f .env ]; then
+  export $(cat .env | grep -v '^#' | xargs)
+fi
+
+# Serve the read-only GraphQL API at $API_ADDR/graphql, owner portfolios
+# (tiles and time capsules) at $API_ADDR/api/owner/{address or ENS}, wrapper sale proceeds at
+# $API_ADDR/api/owner/{address or ENS}/proceeds and
+# $API_ADDR/api/tiles/{id}/proceeds, and marketplace stats at
+# $API_ADDR/api/stats (default :8080).
+go run cmd/api/main.go "$@"
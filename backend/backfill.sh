@@ -0,0 +1,11 @@
+#!/bin/bash
+
+# Load environment variables from .env file
+if [ -f .env ]; then
+  export $(cat .env | grep -v '^#' | xargs)
+fi
+
+# Catch the database up with parallel block-range fetches. Stop the ingestor
+# first. Flags: --from, --to, --chunk, --workers. Rerun with the same flags to
+# resume an interrupted backfill.
+go run cmd/backfill/main.go "$@"
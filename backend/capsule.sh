@@ -0,0 +1,10 @@
+#!/bin/bash
+
+# Load environment variables from .env file
+if [ -f .env ]; then
+  export $(cat .env | grep -v '^#' | xargs)
+fi
+
+# Render a PixelMapTimeCapsule edition and print its base token URI, e.g.
+#   ./capsule.sh -edition 2 -rows 19-29 -columns 35-45 -at 2024-11-17 -name "The Center" -reason "Anniversary"
+go run cmd/capsule/main.go "$@"
@@ -0,0 +1,11 @@
+#!/bin/bash
+
+# Load environment variables from .env file
+if [ -f .env ]; then
+  export $(cat .env | grep -v '^#' | xargs)
+fi
+
+# Label transfers recorded before they were classified as wrap, unwrap, mint,
+# burn, sale or gift, looking up each transaction on Etherscan. Rerun to
+# resume.
+go run cmd/classify-transfers/main.go "$@"
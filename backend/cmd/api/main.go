@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	prettyconsole "github.com/thessem/zap-prettyconsole"
+	ens "github.com/wealdtech/go-ens/v3"
+	"go.uber.org/zap"
+	"pixelmap.io/backend/internal/api"
+	"pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/ingestor"
+)
+
+// api serves the indexed database read-only. It can run next to the indexer
+// against the same database; it does not migrate or write.
+func main() {
+	logger := prettyconsole.NewLogger(zap.InfoLevel)
+	defer logger.Sync()
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: Could not load .env file: %v", err)
+	}
+
+	network, err := ingestor.NetworkFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid network configuration", zap.Error(err))
+	}
+
+	conn, err := db.Open(os.Getenv("DATABASE_URL"), network.Schema)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer conn.Close()
+
+	var opts api.Options
+	if web3URL := os.Getenv("WEB3_URL"); web3URL != "" {
+		client, err := ethclient.Dial(web3URL)
+		if err != nil {
+			logger.Fatal("Failed to connect to Ethereum node", zap.Error(err))
+		}
+		defer client.Close()
+		// Names not cached on any tile are resolved on chain. A name that
+		// does not resolve is reported to the client as an unknown owner.
+		opts.ResolveENS = func(ctx context.Context, name string) (string, error) {
+			address, err := ens.Resolve(client, name)
+			if err != nil {
+				logger.Debug("ENS name did not resolve", zap.String("name", name), zap.Error(err))
+				return "", nil
+			}
+			return address.Hex(), nil
+		}
+	}
+
+	server, err := api.New(logger, db.New(conn), opts)
+	if err != nil {
+		logger.Fatal("Failed to build API", zap.Error(err))
+	}
+
+	addr := os.Getenv("API_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		logger.Info("API server listening", zap.String("addr", addr))
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatal("API server stopped", zap.Error(err))
+		}
+	}()
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Failed to shut down API server", zap.Error(err))
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	prettyconsole "github.com/thessem/zap-prettyconsole"
+	"go.uber.org/zap"
+	"pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/ingestor"
+)
+
+// backfill catches a database up to the chain with parallel block-range
+// fetches. Stop the ingestor service while it runs; rendering of the new data
+// happens when the service starts again. Rerun with the same flags to resume.
+func main() {
+	from := flag.Int64("from", 0, "first block (default: after the last processed block)")
+	to := flag.Int64("to", 0, "last block (default: latest block minus the safety offset)")
+	chunkSize := flag.Int64("chunk", 10000, "blocks per chunk")
+	workers := flag.Int("workers", 4, "concurrent chunk fetches")
+	flag.Parse()
+
+	logger := prettyconsole.NewLogger(zap.InfoLevel)
+	defer logger.Sync()
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: Could not load .env file: %v", err)
+	}
+
+	network, err := ingestor.NetworkFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid network configuration", zap.Error(err))
+	}
+
+	conn, err := db.Open(os.Getenv("DATABASE_URL"), network.Schema)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := db.Migrate(ctx, conn); err != nil {
+		logger.Fatal("Failed to migrate database", zap.Error(err))
+	}
+
+	ingester := ingestor.NewIngestor(logger, conn, os.Getenv("ETHERSCAN_API_KEY"), network)
+	defer ingester.Close()
+
+	if err := ingester.Backfill(ctx, ingestor.BackfillOptions{
+		FromBlock: *from,
+		ToBlock:   *to,
+		ChunkSize: *chunkSize,
+		Workers:   *workers,
+	}); err != nil {
+		logger.Fatal("Backfill stopped", zap.Error(err))
+	}
+	// Images, metadata and the S3 copy are brought up to date by the indexer,
+	// which renders pending data history when it starts.
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	prettyconsole "github.com/thessem/zap-prettyconsole"
+	"go.uber.org/zap"
+	"pixelmap.io/backend/internal/capsule"
+	"pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/ingestor"
+)
+
+// capsule renders a PixelMapTimeCapsule edition and writes its metadata. It
+// prints the CIDs the files will have once pinned and the URI to pass to
+// setBaseTokenURI. For example, the first edition was the first 324 tiles
+// given an image, as they were on 2021-10-22:
+//
+//	./capsule.sh -edition 1 -first 324 -at 2021-10-22 -name "The OG 18x18" -reason "Tiles Updated"
+func main() {
+	edition := flag.Int("edition", 0, "edition number, used in the file names")
+	first := flag.Int("first", 0, "take the first N tiles given an image")
+	rows := flag.String("rows", "0-48", "rows of the map to take, FIRST-LAST, when -first is not set")
+	columns := flag.String("columns", "0-80", "columns of the map to take, FIRST-LAST, when -first is not set")
+	atFlag := flag.String("at", "", "take each tile's image as of this day, YYYY-MM-DD (default: today)")
+	tileSize := flag.Int("tile-size", 512, "pixels per tile in the composite")
+	name := flag.String("name", "", "edition name")
+	description := flag.String("description", "", "edition description")
+	reason := flag.String("reason", "", "the Reason property")
+	out := flag.String("out", "../contracts/metadata/capsules", "directory to write capsuleN.png and capsuleN.json to")
+	flag.Parse()
+
+	logger := prettyconsole.NewLogger(zap.InfoLevel)
+	defer logger.Sync()
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: Could not load .env file: %v", err)
+	}
+
+	if *edition <= 0 || *name == "" || *reason == "" {
+		logger.Fatal("-edition, -name and -reason are required")
+	}
+
+	rule := capsule.Rule{First: *first, At: time.Now().UTC()}
+	if *atFlag != "" {
+		day, err := time.Parse(time.DateOnly, *atFlag)
+		if err != nil {
+			logger.Fatal("Invalid -at", zap.Error(err))
+		}
+		// The whole day counts.
+		rule.At = day.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	var err error
+	if rule.Rows, err = parseRange(*rows); err != nil {
+		logger.Fatal("Invalid -rows", zap.Error(err))
+	}
+	if rule.Columns, err = parseRange(*columns); err != nil {
+		logger.Fatal("Invalid -columns", zap.Error(err))
+	}
+
+	network, err := ingestor.NetworkFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid network configuration", zap.Error(err))
+	}
+
+	conn, err := db.Open(os.Getenv("DATABASE_URL"), network.Schema)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	layout, err := capsule.Select(ctx, db.New(conn), rule)
+	if err != nil {
+		logger.Fatal("Failed to select tiles", zap.Error(err))
+	}
+
+	composite, skipped := capsule.Render(layout, *tileSize)
+	if len(skipped) > 0 {
+		logger.Warn("Some tile images could not be drawn and were left blank", zap.Int32s("tiles", skipped))
+	}
+
+	output, err := capsule.Write(*out, capsule.Edition{
+		Number:       *edition,
+		Name:         *name,
+		Description:  *description,
+		Reason:       *reason,
+		CelebratedOn: rule.At,
+	}, composite)
+	if err != nil {
+		logger.Fatal("Failed to write capsule", zap.Error(err))
+	}
+
+	logger.Info("Wrote time capsule",
+		zap.Int("tiles", len(layout.Cells)),
+		zap.String("image", output.ImagePath),
+		zap.String("imageCid", output.ImageCID),
+		zap.String("metadata", output.MetadataPath),
+		zap.String("metadataCid", output.MetadataCID))
+	fmt.Println(output.BaseTokenURI)
+}
+
+func parseRange(value string) ([2]int, error) {
+	first, last, found := strings.Cut(value, "-")
+	if !found {
+		last = first
+	}
+	from, err := strconv.Atoi(first)
+	if err != nil {
+		return [2]int{}, err
+	}
+	to, err := strconv.Atoi(last)
+	if err != nil {
+		return [2]int{}, err
+	}
+	return [2]int{from, to}, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	prettyconsole "github.com/thessem/zap-prettyconsole"
+	"go.uber.org/zap"
+	"pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/ingestor"
+)
+
+// classify-transfers labels the transfers recorded before transfer types
+// existed, or whose transaction wasn't known when they were ingested, looking
+// up the transaction of each on Etherscan. Rerun it to resume.
+func main() {
+	logger := prettyconsole.NewLogger(zap.InfoLevel)
+	defer logger.Sync()
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: Could not load .env file: %v", err)
+	}
+
+	network, err := ingestor.NetworkFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid network configuration", zap.Error(err))
+	}
+
+	conn, err := db.Open(os.Getenv("DATABASE_URL"), network.Schema)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := db.Migrate(ctx, conn); err != nil {
+		logger.Fatal("Failed to migrate database", zap.Error(err))
+	}
+
+	ingester := ingestor.NewIngestor(logger, conn, os.Getenv("ETHERSCAN_API_KEY"), network)
+	defer ingester.Close()
+
+	classified, err := ingester.ClassifyTransfers(ctx)
+	if err != nil {
+		logger.Fatal("Transfer classification stopped", zap.Int("classified", classified), zap.Error(err))
+	}
+	logger.Info("Transfers classified", zap.Int("classified", classified))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	prettyconsole "github.com/thessem/zap-prettyconsole"
+	"go.uber.org/zap"
+	"pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/ingestor"
+)
+
+// compare-data-changes fills in the previous values, change type and pixel
+// diff of tile updates stored before they were recorded. It needs no
+// Etherscan calls. Rerun it to resume.
+func main() {
+	logger := prettyconsole.NewLogger(zap.InfoLevel)
+	defer logger.Sync()
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: Could not load .env file: %v", err)
+	}
+
+	network, err := ingestor.NetworkFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid network configuration", zap.Error(err))
+	}
+
+	conn, err := db.Open(os.Getenv("DATABASE_URL"), network.Schema)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := db.Migrate(ctx, conn); err != nil {
+		logger.Fatal("Failed to migrate database", zap.Error(err))
+	}
+
+	ingester := ingestor.NewIngestor(logger, conn, "", network)
+	defer ingester.Close()
+
+	compared, err := ingester.CompareDataHistories(ctx)
+	if err != nil {
+		logger.Fatal("Data change backfill stopped", zap.Int("compared", compared), zap.Error(err))
+	}
+	logger.Info("Data changes backfilled", zap.Int("compared", compared))
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	prettyconsole "github.com/thessem/zap-prettyconsole"
+	"go.uber.org/zap"
+	"pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/ingestor"
+	"pixelmap.io/backend/internal/prices"
+	"pixelmap.io/backend/internal/stats"
+)
+
+// prices imports daily ETH/USD prices and refreshes the stats that use them.
+// Import history once from a CSV export, then keep it current from Chainlink
+// or an HTTP provider, e.g. daily from cron. Rerunning a range overwrites it.
+func main() {
+	sourceName := flag.String("source", "chainlink", "csv, http or chainlink")
+	file := flag.String("file", "", "CSV file for -source csv")
+	url := flag.String("url", os.Getenv("ETH_PRICE_URL"), "endpoint for -source http")
+	feed := flag.String("feed", prices.ChainlinkETHUSD.Hex(), "Chainlink ETH/USD feed proxy for -source chainlink")
+	fromFlag := flag.String("from", "", "first day, YYYY-MM-DD (default: the day after the last stored price)")
+	toFlag := flag.String("to", "", "last day, YYYY-MM-DD (default: latest available)")
+	flag.Parse()
+
+	logger := prettyconsole.NewLogger(zap.InfoLevel)
+	defer logger.Sync()
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: Could not load .env file: %v", err)
+	}
+
+	network, err := ingestor.NetworkFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid network configuration", zap.Error(err))
+	}
+
+	conn, err := db.Open(os.Getenv("DATABASE_URL"), network.Schema)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := db.Migrate(ctx, conn); err != nil {
+		logger.Fatal("Failed to migrate database", zap.Error(err))
+	}
+	queries := db.New(conn)
+
+	var source prices.Source
+	switch *sourceName {
+	case "csv":
+		if *file == "" {
+			logger.Fatal("-file is required for -source csv")
+		}
+		source = &prices.CSVSource{Path: *file}
+	case "http":
+		if *url == "" {
+			logger.Fatal("-url or ETH_PRICE_URL is required for -source http")
+		}
+		source = &prices.HTTPSource{URL: *url}
+	case "chainlink":
+		web3URL := os.Getenv("WEB3_URL")
+		if web3URL == "" {
+			logger.Fatal("WEB3_URL is required for -source chainlink")
+		}
+		client, err := ethclient.Dial(web3URL)
+		if err != nil {
+			logger.Fatal("Failed to connect to Ethereum node", zap.Error(err))
+		}
+		defer client.Close()
+		source = &prices.ChainlinkSource{Client: client, Feed: common.HexToAddress(*feed)}
+	default:
+		logger.Fatal("Unknown price source", zap.String("source", *sourceName))
+	}
+
+	var from, to time.Time
+	if *fromFlag != "" {
+		if from, err = time.Parse(time.DateOnly, *fromFlag); err != nil {
+			logger.Fatal("Invalid -from", zap.Error(err))
+		}
+	} else {
+		latest, err := queries.GetLatestEthPriceDay(ctx)
+		if err != nil {
+			logger.Fatal("Failed to get the last stored price", zap.Error(err))
+		}
+		if latest.Year() > 1 {
+			from = latest.AddDate(0, 0, 1)
+		} else if *sourceName == "chainlink" {
+			// Walking the feed back to 2020 takes tens of thousands of calls;
+			// older history should come from a CSV import.
+			from = time.Now().AddDate(0, 0, -30)
+		}
+	}
+	if *toFlag != "" {
+		if to, err = time.Parse(time.DateOnly, *toFlag); err != nil {
+			logger.Fatal("Invalid -to", zap.Error(err))
+		}
+	}
+
+	imported, err := prices.Import(ctx, queries, source, from, to)
+	if err != nil {
+		logger.Fatal("Price import failed", zap.Error(err))
+	}
+	logger.Info("Imported ETH/USD prices",
+		zap.String("source", source.Name()),
+		zap.Int("days", imported))
+
+	// stats.json is rewritten by the indexer on its next cycle.
+	if err := stats.Refresh(ctx, queries); err != nil {
+		logger.Fatal("Failed to refresh stats", zap.Error(err))
+	}
+}
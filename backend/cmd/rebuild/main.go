@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	prettyconsole "github.com/thessem/zap-prettyconsole"
+	"go.uber.org/zap"
+	"pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/ingestor"
+)
+
+// rebuild recomputes tiles, the history tables and the ingestion cursor from
+// the transaction archive alone, with no Etherscan or Ethereum node, prints
+// how the result differs from the current state and asks before committing
+// it. It holds the ingestion lock until then, so a running ingestor waits and
+// goes on from the rebuilt state; renders and stats catch up after that.
+//
+// On a database migrated through 016, events had overwritten some archived
+// calls, and calls too short to hold a selector, such as ETH sent to the
+// wrapper's fallback, were never archived. History rows from those can't be
+// replayed, and rebuild refuses to commit when it would drop any unless
+// -allow-loss is given, with -yes or not.
+func main() {
+	yes := flag.Bool("yes", false, "commit the rebuilt state without asking")
+	allowLoss := flag.Bool("allow-loss", false, "commit even when history rows the archive can't reproduce are dropped")
+	dryRun := flag.Bool("dry-run", false, "print the differences and roll back")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	logger := prettyconsole.NewLogger(zap.InfoLevel)
+	defer logger.Sync()
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: Could not load .env file: %v", err)
+	}
+
+	network, err := ingestor.NetworkFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid network configuration", zap.Error(err))
+	}
+
+	conn, err := db.Open(os.Getenv("DATABASE_URL"), network.Schema)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := db.Migrate(ctx, conn); err != nil {
+		logger.Fatal("Failed to migrate database", zap.Error(err))
+	}
+
+	opts := ingestor.RebuildOptions{AllowLoss: *allowLoss}
+	report, err := ingestor.Rebuild(ctx, logger, conn, network, opts, func(report *ingestor.RebuildReport) (bool, error) {
+		if *asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				return false, err
+			}
+		} else if err := report.WriteText(os.Stdout); err != nil {
+			return false, err
+		}
+
+		switch {
+		case *dryRun:
+			return false, nil
+		case report.Lost() > 0 && !*allowLoss:
+			return false, fmt.Errorf("%w: %d rows, rerun with -allow-loss to drop them", ingestor.ErrRebuildLosesHistory, report.Lost())
+		case *yes:
+			return true, nil
+		}
+		fmt.Fprint(os.Stderr, "Commit the rebuilt state? [y/N] ")
+		answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && answer == "" {
+			return false, nil
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		return answer == "y" || answer == "yes", nil
+	})
+	if err != nil {
+		logger.Fatal("Rebuild failed", zap.Error(err))
+	}
+
+	if report.Committed {
+		logger.Info("Rebuilt state committed", zap.Bool("changed", report.Changed()))
+	} else {
+		logger.Info("Rebuild rolled back", zap.Bool("changed", report.Changed()))
+	}
+}
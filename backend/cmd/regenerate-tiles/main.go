@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"log"
 	"os"
 	"path/filepath"
@@ -12,6 +11,7 @@ import (
 	_ "github.com/lib/pq"
 	"pixelmap.io/backend/internal/db"
 	"pixelmap.io/backend/internal/ingestor"
+	"pixelmap.io/backend/internal/traits"
 )
 
 func loadEnv() {
@@ -56,7 +56,20 @@ func main() {
 		log.Fatal("DATABASE_URL environment variable is not set. Please check your .env file")
 	}
 
-	conn, err := sql.Open("postgres", dbURL)
+	network, err := ingestor.NetworkFromEnv()
+	if err != nil {
+		log.Fatal("Invalid network configuration:", err)
+	}
+
+	if path := os.Getenv("TRAIT_RULES"); path != "" {
+		rules, err := traits.Load(path)
+		if err != nil {
+			log.Fatal("Invalid trait rules:", err)
+		}
+		ingestor.UseTraitRules(rules)
+	}
+
+	conn, err := db.Open(dbURL, network.Schema)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
@@ -90,9 +103,13 @@ func main() {
 			log.Printf("Error fetching data history for tile %d: %v", tile.ID, err)
 			continue
 		}
+		if err := ingestor.PreferTileInputs(ctx, queries, &tile, dataHistory); err != nil {
+			log.Printf("Error fetching update inputs for tile %d: %v", tile.ID, err)
+			continue
+		}
 
 		// Update metadata (this creates the JSON file)
-		if err := ingestor.UpdateTileMetadata(tile, dataHistory, queries, ctx); err != nil {
+		if err := ingestor.UpdateTileMetadata(network, tile, dataHistory, queries, ctx); err != nil {
 			log.Printf("Error updating metadata for tile %d: %v", tile.ID, err)
 			continue
 		}
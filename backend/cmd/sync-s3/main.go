@@ -50,8 +50,16 @@ func main() {
 	logger, _ := zap.NewProduction()
 	defer logger.Sync()
 
+	network, err := ingestor.NetworkFromEnv()
+	if err != nil {
+		log.Fatal("Invalid network configuration:", err)
+	}
+	if network.S3Bucket == "" {
+		log.Fatalf("Network %s has no S3 bucket configured (set S3_BUCKET)", network.Name)
+	}
+
 	// Create S3 syncer
-	s3Syncer, err := ingestor.NewS3Syncer(logger, "cache")
+	s3Syncer, err := ingestor.NewS3Syncer(logger, network.CacheDir, network.S3Bucket)
 	if err != nil {
 		log.Fatal("Failed to create S3 syncer:", err)
 	}
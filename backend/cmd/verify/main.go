@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	prettyconsole "github.com/thessem/zap-prettyconsole"
+	"go.uber.org/zap"
+	"pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/ingestor"
+	"pixelmap.io/backend/internal/verifier"
+)
+
+// verify compares every tile in Postgres against getTile/ownerOf on chain and
+// prints the differences. With -repair the chain's values are written back and
+// each correction is recorded in tile_repairs.
+func main() {
+	repair := flag.Bool("repair", false, "write on-chain values over mismatched tiles")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	block := flag.Int64("block", 0, "block to verify at (default: last ingested block)")
+	flag.Parse()
+
+	logger := prettyconsole.NewLogger(zap.InfoLevel)
+	defer logger.Sync()
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: Could not load .env file: %v", err)
+	}
+
+	network, err := ingestor.NetworkFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid network configuration", zap.Error(err))
+	}
+
+	conn, err := db.Open(os.Getenv("DATABASE_URL"), network.Schema)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer conn.Close()
+
+	ethClient, err := ethclient.Dial(os.Getenv("WEB3_URL"))
+	if err != nil {
+		logger.Fatal("Failed to connect to Ethereum node", zap.Error(err))
+	}
+	defer ethClient.Close()
+
+	v, err := verifier.New(logger, conn, network, ethClient)
+	if err != nil {
+		logger.Fatal("Failed to create verifier", zap.Error(err))
+	}
+
+	opts := verifier.Options{Repair: *repair}
+	if *block > 0 {
+		opts.Block = big.NewInt(*block)
+	}
+
+	report, err := v.Verify(context.Background(), opts)
+	if err != nil {
+		logger.Fatal("Verification failed", zap.Error(err))
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		logger.Fatal("Failed to write report", zap.Error(err))
+	}
+
+	if len(report.Mismatches) > 0 && !*repair {
+		os.Exit(1)
+	}
+}
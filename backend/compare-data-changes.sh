@@ -0,0 +1,10 @@
+#!/bin/bash
+
+# Load environment variables from .env file
+if [ -f .env ]; then
+  export $(cat .env | grep -v '^#' | xargs)
+fi
+
+# Fill in the previous values, change type and pixel diff of tile updates
+# stored before they were recorded. Needs no Etherscan key. Rerun to resume.
+go run cmd/compare-data-changes/main.go "$@"
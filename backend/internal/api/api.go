@@ -0,0 +1,257 @@
+// Package api serves the indexed tiles and their history over HTTP. The
+// GraphQL endpoint is read-only and bounded: every query is checked against
+// depth and complexity limits before it runs, and nested history is fetched
+// in batches rather than once per tile.
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"go.uber.org/zap"
+	db "pixelmap.io/backend/internal/db"
+	portfolio "pixelmap.io/backend/internal/portfolio"
+	proceeds "pixelmap.io/backend/internal/proceeds"
+	stats "pixelmap.io/backend/internal/stats"
+)
+
+const maxRequestBytes = 1 << 20
+
+// Options configures a Server. Zero values use DefaultLimits and only look
+// up ENS names that the indexer has already cached on tiles.
+type Options struct {
+	Limits Limits
+	// ResolveENS maps a name to an address when no tile carries it, e.g.
+	// for an owner whose reverse record has not been refreshed yet.
+	ResolveENS func(ctx context.Context, name string) (string, error)
+}
+
+type Server struct {
+	logger     *zap.Logger
+	queries    *db.Queries
+	schema     graphql.Schema
+	limits     Limits
+	resolveENS func(ctx context.Context, name string) (string, error)
+}
+
+func New(logger *zap.Logger, queries *db.Queries, opts Options) (*Server, error) {
+	s := &Server{
+		logger:     logger,
+		queries:    queries,
+		limits:     opts.Limits.withDefaults(),
+		resolveENS: opts.ResolveENS,
+	}
+
+	schema, err := s.newSchema()
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+	return s, nil
+}
+
+// Handler routes the API endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", s.serveGraphQL)
+	mux.HandleFunc("GET /api/owner/{owner}", s.serveOwner)
+	mux.HandleFunc("GET /api/owner/{owner}/proceeds", s.serveOwnerProceeds)
+	mux.HandleFunc("GET /api/tiles/{id}/proceeds", s.serveTileProceeds)
+	mux.HandleFunc("GET /api/stats", s.serveStats)
+	return mux
+}
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+func (s *Server) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphQLRequest
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				writeErrors(w, http.StatusBadRequest, errors.New("variables must be a JSON object"))
+				return
+			}
+		}
+	case http.MethodPost:
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBytes+1))
+		if err != nil {
+			writeErrors(w, http.StatusBadRequest, err)
+			return
+		}
+		if len(body) > maxRequestBytes {
+			writeErrors(w, http.StatusRequestEntityTooLarge, errors.New("request body too large"))
+			return
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeErrors(w, http.StatusBadRequest, errors.New("request body must be a JSON object with a query"))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeErrors(w, http.StatusMethodNotAllowed, errors.New("use GET or POST"))
+		return
+	}
+
+	if req.Query == "" {
+		writeErrors(w, http.StatusBadRequest, errors.New("missing query"))
+		return
+	}
+
+	start := time.Now()
+	result, status := s.execute(r.Context(), req)
+	s.logger.Debug("Served GraphQL query",
+		zap.String("operation", req.OperationName),
+		zap.Int("status", status),
+		zap.Int("errors", len(result.Errors)),
+		zap.Duration("duration", time.Since(start)))
+
+	writeJSON(w, status, result)
+}
+
+// serveOwner returns the portfolio of an address or ENS name: the tiles it
+// holds and every tile it has bought or been sent.
+func (s *Server) serveOwner(w http.ResponseWriter, r *http.Request) {
+	address, ok := s.ownerAddress(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := portfolio.Load(r.Context(), s.queries, address)
+	if err != nil {
+		s.logger.Error("Failed to load portfolio", zap.String("address", address), zap.Error(err))
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// serveOwnerProceeds returns what an address or ENS name has listed and sold
+// through the wrapper, and the proceeds it can still withdraw.
+func (s *Server) serveOwnerProceeds(w http.ResponseWriter, r *http.Request) {
+	address, ok := s.ownerAddress(w, r)
+	if !ok {
+		return
+	}
+
+	seller, err := proceeds.LoadSeller(r.Context(), s.queries, address)
+	if err != nil {
+		s.logger.Error("Failed to load wrapper proceeds", zap.String("address", address), zap.Error(err))
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, seller)
+}
+
+// serveTileProceeds returns the wrapper's listing of a tile, what is owed
+// from it and the history behind that.
+func (s *Server) serveTileProceeds(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 32)
+	if err == nil {
+		_, err = s.queries.GetTileById(r.Context(), int32(id))
+	}
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, strconv.ErrSyntax) || errors.Is(err, strconv.ErrRange) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown tile"})
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to get tile", zap.Int64("tile", id), zap.Error(err))
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return
+	}
+
+	tile, err := proceeds.LoadTile(r.Context(), s.queries, int32(id))
+	if err != nil {
+		s.logger.Error("Failed to load wrapper proceeds", zap.Int64("tile", id), zap.Error(err))
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, tile)
+}
+
+// ownerAddress resolves the {owner} path value, an address or ENS name. It
+// writes the error response itself when there is no address.
+func (s *Server) ownerAddress(w http.ResponseWriter, r *http.Request) (string, bool) {
+	address := r.PathValue("owner")
+	if common.IsHexAddress(address) {
+		return address, true
+	}
+
+	address, err := s.lookupENS(r.Context(), address)
+	if err != nil {
+		s.logger.Error("Failed to look up ENS name", zap.String("name", r.PathValue("owner")), zap.Error(err))
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return "", false
+	}
+	if address == "" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown owner"})
+		return "", false
+	}
+	return address, true
+}
+
+// serveStats returns the marketplace stats as of the last ingestion cycle.
+func (s *Server) serveStats(w http.ResponseWriter, r *http.Request) {
+	marketStats, err := stats.Load(r.Context(), s.queries)
+	if err != nil {
+		s.logger.Error("Failed to load stats", zap.Error(err))
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, marketStats)
+}
+
+// execute parses, validates and limits the query before running it, so a
+// query that is too expensive never touches the database.
+func (s *Server) execute(ctx context.Context, req graphQLRequest) (*graphql.Result, int) {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, http.StatusBadRequest
+	}
+
+	validation := graphql.ValidateDocument(&s.schema, doc, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}, http.StatusBadRequest
+	}
+
+	if err := s.limits.check(&s.schema, doc, req.OperationName, req.Variables); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, http.StatusBadRequest
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(ctx, s.queries),
+	}), http.StatusOK
+}
+
+func writeErrors(w http.ResponseWriter, status int, errs ...error) {
+	writeJSON(w, status, &graphql.Result{Errors: gqlerrors.FormatErrors(errs...)})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,632 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	db "pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/db/dbtest"
+	"pixelmap.io/backend/internal/portfolio"
+	"pixelmap.io/backend/internal/proceeds"
+	"pixelmap.io/backend/internal/stats"
+)
+
+const (
+	alice = "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	bob   = "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func post(t *testing.T, handler http.Handler, query string, variables map[string]interface{}) (int, graphQLResponse) {
+	t.Helper()
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+
+	var resp graphQLResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp), rec.Body.String())
+	return rec.Code, resp
+}
+
+func newTestServer(t *testing.T, queries *db.Queries, limits Limits) http.Handler {
+	t.Helper()
+	server, err := New(zap.NewNop(), queries, Options{Limits: limits})
+	require.NoError(t, err)
+	return server.Handler()
+}
+
+func TestLimitsRejectDeepQueries(t *testing.T) {
+	handler := newTestServer(t, nil, Limits{MaxDepth: 4})
+
+	code, resp := post(t, handler, `{ sales { nodes { tile { purchases { tile { id } } } } } }`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Message, "query depth 6 exceeds the limit of 4")
+}
+
+func measure(t *testing.T, query string, variables map[string]interface{}) (cost, depth int) {
+	t.Helper()
+	server, err := New(zap.NewNop(), nil, Options{})
+	require.NoError(t, err)
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	require.NoError(t, err)
+
+	m := &measurer{limits: server.limits, schema: &server.schema, fragments: map[string]*ast.FragmentDefinition{}, variables: variables}
+	for _, def := range doc.Definitions {
+		if fragment, ok := def.(*ast.FragmentDefinition); ok {
+			m.fragments[fragment.Name.Value] = fragment
+		}
+	}
+	operation := doc.Definitions[0].(*ast.OperationDefinition)
+	return m.selectionSet(operation.SelectionSet, server.schema.QueryType())
+}
+
+func TestComplexity(t *testing.T) {
+	// tile (1) + id (1) + images (1 + 3 × image)
+	cost, depth := measure(t, `{ tile(id: 1) { id images(last: 3) { image } } }`, nil)
+	assert.Equal(t, 6, cost)
+	assert.Equal(t, 3, depth)
+
+	// tiles (1 + 100 × nodes (1 + id + images (1 + 10 × image)))
+	query := `
+		query Tiles($first: Int) { tiles(first: $first) { nodes { ...TileImages } } }
+		fragment TileImages on Tile { id images { image } }`
+	cost, _ = measure(t, query, map[string]interface{}{"first": float64(100)})
+	assert.Equal(t, 1301, cost)
+
+	// Page sizes above the cap are rejected by the resolver, so they are
+	// counted at the cap; the default page size applies when first is absent.
+	cost, _ = measure(t, `{ sales(first: 5000) { edges { cursor } } }`, nil)
+	assert.Equal(t, 1+100*2, cost)
+	cost, _ = measure(t, `{ sales { pageInfo { hasNextPage } } }`, nil)
+	assert.Equal(t, 1+defaultPageSize*2, cost)
+
+	cost, _ = measure(t, `{ __schema { types { name fields { name } } } }`, nil)
+	assert.Zero(t, cost, "introspection is free")
+}
+
+func TestLimitsRejectComplexQueries(t *testing.T) {
+	handler := newTestServer(t, nil, Limits{MaxComplexity: 1000})
+
+	query := `query Tiles($first: Int) { tiles(first: $first) { nodes { id images { image } } } }`
+	code, resp := post(t, handler, query, map[string]interface{}{"first": 100})
+	assert.Equal(t, http.StatusBadRequest, code)
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Message, "query complexity 1301 exceeds the limit of 1000")
+}
+
+func TestRejectsBadRequests(t *testing.T) {
+	handler := newTestServer(t, nil, Limits{})
+
+	code, resp := post(t, handler, `{ nope }`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, resp.Errors[0].Message, `Cannot query field "nope"`)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/graphql", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	code, _ = post(t, handler, "", nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := encodeCursor("tile", 42)
+	id, err := decodeCursor("tile", cursor)
+	require.NoError(t, err)
+	assert.Equal(t, int32(42), id)
+
+	_, err = decodeCursor("sale", cursor)
+	assert.Error(t, err, "cursors are tied to their connection")
+	_, err = decodeCursor("tile", "not a cursor")
+	assert.Error(t, err)
+}
+
+func TestBatchLoaderFetchesPendingIDsOnce(t *testing.T) {
+	var calls [][]int32
+	loader := newBatchLoader(func(_ context.Context, ids []int32) ([]db.DataHistory, error) {
+		calls = append(calls, append([]int32(nil), ids...))
+		return []db.DataHistory{{ID: 3, TileID: 2}, {ID: 1, TileID: 1}, {ID: 2, TileID: 2}}, nil
+	}, func(h db.DataHistory) int32 { return h.TileID })
+
+	ctx := context.Background()
+	first, second, repeat, empty := loader.load(ctx, 1), loader.load(ctx, 2), loader.load(ctx, 2), loader.load(ctx, 9)
+
+	rows, err := second()
+	require.NoError(t, err)
+	assert.Equal(t, []int32{3, 2}, []int32{rows[0].ID, rows[1].ID}, "order from the query is kept")
+	rows, err = first()
+	require.NoError(t, err)
+	assert.Len(t, rows, 1)
+	rows, err = repeat()
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+	rows, err = empty()
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+
+	assert.Equal(t, [][]int32{{1, 2, 9}}, calls)
+}
+
+func TestBatchLoaderSharesErrors(t *testing.T) {
+	fetchErr := errors.New("boom")
+	loader := newBatchLoader(func(context.Context, []int32) ([]db.Tile, error) {
+		return nil, fetchErr
+	}, func(t db.Tile) int32 { return t.ID })
+
+	first, second := loader.load(context.Background(), 1), loader.load(context.Background(), 2)
+	_, err := first()
+	assert.ErrorIs(t, err, fetchErr)
+	_, err = second()
+	assert.ErrorIs(t, err, fetchErr)
+}
+
+// countingDB counts the queries sent to the database.
+type countingDB struct {
+	*sql.DB
+	queries atomic.Int32
+}
+
+func (c *countingDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	c.queries.Add(1)
+	return c.DB.QueryContext(ctx, query, args...)
+}
+
+func (c *countingDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	c.queries.Add(1)
+	return c.DB.QueryRowContext(ctx, query, args...)
+}
+
+func seed(t *testing.T, conn *sql.DB) {
+	t.Helper()
+	ctx := context.Background()
+	queries := db.New(conn)
+	base := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for id := int32(1); id <= 4; id++ {
+		owner, name := alice, "alice.eth"
+		if id == 4 {
+			owner, name = bob, ""
+		}
+		_, err := queries.InsertTile(ctx, db.InsertTileParams{ID: id, Owner: owner, Ens: name, Price: "1000000000000000000", Wrapped: id == 2})
+		require.NoError(t, err)
+
+		for n := int64(1); n <= 3; n++ {
+			_, err := queries.InsertDataHistory(ctx, db.InsertDataHistoryParams{
+				TimeStamp:   base.Add(time.Duration(n) * time.Hour),
+				BlockNumber: 100*int64(id) + n,
+				Tx:          fmt.Sprintf("0x%d%d", id, n),
+				Image:       fmt.Sprintf("image-%d-%d", id, n),
+				UpdatedBy:   owner,
+				TileID:      id,
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	for n, price := range []string{"500000000000000000", "2000000000000000000", "3250000000000000000"} {
+		_, err := queries.InsertPurchaseHistory(ctx, db.InsertPurchaseHistoryParams{
+			TileID:      int32(n + 1),
+			SoldBy:      bob,
+			PurchasedBy: alice,
+			Price:       price,
+			Tx:          fmt.Sprintf("0xsale%d", n),
+			TimeStamp:   base.AddDate(0, n, 0),
+			BlockNumber: int64(1000 + n),
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestOwnerTilesWithLatestImages(t *testing.T) {
+	conn := dbtest.Open(t)
+	seed(t, conn)
+	counter := &countingDB{DB: conn}
+	handler := newTestServer(t, db.New(counter), Limits{})
+
+	query := `{
+		owner(ens: "ALICE.eth") {
+			address
+			tiles(first: 2) {
+				nodes { id wrapped images(last: 2) { image blockNumber tile { id } } }
+				pageInfo { hasNextPage endCursor }
+			}
+		}
+	}`
+	code, resp := post(t, handler, query, nil)
+	require.Equal(t, http.StatusOK, code, resp.Errors)
+	require.Empty(t, resp.Errors)
+
+	var data struct {
+		Owner struct {
+			Address string
+			Tiles   struct {
+				Nodes []struct {
+					ID      int
+					Wrapped bool
+					Images  []struct {
+						Image       string
+						BlockNumber int
+						Tile        struct{ ID int }
+					}
+				}
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   string
+				}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(resp.Data, &data))
+	assert.Equal(t, alice, data.Owner.Address)
+	tiles := data.Owner.Tiles
+	require.Len(t, tiles.Nodes, 2)
+	assert.Equal(t, 1, tiles.Nodes[0].ID)
+	assert.True(t, tiles.Nodes[1].Wrapped)
+	require.Len(t, tiles.Nodes[1].Images, 2)
+	assert.Equal(t, "image-2-3", tiles.Nodes[1].Images[0].Image, "newest first")
+	assert.Equal(t, 203, tiles.Nodes[1].Images[0].BlockNumber)
+	assert.Equal(t, 2, tiles.Nodes[1].Images[1].Tile.ID)
+	assert.True(t, tiles.PageInfo.HasNextPage)
+
+	// ENS lookup, the tile page, one batch of images and one batch of tiles.
+	assert.Equal(t, int32(4), counter.queries.Load())
+
+	_, resp = post(t, handler, `query($after: String) { tiles(first: 2, after: $after, filter: {owner: "0x`+strings.ToUpper(alice[2:])+`"}) { nodes { id } pageInfo { hasNextPage } } }`,
+		map[string]interface{}{"after": tiles.PageInfo.EndCursor})
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"tiles": {"nodes": [{"id": 3}], "pageInfo": {"hasNextPage": false}}}`, string(resp.Data))
+}
+
+func TestOwnerPortfolio(t *testing.T) {
+	conn := dbtest.Open(t)
+	seed(t, conn)
+	handler := newTestServer(t, db.New(conn), Limits{})
+
+	get := func(path string) (int, portfolio.Portfolio) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var p portfolio.Portfolio
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		}
+		return w.Code, p
+	}
+
+	code, p := get("/api/owner/alice.eth")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, alice, p.Address)
+	assert.Len(t, p.Tiles, 3)
+	assert.Equal(t, 1, p.WrappedCount)
+	assert.Equal(t, 2, p.UnwrappedCount)
+	require.Len(t, p.Acquisitions, 3)
+	assert.Equal(t, "0xsale2", p.Acquisitions[0].Tx)
+	assert.Equal(t, "5.75", p.TotalSpent)
+
+	code, upper := get("/api/owner/0x" + strings.ToUpper(alice[2:]))
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, p, upper, "addresses match in any case")
+
+	code, p = get("/api/owner/" + bob)
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, p.Tiles, 1)
+	assert.Empty(t, p.Acquisitions)
+	assert.Equal(t, "0", p.TotalSpent)
+
+	code, _ = get("/api/owner/nobody.eth")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestOwnerTimeCapsules(t *testing.T) {
+	conn := dbtest.Open(t)
+	seed(t, conn)
+	queries := db.New(conn)
+	handler := newTestServer(t, queries, Limits{})
+
+	zero := "0x0000000000000000000000000000000000000000"
+	at := time.Date(2021, 10, 22, 0, 0, 0, 0, time.UTC)
+	for n, transfer := range []db.InsertTimeCapsuleTransferParams{
+		{TokenID: 1, TransferredFrom: zero, TransferredTo: alice, Tx: "0xmint"},
+		{TokenID: 2, TransferredFrom: zero, TransferredTo: alice, Tx: "0xmint"},
+		{TokenID: 1, TransferredFrom: alice, TransferredTo: bob, Tx: "0xgift"},
+	} {
+		transfer.LogIndex = int32(n)
+		transfer.TimeStamp = at.Add(time.Duration(n) * time.Hour)
+		transfer.BlockNumber = int64(3000 + n)
+		require.NoError(t, queries.InsertTimeCapsuleTransfer(context.Background(), transfer))
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/owner/"+bob, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var p portfolio.Portfolio
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Len(t, p.Tiles, 1, "capsules sit beside tiles")
+	assert.Equal(t, []portfolio.TimeCapsule{{TokenID: 1, Tx: "0xgift", BlockNumber: 3002, Timestamp: at.Add(2 * time.Hour)}}, p.TimeCapsules)
+
+	code, resp := post(t, handler, `{ owner(ens: "alice.eth") { timeCapsules { tokenId owner tx } } }`, nil)
+	require.Equal(t, http.StatusOK, code, resp.Errors)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"owner": {"timeCapsules": [{"tokenId": 2, "owner": "`+alice+`", "tx": "0xmint"}]}}`, string(resp.Data))
+}
+
+func TestWrapperProceeds(t *testing.T) {
+	conn := dbtest.Open(t)
+	seed(t, conn)
+	queries := db.New(conn)
+	handler := newTestServer(t, queries, Limits{})
+
+	at := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	for n, event := range []db.InsertWrapperProceedsHistoryParams{
+		{Event: proceeds.Listed, Seller: alice, Amount: "1250000000000000000", TileID: 2},
+		{Event: proceeds.Sold, Seller: alice, Buyer: sql.NullString{String: bob, Valid: true}, Amount: "1250000000000000000", TileID: 2},
+		{Event: proceeds.Listed, Seller: alice, Amount: "2000000000000000", TileID: 3},
+	} {
+		event.Tx = fmt.Sprintf("0xwrapper%d", n)
+		event.TimeStamp = at.Add(time.Duration(n) * time.Hour)
+		event.BlockNumber = int64(2000 + n)
+		require.NoError(t, queries.InsertWrapperProceedsHistory(context.Background(), event))
+	}
+
+	get := func(path string, v interface{}) int {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), v))
+		}
+		return w.Code
+	}
+
+	var tile proceeds.Tile
+	require.Equal(t, http.StatusOK, get("/api/tiles/2/proceeds", &tile))
+	require.NotNil(t, tile.Pending)
+	assert.Equal(t, bob, tile.Pending.Buyer)
+	assert.Equal(t, "1.25", tile.Pending.Withdrawable)
+	require.Len(t, tile.History, 2)
+	assert.Equal(t, proceeds.Sold, tile.History[0].Event, "newest first")
+
+	var unlisted proceeds.Tile
+	require.Equal(t, http.StatusOK, get("/api/tiles/4/proceeds", &unlisted))
+	assert.Nil(t, unlisted.Pending)
+	assert.Empty(t, unlisted.History)
+
+	var seller proceeds.Seller
+	require.Equal(t, http.StatusOK, get("/api/owner/alice.eth/proceeds", &seller))
+	assert.Equal(t, "1.25", seller.Withdrawable, "the unsold listing is not owed yet")
+	assert.Len(t, seller.Sales, 2)
+	assert.Len(t, seller.History, 3)
+
+	assert.Equal(t, http.StatusNotFound, get("/api/tiles/99999/proceeds", nil))
+	assert.Equal(t, http.StatusNotFound, get("/api/tiles/nine/proceeds", nil))
+	assert.Equal(t, http.StatusNotFound, get("/api/owner/nobody.eth/proceeds", nil))
+}
+
+func TestMarketStats(t *testing.T) {
+	conn := dbtest.Open(t)
+	seed(t, conn)
+	queries := db.New(conn)
+	require.NoError(t, stats.Refresh(context.Background(), queries))
+	handler := newTestServer(t, queries, Limits{})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/stats", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var s stats.Stats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &s))
+
+	assert.Equal(t, stats.Listings{ListedTiles: 3, FloorPrice: "1"}, s.Listings, "the wrapped tile is not listed")
+	all := s.Markets[stats.MarketAll]
+	assert.Equal(t, stats.Aggregate{Sales: 3, Volume: "5.75", UniqueBuyers: 1, AveragePrice: "1.916666666666666667", MedianPrice: "2", MinPrice: "0.5", MaxPrice: "3.25"}, all.Totals, "no USD volume without prices")
+	require.Len(t, all.Monthly, 3)
+	assert.Equal(t, "2021-04", all.Monthly[1].Period)
+	assert.Equal(t, all.Totals, s.Markets[stats.MarketOriginal].Totals)
+	assert.Zero(t, s.Markets[stats.MarketWrapper].Totals.Sales)
+}
+
+func TestSaleUSDPrices(t *testing.T) {
+	conn := dbtest.Open(t)
+	seed(t, conn)
+	queries := db.New(conn)
+	require.NoError(t, queries.UpsertEthPrice(context.Background(), db.UpsertEthPriceParams{
+		Day:      time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC),
+		PriceUsd: "1919.52",
+		Source:   "csv",
+	}))
+	handler := newTestServer(t, queries, Limits{})
+
+	_, resp := post(t, handler, `{ sales { nodes { tileId ethPriceUsd salePriceUsd } } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"sales": {"nodes": [
+		{"tileId": 1, "ethPriceUsd": null, "salePriceUsd": null},
+		{"tileId": 2, "ethPriceUsd": "1919.52", "salePriceUsd": "3839.04"},
+		{"tileId": 3, "ethPriceUsd": null, "salePriceUsd": null}
+	]}}`, string(resp.Data))
+
+	require.NoError(t, stats.Refresh(context.Background(), queries))
+	s, err := stats.Load(context.Background(), queries)
+	require.NoError(t, err)
+	totals := s.Markets[stats.MarketAll].Totals
+	assert.Equal(t, "3839.04", totals.VolumeUSD)
+	assert.Equal(t, 1, totals.PricedSales)
+}
+
+func TestSalePayments(t *testing.T) {
+	conn := dbtest.Open(t)
+	seed(t, conn)
+	queries := db.New(conn)
+	require.NoError(t, queries.UpdatePurchasePayment(context.Background(), db.UpdatePurchasePaymentParams{
+		TileID:        2,
+		Tx:            "0xsale1",
+		AskingPrice:   sql.NullString{String: "1000000000000000000", Valid: true},
+		SellerPayout:  sql.NullString{String: "2000000000000000000", Valid: true},
+		CreatorFee:    sql.NullString{String: "0", Valid: true},
+		PriceMismatch: true,
+	}))
+	handler := newTestServer(t, queries, Limits{})
+
+	_, resp := post(t, handler, `{ sales(first: 2) { nodes { tileId priceWei askingPriceWei sellerPayoutWei creatorFeeWei priceMismatch } } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"sales": {"nodes": [
+		{"tileId": 1, "priceWei": "500000000000000000", "askingPriceWei": null, "sellerPayoutWei": null, "creatorFeeWei": null, "priceMismatch": false},
+		{"tileId": 2, "priceWei": "2000000000000000000", "askingPriceWei": "1000000000000000000", "sellerPayoutWei": "2000000000000000000", "creatorFeeWei": "0", "priceMismatch": true}
+	]}}`, string(resp.Data))
+}
+
+func TestImageChangeRecords(t *testing.T) {
+	conn := dbtest.Open(t)
+	seed(t, conn)
+	queries := db.New(conn)
+	_, err := queries.InsertDataHistory(context.Background(), db.InsertDataHistoryParams{
+		TimeStamp:     time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC),
+		BlockNumber:   104,
+		Tx:            "0x14",
+		Image:         "image-1-4",
+		Url:           "https://example.com",
+		UpdatedBy:     alice,
+		TileID:        1,
+		ChangeType:    sql.NullString{String: "multiple", Valid: true},
+		PreviousImage: sql.NullString{String: "image-1-3", Valid: true},
+		PreviousUrl:   sql.NullString{String: "", Valid: true},
+		PixelsChanged: sql.NullInt32{Int32: 4, Valid: true},
+		DiffMinX:      sql.NullInt32{Int32: 2, Valid: true},
+		DiffMinY:      sql.NullInt32{Int32: 5, Valid: true},
+		DiffMaxX:      sql.NullInt32{Int32: 3, Valid: true},
+		DiffMaxY:      sql.NullInt32{Int32: 6, Valid: true},
+	})
+	require.NoError(t, err)
+	handler := newTestServer(t, queries, Limits{})
+
+	_, resp := post(t, handler, `{ tile(id: 1) { images(last: 2) {
+		tx changeType previousImage previousUrl previousPriceWei pixelsChanged diffBounds { minX minY maxX maxY }
+	} } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"tile": {"images": [
+		{"tx": "0x14", "changeType": "multiple", "previousImage": "image-1-3", "previousUrl": "", "previousPriceWei": null,
+		 "pixelsChanged": 4, "diffBounds": {"minX": 2, "minY": 5, "maxX": 3, "maxY": 6}},
+		{"tx": "0x13", "changeType": null, "previousImage": null, "previousUrl": null, "previousPriceWei": null,
+		 "pixelsChanged": null, "diffBounds": null}
+	]}}`, string(resp.Data))
+}
+
+func TestTransferTypes(t *testing.T) {
+	conn := dbtest.Open(t)
+	seed(t, conn)
+	queries := db.New(conn)
+	at := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+	for n, transfer := range []db.InsertTransferHistoryParams{
+		{TransferredFrom: "0x0000000000000000000000000000000000000000", TransferredTo: alice, Tx: "0xwrap", TransferType: sql.NullString{String: "wrap", Valid: true}},
+		{TransferredFrom: alice, TransferredTo: bob, Tx: "0xsale", TransferType: sql.NullString{String: "sale", Valid: true}},
+		{TransferredFrom: bob, TransferredTo: alice, Tx: "0xunknown"},
+	} {
+		transfer.TileID = 2
+		transfer.IsWrapperContract = true
+		transfer.TimeStamp = at
+		transfer.BlockNumber = int64(5000 + n)
+		_, err := queries.InsertTransferHistory(context.Background(), transfer)
+		require.NoError(t, err)
+	}
+	handler := newTestServer(t, queries, Limits{})
+
+	_, resp := post(t, handler, `{ transfers { nodes { tx transferType isWrapperContract } } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"transfers": {"nodes": [
+		{"tx": "0xwrap", "transferType": "wrap", "isWrapperContract": true},
+		{"tx": "0xsale", "transferType": "sale", "isWrapperContract": true},
+		{"tx": "0xunknown", "transferType": null, "isWrapperContract": true}
+	]}}`, string(resp.Data))
+
+	_, resp = post(t, handler, `{ transfers(filter: {type: "sale"}) { nodes { tx } } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"transfers": {"nodes": [{"tx": "0xsale"}]}}`, string(resp.Data))
+}
+
+func TestWrappedTileOperators(t *testing.T) {
+	conn := dbtest.Open(t)
+	seed(t, conn)
+	queries := db.New(conn)
+	ctx := context.Background()
+	const marketplace = "0x1e0049783f008a0085193e00003d00cd54003c71"
+	at := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, queries.InsertWrapperApproval(ctx, db.InsertWrapperApprovalParams{
+		TimeStamp: at, BlockNumber: 4000, Tx: "0xapprove", TileID: 2, Approved: bob, ApprovedBy: alice,
+	}))
+	for n, approved := range []bool{true, false, true} {
+		require.NoError(t, queries.InsertWrapperOperatorApproval(ctx, db.InsertWrapperOperatorApprovalParams{
+			TimeStamp: at, BlockNumber: int64(4001 + n), Tx: fmt.Sprintf("0xall%d", n), Owner: alice, Operator: marketplace, Approved: approved,
+		}))
+	}
+	require.NoError(t, queries.InsertContractAdminChange(ctx, db.InsertContractAdminChangeParams{
+		TimeStamp: at, BlockNumber: 4010, Tx: "0xext", Contract: "wrapper", Action: "setTokenExtension", Value: sql.NullString{String: ".json", Valid: true}, ChangedBy: alice,
+	}))
+	require.NoError(t, queries.InsertTimeCapsuleMetadataChange(ctx, db.InsertTimeCapsuleMetadataChangeParams{
+		TimeStamp: at, BlockNumber: 4011, Tx: "0xlock", Action: "lockMetadata", UpdatedBy: alice,
+	}))
+	handler := newTestServer(t, queries, Limits{})
+
+	_, resp := post(t, handler, `{ tiles(first: 2) { nodes { id operators { operator kind tx } } } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"tiles": {"nodes": [
+		{"id": 1, "operators": []},
+		{"id": 2, "operators": [
+			{"operator": "`+marketplace+`", "kind": "operator", "tx": "0xall2"},
+			{"operator": "`+bob+`", "kind": "token", "tx": "0xapprove"}
+		]}
+	]}}`, string(resp.Data), "only the wrapped tile can be moved by others")
+
+	_, resp = post(t, handler, `{ owner(address: "`+alice+`") { operatorApprovals { operator blockNumber } } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"owner": {"operatorApprovals": [{"operator": "`+marketplace+`", "blockNumber": 4003}]}}`, string(resp.Data))
+
+	_, resp = post(t, handler, `{ governanceLog { contract action value } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"governanceLog": [
+		{"contract": "timecapsule", "action": "lockMetadata", "value": null},
+		{"contract": "wrapper", "action": "setTokenExtension", "value": ".json"}
+	]}`, string(resp.Data))
+}
+
+func TestSalesFilters(t *testing.T) {
+	conn := dbtest.Open(t)
+	seed(t, conn)
+	handler := newTestServer(t, db.New(conn), Limits{})
+
+	_, resp := post(t, handler, `{ sales(filter: {minPrice: "1", since: "2021-04-01"}) { nodes { tileId price priceWei buyer tile { id } } } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"sales": {"nodes": [
+		{"tileId": 2, "price": "2", "priceWei": "2000000000000000000", "buyer": "`+alice+`", "tile": {"id": 2}},
+		{"tileId": 3, "price": "3.25", "priceWei": "3250000000000000000", "buyer": "`+alice+`", "tile": {"id": 3}}
+	]}}`, string(resp.Data))
+
+	_, resp = post(t, handler, `{ sales(filter: {minPrice: "lots"}) { nodes { id } } }`, nil)
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Message, `invalid price "lots"`)
+
+	_, resp = post(t, handler, `{ sales(first: 500) { nodes { id } } }`, nil)
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Message, "first must be between 1 and 100")
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Limits bound what a single query may ask for.
+type Limits struct {
+	// MaxDepth is the deepest field nesting allowed, counting every field
+	// including connection wrappers such as edges and node.
+	MaxDepth int
+	// MaxComplexity caps the estimated number of resolved fields. Each field
+	// costs one, and the cost of a paged field's children is multiplied by
+	// the number of items it may return.
+	MaxComplexity int
+	// MaxPageSize caps first and last on every list argument.
+	MaxPageSize int
+}
+
+var DefaultLimits = Limits{
+	MaxDepth:      10,
+	MaxComplexity: 20000,
+	MaxPageSize:   100,
+}
+
+func (l Limits) withDefaults() Limits {
+	if l.MaxDepth <= 0 {
+		l.MaxDepth = DefaultLimits.MaxDepth
+	}
+	if l.MaxComplexity <= 0 {
+		l.MaxComplexity = DefaultLimits.MaxComplexity
+	}
+	if l.MaxPageSize <= 0 {
+		l.MaxPageSize = DefaultLimits.MaxPageSize
+	}
+	return l
+}
+
+// check measures the operation that will run. It expects a document that has
+// already passed validation, so fragments are known and acyclic.
+func (l Limits) check(schema *graphql.Schema, doc *ast.Document, operationName string, variables map[string]interface{}) error {
+	var operation *ast.OperationDefinition
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		case *ast.FragmentDefinition:
+			fragments[def.Name.Value] = def
+		}
+	}
+	if operation == nil {
+		return fmt.Errorf("unknown operation %q", operationName)
+	}
+	if operation.Operation != ast.OperationTypeQuery {
+		return fmt.Errorf("only queries are supported")
+	}
+
+	m := &measurer{limits: l, schema: schema, fragments: fragments, variables: variables}
+	cost, depth := m.selectionSet(operation.SelectionSet, schema.QueryType())
+	if depth > l.MaxDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", depth, l.MaxDepth)
+	}
+	if cost > l.MaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", cost, l.MaxComplexity)
+	}
+	return nil
+}
+
+type measurer struct {
+	limits    Limits
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+func (m *measurer) selectionSet(set *ast.SelectionSet, parent graphql.Type) (cost, depth int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	for _, selection := range set.Selections {
+		var c, d int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			c, d = m.field(selection, parent)
+		case *ast.InlineFragment:
+			typ := parent
+			if selection.TypeCondition != nil {
+				typ = m.schema.Type(selection.TypeCondition.Name.Value)
+			}
+			c, d = m.selectionSet(selection.SelectionSet, typ)
+		case *ast.FragmentSpread:
+			if fragment, ok := m.fragments[selection.Name.Value]; ok {
+				c, d = m.selectionSet(fragment.SelectionSet, m.schema.Type(fragment.TypeCondition.Name.Value))
+			}
+		}
+		cost += c
+		if d > depth {
+			depth = d
+		}
+	}
+	return cost, depth
+}
+
+func (m *measurer) field(field *ast.Field, parent graphql.Type) (cost, depth int) {
+	name := field.Name.Value
+	// Introspection is answered from the schema without touching the database.
+	if strings.HasPrefix(name, "__") {
+		return 0, 0
+	}
+
+	object, ok := parent.(*graphql.Object)
+	if !ok {
+		return 1, 1
+	}
+	def, ok := object.Fields()[name]
+	if !ok {
+		return 1, 1
+	}
+
+	childCost, childDepth := m.selectionSet(field.SelectionSet, namedType(def.Type))
+	if size, paged := m.pageSize(field, def); paged {
+		childCost *= size
+	}
+	return 1 + childCost, 1 + childDepth
+}
+
+// pageSize is the number of items a paged field may return: its first or last
+// argument, or the argument's default, capped at MaxPageSize. Fields without
+// either argument, such as a connection's edges, are not paged themselves.
+func (m *measurer) pageSize(field *ast.Field, def *graphql.FieldDefinition) (int, bool) {
+	size, paged := m.limits.MaxPageSize, false
+	for _, arg := range def.Args {
+		if arg.Name() == "first" || arg.Name() == "last" {
+			paged = true
+			if n, ok := arg.DefaultValue.(int); ok {
+				size = n
+			}
+		}
+	}
+	if !paged {
+		return 0, false
+	}
+
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" && arg.Name.Value != "last" {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil {
+				size = n
+			}
+		case *ast.Variable:
+			if n, ok := intVariable(m.variables[value.Name.Value]); ok {
+				size = n
+			}
+		}
+	}
+
+	if size < 1 {
+		size = 1
+	}
+	if size > m.limits.MaxPageSize {
+		size = m.limits.MaxPageSize
+	}
+	return size, true
+}
+
+func namedType(t graphql.Type) graphql.Type {
+	for {
+		switch typ := t.(type) {
+		case *graphql.NonNull:
+			t = typ.OfType
+		case *graphql.List:
+			t = typ.OfType
+		default:
+			return t
+		}
+	}
+}
+
+// intVariable reads a decoded JSON number.
+func intVariable(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int(n), true
+	case int:
+		return n, true
+	}
+	return 0, false
+}
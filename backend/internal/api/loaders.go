@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"sync"
+
+	db "pixelmap.io/backend/internal/db"
+)
+
+type loadersKey struct{}
+
+// loaders batch the per-tile lookups of one request. A resolver registers its
+// tile and returns a thunk; graphql-go resolves every sibling field before it
+// calls any thunk, so the first thunk to run fetches the whole batch with one
+// query.
+type loaders struct {
+	tiles     *batchLoader[db.Tile]
+	images    *batchLoader[db.DataHistory]
+	purchases *batchLoader[db.PurchaseHistory]
+	transfers *batchLoader[db.TransferHistory]
+	wrappings *batchLoader[db.WrappingHistory]
+	operators *batchLoader[db.WrappedTileOperator]
+	// ethPrices is keyed by sale ID rather than tile ID.
+	ethPrices *batchLoader[db.GetEthPricesBySaleIdsRow]
+}
+
+func withLoaders(ctx context.Context, queries *db.Queries) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{
+		tiles: newBatchLoader(queries.GetTilesByIds,
+			func(t db.Tile) int32 { return t.ID }),
+		images: newBatchLoader(queries.GetDataHistoryByTileIds,
+			func(h db.DataHistory) int32 { return h.TileID }),
+		purchases: newBatchLoader(queries.GetPurchaseHistoryByTileIds,
+			func(h db.PurchaseHistory) int32 { return h.TileID }),
+		transfers: newBatchLoader(queries.GetTransferHistoryByTileIds,
+			func(h db.TransferHistory) int32 { return h.TileID }),
+		wrappings: newBatchLoader(queries.GetWrappingHistoryByTileIds,
+			func(h db.WrappingHistory) int32 { return h.TileID }),
+		operators: newBatchLoader(queries.GetTileOperatorsByTileIds,
+			func(o db.WrappedTileOperator) int32 { return o.TileID }),
+		ethPrices: newBatchLoader(queries.GetEthPricesBySaleIds,
+			func(r db.GetEthPricesBySaleIdsRow) int32 { return r.PurchaseID }),
+	})
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// batchLoader collects tile IDs and fetches the rows for all of them at once.
+// Rows are grouped by tile in the order the query returns them.
+type batchLoader[T any] struct {
+	fetch  func(ctx context.Context, tileIDs []int32) ([]T, error)
+	tileID func(T) int32
+
+	mu      sync.Mutex
+	pending []int32
+	loaded  map[int32][]T
+	failed  map[int32]error
+}
+
+func newBatchLoader[T any](fetch func(context.Context, []int32) ([]T, error), tileID func(T) int32) *batchLoader[T] {
+	return &batchLoader[T]{
+		fetch:  fetch,
+		tileID: tileID,
+		loaded: make(map[int32][]T),
+		failed: make(map[int32]error),
+	}
+}
+
+// load queues id and returns a thunk for its rows.
+func (l *batchLoader[T]) load(ctx context.Context, id int32) func() ([]T, error) {
+	l.mu.Lock()
+	if _, done := l.loaded[id]; !done {
+		l.pending = append(l.pending, id)
+	}
+	l.mu.Unlock()
+
+	return func() ([]T, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if rows, ok := l.loaded[id]; ok {
+			return rows, nil
+		}
+		if err, ok := l.failed[id]; ok {
+			return nil, err
+		}
+
+		batch := uniqueIDs(l.pending)
+		l.pending = nil
+		rows, err := l.fetch(ctx, batch)
+		if err != nil {
+			for _, tileID := range batch {
+				l.failed[tileID] = err
+			}
+			return nil, err
+		}
+
+		for _, tileID := range batch {
+			l.loaded[tileID] = nil
+		}
+		for _, row := range rows {
+			tileID := l.tileID(row)
+			l.loaded[tileID] = append(l.loaded[tileID], row)
+		}
+		return l.loaded[id], nil
+	}
+}
+
+func uniqueIDs(ids []int32) []int32 {
+	seen := make(map[int32]bool, len(ids))
+	unique := ids[:0]
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,1017 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/graphql-go/graphql"
+	db "pixelmap.io/backend/internal/db"
+	utils "pixelmap.io/backend/internal/utils"
+)
+
+const (
+	defaultPageSize    = 50
+	defaultHistorySize = 10
+)
+
+type owner struct {
+	address string
+	ens     string
+}
+
+type connectionEdge struct {
+	cursor string
+	node   interface{}
+}
+
+type connection struct {
+	edges       []connectionEdge
+	hasNextPage bool
+}
+
+// newConnection turns a page fetched with one extra row into a connection;
+// the extra row only tells whether another page follows.
+func newConnection[T any](kind string, rows []T, first int, id func(T) int32) *connection {
+	c := &connection{edges: []connectionEdge{}}
+	if len(rows) > first {
+		rows = rows[:first]
+		c.hasNextPage = true
+	}
+	for _, row := range rows {
+		c.edges = append(c.edges, connectionEdge{cursor: encodeCursor(kind, id(row)), node: row})
+	}
+	return c
+}
+
+// Cursors are opaque to clients but are just the row id, tagged with the kind
+// of row so a cursor from one connection is not accepted by another.
+func encodeCursor(kind string, id int32) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(kind + ":" + strconv.Itoa(int(id))))
+}
+
+func decodeCursor(kind, cursor string) (int32, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if id, ok := strings.CutPrefix(string(raw), kind+":"); ok {
+			if n, err := strconv.ParseInt(id, 10, 32); err == nil {
+				return int32(n), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid %s cursor %q", kind, cursor)
+}
+
+// event holds the columns every history table shares.
+type event struct {
+	ID          int32
+	TileID      int32
+	BlockNumber int64
+	TimeStamp   time.Time
+	Tx          string
+	LogIndex    int32
+}
+
+func eventOf(source interface{}) event {
+	switch h := source.(type) {
+	case db.DataHistory:
+		return event{h.ID, h.TileID, h.BlockNumber, h.TimeStamp, h.Tx, h.LogIndex}
+	case db.PurchaseHistory:
+		return event{h.ID, h.TileID, h.BlockNumber, h.TimeStamp, h.Tx, h.LogIndex}
+	case db.TransferHistory:
+		return event{h.ID, h.TileID, h.BlockNumber, h.TimeStamp, h.Tx, h.LogIndex}
+	case db.WrappingHistory:
+		return event{h.ID, h.TileID, h.BlockNumber, h.TimeStamp, h.Tx, h.LogIndex}
+	}
+	panic(fmt.Sprintf("api: %T is not a history row", source))
+}
+
+func eventFields(fields graphql.Fields) graphql.Fields {
+	fields["id"] = &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return eventOf(p.Source).ID, nil
+	}}
+	fields["tileId"] = &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return eventOf(p.Source).TileID, nil
+	}}
+	fields["blockNumber"] = &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return eventOf(p.Source).BlockNumber, nil
+	}}
+	fields["timestamp"] = &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.String),
+		Description: "Block time, RFC 3339 in UTC.",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return eventOf(p.Source).TimeStamp.UTC().Format(time.RFC3339), nil
+		},
+	}
+	fields["tx"] = &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return eventOf(p.Source).Tx, nil
+	}}
+	fields["logIndex"] = &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return eventOf(p.Source).LogIndex, nil
+	}}
+	return fields
+}
+
+func connectionType(name string, node *graphql.Object, pageInfo *graphql.Object) *graphql.Object {
+	edge := graphql.NewObject(graphql.ObjectConfig{
+		Name: name + "Edge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(connectionEdge).cursor, nil
+			}},
+			"node": &graphql.Field{Type: graphql.NewNonNull(node), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(connectionEdge).node, nil
+			}},
+		},
+	})
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name + "Connection",
+		Fields: graphql.Fields{
+			"edges": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edge))), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*connection).edges, nil
+			}},
+			"nodes": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(node))), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				edges := p.Source.(*connection).edges
+				nodes := make([]interface{}, len(edges))
+				for n, edge := range edges {
+					nodes[n] = edge.node
+				}
+				return nodes, nil
+			}},
+			"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfo), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source, nil
+			}},
+		},
+	})
+}
+
+func (s *Server) newSchema() (graphql.Schema, error) {
+	pageInfo := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*connection).hasNextPage, nil
+			}},
+			"endCursor": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				edges := p.Source.(*connection).edges
+				if len(edges) == 0 {
+					return nil, nil
+				}
+				return edges[len(edges)-1].cursor, nil
+			}},
+		},
+	})
+
+	pixelBounds := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "PixelBounds",
+		Description: "The smallest box holding the pixels an update changed, in pixels from the tile's top left, inclusive.",
+		Fields: graphql.Fields{
+			"minX": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.DataHistory).DiffMinX.Int32, nil
+			}},
+			"minY": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.DataHistory).DiffMinY.Int32, nil
+			}},
+			"maxX": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.DataHistory).DiffMaxX.Int32, nil
+			}},
+			"maxY": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.DataHistory).DiffMaxY.Int32, nil
+			}},
+		},
+	})
+
+	imageChange := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "ImageChange",
+		Description: "A setTile or setTileData call that changed a tile's image, URL or price.",
+		Fields: eventFields(graphql.Fields{
+			"image": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.DataHistory).Image, nil
+			}},
+			"url": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.DataHistory).Url, nil
+			}},
+			"price": &graphql.Field{Type: graphql.String, Description: "Asking price in ETH.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if price := p.Source.(db.DataHistory).Price; price.Valid {
+					return utils.FormatWei(price.String), nil
+				}
+				return nil, nil
+			}},
+			"priceWei": &graphql.Field{Type: graphql.String, Description: "Asking price in wei.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if price := p.Source.(db.DataHistory).Price; price.Valid {
+					return price.String, nil
+				}
+				return nil, nil
+			}},
+			"updatedBy": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.DataHistory).UpdatedBy, nil
+			}},
+			"changeType": &graphql.Field{Type: graphql.String, Description: "What the update changed against the tile's previous one: image, url, price, multiple or none. Null until the change has been backfilled.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if changeType := p.Source.(db.DataHistory).ChangeType; changeType.Valid {
+					return changeType.String, nil
+				}
+				return nil, nil
+			}},
+			"previousImage": &graphql.Field{Type: graphql.String, Description: "The image before the update; null for a tile's first update.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if image := p.Source.(db.DataHistory).PreviousImage; image.Valid {
+					return image.String, nil
+				}
+				return nil, nil
+			}},
+			"previousUrl": &graphql.Field{Type: graphql.String, Description: "The URL before the update; null for a tile's first update.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if url := p.Source.(db.DataHistory).PreviousUrl; url.Valid {
+					return url.String, nil
+				}
+				return nil, nil
+			}},
+			"previousPrice": &graphql.Field{Type: graphql.String, Description: "Asking price in ETH before the update.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if price := p.Source.(db.DataHistory).PreviousPrice; price.Valid {
+					return utils.FormatWei(price.String), nil
+				}
+				return nil, nil
+			}},
+			"previousPriceWei": &graphql.Field{Type: graphql.String, Description: "Asking price in wei before the update.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return weiOrNil(p.Source.(db.DataHistory).PreviousPrice), nil
+			}},
+			"pixelsChanged": &graphql.Field{Type: graphql.Int, Description: "How many of the tile's 256 pixels the update changed.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if pixels := p.Source.(db.DataHistory).PixelsChanged; pixels.Valid {
+					return pixels.Int32, nil
+				}
+				return nil, nil
+			}},
+			"diffBounds": &graphql.Field{Type: pixelBounds, Description: "Where the changed pixels are; null when none changed.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if h := p.Source.(db.DataHistory); h.DiffMinX.Valid {
+					return h, nil
+				}
+				return nil, nil
+			}},
+		}),
+	})
+
+	sale := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Sale",
+		Fields: eventFields(graphql.Fields{
+			"seller": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.PurchaseHistory).SoldBy, nil
+			}},
+			"buyer": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.PurchaseHistory).PurchasedBy, nil
+			}},
+			"price": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "Sale price in ETH.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return utils.FormatWei(p.Source.(db.PurchaseHistory).Price), nil
+			}},
+			"priceWei": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "Sale price in wei.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.PurchaseHistory).Price, nil
+			}},
+			"askingPriceWei": &graphql.Field{Type: graphql.String, Description: "The tile's asking price in wei as the index had it at the sale.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return weiOrNil(p.Source.(db.PurchaseHistory).AskingPrice), nil
+			}},
+			"priceMismatch": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Description: "Whether the price paid differs from askingPriceWei.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.PurchaseHistory).PriceMismatch, nil
+			}},
+			"sellerPayoutWei": &graphql.Field{Type: graphql.String, Description: "Wei the contract forwarded to the tile's owner, null if not yet known.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return weiOrNil(p.Source.(db.PurchaseHistory).SellerPayout), nil
+			}},
+			"creatorFeeWei": &graphql.Field{Type: graphql.String, Description: "Wei the contract forwarded to the PixelMap creator, null if not yet known.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return weiOrNil(p.Source.(db.PurchaseHistory).CreatorFee), nil
+			}},
+			"ethPriceUsd": &graphql.Field{Type: graphql.String, Description: "ETH/USD price on the day of the sale, if one has been imported.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return resolveSaleUSD(p, func(_, ethPrice *big.Rat) string { return ethPrice.FloatString(2) })
+			}},
+			"salePriceUsd": &graphql.Field{Type: graphql.String, Description: "Sale price in USD at the day's ETH/USD price.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return resolveSaleUSD(p, func(price, ethPrice *big.Rat) string { return new(big.Rat).Mul(price, ethPrice).FloatString(2) })
+			}},
+		}),
+	})
+
+	transfer := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Transfer",
+		Fields: eventFields(graphql.Fields{
+			"from": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.TransferHistory).TransferredFrom, nil
+			}},
+			"to": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.TransferHistory).TransferredTo, nil
+			}},
+			"transferType": &graphql.Field{
+				Type:        graphql.String,
+				Description: "wrap, unwrap, mint, burn, sale or gift; null until classified.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if transferType := p.Source.(db.TransferHistory).TransferType; transferType.Valid {
+						return transferType.String, nil
+					}
+					return nil, nil
+				},
+			},
+			"isWrapperContract": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Whether this is a transfer of the wrapper's ERC-721 token.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(db.TransferHistory).IsWrapperContract, nil
+				},
+			},
+		}),
+	})
+
+	wrapping := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Wrapping",
+		Fields: eventFields(graphql.Fields{
+			"wrapped": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.WrappingHistory).Wrapped, nil
+			}},
+			"updatedBy": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.WrappingHistory).UpdatedBy, nil
+			}},
+		}),
+	})
+
+	historyArgs := graphql.FieldConfigArgument{
+		"last": &graphql.ArgumentConfig{
+			Type:         graphql.Int,
+			DefaultValue: defaultHistorySize,
+			Description:  "How many of the most recent entries to return, newest first.",
+		},
+	}
+
+	tileOperator := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TileOperator",
+		Description: "An address other than its owner that can move a wrapped tile.",
+		Fields: graphql.Fields{
+			"operator": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.WrappedTileOperator).Operator, nil
+			}},
+			"kind": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "token for the address approved for this tile, operator for one the owner approved for all their tiles.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(db.WrappedTileOperator).Kind, nil
+				},
+			},
+			"blockNumber": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.WrappedTileOperator).BlockNumber, nil
+			}},
+			"timestamp": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Time of the approval, RFC 3339 in UTC.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(db.WrappedTileOperator).TimeStamp.UTC().Format(time.RFC3339), nil
+				},
+			},
+			"tx": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.WrappedTileOperator).Tx, nil
+			}},
+		},
+	})
+
+	tile := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Tile",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.Tile).ID, nil
+			}},
+			"owner": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.Tile).Owner, nil
+			}},
+			"ens": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return nullIfEmpty(p.Source.(db.Tile).Ens), nil
+			}},
+			"image": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.Tile).Image, nil
+			}},
+			"url": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.Tile).Url, nil
+			}},
+			"price": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "Asking price in ETH.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return utils.FormatWei(p.Source.(db.Tile).Price), nil
+			}},
+			"priceWei": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "Asking price in wei.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.Tile).Price, nil
+			}},
+			"wrapped": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.Tile).Wrapped, nil
+			}},
+			"openseaPrice": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return nullIfEmpty(p.Source.(db.Tile).OpenseaPrice), nil
+			}},
+			"images": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(imageChange))),
+				Args: historyArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadHistory(s, p, loadersFrom(p.Context).images)
+				},
+			},
+			"purchases": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(sale))),
+				Args: historyArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadHistory(s, p, loadersFrom(p.Context).purchases)
+				},
+			},
+			"transfers": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(transfer))),
+				Args: historyArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadHistory(s, p, loadersFrom(p.Context).transfers)
+				},
+			},
+			"wrappings": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(wrapping))),
+				Args: historyArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadHistory(s, p, loadersFrom(p.Context).wrappings)
+				},
+			},
+			"operators": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(tileOperator))),
+				Description: "Who besides its owner can move the tile. Always empty for a tile that is not wrapped.",
+				Resolve:     s.resolveTileOperators,
+			},
+		},
+	})
+
+	// History rows link back to their tile through the batched tile loader.
+	tileField := &graphql.Field{Type: graphql.NewNonNull(tile), Resolve: s.resolveEventTile}
+	for _, object := range []*graphql.Object{imageChange, sale, transfer, wrapping} {
+		object.AddFieldConfig("tile", tileField)
+	}
+
+	tileConnection := connectionType("Tile", tile, pageInfo)
+	saleConnection := connectionType("Sale", sale, pageInfo)
+	transferConnection := connectionType("Transfer", transfer, pageInfo)
+
+	pageArgs := func(extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		extra["first"] = &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize}
+		extra["after"] = &graphql.ArgumentConfig{Type: graphql.String, Description: "endCursor of the previous page."}
+		return extra
+	}
+
+	timeCapsule := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TimeCapsule",
+		Description: "A PixelMapTimeCapsule token and the transfer that brought it to its holder.",
+		Fields: graphql.Fields{
+			"tokenId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.TimeCapsuleToken).TokenID, nil
+			}},
+			"owner": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.TimeCapsuleToken).Owner, nil
+			}},
+			"blockNumber": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.TimeCapsuleToken).AcquiredBlock, nil
+			}},
+			"timestamp": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Block time, RFC 3339 in UTC.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(db.TimeCapsuleToken).AcquiredAt.UTC().Format(time.RFC3339), nil
+				},
+			},
+			"tx": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.TimeCapsuleToken).AcquiredTx, nil
+			}},
+		},
+	})
+
+	operatorApproval := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "OperatorApproval",
+		Description: "An operator approved for all of an owner's wrapped tiles, and the setApprovalForAll that approved it.",
+		Fields: graphql.Fields{
+			"operator": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.WrapperOperator).Operator, nil
+			}},
+			"blockNumber": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.WrapperOperator).BlockNumber, nil
+			}},
+			"timestamp": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Block time, RFC 3339 in UTC.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(db.WrapperOperator).TimeStamp.UTC().Format(time.RFC3339), nil
+				},
+			},
+			"tx": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.WrapperOperator).Tx, nil
+			}},
+		},
+	})
+
+	governanceChange := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "GovernanceChange",
+		Description: "An owner-only change to the wrapper or time capsule contract.",
+		Fields: graphql.Fields{
+			"contract": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "wrapper or timecapsule.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.GovernanceLog).Contract, nil
+			}},
+			"action": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "The method called, such as setBaseTokenURI or transferOwnership.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.GovernanceLog).Action, nil
+			}},
+			"value": &graphql.Field{Type: graphql.String, Description: "The new URI, extension or owner; null for calls without one.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if value := p.Source.(db.GovernanceLog).Value; value.Valid {
+					return value.String, nil
+				}
+				return nil, nil
+			}},
+			"changedBy": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.GovernanceLog).ChangedBy, nil
+			}},
+			"blockNumber": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.GovernanceLog).BlockNumber, nil
+			}},
+			"timestamp": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Block time, RFC 3339 in UTC.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(db.GovernanceLog).TimeStamp.UTC().Format(time.RFC3339), nil
+				},
+			},
+			"tx": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(db.GovernanceLog).Tx, nil
+			}},
+		},
+	})
+
+	ownerType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Owner",
+		Fields: graphql.Fields{
+			"address": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*owner).address, nil
+			}},
+			"ens":   &graphql.Field{Type: graphql.String, Resolve: s.resolveOwnerENS},
+			"tiles": &graphql.Field{Type: graphql.NewNonNull(tileConnection), Args: pageArgs(graphql.FieldConfigArgument{}), Resolve: s.resolveOwnerTiles},
+			"timeCapsules": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(timeCapsule))),
+				Description: "PixelMapTimeCapsule tokens the owner holds.",
+				Resolve:     s.resolveOwnerTimeCapsules,
+			},
+			"operatorApprovals": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(operatorApproval))),
+				Description: "Operators the owner has approved for all their wrapped tiles and not revoked, newest first.",
+				Resolve:     s.resolveOwnerOperatorApprovals,
+			},
+		},
+	})
+
+	tileFilter := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "TileFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"ids":      &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.Int))},
+			"owner":    &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Owner address, case-insensitive."},
+			"ens":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"wrapped":  &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+			"hasImage": &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		},
+	})
+
+	saleFilter := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "SaleFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"tileId":   &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"buyer":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"seller":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"minPrice": &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Inclusive, in ETH."},
+			"maxPrice": &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Inclusive, in ETH."},
+			"since":    &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Inclusive RFC 3339 time or YYYY-MM-DD date."},
+			"until":    &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Exclusive RFC 3339 time or YYYY-MM-DD date."},
+		},
+	})
+
+	transferFilter := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "TransferFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"tileId":  &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"from":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"to":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"address": &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Matches either side of the transfer."},
+			"type":    &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "wrap, unwrap, mint, burn, sale or gift."},
+			"since":   &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Inclusive RFC 3339 time or YYYY-MM-DD date."},
+			"until":   &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Exclusive RFC 3339 time or YYYY-MM-DD date."},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"tile": &graphql.Field{
+				Type:    tile,
+				Args:    graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}},
+				Resolve: s.resolveTile,
+			},
+			"tiles": &graphql.Field{
+				Type:    graphql.NewNonNull(tileConnection),
+				Args:    pageArgs(graphql.FieldConfigArgument{"filter": &graphql.ArgumentConfig{Type: tileFilter}}),
+				Resolve: s.resolveTiles,
+			},
+			"owner": &graphql.Field{
+				Type:        ownerType,
+				Description: "Looks up an owner by address or ENS name. Pass exactly one.",
+				Args: graphql.FieldConfigArgument{
+					"address": &graphql.ArgumentConfig{Type: graphql.String},
+					"ens":     &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: s.resolveOwner,
+			},
+			"sales": &graphql.Field{
+				Type:    graphql.NewNonNull(saleConnection),
+				Args:    pageArgs(graphql.FieldConfigArgument{"filter": &graphql.ArgumentConfig{Type: saleFilter}}),
+				Resolve: s.resolveSales,
+			},
+			"transfers": &graphql.Field{
+				Type:    graphql.NewNonNull(transferConnection),
+				Args:    pageArgs(graphql.FieldConfigArgument{"filter": &graphql.ArgumentConfig{Type: transferFilter}}),
+				Resolve: s.resolveTransfers,
+			},
+			"governanceLog": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(governanceChange))),
+				Description: "Admin changes to the wrapper and time capsule contracts, newest first.",
+				Args: graphql.FieldConfigArgument{
+					"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+				},
+				Resolve: s.resolveGovernanceLog,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+func (s *Server) resolveTile(p graphql.ResolveParams) (interface{}, error) {
+	tile, err := s.queries.GetTileById(p.Context, int32(p.Args["id"].(int)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tile, nil
+}
+
+func (s *Server) resolveEventTile(p graphql.ResolveParams) (interface{}, error) {
+	thunk := loadersFrom(p.Context).tiles.load(p.Context, eventOf(p.Source).TileID)
+	return func() (interface{}, error) {
+		tiles, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if len(tiles) == 0 {
+			return nil, fmt.Errorf("tile %d not found", eventOf(p.Source).TileID)
+		}
+		return tiles[0], nil
+	}, nil
+}
+
+func loadHistory[T any](s *Server, p graphql.ResolveParams, loader *batchLoader[T]) (interface{}, error) {
+	last, err := s.pageSize(p.Args, "last")
+	if err != nil {
+		return nil, err
+	}
+
+	thunk := loader.load(p.Context, p.Source.(db.Tile).ID)
+	return func() (interface{}, error) {
+		rows, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) > last {
+			rows = rows[:last]
+		}
+		items := make([]interface{}, len(rows))
+		for n, row := range rows {
+			items[n] = row
+		}
+		return items, nil
+	}, nil
+}
+
+// resolveSaleUSD batches the ETH/USD price lookup for the sales of a page and
+// formats a value from the sale price and the day's ETH/USD price.
+func resolveSaleUSD(p graphql.ResolveParams, format func(price, ethPrice *big.Rat) string) (interface{}, error) {
+	sale := p.Source.(db.PurchaseHistory)
+	thunk := loadersFrom(p.Context).ethPrices.load(p.Context, sale.ID)
+	return func() (interface{}, error) {
+		rows, err := thunk()
+		if err != nil || len(rows) == 0 {
+			return nil, err
+		}
+		price, ok := utils.WeiToEth(sale.Price)
+		if !ok {
+			return nil, fmt.Errorf("invalid stored price %q for sale %d", sale.Price, sale.ID)
+		}
+		ethPrice, ok := new(big.Rat).SetString(rows[0].PriceUsd)
+		if !ok {
+			return nil, fmt.Errorf("invalid stored ETH price %q", rows[0].PriceUsd)
+		}
+		return format(price, ethPrice), nil
+	}, nil
+}
+
+func (s *Server) resolveTiles(p graphql.ResolveParams) (interface{}, error) {
+	filter, _ := p.Args["filter"].(map[string]interface{})
+	params := db.ListTilesPageParams{
+		Owner:    nullAddress(filter["owner"]),
+		Ens:      nullString(filter["ens"]),
+		Wrapped:  nullBool(filter["wrapped"]),
+		HasImage: nullBool(filter["hasImage"]),
+		Ids:      int32s(filter["ids"]),
+	}
+	return s.tilePage(p, params)
+}
+
+func (s *Server) tilePage(p graphql.ResolveParams, params db.ListTilesPageParams) (interface{}, error) {
+	first, err := s.pageSize(p.Args, "first")
+	if err != nil {
+		return nil, err
+	}
+	if params.AfterID, err = afterID(p.Args, "tile"); err != nil {
+		return nil, err
+	}
+	params.PageSize = int32(first + 1)
+
+	tiles, err := s.queries.ListTilesPage(p.Context, params)
+	if err != nil {
+		return nil, err
+	}
+	return newConnection("tile", tiles, first, func(t db.Tile) int32 { return t.ID }), nil
+}
+
+func (s *Server) resolveOwner(p graphql.ResolveParams) (interface{}, error) {
+	address, _ := p.Args["address"].(string)
+	name, _ := p.Args["ens"].(string)
+	if (address == "") == (name == "") {
+		return nil, errors.New("pass exactly one of address or ens")
+	}
+
+	if address != "" {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid address %q", address)
+		}
+		return &owner{address: utils.NormalizeAddress(address)}, nil
+	}
+
+	address, err := s.lookupENS(p.Context, name)
+	if err != nil || address == "" {
+		return nil, err
+	}
+	return &owner{address: address, ens: name}, nil
+}
+
+// lookupENS finds the owner behind name, first among the names cached on
+// tiles and then through ResolveENS. It returns "" for an unknown name.
+func (s *Server) lookupENS(ctx context.Context, name string) (string, error) {
+	address, err := s.queries.GetOwnerByENS(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) && s.resolveENS != nil {
+		address, err = s.resolveENS(ctx, name)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return utils.NormalizeAddress(address), nil
+}
+
+// resolveOwnerENS reports the name cached on the owner's tiles when the owner
+// was looked up by address.
+func (s *Server) resolveOwnerENS(p graphql.ResolveParams) (interface{}, error) {
+	o := p.Source.(*owner)
+	if o.ens != "" {
+		return o.ens, nil
+	}
+
+	tiles, err := s.queries.ListTilesPage(p.Context, db.ListTilesPageParams{
+		Owner:    sql.NullString{String: o.address, Valid: true},
+		PageSize: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(tiles) == 0 {
+		return nil, nil
+	}
+	return nullIfEmpty(tiles[0].Ens), nil
+}
+
+func (s *Server) resolveOwnerTiles(p graphql.ResolveParams) (interface{}, error) {
+	return s.tilePage(p, db.ListTilesPageParams{
+		Owner: sql.NullString{String: p.Source.(*owner).address, Valid: true},
+	})
+}
+
+func (s *Server) resolveOwnerTimeCapsules(p graphql.ResolveParams) (interface{}, error) {
+	capsules, err := s.queries.GetTimeCapsulesByOwner(p.Context, p.Source.(*owner).address)
+	if err != nil {
+		return nil, err
+	}
+	if capsules == nil {
+		capsules = []db.TimeCapsuleToken{}
+	}
+	return capsules, nil
+}
+
+func (s *Server) resolveOwnerOperatorApprovals(p graphql.ResolveParams) (interface{}, error) {
+	operators, err := s.queries.GetOperatorsByOwner(p.Context, p.Source.(*owner).address)
+	if err != nil {
+		return nil, err
+	}
+	if operators == nil {
+		operators = []db.WrapperOperator{}
+	}
+	return operators, nil
+}
+
+func (s *Server) resolveTileOperators(p graphql.ResolveParams) (interface{}, error) {
+	thunk := loadersFrom(p.Context).operators.load(p.Context, p.Source.(db.Tile).ID)
+	return func() (interface{}, error) {
+		operators, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if operators == nil {
+			operators = []db.WrappedTileOperator{}
+		}
+		return operators, nil
+	}, nil
+}
+
+func (s *Server) resolveGovernanceLog(p graphql.ResolveParams) (interface{}, error) {
+	first, err := s.pageSize(p.Args, "first")
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.queries.ListGovernanceLog(p.Context, int32(first))
+	if err != nil {
+		return nil, err
+	}
+	if changes == nil {
+		changes = []db.GovernanceLog{}
+	}
+	return changes, nil
+}
+
+func (s *Server) resolveSales(p graphql.ResolveParams) (interface{}, error) {
+	first, err := s.pageSize(p.Args, "first")
+	if err != nil {
+		return nil, err
+	}
+	after, err := afterID(p.Args, "sale")
+	if err != nil {
+		return nil, err
+	}
+
+	filter, _ := p.Args["filter"].(map[string]interface{})
+	params := db.ListPurchasesPageParams{
+		AfterID:  after,
+		TileID:   nullInt32(filter["tileId"]),
+		Buyer:    nullAddress(filter["buyer"]),
+		Seller:   nullAddress(filter["seller"]),
+		PageSize: int32(first + 1),
+	}
+	if params.MinPrice, err = nullPrice(filter["minPrice"]); err != nil {
+		return nil, err
+	}
+	if params.MaxPrice, err = nullPrice(filter["maxPrice"]); err != nil {
+		return nil, err
+	}
+	if params.Since, err = nullTime(filter["since"]); err != nil {
+		return nil, err
+	}
+	if params.Until, err = nullTime(filter["until"]); err != nil {
+		return nil, err
+	}
+
+	sales, err := s.queries.ListPurchasesPage(p.Context, params)
+	if err != nil {
+		return nil, err
+	}
+	return newConnection("sale", sales, first, func(h db.PurchaseHistory) int32 { return h.ID }), nil
+}
+
+func (s *Server) resolveTransfers(p graphql.ResolveParams) (interface{}, error) {
+	first, err := s.pageSize(p.Args, "first")
+	if err != nil {
+		return nil, err
+	}
+	after, err := afterID(p.Args, "transfer")
+	if err != nil {
+		return nil, err
+	}
+
+	filter, _ := p.Args["filter"].(map[string]interface{})
+	params := db.ListTransfersPageParams{
+		AfterID:      after,
+		TileID:       nullInt32(filter["tileId"]),
+		FromAddress:  nullAddress(filter["from"]),
+		ToAddress:    nullAddress(filter["to"]),
+		Address:      nullAddress(filter["address"]),
+		TransferType: nullString(filter["type"]),
+		PageSize:     int32(first + 1),
+	}
+	if params.Since, err = nullTime(filter["since"]); err != nil {
+		return nil, err
+	}
+	if params.Until, err = nullTime(filter["until"]); err != nil {
+		return nil, err
+	}
+
+	transfers, err := s.queries.ListTransfersPage(p.Context, params)
+	if err != nil {
+		return nil, err
+	}
+	return newConnection("transfer", transfers, first, func(h db.TransferHistory) int32 { return h.ID }), nil
+}
+
+func (s *Server) pageSize(args map[string]interface{}, name string) (int, error) {
+	size, _ := args[name].(int)
+	if size < 1 || size > s.limits.MaxPageSize {
+		return 0, fmt.Errorf("%s must be between 1 and %d", name, s.limits.MaxPageSize)
+	}
+	return size, nil
+}
+
+func afterID(args map[string]interface{}, kind string) (int32, error) {
+	cursor, _ := args["after"].(string)
+	if cursor == "" {
+		return 0, nil
+	}
+	return decodeCursor(kind, cursor)
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// weiOrNil resolves a nullable wei amount.
+func weiOrNil(wei sql.NullString) interface{} {
+	if wei.Valid {
+		return wei.String
+	}
+	return nil
+}
+
+func nullString(v interface{}) sql.NullString {
+	s, ok := v.(string)
+	return sql.NullString{String: s, Valid: ok}
+}
+
+// nullAddress matches the lowercase form addresses are stored in.
+func nullAddress(v interface{}) sql.NullString {
+	s, ok := v.(string)
+	return sql.NullString{String: utils.NormalizeAddress(s), Valid: ok}
+}
+
+func nullBool(v interface{}) sql.NullBool {
+	b, ok := v.(bool)
+	return sql.NullBool{Bool: b, Valid: ok}
+}
+
+func nullInt32(v interface{}) sql.NullInt32 {
+	n, ok := v.(int)
+	return sql.NullInt32{Int32: int32(n), Valid: ok}
+}
+
+func int32s(v interface{}) []int32 {
+	values, _ := v.([]interface{})
+	ids := make([]int32, 0, len(values))
+	for _, value := range values {
+		if n, ok := value.(int); ok {
+			ids = append(ids, int32(n))
+		}
+	}
+	return ids
+}
+
+// nullPrice converts a price filter in ETH to the wei stored in the tables.
+func nullPrice(v interface{}) (sql.NullString, error) {
+	s, ok := v.(string)
+	if !ok {
+		return sql.NullString{}, nil
+	}
+	wei, valid := utils.ParseEther(s)
+	if !valid {
+		return sql.NullString{}, fmt.Errorf("invalid price %q", s)
+	}
+	return sql.NullString{String: wei.String(), Valid: true}, nil
+}
+
+func nullTime(v interface{}) (sql.NullTime, error) {
+	s, ok := v.(string)
+	if !ok {
+		return sql.NullTime{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return sql.NullTime{Time: t.UTC(), Valid: true}, nil
+		}
+	}
+	return sql.NullTime{}, fmt.Errorf("invalid time %q: use RFC 3339 or YYYY-MM-DD", s)
+}
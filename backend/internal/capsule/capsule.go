@@ -0,0 +1,268 @@
+// Package capsule builds PixelMapTimeCapsule editions: a composite image of
+// tiles picked by a rule, drawn from the images they had at a chosen moment,
+// and the ERC-721 metadata for it. Every token of the contract shares one
+// tokenURI, the _baseTokenURI, which is the IPFS URI of the metadata file;
+// CIDs are computed locally so the URIs are known before anything is pinned.
+package capsule
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/image/draw"
+	db "pixelmap.io/backend/internal/db"
+	utils "pixelmap.io/backend/internal/utils"
+)
+
+// The map is 81 tiles wide and 49 tall, plus tile 3969 alone on row 49.
+const (
+	mapColumns = 81
+	mapRows    = 50
+	lastTile   = 3969
+)
+
+// Rule picks the tiles of a capsule. With First set it takes the first tiles
+// ever given an image, laid out in a square in the order they were updated;
+// otherwise it takes the block of the map between Rows and Columns, laid out
+// as on the map. Either way each tile shows the image it had at At.
+type Rule struct {
+	First   int
+	Rows    [2]int
+	Columns [2]int
+	At      time.Time
+}
+
+// Validate checks the rule against the map.
+func (r Rule) Validate() error {
+	if r.At.IsZero() {
+		return fmt.Errorf("the capsule needs a moment to record")
+	}
+	if r.First > 0 {
+		if r.First > lastTile+1 {
+			return fmt.Errorf("there are only %d tiles", lastTile+1)
+		}
+		return nil
+	}
+	if r.Rows[0] < 0 || r.Rows[0] > r.Rows[1] || r.Rows[1] >= mapRows {
+		return fmt.Errorf("rows %d-%d are not on the map", r.Rows[0], r.Rows[1])
+	}
+	if r.Columns[0] < 0 || r.Columns[0] > r.Columns[1] || r.Columns[1] >= mapColumns {
+		return fmt.Errorf("columns %d-%d are not on the map", r.Columns[0], r.Columns[1])
+	}
+	return nil
+}
+
+// Cell is one tile placed in the composite.
+type Cell struct {
+	TileID int32
+	Column int
+	Row    int
+	Image  string
+}
+
+// Layout is a grid of tiles. Cells without an image are left transparent.
+type Layout struct {
+	Columns int
+	Rows    int
+	Cells   []Cell
+}
+
+// Select lays out the tiles the rule picks.
+func Select(ctx context.Context, queries *db.Queries, rule Rule) (*Layout, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	rows, err := queries.ListTileImagesAt(ctx, rule.At)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tile images: %w", err)
+	}
+	images := make(map[int32]string, len(rows))
+	for _, row := range rows {
+		images[row.TileID] = row.Image
+	}
+
+	if rule.First == 0 {
+		return RegionLayout(rule.Rows, rule.Columns, images), nil
+	}
+
+	updates, err := queries.ListFirstImageUpdates(ctx, db.ListFirstImageUpdatesParams{
+		TimeStamp: rule.At,
+		Limit:     int32(rule.First),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get first image updates: %w", err)
+	}
+	if len(updates) < rule.First {
+		return nil, fmt.Errorf("only %d tiles had an image by %s", len(updates), rule.At.Format(time.DateOnly))
+	}
+	ids := make([]int32, len(updates))
+	for i, update := range updates {
+		ids[i] = update.TileID
+	}
+	return SquareLayout(ids, images), nil
+}
+
+// SquareLayout places tiles row by row in the smallest square that holds
+// them.
+func SquareLayout(ids []int32, images map[int32]string) *Layout {
+	side := int(math.Ceil(math.Sqrt(float64(len(ids)))))
+	layout := &Layout{Columns: side, Rows: side}
+	if side > 0 {
+		layout.Rows = (len(ids) + side - 1) / side
+	}
+	for i, id := range ids {
+		layout.Cells = append(layout.Cells, Cell{
+			TileID: id,
+			Column: i % side,
+			Row:    i / side,
+			Image:  images[id],
+		})
+	}
+	return layout
+}
+
+// RegionLayout places the tiles between rows and columns, inclusive, as they
+// sit on the map.
+func RegionLayout(rows, columns [2]int, images map[int32]string) *Layout {
+	layout := &Layout{
+		Columns: columns[1] - columns[0] + 1,
+		Rows:    rows[1] - rows[0] + 1,
+	}
+	for row := rows[0]; row <= rows[1]; row++ {
+		for column := columns[0]; column <= columns[1]; column++ {
+			id := int32(row*mapColumns + column)
+			if id > lastTile {
+				continue
+			}
+			layout.Cells = append(layout.Cells, Cell{
+				TileID: id,
+				Column: column - columns[0],
+				Row:    row - rows[0],
+				Image:  images[id],
+			})
+		}
+	}
+	return layout
+}
+
+// Render draws the layout with each tile tileSize pixels square, returning
+// the composite and the tiles whose images could not be drawn.
+func Render(layout *Layout, tileSize int) (*image.RGBA, []int32) {
+	canvas := image.NewRGBA(image.Rect(0, 0, layout.Columns*tileSize, layout.Rows*tileSize))
+	var skipped []int32
+	for _, cell := range layout.Cells {
+		if cell.Image == "" {
+			continue
+		}
+		tile, err := utils.DecodeTileImage(cell.Image)
+		if err != nil || tile == nil {
+			skipped = append(skipped, cell.TileID)
+			continue
+		}
+		x, y := cell.Column*tileSize, cell.Row*tileSize
+		draw.NearestNeighbor.Scale(canvas, image.Rect(x, y, x+tileSize, y+tileSize), tile, tile.Bounds(), draw.Over, nil)
+	}
+	return canvas, skipped
+}
+
+// Property is one entry of a capsule's properties.
+type Property struct {
+	TraitType string `json:"trait_type"`
+	Value     string `json:"value"`
+}
+
+// Metadata is a capsule's ERC-721 metadata, in the shape of the editions
+// already published.
+type Metadata struct {
+	ImageURL    string     `json:"image_url"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	ExternalURL string     `json:"external_url"`
+	Properties  []Property `json:"properties"`
+}
+
+// Edition describes a capsule for its metadata.
+type Edition struct {
+	Number       int
+	Name         string
+	Description  string
+	Reason       string
+	CelebratedOn time.Time
+}
+
+// NewMetadata returns the metadata of an edition whose image has imageCID.
+func NewMetadata(edition Edition, imageCID string) Metadata {
+	return Metadata{
+		ImageURL:    "ipfs://" + imageCID,
+		Name:        edition.Name,
+		Description: edition.Description,
+		ExternalURL: "https://pixelmap.io/",
+		Properties: []Property{
+			{TraitType: "Celebrated On", Value: edition.CelebratedOn.Format("January 2, 2006")},
+			{TraitType: "Reason", Value: edition.Reason},
+		},
+	}
+}
+
+// Encode renders metadata as it is pinned.
+func (m Metadata) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(m); err != nil {
+		return nil, fmt.Errorf("failed to encode capsule metadata: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Output is what Write produced. BaseTokenURI is the value for
+// setBaseTokenURI once both files are pinned.
+type Output struct {
+	ImagePath    string
+	MetadataPath string
+	ImageCID     string
+	MetadataCID  string
+	BaseTokenURI string
+}
+
+// Write saves capsule{n}.png and capsule{n}.json to dir.
+func Write(dir string, edition Edition, composite image.Image) (*Output, error) {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, composite); err != nil {
+		return nil, fmt.Errorf("failed to encode capsule image: %w", err)
+	}
+	imageBytes := encoded.Bytes()
+
+	out := &Output{
+		ImagePath:    filepath.Join(dir, fmt.Sprintf("capsule%d.png", edition.Number)),
+		MetadataPath: filepath.Join(dir, fmt.Sprintf("capsule%d.json", edition.Number)),
+		ImageCID:     CIDv0(imageBytes),
+	}
+	metadata, err := NewMetadata(edition, out.ImageCID).Encode()
+	if err != nil {
+		return nil, err
+	}
+	out.MetadataCID = CIDv0(metadata)
+	out.BaseTokenURI = "ipfs://" + out.MetadataCID
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create capsule directory: %w", err)
+	}
+	if err := os.WriteFile(out.ImagePath, imageBytes, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write capsule image: %w", err)
+	}
+	if err := os.WriteFile(out.MetadataPath, metadata, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write capsule metadata: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,100 @@
+package capsule
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The first edition, pinned by hand, and the contract's _baseTokenURI.
+const publishedCapsules = "../../../contracts/metadata/capsules"
+
+func TestCIDv0(t *testing.T) {
+	assert.Equal(t, "QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH", CIDv0(nil))
+	assert.Equal(t, "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o", CIDv0([]byte("hello world\n")))
+
+	// 373658 bytes, so two chunks under one root.
+	image, err := os.ReadFile(filepath.Join(publishedCapsules, "capsule1.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "QmWyQtQdayMvTqDaXsiqiH2d38tF954jhASTHNgXh8WgJB", CIDv0(image))
+}
+
+func TestMetadataMatchesPublishedEdition(t *testing.T) {
+	published, err := os.ReadFile(filepath.Join(publishedCapsules, "capsule1.json"))
+	require.NoError(t, err)
+
+	metadata, err := NewMetadata(Edition{
+		Number:       1,
+		Name:         "The OG 18x18",
+		Description:  "This time capsule was recorded on October 22, 2021, to celebrate the very first 18x18 (324) tiles that were updated with an image.",
+		Reason:       "Tiles Updated",
+		CelebratedOn: time.Date(2021, 10, 22, 0, 0, 0, 0, time.UTC),
+	}, "QmWyQtQdayMvTqDaXsiqiH2d38tF954jhASTHNgXh8WgJB").Encode()
+	require.NoError(t, err)
+
+	assert.Equal(t, string(published), string(metadata))
+	assert.Equal(t, "QmUAwy3qSmTAdz9fvUmkwfmrjsLbbup5LusXzXJ9TpcddH", CIDv0(metadata), "PixelMapTimeCapsule._baseTokenURI")
+}
+
+func TestLayouts(t *testing.T) {
+	square := SquareLayout([]int32{1984, 7, 3000, 12, 5}, map[int32]string{7: "img"})
+	assert.Equal(t, 3, square.Columns)
+	assert.Equal(t, 2, square.Rows)
+	require.Len(t, square.Cells, 5)
+	assert.Equal(t, Cell{TileID: 7, Column: 1, Row: 0, Image: "img"}, square.Cells[1])
+	assert.Equal(t, Cell{TileID: 12, Column: 0, Row: 1}, square.Cells[3])
+
+	center := RegionLayout([2]int{19, 29}, [2]int{35, 45}, nil)
+	assert.Equal(t, 11, center.Columns)
+	assert.Equal(t, 11, center.Rows)
+	require.Len(t, center.Cells, 121)
+	assert.Equal(t, int32(1574), center.Cells[0].TileID)
+	assert.Equal(t, Cell{TileID: 2394, Column: 10, Row: 10}, center.Cells[120])
+
+	bottom := RegionLayout([2]int{48, 49}, [2]int{0, 80}, nil)
+	assert.Len(t, bottom.Cells, 82, "row 49 only has tile 3969")
+}
+
+func TestRuleValidate(t *testing.T) {
+	at := time.Date(2021, 10, 22, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, Rule{First: 324, At: at}.Validate())
+	assert.NoError(t, Rule{Rows: [2]int{0, 48}, Columns: [2]int{0, 80}, At: at}.Validate())
+	assert.Error(t, Rule{First: 324}.Validate())
+	assert.Error(t, Rule{First: 4000, At: at}.Validate())
+	assert.Error(t, Rule{Rows: [2]int{5, 2}, Columns: [2]int{0, 80}, At: at}.Validate())
+	assert.Error(t, Rule{Rows: [2]int{0, 0}, Columns: [2]int{0, 81}, At: at}.Validate())
+}
+
+func TestRenderAndWrite(t *testing.T) {
+	red := strings.Repeat("f00", 256)
+	layout := &Layout{Columns: 2, Rows: 1, Cells: []Cell{
+		{TileID: 1, Column: 0, Row: 0, Image: red},
+		{TileID: 2, Column: 1, Row: 0, Image: "junk"},
+	}}
+
+	composite, skipped := Render(layout, 4)
+	assert.Equal(t, []int32{2}, skipped)
+	assert.Equal(t, 8, composite.Bounds().Dx())
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, composite.RGBAAt(3, 3))
+	assert.Equal(t, color.RGBA{}, composite.RGBAAt(4, 0))
+
+	dir := t.TempDir()
+	out, err := Write(dir, Edition{Number: 2, Name: "Red", Reason: "Test", CelebratedOn: time.Now()}, composite)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "capsule2.png"), out.ImagePath)
+	assert.Equal(t, "ipfs://"+out.MetadataCID, out.BaseTokenURI)
+
+	written, err := os.ReadFile(out.ImagePath)
+	require.NoError(t, err)
+	assert.Equal(t, out.ImageCID, CIDv0(written))
+	metadata, err := os.ReadFile(out.MetadataPath)
+	require.NoError(t, err)
+	assert.Equal(t, out.MetadataCID, CIDv0(metadata))
+	assert.Contains(t, string(metadata), `"image_url": "ipfs://`+out.ImageCID+`"`)
+}
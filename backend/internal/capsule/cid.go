@@ -0,0 +1,97 @@
+package capsule
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/mr-tron/base58"
+)
+
+// `ipfs add` defaults: 256 KiB chunks in a balanced DAG of up to 174 links
+// per node, every node a dag-pb UnixFS file.
+const (
+	chunkSize = 256 * 1024
+	maxLinks  = 174
+)
+
+// dagNode is an encoded dag-pb node with what a parent needs to link to it.
+type dagNode struct {
+	hash     []byte // sha2-256 multihash
+	tsize    uint64 // encoded size of the node and everything below it
+	filesize uint64 // bytes of file data below it
+}
+
+// CIDv0 returns the CID `ipfs add` gives data with its default settings, so
+// the URIs written into metadata match what pinning the files produces.
+func CIDv0(data []byte) string {
+	var level []dagNode
+	for offset := 0; ; offset += chunkSize {
+		end := min(offset+chunkSize, len(data))
+		size := uint64(end - offset)
+		level = append(level, newDagNode(nil, unixfsFile(data[offset:end], size, nil), size))
+		if end == len(data) {
+			break
+		}
+	}
+
+	for len(level) > 1 {
+		var parents []dagNode
+		for start := 0; start < len(level); start += maxLinks {
+			children := level[start:min(start+maxLinks, len(level))]
+			var filesize uint64
+			blocksizes := make([]uint64, len(children))
+			for i, child := range children {
+				filesize += child.filesize
+				blocksizes[i] = child.filesize
+			}
+			parents = append(parents, newDagNode(children, unixfsFile(nil, filesize, blocksizes), filesize))
+		}
+		level = parents
+	}
+	return base58.Encode(level[0].hash)
+}
+
+func newDagNode(links []dagNode, data []byte, filesize uint64) dagNode {
+	// dag-pb puts Links (field 2) before Data (field 1).
+	var encoded []byte
+	node := dagNode{filesize: filesize}
+	for _, link := range links {
+		var pbLink []byte
+		pbLink = appendBytes(pbLink, 1, link.hash)
+		pbLink = appendBytes(pbLink, 2, nil) // empty name
+		pbLink = appendVarint(pbLink, 3, link.tsize)
+		encoded = appendBytes(encoded, 2, pbLink)
+		node.tsize += link.tsize
+	}
+	encoded = appendBytes(encoded, 1, data)
+
+	digest := sha256.Sum256(encoded)
+	node.hash = append([]byte{0x12, 0x20}, digest[:]...)
+	node.tsize += uint64(len(encoded))
+	return node
+}
+
+// unixfsFile encodes a UnixFS Data message of type File.
+func unixfsFile(content []byte, filesize uint64, blocksizes []uint64) []byte {
+	var msg []byte
+	msg = appendVarint(msg, 1, 2) // Type: File
+	if len(content) > 0 {
+		msg = appendBytes(msg, 2, content)
+	}
+	msg = appendVarint(msg, 3, filesize)
+	for _, size := range blocksizes {
+		msg = appendVarint(msg, 4, size)
+	}
+	return msg
+}
+
+func appendVarint(b []byte, field int, value uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3)
+	return binary.AppendUvarint(b, value)
+}
+
+func appendBytes(b []byte, field int, value []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
@@ -0,0 +1,117 @@
+// Package dbtest provides throwaway, fully migrated Postgres databases for
+// tests that need to run the real queries.
+package dbtest
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/lib/pq"
+	"pixelmap.io/backend/internal/db"
+)
+
+// Open returns a connection to an empty, migrated database that is discarded
+// when the test finishes.
+//
+// When TEST_DATABASE_URL is set the database is a fresh schema inside that
+// server. Otherwise an embedded Postgres is started for the test. If neither
+// is possible (short mode, no binaries offline, running as root) the test is
+// skipped rather than failed, unless DBTEST_REQUIRED is set.
+func Open(t testing.TB) *sql.DB {
+	t.Helper()
+
+	if testing.Short() {
+		skipf(t, "skipping database test in short mode")
+	}
+
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		databaseURL = startEmbedded(t)
+	}
+
+	schema := "test_" + randomSuffix(t)
+	conn, err := db.Open(databaseURL, schema)
+	if err != nil {
+		skipf(t, "postgres unavailable: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Exec("DROP SCHEMA IF EXISTS " + pq.QuoteIdentifier(schema) + " CASCADE")
+		conn.Close()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := conn.PingContext(ctx); err != nil {
+		skipf(t, "postgres unavailable: %v", err)
+	}
+	if err := db.Migrate(ctx, conn); err != nil {
+		t.Fatalf("migrating test database: %v", err)
+	}
+
+	return conn
+}
+
+// skipf skips the test, or fails it when DBTEST_REQUIRED is set so that CI
+// cannot pass without running the database tests.
+func skipf(t testing.TB, format string, args ...interface{}) {
+	t.Helper()
+	if os.Getenv("DBTEST_REQUIRED") != "" {
+		t.Fatalf(format, args...)
+	}
+	t.Skipf(format, args...)
+}
+
+func startEmbedded(t testing.TB) string {
+	t.Helper()
+
+	port, err := freePort()
+	if err != nil {
+		skipf(t, "no free port for embedded postgres: %v", err)
+	}
+
+	dir := t.TempDir()
+	config := embeddedpostgres.DefaultConfig().
+		Port(port).
+		RuntimePath(filepath.Join(dir, "runtime")).
+		DataPath(filepath.Join(dir, "data")).
+		StartTimeout(45 * time.Second).
+		Logger(io.Discard)
+
+	server := embeddedpostgres.NewDatabase(config)
+	if err := server.Start(); err != nil {
+		skipf(t, "embedded postgres unavailable (set TEST_DATABASE_URL to use an existing server): %v", err)
+	}
+	t.Cleanup(func() {
+		if err := server.Stop(); err != nil {
+			t.Logf("stopping embedded postgres: %v", err)
+		}
+	})
+
+	return config.GetConnectionURL() + "?sslmode=disable"
+}
+
+func freePort() (uint32, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return uint32(listener.Addr().(*net.TCPAddr).Port), nil
+}
+
+func randomSuffix(t testing.TB) string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		t.Fatalf("generating schema name: %v", err)
+	}
+	return hex.EncodeToString(buf)
+}
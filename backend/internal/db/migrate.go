@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Open connects to Postgres with every unqualified table name resolved inside
+// schema, so each network profile keeps its own copy of the tables. The
+// schema is created if it does not exist yet.
+func Open(databaseURL, schema string) (*sql.DB, error) {
+	conn, err := sql.Open("postgres", withSearchPath(databaseURL, schema))
+	if err != nil {
+		return nil, err
+	}
+
+	if schema != "" && schema != "public" {
+		if _, err := conn.Exec("CREATE SCHEMA IF NOT EXISTS " + pq.QuoteIdentifier(schema)); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("creating schema %s: %w", schema, err)
+		}
+	}
+
+	return conn, nil
+}
+
+// withSearchPath adds a search_path run-time parameter to either form of
+// connection string lib/pq accepts.
+func withSearchPath(databaseURL, schema string) string {
+	if schema == "" || schema == "public" {
+		return databaseURL
+	}
+
+	if strings.HasPrefix(databaseURL, "postgres://") || strings.HasPrefix(databaseURL, "postgresql://") {
+		u, err := url.Parse(databaseURL)
+		if err == nil {
+			q := u.Query()
+			q.Set("search_path", schema)
+			u.RawQuery = q.Encode()
+			return u.String()
+		}
+	}
+
+	return strings.TrimSpace(databaseURL + " search_path=" + schema)
+}
+
+// Migrate applies every embedded migration that has not been recorded in
+// schema_migrations yet, each in its own transaction.
+func Migrate(ctx context.Context, conn *sql.DB) error {
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version VARCHAR(255) PRIMARY KEY,
+    applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+)`); err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+
+	names, err := migrationNames()
+	if err != nil {
+		return err
+	}
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	// Databases created before migrations were tracked already have the
+	// initial schema; record it instead of failing on CREATE TABLE.
+	if len(applied) == 0 {
+		var existing sql.NullString
+		if err := conn.QueryRowContext(ctx, `SELECT to_regclass('tiles')::TEXT`).Scan(&existing); err != nil {
+			return fmt.Errorf("checking for existing schema: %w", err)
+		}
+		if existing.Valid {
+			if _, err := conn.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, names[0]); err != nil {
+				return fmt.Errorf("recording initial schema: %w", err)
+			}
+			applied[names[0]] = true
+		}
+	}
+
+	for _, name := range names {
+		if applied[name] {
+			continue
+		}
+		if err := applyMigration(ctx, conn, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func migrationNames() ([]string, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no migrations embedded")
+	}
+	return names, nil
+}
+
+func appliedMigrations(ctx context.Context, conn *sql.DB) (map[string]bool, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("listing applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+func applyMigration(ctx context.Context, conn *sql.DB, name string) error {
+	contents, err := migrationFiles.ReadFile(path.Join("migrations", name))
+	if err != nil {
+		return fmt.Errorf("reading migration %s: %w", name, err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, string(contents)); err != nil {
+		return fmt.Errorf("applying migration %s: %w", name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, name); err != nil {
+		return fmt.Errorf("recording migration %s: %w", name, err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,15 @@
+-- 002_tile_repairs.sql
+
+-- Audit trail for corrections written by `verify --repair`. Each row is one
+-- field of one tile that disagreed with the chain at block_number.
+CREATE TABLE tile_repairs (
+    id SERIAL PRIMARY KEY,
+    tile_id INTEGER NOT NULL REFERENCES tiles(id),
+    field VARCHAR(32) NOT NULL,
+    old_value TEXT NOT NULL,
+    new_value TEXT NOT NULL,
+    block_number BIGINT NOT NULL,
+    repaired_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX tile_repairs_tile_id_idx ON tile_repairs (tile_id);
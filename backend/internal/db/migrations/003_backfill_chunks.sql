@@ -0,0 +1,18 @@
+-- 003_backfill_chunks.sql
+
+-- Progress of a historical backfill. Each row is one block range: fetched by
+-- any worker in parallel, then applied strictly in start_block order. Fetched
+-- results are kept until the chunk is applied so an interrupted run resumes
+-- without calling Etherscan again.
+CREATE TABLE backfill_chunks (
+    id SERIAL PRIMARY KEY,
+    start_block BIGINT NOT NULL,
+    end_block BIGINT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    transactions JSONB NOT NULL DEFAULT '[]',
+    transaction_count INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(start_block, end_block)
+);
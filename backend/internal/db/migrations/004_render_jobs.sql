@@ -0,0 +1,29 @@
+-- 004_render_jobs.sql
+
+-- Durable queue of tile renders. A job renders a tile's images and metadata
+-- up to block_number. Each tile has at most one pending job: further updates
+-- raise its block instead of adding rows, so a tile changed many times while
+-- the renderer is busy is rendered once. Failed attempts go back to pending
+-- with a delay until max attempts, then stay as 'failed' with their error.
+CREATE TABLE render_jobs (
+    id SERIAL PRIMARY KEY,
+    tile_id INTEGER NOT NULL REFERENCES tiles(id),
+    block_number BIGINT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    run_after TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX render_jobs_pending_tile ON render_jobs (tile_id) WHERE status = 'pending';
+CREATE INDEX render_jobs_tile_block ON render_jobs (tile_id, block_number);
+CREATE INDEX render_jobs_status ON render_jobs (status, run_after);
+
+-- Carry over data history the old renderer had not reached yet.
+INSERT INTO render_jobs (tile_id, block_number)
+SELECT tile_id, MAX(block_number)
+FROM data_histories
+WHERE id > COALESCE((SELECT value FROM current_state WHERE state = 'LAST_PROCESSED_DATA_HISTORY_ID'), 0)
+GROUP BY tile_id;
@@ -0,0 +1,37 @@
+-- 005_normalize_addresses.sql
+
+-- Addresses were stored in whichever case their source used: transfers wrote
+-- EIP-55 checksums while purchases wrote Etherscan's lowercase form, so the
+-- same owner could appear twice. Store every address lowercase and keep it
+-- that way, so owners can be matched with plain equality and an index.
+UPDATE tiles SET owner = LOWER(owner) WHERE owner <> LOWER(owner);
+
+UPDATE purchase_histories
+SET sold_by = LOWER(sold_by), purchased_by = LOWER(purchased_by)
+WHERE sold_by <> LOWER(sold_by) OR purchased_by <> LOWER(purchased_by);
+
+UPDATE transfer_histories
+SET transferred_from = LOWER(transferred_from), transferred_to = LOWER(transferred_to)
+WHERE transferred_from <> LOWER(transferred_from) OR transferred_to <> LOWER(transferred_to);
+
+UPDATE wrapping_histories SET updated_by = LOWER(updated_by) WHERE updated_by <> LOWER(updated_by);
+
+-- updated_by holds the ENS name instead of the address when one was known.
+UPDATE data_histories SET updated_by = LOWER(updated_by)
+WHERE updated_by ~ '^0x[0-9a-fA-F]{40}$' AND updated_by <> LOWER(updated_by);
+
+UPDATE pixel_map_transaction
+SET "from" = LOWER("from"), "to" = LOWER("to"), contract_address = LOWER(contract_address)
+WHERE "from" <> LOWER("from") OR "to" <> LOWER("to") OR contract_address <> LOWER(contract_address);
+
+ALTER TABLE tiles ADD CONSTRAINT tiles_owner_lowercase CHECK (owner = LOWER(owner));
+ALTER TABLE purchase_histories ADD CONSTRAINT purchase_histories_addresses_lowercase
+    CHECK (sold_by = LOWER(sold_by) AND purchased_by = LOWER(purchased_by));
+ALTER TABLE transfer_histories ADD CONSTRAINT transfer_histories_addresses_lowercase
+    CHECK (transferred_from = LOWER(transferred_from) AND transferred_to = LOWER(transferred_to));
+ALTER TABLE wrapping_histories ADD CONSTRAINT wrapping_histories_updated_by_lowercase
+    CHECK (updated_by = LOWER(updated_by));
+
+CREATE INDEX tiles_owner_idx ON tiles (owner);
+CREATE INDEX purchase_histories_purchased_by_idx ON purchase_histories (purchased_by);
+CREATE INDEX transfer_histories_transferred_to_idx ON transfer_histories (transferred_to);
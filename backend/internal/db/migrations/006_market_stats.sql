@@ -0,0 +1,61 @@
+-- 006_market_stats.sql
+
+-- Which contract a sale went through. Every purchase recorded so far was a
+-- buyTile on the original contract.
+ALTER TABLE purchase_histories
+    ADD COLUMN market VARCHAR(16) NOT NULL DEFAULT 'original'
+    CHECK (market IN ('original', 'wrapper'));
+
+-- Sale aggregates per day, per month and over all time, for each market and
+-- for both together (market 'all'). They are materialized views refreshed
+-- after every ingestion cycle rather than computed per request. The median is
+-- the mean of the lower and upper middle values so it stays exact.
+CREATE MATERIALIZED VIEW market_stats_daily AS
+SELECT
+    date_trunc('day', time_stamp)::DATE AS period,
+    COALESCE(market, 'all')::VARCHAR(16) AS market,
+    COUNT(*)::INTEGER AS sales,
+    SUM(price)::NUMERIC AS volume,
+    COUNT(DISTINCT purchased_by)::INTEGER AS unique_buyers,
+    AVG(price)::NUMERIC AS average_price,
+    ((percentile_disc(0.5) WITHIN GROUP (ORDER BY price)
+      + percentile_disc(0.5) WITHIN GROUP (ORDER BY price DESC)) / 2)::NUMERIC AS median_price,
+    MIN(price)::NUMERIC AS min_price,
+    MAX(price)::NUMERIC AS max_price
+FROM purchase_histories
+GROUP BY GROUPING SETS ((date_trunc('day', time_stamp)::DATE, market), (date_trunc('day', time_stamp)::DATE));
+
+CREATE UNIQUE INDEX market_stats_daily_period_market_idx ON market_stats_daily (period, market);
+
+CREATE MATERIALIZED VIEW market_stats_monthly AS
+SELECT
+    date_trunc('month', time_stamp)::DATE AS period,
+    COALESCE(market, 'all')::VARCHAR(16) AS market,
+    COUNT(*)::INTEGER AS sales,
+    SUM(price)::NUMERIC AS volume,
+    COUNT(DISTINCT purchased_by)::INTEGER AS unique_buyers,
+    AVG(price)::NUMERIC AS average_price,
+    ((percentile_disc(0.5) WITHIN GROUP (ORDER BY price)
+      + percentile_disc(0.5) WITHIN GROUP (ORDER BY price DESC)) / 2)::NUMERIC AS median_price,
+    MIN(price)::NUMERIC AS min_price,
+    MAX(price)::NUMERIC AS max_price
+FROM purchase_histories
+GROUP BY GROUPING SETS ((date_trunc('month', time_stamp)::DATE, market), (date_trunc('month', time_stamp)::DATE));
+
+CREATE UNIQUE INDEX market_stats_monthly_period_market_idx ON market_stats_monthly (period, market);
+
+CREATE MATERIALIZED VIEW market_stats_totals AS
+SELECT
+    COALESCE(market, 'all')::VARCHAR(16) AS market,
+    COUNT(*)::INTEGER AS sales,
+    SUM(price)::NUMERIC AS volume,
+    COUNT(DISTINCT purchased_by)::INTEGER AS unique_buyers,
+    AVG(price)::NUMERIC AS average_price,
+    ((percentile_disc(0.5) WITHIN GROUP (ORDER BY price)
+      + percentile_disc(0.5) WITHIN GROUP (ORDER BY price DESC)) / 2)::NUMERIC AS median_price,
+    MIN(price)::NUMERIC AS min_price,
+    MAX(price)::NUMERIC AS max_price
+FROM purchase_histories
+GROUP BY GROUPING SETS ((market), ());
+
+CREATE UNIQUE INDEX market_stats_totals_market_idx ON market_stats_totals (market);
@@ -0,0 +1,77 @@
+-- 007_eth_prices.sql
+
+-- Daily ETH/USD prices, one per UTC day, from whichever source imported them
+-- last (see internal/prices). Sales are valued at the price of the day they
+-- happened on.
+CREATE TABLE eth_prices (
+    day DATE PRIMARY KEY,
+    price_usd NUMERIC(20, 8) NOT NULL CHECK (price_usd > 0),
+    source VARCHAR(32) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- The stats views gain the USD volume. priced_sales counts the sales that had
+-- a price for their day, so a partial import is visible rather than silently
+-- undercounted.
+DROP MATERIALIZED VIEW market_stats_daily;
+DROP MATERIALIZED VIEW market_stats_monthly;
+DROP MATERIALIZED VIEW market_stats_totals;
+
+CREATE MATERIALIZED VIEW market_stats_daily AS
+SELECT
+    date_trunc('day', time_stamp)::DATE AS period,
+    COALESCE(market, 'all')::VARCHAR(16) AS market,
+    COUNT(*)::INTEGER AS sales,
+    SUM(price)::NUMERIC AS volume,
+    COUNT(DISTINCT purchased_by)::INTEGER AS unique_buyers,
+    AVG(price)::NUMERIC AS average_price,
+    ((percentile_disc(0.5) WITHIN GROUP (ORDER BY price)
+      + percentile_disc(0.5) WITHIN GROUP (ORDER BY price DESC)) / 2)::NUMERIC AS median_price,
+    MIN(price)::NUMERIC AS min_price,
+    MAX(price)::NUMERIC AS max_price,
+    SUM(price * eth_prices.price_usd)::NUMERIC(20, 2) AS volume_usd,
+    COUNT(eth_prices.price_usd)::INTEGER AS priced_sales
+FROM purchase_histories
+LEFT JOIN eth_prices ON eth_prices.day = purchase_histories.time_stamp::DATE
+GROUP BY GROUPING SETS ((date_trunc('day', time_stamp)::DATE, market), (date_trunc('day', time_stamp)::DATE));
+
+CREATE UNIQUE INDEX market_stats_daily_period_market_idx ON market_stats_daily (period, market);
+
+CREATE MATERIALIZED VIEW market_stats_monthly AS
+SELECT
+    date_trunc('month', time_stamp)::DATE AS period,
+    COALESCE(market, 'all')::VARCHAR(16) AS market,
+    COUNT(*)::INTEGER AS sales,
+    SUM(price)::NUMERIC AS volume,
+    COUNT(DISTINCT purchased_by)::INTEGER AS unique_buyers,
+    AVG(price)::NUMERIC AS average_price,
+    ((percentile_disc(0.5) WITHIN GROUP (ORDER BY price)
+      + percentile_disc(0.5) WITHIN GROUP (ORDER BY price DESC)) / 2)::NUMERIC AS median_price,
+    MIN(price)::NUMERIC AS min_price,
+    MAX(price)::NUMERIC AS max_price,
+    SUM(price * eth_prices.price_usd)::NUMERIC(20, 2) AS volume_usd,
+    COUNT(eth_prices.price_usd)::INTEGER AS priced_sales
+FROM purchase_histories
+LEFT JOIN eth_prices ON eth_prices.day = purchase_histories.time_stamp::DATE
+GROUP BY GROUPING SETS ((date_trunc('month', time_stamp)::DATE, market), (date_trunc('month', time_stamp)::DATE));
+
+CREATE UNIQUE INDEX market_stats_monthly_period_market_idx ON market_stats_monthly (period, market);
+
+CREATE MATERIALIZED VIEW market_stats_totals AS
+SELECT
+    COALESCE(market, 'all')::VARCHAR(16) AS market,
+    COUNT(*)::INTEGER AS sales,
+    SUM(price)::NUMERIC AS volume,
+    COUNT(DISTINCT purchased_by)::INTEGER AS unique_buyers,
+    AVG(price)::NUMERIC AS average_price,
+    ((percentile_disc(0.5) WITHIN GROUP (ORDER BY price)
+      + percentile_disc(0.5) WITHIN GROUP (ORDER BY price DESC)) / 2)::NUMERIC AS median_price,
+    MIN(price)::NUMERIC AS min_price,
+    MAX(price)::NUMERIC AS max_price,
+    SUM(price * eth_prices.price_usd)::NUMERIC(20, 2) AS volume_usd,
+    COUNT(eth_prices.price_usd)::INTEGER AS priced_sales
+FROM purchase_histories
+LEFT JOIN eth_prices ON eth_prices.day = purchase_histories.time_stamp::DATE
+GROUP BY GROUPING SETS ((market), ());
+
+CREATE UNIQUE INDEX market_stats_totals_market_idx ON market_stats_totals (market);
@@ -0,0 +1,172 @@
+-- 008_wei_prices.sql
+
+-- Prices were stored as ETH in DECIMAL(10, 2), which rounded anything below
+-- a cent (0.002 ETH became 0.00), and in tiles.price as a string capped at
+-- 1000 ETH. From here on tiles.price, data_histories.price and
+-- purchase_histories.price hold exact wei; the API and the JSON exports
+-- format them as ETH.
+
+-- The stats views depend on purchase_histories.price; they are recreated
+-- below over wei.
+DROP MATERIALIZED VIEW market_stats_daily;
+DROP MATERIALIZED VIEW market_stats_monthly;
+DROP MATERIALIZED VIEW market_stats_totals;
+
+-- Decodes a big-endian hex word, e.g. a uint256 calldata argument.
+CREATE FUNCTION pg_temp.hex_to_numeric(hex TEXT) RETURNS NUMERIC AS $$
+DECLARE
+    result NUMERIC := 0;
+    i INTEGER;
+BEGIN
+    FOR i IN 1..length(hex) LOOP
+        result := result * 16 + (position(lower(substr(hex, i, 1)) IN '0123456789abcdef') - 1);
+    END LOOP;
+    RETURN result;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Convert what is stored first; the exact values from calldata replace it
+-- where the transaction is archived.
+ALTER TABLE data_histories
+    ALTER COLUMN price TYPE NUMERIC(78, 0) USING ROUND(price * 1e18);
+
+ALTER TABLE purchase_histories
+    ALTER COLUMN price TYPE NUMERIC(78, 0) USING ROUND(price * 1e18);
+
+ALTER TABLE tiles
+    ALTER COLUMN price TYPE NUMERIC(78, 0) USING CASE
+        WHEN price ~ '^[0-9]+(\.[0-9]+)?$' THEN ROUND(price::NUMERIC * 1e18)
+        ELSE 0
+    END;
+
+-- setTile(uint256 location, string image, string url, uint256 price): the
+-- price is the fourth head word of the calldata, after the selector.
+UPDATE data_histories d
+SET price = pg_temp.hex_to_numeric(substr(t.input, 3 + 8 + 3 * 64, 64))
+FROM pixel_map_transaction t
+WHERE t.hash = d.tx
+  AND left(t.input, 10) = '0x678d9758'
+  AND length(t.input) >= 2 + 8 + 4 * 64;
+
+-- setTileData is the wrapper calling setTile with a price of 0, which leaves
+-- the wrapped tile unlisted on the original contract.
+UPDATE data_histories d
+SET price = 0
+FROM pixel_map_transaction t
+WHERE t.hash = d.tx
+  AND left(t.input, 10) = '0x345dadeb';
+
+-- What a tile was listed at on the original contract, and from when: setTile
+-- prices, and unwrap(uint256 location, uint256 _salePrice), 0x6e286671, with
+-- which the wrapper lists the tile at the sale price.
+CREATE TEMP VIEW tile_listings AS
+SELECT tile_id, block_number, log_index, price
+FROM data_histories
+WHERE price IS NOT NULL
+UNION ALL
+SELECT pg_temp.hex_to_numeric(substr(input, 3 + 8, 64))::INTEGER,
+    block_number,
+    transaction_index,
+    pg_temp.hex_to_numeric(substr(input, 3 + 8 + 64, 64))
+FROM pixel_map_transaction
+WHERE left(input, 10) = '0x6e286671'
+  AND NOT is_error
+  AND length(input) >= 2 + 8 + 2 * 64;
+
+-- A purchase was recorded at the tile's asking price: the last listing
+-- before it, or the 2 ETH unclaimed tiles sold for, which the converted
+-- value already holds.
+UPDATE purchase_histories p
+SET price = l.price
+FROM LATERAL (
+    SELECT price FROM tile_listings
+    WHERE tile_id = p.tile_id
+      AND (block_number, log_index) < (p.block_number, p.log_index)
+    ORDER BY block_number DESC, log_index DESC
+    LIMIT 1
+) l
+WHERE l.price > 0;
+
+-- buyTile and wrap leave a tile unlisted on the original contract, so its
+-- price is the last listing only when nothing bought it since.
+UPDATE tiles
+SET price = CASE WHEN latest.kind = 'set' THEN latest.price ELSE 0 END
+FROM (
+    SELECT DISTINCT ON (tile_id) tile_id, kind, price
+    FROM (
+        SELECT tile_id, block_number, log_index, 'set' AS kind, price FROM tile_listings
+        UNION ALL
+        SELECT tile_id, block_number, log_index, 'bought', NULL FROM purchase_histories
+        UNION ALL
+        SELECT tile_id, block_number, log_index, 'bought', NULL FROM wrapping_histories WHERE wrapped
+    ) events
+    ORDER BY tile_id, block_number DESC, log_index DESC
+) latest
+WHERE latest.tile_id = tiles.id;
+
+DROP VIEW tile_listings;
+
+ALTER TABLE tiles ALTER COLUMN price SET DEFAULT 2000000000000000000;
+ALTER TABLE tiles ADD CONSTRAINT tiles_price_nonnegative CHECK (price >= 0);
+ALTER TABLE data_histories ADD CONSTRAINT data_histories_price_nonnegative CHECK (price >= 0);
+ALTER TABLE purchase_histories ADD CONSTRAINT purchase_histories_price_nonnegative CHECK (price >= 0);
+
+-- As in 007, over wei. Averages and medians are rounded to whole wei.
+CREATE MATERIALIZED VIEW market_stats_daily AS
+SELECT
+    date_trunc('day', time_stamp)::DATE AS period,
+    COALESCE(market, 'all')::VARCHAR(16) AS market,
+    COUNT(*)::INTEGER AS sales,
+    SUM(price)::NUMERIC(78, 0) AS volume,
+    COUNT(DISTINCT purchased_by)::INTEGER AS unique_buyers,
+    ROUND(AVG(price))::NUMERIC(78, 0) AS average_price,
+    ROUND((percentile_disc(0.5) WITHIN GROUP (ORDER BY price)
+      + percentile_disc(0.5) WITHIN GROUP (ORDER BY price DESC)) / 2)::NUMERIC(78, 0) AS median_price,
+    MIN(price)::NUMERIC(78, 0) AS min_price,
+    MAX(price)::NUMERIC(78, 0) AS max_price,
+    SUM(price * eth_prices.price_usd / 1e18)::NUMERIC(20, 2) AS volume_usd,
+    COUNT(eth_prices.price_usd)::INTEGER AS priced_sales
+FROM purchase_histories
+LEFT JOIN eth_prices ON eth_prices.day = purchase_histories.time_stamp::DATE
+GROUP BY GROUPING SETS ((date_trunc('day', time_stamp)::DATE, market), (date_trunc('day', time_stamp)::DATE));
+
+CREATE UNIQUE INDEX market_stats_daily_period_market_idx ON market_stats_daily (period, market);
+
+CREATE MATERIALIZED VIEW market_stats_monthly AS
+SELECT
+    date_trunc('month', time_stamp)::DATE AS period,
+    COALESCE(market, 'all')::VARCHAR(16) AS market,
+    COUNT(*)::INTEGER AS sales,
+    SUM(price)::NUMERIC(78, 0) AS volume,
+    COUNT(DISTINCT purchased_by)::INTEGER AS unique_buyers,
+    ROUND(AVG(price))::NUMERIC(78, 0) AS average_price,
+    ROUND((percentile_disc(0.5) WITHIN GROUP (ORDER BY price)
+      + percentile_disc(0.5) WITHIN GROUP (ORDER BY price DESC)) / 2)::NUMERIC(78, 0) AS median_price,
+    MIN(price)::NUMERIC(78, 0) AS min_price,
+    MAX(price)::NUMERIC(78, 0) AS max_price,
+    SUM(price * eth_prices.price_usd / 1e18)::NUMERIC(20, 2) AS volume_usd,
+    COUNT(eth_prices.price_usd)::INTEGER AS priced_sales
+FROM purchase_histories
+LEFT JOIN eth_prices ON eth_prices.day = purchase_histories.time_stamp::DATE
+GROUP BY GROUPING SETS ((date_trunc('month', time_stamp)::DATE, market), (date_trunc('month', time_stamp)::DATE));
+
+CREATE UNIQUE INDEX market_stats_monthly_period_market_idx ON market_stats_monthly (period, market);
+
+CREATE MATERIALIZED VIEW market_stats_totals AS
+SELECT
+    COALESCE(market, 'all')::VARCHAR(16) AS market,
+    COUNT(*)::INTEGER AS sales,
+    SUM(price)::NUMERIC(78, 0) AS volume,
+    COUNT(DISTINCT purchased_by)::INTEGER AS unique_buyers,
+    ROUND(AVG(price))::NUMERIC(78, 0) AS average_price,
+    ROUND((percentile_disc(0.5) WITHIN GROUP (ORDER BY price)
+      + percentile_disc(0.5) WITHIN GROUP (ORDER BY price DESC)) / 2)::NUMERIC(78, 0) AS median_price,
+    MIN(price)::NUMERIC(78, 0) AS min_price,
+    MAX(price)::NUMERIC(78, 0) AS max_price,
+    SUM(price * eth_prices.price_usd / 1e18)::NUMERIC(20, 2) AS volume_usd,
+    COUNT(eth_prices.price_usd)::INTEGER AS priced_sales
+FROM purchase_histories
+LEFT JOIN eth_prices ON eth_prices.day = purchase_histories.time_stamp::DATE
+GROUP BY GROUPING SETS ((market), ());
+
+CREATE UNIQUE INDEX market_stats_totals_market_idx ON market_stats_totals (market);
@@ -0,0 +1,130 @@
+-- 009_wrapper_proceeds.sql
+
+-- Unwrapping lists a tile on the original contract from the wrapper, which
+-- holds the sale proceeds until the unwrapper calls withdrawETH. Each row is
+-- one step of that: the listing made by unwrap, the buyTile that sold it and
+-- the withdrawal of the proceeds. Amounts are in wei.
+CREATE TABLE wrapper_proceeds_histories (
+    id SERIAL PRIMARY KEY,
+    time_stamp TIMESTAMP NOT NULL,
+    block_number BIGINT NOT NULL,
+    tx VARCHAR(66) NOT NULL,
+    log_index INTEGER NOT NULL,
+    event VARCHAR(16) NOT NULL CHECK (event IN ('listed', 'sold', 'withdrawn')),
+    seller VARCHAR(42) NOT NULL,
+    buyer VARCHAR(42),
+    amount NUMERIC(78, 0) NOT NULL CHECK (amount >= 0),
+    tile_id INTEGER NOT NULL REFERENCES tiles(id),
+    UNIQUE(tile_id, tx, event)
+);
+
+CREATE INDEX wrapper_proceeds_histories_tile_idx ON wrapper_proceeds_histories (tile_id, block_number, log_index);
+CREATE INDEX wrapper_proceeds_histories_seller_idx ON wrapper_proceeds_histories (seller);
+
+-- The wrapper's pendingLocationSales as of the last ingested block: the
+-- latest listing of each tile, whether it sold and whether the seller has
+-- withdrawn since. withdrawable matches getwithdrawableETHforLocation.
+CREATE VIEW wrapper_pending_sales AS
+SELECT
+    listing.tile_id,
+    listing.seller,
+    listing.amount AS sale_price,
+    listing.tx AS listed_tx,
+    listing.time_stamp AS listed_at,
+    sale.buyer,
+    sale.tx AS sold_tx,
+    sale.time_stamp AS sold_at,
+    withdrawal.tx AS withdrawn_tx,
+    withdrawal.time_stamp AS withdrawn_at,
+    (CASE WHEN sale.id IS NOT NULL AND withdrawal.id IS NULL THEN listing.amount ELSE 0 END)::NUMERIC(78, 0) AS withdrawable
+FROM (
+    SELECT DISTINCT ON (tile_id) *
+    FROM wrapper_proceeds_histories
+    WHERE event = 'listed'
+    ORDER BY tile_id, block_number DESC, log_index DESC
+) listing
+LEFT JOIN LATERAL (
+    SELECT * FROM wrapper_proceeds_histories s
+    WHERE s.tile_id = listing.tile_id AND s.event = 'sold'
+      AND (s.block_number, s.log_index) > (listing.block_number, listing.log_index)
+    ORDER BY s.block_number, s.log_index
+    LIMIT 1
+) sale ON TRUE
+LEFT JOIN LATERAL (
+    SELECT * FROM wrapper_proceeds_histories w
+    WHERE w.tile_id = listing.tile_id AND w.event = 'withdrawn'
+      AND (w.block_number, w.log_index) > (listing.block_number, listing.log_index)
+    ORDER BY w.block_number, w.log_index
+    LIMIT 1
+) withdrawal ON TRUE;
+
+-- Backfill from the archived wrapper calls. Selectors are those of
+-- unwrap(uint256,uint256) and withdrawETH(uint256); arguments are 32-byte
+-- words after the selector.
+CREATE OR REPLACE FUNCTION pg_temp.hex_to_numeric(hex TEXT) RETURNS NUMERIC AS $$
+DECLARE
+    result NUMERIC := 0;
+    i INTEGER;
+BEGIN
+    FOR i IN 1..length(hex) LOOP
+        result := result * 16 + (position(lower(substr(hex, i, 1)) IN '0123456789abcdef') - 1);
+    END LOOP;
+    RETURN result;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+INSERT INTO wrapper_proceeds_histories (time_stamp, block_number, tx, log_index, event, seller, amount, tile_id)
+SELECT t.time_stamp, t.block_number, t.hash, t.transaction_index, 'listed', t."from",
+       pg_temp.hex_to_numeric(substr(t.input, 3 + 8 + 64, 64)),
+       pg_temp.hex_to_numeric(substr(t.input, 3 + 8, 64))::INTEGER
+FROM pixel_map_transaction t
+WHERE left(t.input, 10) = '0x6e286671'
+  AND length(t.input) >= 2 + 8 + 2 * 64
+  AND NOT t.is_error
+  AND EXISTS (SELECT 1 FROM tiles WHERE id = pg_temp.hex_to_numeric(substr(t.input, 3 + 8, 64)))
+ON CONFLICT DO NOTHING;
+
+-- A tile listed by the wrapper sells to the first buyTile after the listing.
+-- The proceeds belong to the unwrapper, who is the real seller.
+WITH sales AS (
+    SELECT DISTINCT ON (l.id) l.id AS listing_id, l.seller, l.amount, p.id AS purchase_id
+    FROM wrapper_proceeds_histories l
+    JOIN purchase_histories p ON p.tile_id = l.tile_id
+        AND (p.block_number, p.log_index) > (l.block_number, l.log_index)
+    WHERE l.event = 'listed'
+      AND l.amount > 0
+      AND NOT EXISTS (
+          SELECT 1 FROM wrapper_proceeds_histories later
+          WHERE later.tile_id = l.tile_id AND later.event = 'listed'
+            AND (later.block_number, later.log_index) > (l.block_number, l.log_index)
+            AND (later.block_number, later.log_index) < (p.block_number, p.log_index)
+      )
+    ORDER BY l.id, p.block_number, p.log_index
+),
+recorded AS (
+    INSERT INTO wrapper_proceeds_histories (time_stamp, block_number, tx, log_index, event, seller, buyer, amount, tile_id)
+    SELECT p.time_stamp, p.block_number, p.tx, p.log_index, 'sold', sales.seller, p.purchased_by, sales.amount, p.tile_id
+    FROM sales
+    JOIN purchase_histories p ON p.id = sales.purchase_id
+    ON CONFLICT DO NOTHING
+)
+UPDATE purchase_histories p
+SET market = 'wrapper', sold_by = sales.seller
+FROM sales
+WHERE p.id = sales.purchase_id;
+
+INSERT INTO wrapper_proceeds_histories (time_stamp, block_number, tx, log_index, event, seller, amount, tile_id)
+SELECT t.time_stamp, t.block_number, t.hash, t.transaction_index, 'withdrawn', t."from", pending.amount, pending.tile_id
+FROM pixel_map_transaction t
+JOIN LATERAL (
+    SELECT tile_id, amount FROM wrapper_proceeds_histories l
+    WHERE l.event = 'listed'
+      AND l.tile_id = pg_temp.hex_to_numeric(substr(t.input, 3 + 8, 64))
+      AND (l.block_number, l.log_index) < (t.block_number, t.transaction_index)
+    ORDER BY l.block_number DESC, l.log_index DESC
+    LIMIT 1
+) pending ON TRUE
+WHERE left(t.input, 10) = '0xf14210a6'
+  AND length(t.input) >= 2 + 8 + 64
+  AND NOT t.is_error
+ON CONFLICT DO NOTHING;
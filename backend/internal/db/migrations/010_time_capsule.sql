@@ -0,0 +1,57 @@
+-- 010_time_capsule.sql
+
+-- PixelMapTimeCapsule is a plain ERC-721 whose owner mints editions with
+-- MultiMintOwner and points every token at one metadata file with
+-- setBaseTokenURI. Mints are recorded per call; who holds each token comes
+-- from its Transfer events, mints included.
+CREATE TABLE time_capsule_mints (
+    id SERIAL PRIMARY KEY,
+    time_stamp TIMESTAMP NOT NULL,
+    block_number BIGINT NOT NULL,
+    tx VARCHAR(66) NOT NULL,
+    log_index INTEGER NOT NULL,
+    minted_by VARCHAR(42) NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    UNIQUE(tx)
+);
+
+CREATE TABLE time_capsule_transfers (
+    id SERIAL PRIMARY KEY,
+    time_stamp TIMESTAMP NOT NULL,
+    block_number BIGINT NOT NULL,
+    tx VARCHAR(66) NOT NULL,
+    log_index INTEGER NOT NULL,
+    token_id INTEGER NOT NULL,
+    transferred_from VARCHAR(42) NOT NULL,
+    transferred_to VARCHAR(42) NOT NULL,
+    UNIQUE(tx, log_index)
+);
+
+CREATE INDEX time_capsule_transfers_token_idx ON time_capsule_transfers (token_id, block_number, log_index);
+CREATE INDEX time_capsule_transfers_to_idx ON time_capsule_transfers (transferred_to);
+
+-- The owner's changes to the collection's metadata. value is the new URI, or
+-- NULL for lockMetadata, after which neither URI can change.
+CREATE TABLE time_capsule_metadata_changes (
+    id SERIAL PRIMARY KEY,
+    time_stamp TIMESTAMP NOT NULL,
+    block_number BIGINT NOT NULL,
+    tx VARCHAR(66) NOT NULL,
+    log_index INTEGER NOT NULL,
+    action VARCHAR(16) NOT NULL CHECK (action IN ('setBaseTokenURI', 'setContractURI', 'lockMetadata')),
+    value TEXT,
+    updated_by VARCHAR(42) NOT NULL,
+    UNIQUE(tx)
+);
+
+-- Each token's holder as of the last ingested block. Burnt tokens are held
+-- by the zero address.
+CREATE VIEW time_capsule_tokens AS
+SELECT DISTINCT ON (token_id)
+    token_id,
+    transferred_to AS owner,
+    time_stamp AS acquired_at,
+    block_number AS acquired_block,
+    tx AS acquired_tx
+FROM time_capsule_transfers
+ORDER BY token_id, block_number DESC, log_index DESC;
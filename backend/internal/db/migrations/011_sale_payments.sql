@@ -0,0 +1,46 @@
+-- 011_sale_payments.sql
+
+-- ETH moved by contract code within a transaction, from Etherscan's
+-- txlistinternal: what buyTile forwarded to the seller or the creator, and
+-- what the wrapper paid the original contract. Archived next to
+-- pixel_map_transaction so sales can be re-derived offline.
+CREATE TABLE pixel_map_internal_transaction (
+    id SERIAL PRIMARY KEY,
+    block_number BIGINT NOT NULL,
+    time_stamp TIMESTAMP NOT NULL,
+    hash VARCHAR(66) NOT NULL,
+    trace_id VARCHAR(64) NOT NULL,
+    "from" VARCHAR(42) NOT NULL,
+    "to" VARCHAR(42) NOT NULL,
+    value NUMERIC(78, 0) NOT NULL,
+    is_error BOOLEAN NOT NULL,
+    UNIQUE(hash, trace_id)
+);
+
+CREATE INDEX pixel_map_internal_transaction_hash_idx ON pixel_map_internal_transaction (hash);
+
+-- A purchase was recorded at the asking price the index held for the tile.
+-- buyTile only succeeds when msg.value equals the contract's price, so from
+-- here on price is the transaction value, asking_price what the index
+-- expected and price_mismatch whether they differ. seller_payout and
+-- creator_fee split what the contract forwarded: to the tile's owner (the
+-- wrapper, for a tile it listed on unwrap) and to the PixelMap creator, who
+-- is paid for unclaimed tiles. They are NULL until the sale's internal
+-- transactions have been fetched; re-running the range with backfill fills
+-- them in.
+ALTER TABLE purchase_histories
+    ADD COLUMN asking_price NUMERIC(78, 0),
+    ADD COLUMN seller_payout NUMERIC(78, 0),
+    ADD COLUMN creator_fee NUMERIC(78, 0),
+    ADD COLUMN price_mismatch BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- buyTile(uint256 location) is 0x329ce29e.
+UPDATE purchase_histories p
+SET asking_price = p.price,
+    price = t.value,
+    price_mismatch = t.value <> p.price
+FROM pixel_map_transaction t
+WHERE t.hash = p.tx
+  AND left(t.input, 10) = '0x329ce29e';
+
+CREATE INDEX purchase_histories_price_mismatch_idx ON purchase_histories (tile_id) WHERE price_mismatch;
@@ -0,0 +1,106 @@
+-- 012_approvals.sql
+
+-- approve calls on the wrapper. A token approval lasts until the tile next
+-- moves, since every transfer clears it; approving the zero address clears it
+-- too.
+CREATE TABLE wrapper_approvals (
+    id SERIAL PRIMARY KEY,
+    time_stamp TIMESTAMP NOT NULL,
+    block_number BIGINT NOT NULL,
+    tx VARCHAR(66) NOT NULL,
+    log_index INTEGER NOT NULL,
+    tile_id INTEGER NOT NULL REFERENCES tiles(id),
+    approved VARCHAR(42) NOT NULL,
+    approved_by VARCHAR(42) NOT NULL,
+    UNIQUE(tx, tile_id),
+    CHECK (approved = LOWER(approved) AND approved_by = LOWER(approved_by))
+);
+
+CREATE INDEX wrapper_approvals_tile_idx ON wrapper_approvals (tile_id, block_number, log_index);
+
+-- setApprovalForAll calls on the wrapper. An operator may move every tile its
+-- owner holds, now or later, until the owner revokes it.
+CREATE TABLE wrapper_operator_approvals (
+    id SERIAL PRIMARY KEY,
+    time_stamp TIMESTAMP NOT NULL,
+    block_number BIGINT NOT NULL,
+    tx VARCHAR(66) NOT NULL,
+    log_index INTEGER NOT NULL,
+    owner VARCHAR(42) NOT NULL,
+    operator VARCHAR(42) NOT NULL,
+    approved BOOLEAN NOT NULL,
+    UNIQUE(tx, operator),
+    CHECK (owner = LOWER(owner) AND operator = LOWER(operator))
+);
+
+CREATE INDEX wrapper_operator_approvals_owner_idx ON wrapper_operator_approvals (owner, operator, block_number, log_index);
+
+-- Owner-only calls that change a contract rather than a tile: the wrapper's
+-- token and contract URIs and extension, and ownership of the wrapper and the
+-- time capsule. value is the new URI, extension or owner.
+CREATE TABLE contract_admin_changes (
+    id SERIAL PRIMARY KEY,
+    time_stamp TIMESTAMP NOT NULL,
+    block_number BIGINT NOT NULL,
+    tx VARCHAR(66) NOT NULL,
+    log_index INTEGER NOT NULL,
+    contract VARCHAR(16) NOT NULL CHECK (contract IN ('wrapper', 'timecapsule')),
+    action VARCHAR(32) NOT NULL,
+    value TEXT,
+    changed_by VARCHAR(42) NOT NULL,
+    UNIQUE(tx, contract)
+);
+
+-- Each tile's token approval as of the last ingested block: its latest
+-- approve, unless the tile has moved or been unwrapped since, or the approval
+-- was cleared.
+CREATE VIEW wrapper_tile_approvals AS
+SELECT tile_id, approved, approved_by, tx, time_stamp, block_number
+FROM (
+    SELECT DISTINCT ON (a.tile_id) a.*
+    FROM wrapper_approvals a
+    WHERE NOT EXISTS (
+        SELECT 1 FROM transfer_histories t
+        WHERE t.tile_id = a.tile_id
+          AND (t.block_number, t.log_index) > (a.block_number, a.log_index)
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM wrapping_histories w
+        WHERE w.tile_id = a.tile_id
+          AND (w.block_number, w.log_index) > (a.block_number, a.log_index)
+    )
+    ORDER BY a.tile_id, a.block_number DESC, a.log_index DESC, a.id DESC
+) latest
+WHERE approved <> '0x0000000000000000000000000000000000000000';
+
+-- Each owner's operators as of the last ingested block.
+CREATE VIEW wrapper_operators AS
+SELECT owner, operator, tx, time_stamp, block_number
+FROM (
+    SELECT DISTINCT ON (owner, operator) *
+    FROM wrapper_operator_approvals
+    ORDER BY owner, operator, block_number DESC, log_index DESC, id DESC
+) latest
+WHERE approved;
+
+-- Everyone other than its owner who can move a wrapped tile: the address
+-- approved for the tile ('token') and the owner's operators ('operator').
+CREATE VIEW wrapped_tile_operators AS
+SELECT tiles.id AS tile_id, tiles.owner, a.approved AS operator, 'token'::VARCHAR(8) AS kind, a.tx, a.time_stamp, a.block_number
+FROM tiles
+JOIN wrapper_tile_approvals a ON a.tile_id = tiles.id
+WHERE tiles.wrapped
+UNION ALL
+SELECT tiles.id, tiles.owner, o.operator, 'operator'::VARCHAR(8), o.tx, o.time_stamp, o.block_number
+FROM tiles
+JOIN wrapper_operators o ON o.owner = tiles.owner
+WHERE tiles.wrapped;
+
+-- Every admin change to the wrapper and the time capsule, including the time
+-- capsule's metadata changes.
+CREATE VIEW governance_log AS
+SELECT contract, action, value, changed_by, tx, time_stamp, block_number, log_index
+FROM contract_admin_changes
+UNION ALL
+SELECT 'timecapsule'::VARCHAR(16), action::VARCHAR(32), value, updated_by, tx, time_stamp, block_number, log_index
+FROM time_capsule_metadata_changes;
@@ -0,0 +1,25 @@
+-- 013_transfer_types.sql
+
+-- What kind of move each transfer was, see classifyTransfer in the ingestor:
+-- wrap and unwrap for the wrapper minting and burning a tile's token, mint
+-- and burn for any other token, sale for a transfer made through a known
+-- marketplace or paid for with ETH, and gift for the rest. NULL until the
+-- transfer has been classified. is_wrapper_contract is set for transfers of
+-- the wrapper's ERC-721 token rather than of the original contract's tile.
+ALTER TABLE transfer_histories
+    ADD COLUMN transfer_type VARCHAR(8)
+        CHECK (transfer_type IN ('wrap', 'unwrap', 'mint', 'burn', 'sale', 'gift')),
+    ADD COLUMN is_wrapper_contract BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Every transfer so far came from the wrapper's Transfer events, and the
+-- wrapper only mints and burns to wrap and unwrap. Telling a sale from a gift
+-- needs the transaction the transfer was made in, which `classify-transfers`
+-- looks up.
+UPDATE transfer_histories
+SET is_wrapper_contract = TRUE,
+    transfer_type = CASE
+        WHEN transferred_from = '0x0000000000000000000000000000000000000000' THEN 'wrap'
+        WHEN transferred_to = '0x0000000000000000000000000000000000000000' THEN 'unwrap'
+    END;
+
+CREATE INDEX transfer_histories_unclassified_idx ON transfer_histories (id) WHERE transfer_type IS NULL;
@@ -0,0 +1,25 @@
+-- 014_data_changes.sql
+
+-- Each tile update records what it changed against the update before it for
+-- the same tile: the previous image, URL and price (NULL for a tile's first
+-- update), which of them changed as change_type, and how many of the tile's
+-- 16x16 pixels differ with the smallest box holding them (diff_min_x through
+-- diff_max_y, inclusive and NULL when no pixel changed). change_type is one
+-- of image, url, price, multiple when more than one changed, and none when
+-- the update repeated the tile's data.
+ALTER TABLE data_histories
+    ADD COLUMN change_type VARCHAR(8)
+        CHECK (change_type IN ('image', 'url', 'price', 'multiple', 'none')),
+    ADD COLUMN previous_image VARCHAR(800),
+    ADD COLUMN previous_url TEXT,
+    ADD COLUMN previous_price NUMERIC(78, 0),
+    ADD COLUMN pixels_changed INTEGER CHECK (pixels_changed BETWEEN 0 AND 256),
+    ADD COLUMN diff_min_x INTEGER,
+    ADD COLUMN diff_min_y INTEGER,
+    ADD COLUMN diff_max_x INTEGER,
+    ADD COLUMN diff_max_y INTEGER;
+
+-- Comparing images needs the tile decoder, so updates recorded before this
+-- migration keep a NULL change_type until `compare-data-changes` fills them
+-- in.
+CREATE INDEX data_histories_uncompared_idx ON data_histories (id) WHERE change_type IS NULL;
@@ -0,0 +1,27 @@
+-- 015_tile_update_inputs.sql
+
+-- The arguments of every setTile and setTileData call as decoded from the
+-- calldata, whole. tiles and data_histories keep at most 800 characters of
+-- image and tiles 255 of URL; image_truncated and url_truncated mark the
+-- calls that did not fit, and the renderer and the metadata export read the
+-- image and URL from here instead. Postgres text can't hold NUL bytes or
+-- invalid UTF-8, which are replaced everywhere and flagged with
+-- invalid_encoding; the exact bytes stay in pixel_map_transaction.input.
+-- Calls ingested before this migration are backfilled from their archived
+-- calldata when the ingestor starts.
+CREATE TABLE tile_update_inputs (
+    tx VARCHAR(66) PRIMARY KEY,
+    tile_id INTEGER NOT NULL REFERENCES tiles(id),
+    method VARCHAR(16) NOT NULL,
+    time_stamp TIMESTAMP NOT NULL,
+    block_number BIGINT NOT NULL,
+    log_index INTEGER NOT NULL,
+    image TEXT NOT NULL,
+    url TEXT NOT NULL,
+    price NUMERIC(78, 0),
+    image_truncated BOOLEAN NOT NULL,
+    url_truncated BOOLEAN NOT NULL,
+    invalid_encoding BOOLEAN NOT NULL
+);
+
+CREATE INDEX tile_update_inputs_tile_id_idx ON tile_update_inputs (tile_id, block_number DESC, log_index DESC);
@@ -0,0 +1,31 @@
+-- 016_archive_log_index.sql
+
+-- Transfer events are archived next to the calls that emitted them, with
+-- the same hash and transaction index, so the archive is keyed by log index
+-- too: -1 for a call, the event's position in its block's logs otherwise.
+-- Until now an event overwrote its call's row, or another event's, whenever
+-- they collided; those rows are lost and `rebuild` replays what is left.
+ALTER TABLE pixel_map_transaction
+    ADD COLUMN log_index INTEGER NOT NULL DEFAULT -1;
+
+-- Events are converted from getLogs results, which have no block hash, and
+-- carry their log index as the nonce.
+UPDATE pixel_map_transaction
+SET log_index = nonce
+WHERE block_hash = '';
+
+ALTER TABLE pixel_map_transaction
+    DROP CONSTRAINT pixel_map_transaction_hash_transaction_index_key,
+    ADD CONSTRAINT pixel_map_transaction_hash_transaction_index_log_index_key
+        UNIQUE (hash, transaction_index, log_index);
+
+-- getLogs reports the transaction index in hex, which was stored as 0. The
+-- call in the same transaction has the real one, when it is archived.
+UPDATE pixel_map_transaction e
+SET transaction_index = c.transaction_index
+FROM pixel_map_transaction c
+WHERE e.log_index >= 0
+  AND c.log_index = -1
+  AND c.hash = e.hash;
+
+CREATE INDEX pixel_map_transaction_order_idx ON pixel_map_transaction (block_number, transaction_index, log_index);
@@ -6,25 +6,130 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+type BackfillChunk struct {
+	ID               int32           `json:"id"`
+	StartBlock       int64           `json:"start_block"`
+	EndBlock         int64           `json:"end_block"`
+	Status           string          `json:"status"`
+	Transactions     json.RawMessage `json:"transactions"`
+	TransactionCount int32           `json:"transaction_count"`
+	Attempts         int32           `json:"attempts"`
+	LastError        string          `json:"last_error"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+type ContractAdminChange struct {
+	ID          int32          `json:"id"`
+	TimeStamp   time.Time      `json:"time_stamp"`
+	BlockNumber int64          `json:"block_number"`
+	Tx          string         `json:"tx"`
+	LogIndex    int32          `json:"log_index"`
+	Contract    string         `json:"contract"`
+	Action      string         `json:"action"`
+	Value       sql.NullString `json:"value"`
+	ChangedBy   string         `json:"changed_by"`
+}
+
 type CurrentState struct {
 	State string `json:"state"`
 	Value int64  `json:"value"`
 }
 
 type DataHistory struct {
-	ID          int32          `json:"id"`
+	ID            int32          `json:"id"`
+	TimeStamp     time.Time      `json:"time_stamp"`
+	BlockNumber   int64          `json:"block_number"`
+	Tx            string         `json:"tx"`
+	LogIndex      int32          `json:"log_index"`
+	Image         string         `json:"image"`
+	Price         sql.NullString `json:"price"`
+	Url           string         `json:"url"`
+	UpdatedBy     string         `json:"updated_by"`
+	TileID        int32          `json:"tile_id"`
+	ChangeType    sql.NullString `json:"change_type"`
+	PreviousImage sql.NullString `json:"previous_image"`
+	PreviousUrl   sql.NullString `json:"previous_url"`
+	PreviousPrice sql.NullString `json:"previous_price"`
+	PixelsChanged sql.NullInt32  `json:"pixels_changed"`
+	DiffMinX      sql.NullInt32  `json:"diff_min_x"`
+	DiffMinY      sql.NullInt32  `json:"diff_min_y"`
+	DiffMaxX      sql.NullInt32  `json:"diff_max_x"`
+	DiffMaxY      sql.NullInt32  `json:"diff_max_y"`
+}
+
+type EthPrice struct {
+	Day       time.Time `json:"day"`
+	PriceUsd  string    `json:"price_usd"`
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type GovernanceLog struct {
+	Contract    string         `json:"contract"`
+	Action      string         `json:"action"`
+	Value       sql.NullString `json:"value"`
+	ChangedBy   string         `json:"changed_by"`
+	Tx          string         `json:"tx"`
 	TimeStamp   time.Time      `json:"time_stamp"`
 	BlockNumber int64          `json:"block_number"`
-	Tx          string         `json:"tx"`
 	LogIndex    int32          `json:"log_index"`
-	Image       string         `json:"image"`
-	Price       sql.NullString `json:"price"`
-	Url         string         `json:"url"`
-	UpdatedBy   string         `json:"updated_by"`
-	TileID      int32          `json:"tile_id"`
+}
+
+type MarketStatsDaily struct {
+	Period       time.Time      `json:"period"`
+	Market       string         `json:"market"`
+	Sales        int32          `json:"sales"`
+	Volume       string         `json:"volume"`
+	UniqueBuyers int32          `json:"unique_buyers"`
+	AveragePrice string         `json:"average_price"`
+	MedianPrice  string         `json:"median_price"`
+	MinPrice     string         `json:"min_price"`
+	MaxPrice     string         `json:"max_price"`
+	VolumeUsd    sql.NullString `json:"volume_usd"`
+	PricedSales  int32          `json:"priced_sales"`
+}
+
+type MarketStatsMonthly struct {
+	Period       time.Time      `json:"period"`
+	Market       string         `json:"market"`
+	Sales        int32          `json:"sales"`
+	Volume       string         `json:"volume"`
+	UniqueBuyers int32          `json:"unique_buyers"`
+	AveragePrice string         `json:"average_price"`
+	MedianPrice  string         `json:"median_price"`
+	MinPrice     string         `json:"min_price"`
+	MaxPrice     string         `json:"max_price"`
+	VolumeUsd    sql.NullString `json:"volume_usd"`
+	PricedSales  int32          `json:"priced_sales"`
+}
+
+type MarketStatsTotal struct {
+	Market       string         `json:"market"`
+	Sales        int32          `json:"sales"`
+	Volume       string         `json:"volume"`
+	UniqueBuyers int32          `json:"unique_buyers"`
+	AveragePrice string         `json:"average_price"`
+	MedianPrice  string         `json:"median_price"`
+	MinPrice     string         `json:"min_price"`
+	MaxPrice     string         `json:"max_price"`
+	VolumeUsd    sql.NullString `json:"volume_usd"`
+	PricedSales  int32          `json:"priced_sales"`
+}
+
+type PixelMapInternalTransaction struct {
+	ID          int32     `json:"id"`
+	BlockNumber int64     `json:"block_number"`
+	TimeStamp   time.Time `json:"time_stamp"`
+	Hash        string    `json:"hash"`
+	TraceID     string    `json:"trace_id"`
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Value       string    `json:"value"`
+	IsError     bool      `json:"is_error"`
 }
 
 type PixelMapTransaction struct {
@@ -47,18 +152,24 @@ type PixelMapTransaction struct {
 	CumulativeGasUsed int64        `json:"cumulative_gas_used"`
 	GasUsed           int64        `json:"gas_used"`
 	Confirmations     int64        `json:"confirmations"`
+	LogIndex          int32        `json:"log_index"`
 }
 
 type PurchaseHistory struct {
-	ID          int32     `json:"id"`
-	TimeStamp   time.Time `json:"time_stamp"`
-	BlockNumber int64     `json:"block_number"`
-	Tx          string    `json:"tx"`
-	LogIndex    int32     `json:"log_index"`
-	SoldBy      string    `json:"sold_by"`
-	PurchasedBy string    `json:"purchased_by"`
-	Price       string    `json:"price"`
-	TileID      int32     `json:"tile_id"`
+	ID            int32          `json:"id"`
+	TimeStamp     time.Time      `json:"time_stamp"`
+	BlockNumber   int64          `json:"block_number"`
+	Tx            string         `json:"tx"`
+	LogIndex      int32          `json:"log_index"`
+	SoldBy        string         `json:"sold_by"`
+	PurchasedBy   string         `json:"purchased_by"`
+	Price         string         `json:"price"`
+	TileID        int32          `json:"tile_id"`
+	Market        string         `json:"market"`
+	AskingPrice   sql.NullString `json:"asking_price"`
+	SellerPayout  sql.NullString `json:"seller_payout"`
+	CreatorFee    sql.NullString `json:"creator_fee"`
+	PriceMismatch bool           `json:"price_mismatch"`
 }
 
 type Tile struct {
@@ -72,15 +183,94 @@ type Tile struct {
 	OpenseaPrice string `json:"opensea_price"`
 }
 
-type TransferHistory struct {
+type RenderJob struct {
+	ID          int32     `json:"id"`
+	TileID      int32     `json:"tile_id"`
+	BlockNumber int64     `json:"block_number"`
+	Status      string    `json:"status"`
+	Attempts    int32     `json:"attempts"`
+	LastError   string    `json:"last_error"`
+	RunAfter    time.Time `json:"run_after"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type TileRepair struct {
+	ID          int32     `json:"id"`
+	TileID      int32     `json:"tile_id"`
+	Field       string    `json:"field"`
+	OldValue    string    `json:"old_value"`
+	NewValue    string    `json:"new_value"`
+	BlockNumber int64     `json:"block_number"`
+	RepairedAt  time.Time `json:"repaired_at"`
+}
+
+type TileUpdateInput struct {
+	Tx              string         `json:"tx"`
+	TileID          int32          `json:"tile_id"`
+	Method          string         `json:"method"`
+	TimeStamp       time.Time      `json:"time_stamp"`
+	BlockNumber     int64          `json:"block_number"`
+	LogIndex        int32          `json:"log_index"`
+	Image           string         `json:"image"`
+	Url             string         `json:"url"`
+	Price           sql.NullString `json:"price"`
+	ImageTruncated  bool           `json:"image_truncated"`
+	UrlTruncated    bool           `json:"url_truncated"`
+	InvalidEncoding bool           `json:"invalid_encoding"`
+}
+
+type TimeCapsuleMetadataChange struct {
+	ID          int32          `json:"id"`
+	TimeStamp   time.Time      `json:"time_stamp"`
+	BlockNumber int64          `json:"block_number"`
+	Tx          string         `json:"tx"`
+	LogIndex    int32          `json:"log_index"`
+	Action      string         `json:"action"`
+	Value       sql.NullString `json:"value"`
+	UpdatedBy   string         `json:"updated_by"`
+}
+
+type TimeCapsuleMint struct {
+	ID          int32     `json:"id"`
+	TimeStamp   time.Time `json:"time_stamp"`
+	BlockNumber int64     `json:"block_number"`
+	Tx          string    `json:"tx"`
+	LogIndex    int32     `json:"log_index"`
+	MintedBy    string    `json:"minted_by"`
+	Amount      int32     `json:"amount"`
+}
+
+type TimeCapsuleToken struct {
+	TokenID       int32     `json:"token_id"`
+	Owner         string    `json:"owner"`
+	AcquiredAt    time.Time `json:"acquired_at"`
+	AcquiredBlock int64     `json:"acquired_block"`
+	AcquiredTx    string    `json:"acquired_tx"`
+}
+
+type TimeCapsuleTransfer struct {
 	ID              int32     `json:"id"`
 	TimeStamp       time.Time `json:"time_stamp"`
 	BlockNumber     int64     `json:"block_number"`
 	Tx              string    `json:"tx"`
 	LogIndex        int32     `json:"log_index"`
+	TokenID         int32     `json:"token_id"`
 	TransferredFrom string    `json:"transferred_from"`
 	TransferredTo   string    `json:"transferred_to"`
-	TileID          int32     `json:"tile_id"`
+}
+
+type TransferHistory struct {
+	ID                int32          `json:"id"`
+	TimeStamp         time.Time      `json:"time_stamp"`
+	BlockNumber       int64          `json:"block_number"`
+	Tx                string         `json:"tx"`
+	LogIndex          int32          `json:"log_index"`
+	TransferredFrom   string         `json:"transferred_from"`
+	TransferredTo     string         `json:"transferred_to"`
+	TileID            int32          `json:"tile_id"`
+	TransferType      sql.NullString `json:"transfer_type"`
+	IsWrapperContract bool           `json:"is_wrapper_contract"`
 }
 
 type WrappingHistory struct {
@@ -93,3 +283,79 @@ type WrappingHistory struct {
 	UpdatedBy   string    `json:"updated_by"`
 	TileID      int32     `json:"tile_id"`
 }
+
+type WrappedTileOperator struct {
+	TileID      int32     `json:"tile_id"`
+	Owner       string    `json:"owner"`
+	Operator    string    `json:"operator"`
+	Kind        string    `json:"kind"`
+	Tx          string    `json:"tx"`
+	TimeStamp   time.Time `json:"time_stamp"`
+	BlockNumber int64     `json:"block_number"`
+}
+
+type WrapperApproval struct {
+	ID          int32     `json:"id"`
+	TimeStamp   time.Time `json:"time_stamp"`
+	BlockNumber int64     `json:"block_number"`
+	Tx          string    `json:"tx"`
+	LogIndex    int32     `json:"log_index"`
+	TileID      int32     `json:"tile_id"`
+	Approved    string    `json:"approved"`
+	ApprovedBy  string    `json:"approved_by"`
+}
+
+type WrapperOperator struct {
+	Owner       string    `json:"owner"`
+	Operator    string    `json:"operator"`
+	Tx          string    `json:"tx"`
+	TimeStamp   time.Time `json:"time_stamp"`
+	BlockNumber int64     `json:"block_number"`
+}
+
+type WrapperOperatorApproval struct {
+	ID          int32     `json:"id"`
+	TimeStamp   time.Time `json:"time_stamp"`
+	BlockNumber int64     `json:"block_number"`
+	Tx          string    `json:"tx"`
+	LogIndex    int32     `json:"log_index"`
+	Owner       string    `json:"owner"`
+	Operator    string    `json:"operator"`
+	Approved    bool      `json:"approved"`
+}
+
+type WrapperPendingSale struct {
+	TileID       int32          `json:"tile_id"`
+	Seller       string         `json:"seller"`
+	SalePrice    string         `json:"sale_price"`
+	ListedTx     string         `json:"listed_tx"`
+	ListedAt     time.Time      `json:"listed_at"`
+	Buyer        sql.NullString `json:"buyer"`
+	SoldTx       sql.NullString `json:"sold_tx"`
+	SoldAt       sql.NullTime   `json:"sold_at"`
+	WithdrawnTx  sql.NullString `json:"withdrawn_tx"`
+	WithdrawnAt  sql.NullTime   `json:"withdrawn_at"`
+	Withdrawable string         `json:"withdrawable"`
+}
+
+type WrapperProceedsHistory struct {
+	ID          int32          `json:"id"`
+	TimeStamp   time.Time      `json:"time_stamp"`
+	BlockNumber int64          `json:"block_number"`
+	Tx          string         `json:"tx"`
+	LogIndex    int32          `json:"log_index"`
+	Event       string         `json:"event"`
+	Seller      string         `json:"seller"`
+	Buyer       sql.NullString `json:"buyer"`
+	Amount      string         `json:"amount"`
+	TileID      int32          `json:"tile_id"`
+}
+
+type WrapperTileApproval struct {
+	TileID      int32     `json:"tile_id"`
+	Approved    string    `json:"approved"`
+	ApprovedBy  string    `json:"approved_by"`
+	Tx          string    `json:"tx"`
+	TimeStamp   time.Time `json:"time_stamp"`
+	BlockNumber int64     `json:"block_number"`
+}
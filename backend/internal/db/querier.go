@@ -6,39 +6,144 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
+	ClaimRenderJob(ctx context.Context) (RenderJob, error)
+	CompleteRenderJob(ctx context.Context, id int32) error
+	CountRenderJobsByStatus(ctx context.Context) ([]CountRenderJobsByStatusRow, error)
 	DeleteDataHistory(ctx context.Context, id int32) error
+	EnqueueRenderJob(ctx context.Context, arg EnqueueRenderJobParams) error
+	FailRenderJob(ctx context.Context, arg FailRenderJobParams) error
 	GetCurrentState(ctx context.Context, state string) (CurrentState, error)
 	GetDataHistoryByTileId(ctx context.Context, tileID int32) ([]DataHistory, error)
+	GetDataHistoryByTileIds(ctx context.Context, tileIds []int32) ([]DataHistory, error)
 	GetDataHistoryByTx(ctx context.Context, arg GetDataHistoryByTxParams) (DataHistory, error)
+	// The ETH/USD price on the day of each sale that has one.
+	GetEthPricesBySaleIds(ctx context.Context, ids []int32) ([]GetEthPricesBySaleIdsRow, error)
 	GetLastProcessedBlock(ctx context.Context) (int64, error)
 	GetLastProcessedDataHistoryID(ctx context.Context) (int32, error)
 	GetLatestBlockNumber(ctx context.Context) (interface{}, error)
 	GetLatestDataHistoryByTileId(ctx context.Context, tileID int32) (DataHistory, error)
+	// The last day with a price, or the zero date when there are none.
+	GetLatestEthPriceDay(ctx context.Context) (time.Time, error)
 	GetLatestPurchaseHistoryByTileId(ctx context.Context, tileID int32) (PurchaseHistory, error)
 	GetLatestTileImages(ctx context.Context) ([]GetLatestTileImagesRow, error)
+	// Tiles for sale on the original contract. Wrapped tiles are held by the
+	// wrapper and cannot be bought there.
+	GetListingSummary(ctx context.Context) (GetListingSummaryRow, error)
+	GetOperatorsByOwner(ctx context.Context, address string) ([]WrapperOperator, error)
+	GetOwnerByENS(ctx context.Context, ens string) (string, error)
+	GetPreviousDataHistory(ctx context.Context, arg GetPreviousDataHistoryParams) (DataHistory, error)
 	GetPurchaseHistoryByTileId(ctx context.Context, tileID int32) ([]PurchaseHistory, error)
+	GetPurchaseHistoryByTileIds(ctx context.Context, tileIds []int32) ([]PurchaseHistory, error)
+	GetPurchasesByBuyer(ctx context.Context, address string) ([]PurchaseHistory, error)
 	GetTileById(ctx context.Context, id int32) (Tile, error)
+	GetTileOperatorsByTileIds(ctx context.Context, tileIds []int32) ([]WrappedTileOperator, error)
+	GetTileRepairsByTileId(ctx context.Context, tileID int32) ([]TileRepair, error)
+	GetTileUpdateInputsByTileId(ctx context.Context, tileID int32) ([]TileUpdateInput, error)
+	GetTilesByIds(ctx context.Context, ids []int32) ([]Tile, error)
 	GetTilesByOwner(ctx context.Context, owner string) ([]Tile, error)
+	GetTimeCapsulesByOwner(ctx context.Context, address string) ([]TimeCapsuleToken, error)
+	GetTransferHistoryByTileIds(ctx context.Context, tileIds []int32) ([]TransferHistory, error)
+	GetTransfersByRecipient(ctx context.Context, address string) ([]TransferHistory, error)
 	GetUnprocessedDataHistory(ctx context.Context, id int32) ([]DataHistory, error)
 	GetWrappedTiles(ctx context.Context) ([]Tile, error)
+	// The wrapper's latest listing of a tile and what became of it.
+	GetWrapperPendingSale(ctx context.Context, tileID int32) (WrapperPendingSale, error)
+	GetWrapperPendingSalesBySeller(ctx context.Context, seller string) ([]WrapperPendingSale, error)
+	GetWrapperProceedsBySeller(ctx context.Context, seller string) ([]WrapperProceedsHistory, error)
+	GetWrapperProceedsByTileId(ctx context.Context, tileID int32) ([]WrapperProceedsHistory, error)
+	GetWrappingHistoryByTileIds(ctx context.Context, tileIds []int32) ([]WrappingHistory, error)
+	InsertBackfillChunk(ctx context.Context, arg InsertBackfillChunkParams) error
+	InsertContractAdminChange(ctx context.Context, arg InsertContractAdminChangeParams) error
 	InsertDataHistory(ctx context.Context, arg InsertDataHistoryParams) (int32, error)
+	InsertPixelMapInternalTransaction(ctx context.Context, arg InsertPixelMapInternalTransactionParams) error
 	InsertPixelMapTransaction(ctx context.Context, arg InsertPixelMapTransactionParams) (int32, error)
 	InsertPurchaseHistory(ctx context.Context, arg InsertPurchaseHistoryParams) (int32, error)
 	InsertTile(ctx context.Context, arg InsertTileParams) (int32, error)
+	InsertTileRepair(ctx context.Context, arg InsertTileRepairParams) (int32, error)
+	InsertTileUpdateInput(ctx context.Context, arg InsertTileUpdateInputParams) error
+	InsertTimeCapsuleMetadataChange(ctx context.Context, arg InsertTimeCapsuleMetadataChangeParams) error
+	InsertTimeCapsuleMint(ctx context.Context, arg InsertTimeCapsuleMintParams) error
+	InsertTimeCapsuleTransfer(ctx context.Context, arg InsertTimeCapsuleTransferParams) error
 	InsertTransferHistory(ctx context.Context, arg InsertTransferHistoryParams) (int32, error)
+	InsertWrapperApproval(ctx context.Context, arg InsertWrapperApprovalParams) error
+	InsertWrapperOperatorApproval(ctx context.Context, arg InsertWrapperOperatorApprovalParams) error
+	InsertWrapperProceedsHistory(ctx context.Context, arg InsertWrapperProceedsHistoryParams) error
 	InsertWrappingHistory(ctx context.Context, arg InsertWrappingHistoryParams) (int32, error)
+	ListArchivedInternalTransactions(ctx context.Context, hashes []string) ([]PixelMapInternalTransaction, error)
+	ListArchivedTransactions(ctx context.Context, arg ListArchivedTransactionsParams) ([]PixelMapTransaction, error)
+	ListBackfillChunks(ctx context.Context, arg ListBackfillChunksParams) ([]BackfillChunk, error)
+	// Tiles in the order they were first given an image, up to a moment.
+	ListFirstImageUpdates(ctx context.Context, arg ListFirstImageUpdatesParams) ([]ListFirstImageUpdatesRow, error)
+	ListGovernanceLog(ctx context.Context, rowLimit int32) ([]GovernanceLog, error)
+	ListLatestTruncatedTileUpdateInputs(ctx context.Context) ([]TileUpdateInput, error)
+	ListMarketStatsDaily(ctx context.Context) ([]MarketStatsDaily, error)
+	ListMarketStatsMonthly(ctx context.Context) ([]MarketStatsMonthly, error)
+	ListMarketStatsTotals(ctx context.Context) ([]MarketStatsTotal, error)
+	ListPurchaseHistories(ctx context.Context) ([]PurchaseHistory, error)
+	// Keyset page of sales in insertion order after after_id.
+	ListPurchasesPage(ctx context.Context, arg ListPurchasesPageParams) ([]PurchaseHistory, error)
+	ListRenderJobsByStatus(ctx context.Context, arg ListRenderJobsByStatusParams) ([]RenderJob, error)
+	// The image each tile showed at a moment, for tiles that had one.
+	ListTileImagesAt(ctx context.Context, timeStamp time.Time) ([]ListTileImagesAtRow, error)
+	// Archived tile updates ingested before tile_update_inputs existed, with the
+	// tile and price their data history recorded.
+	ListTileUpdatesWithoutInputs(ctx context.Context, arg ListTileUpdatesWithoutInputsParams) ([]ListTileUpdatesWithoutInputsRow, error)
 	ListTiles(ctx context.Context, arg ListTilesParams) ([]Tile, error)
+	// Keyset page of tiles after after_id. NULL filters match every tile, as does
+	// a NULL or empty ids array.
+	ListTilesPage(ctx context.Context, arg ListTilesPageParams) ([]Tile, error)
+	ListTimeCapsuleMetadataChanges(ctx context.Context) ([]TimeCapsuleMetadataChange, error)
+	ListTimeCapsuleTokens(ctx context.Context) ([]TimeCapsuleToken, error)
+	ListTransferHistories(ctx context.Context) ([]TransferHistory, error)
+	// Keyset page of transfers in insertion order after after_id. address matches
+	// either side of the transfer.
+	ListTransfersPage(ctx context.Context, arg ListTransfersPageParams) ([]TransferHistory, error)
+	ListUnclassifiedTransfers(ctx context.Context, arg ListUnclassifiedTransfersParams) ([]TransferHistory, error)
+	ListUncomparedDataHistories(ctx context.Context, arg ListUncomparedDataHistoriesParams) ([]DataHistory, error)
+	// Held by a rebuild until it commits, keeping the ingestor's writes out.
+	// The key is per schema, so each network's ingestion is locked on its own.
+	LockIngestion(ctx context.Context) error
+	// Held by each transaction the ingestor writes, which waits for a rebuild.
+	LockIngestionShared(ctx context.Context) error
+	MarkBackfillChunkApplied(ctx context.Context, id int32) error
+	MarkBackfillChunkFailed(ctx context.Context, arg MarkBackfillChunkFailedParams) error
+	MarkBackfillChunkFetched(ctx context.Context, arg MarkBackfillChunkFetchedParams) error
+	RefreshMarketStatsDaily(ctx context.Context) error
+	RefreshMarketStatsMonthly(ctx context.Context) error
+	RefreshMarketStatsTotals(ctx context.Context) error
+	RepairTile(ctx context.Context, arg RepairTileParams) error
+	RequeueFailedRenderJobs(ctx context.Context) (int64, error)
+	// Returns jobs left running by a process that stopped without finishing
+	// them to the queue.
+	ResetRunningRenderJobs(ctx context.Context) (int64, error)
+	ResetTiles(ctx context.Context, arg ResetTilesParams) (int64, error)
+	// Puts a failed attempt back in the queue after a delay, or marks it
+	// superseded when a newer pending job for the tile already covers it. A job
+	// enqueued after the check makes it fail on render_jobs_pending_tile, and
+	// the caller supersedes it with SupersedeRenderJob instead.
+	RetryRenderJob(ctx context.Context, arg RetryRenderJobParams) error
+	// Retires a failed attempt that a job enqueued for the tile since covers.
+	SupersedeRenderJob(ctx context.Context, arg SupersedeRenderJobParams) error
+	TruncateDerivedTables(ctx context.Context) error
 	UpdateCurrentState(ctx context.Context, arg UpdateCurrentStateParams) error
+	UpdateDataHistoryChange(ctx context.Context, arg UpdateDataHistoryChangeParams) error
 	UpdateLastProcessedBlock(ctx context.Context, value int64) error
 	UpdateLastProcessedDataHistoryID(ctx context.Context, dollar_1 int32) error
+	UpdatePurchaseMarket(ctx context.Context, arg UpdatePurchaseMarketParams) error
+	UpdatePurchasePayment(ctx context.Context, arg UpdatePurchasePaymentParams) error
 	UpdateTile(ctx context.Context, arg UpdateTileParams) error
 	UpdateTileENS(ctx context.Context, arg UpdateTileENSParams) error
 	UpdateTileOpenSeaPrice(ctx context.Context, arg UpdateTileOpenSeaPriceParams) error
 	UpdateTileOwner(ctx context.Context, arg UpdateTileOwnerParams) error
+	// The asking price in wei on the original contract.
+	UpdateTilePrice(ctx context.Context, arg UpdateTilePriceParams) error
+	UpdateTransferType(ctx context.Context, arg UpdateTransferTypeParams) error
 	UpdateWrappedStatus(ctx context.Context, arg UpdateWrappedStatusParams) error
+	UpsertEthPrice(ctx context.Context, arg UpsertEthPriceParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -34,7 +34,7 @@ func (q *Queries) GetCurrentState(ctx context.Context, state string) (CurrentSta
 }
 
 const getDataHistoryByTileId = `-- name: GetDataHistoryByTileId :many
-SELECT id, time_stamp, block_number, tx, log_index, image, price, url, updated_by, tile_id FROM data_histories
+SELECT id, time_stamp, block_number, tx, log_index, image, price, url, updated_by, tile_id, change_type, previous_image, previous_url, previous_price, pixels_changed, diff_min_x, diff_min_y, diff_max_x, diff_max_y FROM data_histories
 WHERE tile_id = $1
 ORDER BY time_stamp DESC
 `
@@ -59,6 +59,15 @@ func (q *Queries) GetDataHistoryByTileId(ctx context.Context, tileID int32) ([]D
 			&i.Url,
 			&i.UpdatedBy,
 			&i.TileID,
+			&i.ChangeType,
+			&i.PreviousImage,
+			&i.PreviousUrl,
+			&i.PreviousPrice,
+			&i.PixelsChanged,
+			&i.DiffMinX,
+			&i.DiffMinY,
+			&i.DiffMaxX,
+			&i.DiffMaxY,
 		); err != nil {
 			return nil, err
 		}
@@ -74,7 +83,7 @@ func (q *Queries) GetDataHistoryByTileId(ctx context.Context, tileID int32) ([]D
 }
 
 const getDataHistoryByTx = `-- name: GetDataHistoryByTx :one
-SELECT id, time_stamp, block_number, tx, log_index, image, price, url, updated_by, tile_id FROM data_histories
+SELECT id, time_stamp, block_number, tx, log_index, image, price, url, updated_by, tile_id, change_type, previous_image, previous_url, previous_price, pixels_changed, diff_min_x, diff_min_y, diff_max_x, diff_max_y FROM data_histories
 WHERE tx = $1 AND tile_id = $2
 LIMIT 1
 `
@@ -98,6 +107,15 @@ func (q *Queries) GetDataHistoryByTx(ctx context.Context, arg GetDataHistoryByTx
 		&i.Url,
 		&i.UpdatedBy,
 		&i.TileID,
+		&i.ChangeType,
+		&i.PreviousImage,
+		&i.PreviousUrl,
+		&i.PreviousPrice,
+		&i.PixelsChanged,
+		&i.DiffMinX,
+		&i.DiffMinY,
+		&i.DiffMaxX,
+		&i.DiffMaxY,
 	)
 	return i, err
 }
@@ -141,7 +159,7 @@ func (q *Queries) GetLatestBlockNumber(ctx context.Context) (interface{}, error)
 }
 
 const getLatestDataHistoryByTileId = `-- name: GetLatestDataHistoryByTileId :one
-SELECT id, time_stamp, block_number, tx, log_index, image, price, url, updated_by, tile_id FROM data_histories
+SELECT id, time_stamp, block_number, tx, log_index, image, price, url, updated_by, tile_id, change_type, previous_image, previous_url, previous_price, pixels_changed, diff_min_x, diff_min_y, diff_max_x, diff_max_y FROM data_histories
 WHERE tile_id = $1
 ORDER BY time_stamp DESC
 LIMIT 1
@@ -161,12 +179,21 @@ func (q *Queries) GetLatestDataHistoryByTileId(ctx context.Context, tileID int32
 		&i.Url,
 		&i.UpdatedBy,
 		&i.TileID,
+		&i.ChangeType,
+		&i.PreviousImage,
+		&i.PreviousUrl,
+		&i.PreviousPrice,
+		&i.PixelsChanged,
+		&i.DiffMinX,
+		&i.DiffMinY,
+		&i.DiffMaxX,
+		&i.DiffMaxY,
 	)
 	return i, err
 }
 
 const getLatestPurchaseHistoryByTileId = `-- name: GetLatestPurchaseHistoryByTileId :one
-SELECT id, time_stamp, block_number, tx, log_index, sold_by, purchased_by, price, tile_id FROM purchase_histories
+SELECT id, time_stamp, block_number, tx, log_index, sold_by, purchased_by, price, tile_id, market, asking_price, seller_payout, creator_fee, price_mismatch FROM purchase_histories
 WHERE tile_id = $1
 ORDER BY time_stamp DESC, log_index DESC
 LIMIT 1
@@ -185,6 +212,11 @@ func (q *Queries) GetLatestPurchaseHistoryByTileId(ctx context.Context, tileID i
 		&i.PurchasedBy,
 		&i.Price,
 		&i.TileID,
+		&i.Market,
+		&i.AskingPrice,
+		&i.SellerPayout,
+		&i.CreatorFee,
+		&i.PriceMismatch,
 	)
 	return i, err
 }
@@ -228,7 +260,7 @@ func (q *Queries) GetLatestTileImages(ctx context.Context) ([]GetLatestTileImage
 }
 
 const getPurchaseHistoryByTileId = `-- name: GetPurchaseHistoryByTileId :many
-SELECT id, time_stamp, block_number, tx, log_index, sold_by, purchased_by, price, tile_id FROM purchase_histories
+SELECT id, time_stamp, block_number, tx, log_index, sold_by, purchased_by, price, tile_id, market, asking_price, seller_payout, creator_fee, price_mismatch FROM purchase_histories
 WHERE tile_id = $1
 ORDER BY time_stamp DESC, log_index DESC
 `
@@ -252,6 +284,11 @@ func (q *Queries) GetPurchaseHistoryByTileId(ctx context.Context, tileID int32)
 			&i.PurchasedBy,
 			&i.Price,
 			&i.TileID,
+			&i.Market,
+			&i.AskingPrice,
+			&i.SellerPayout,
+			&i.CreatorFee,
+			&i.PriceMismatch,
 		); err != nil {
 			return nil, err
 		}
@@ -326,7 +363,7 @@ func (q *Queries) GetTilesByOwner(ctx context.Context, owner string) ([]Tile, er
 }
 
 const getUnprocessedDataHistory = `-- name: GetUnprocessedDataHistory :many
-SELECT id, time_stamp, block_number, tx, log_index, image, price, url, updated_by, tile_id FROM data_histories
+SELECT id, time_stamp, block_number, tx, log_index, image, price, url, updated_by, tile_id, change_type, previous_image, previous_url, previous_price, pixels_changed, diff_min_x, diff_min_y, diff_max_x, diff_max_y FROM data_histories
 WHERE id > $1
 ORDER BY id ASC
 `
@@ -351,6 +388,15 @@ func (q *Queries) GetUnprocessedDataHistory(ctx context.Context, id int32) ([]Da
 			&i.Url,
 			&i.UpdatedBy,
 			&i.TileID,
+			&i.ChangeType,
+			&i.PreviousImage,
+			&i.PreviousUrl,
+			&i.PreviousPrice,
+			&i.PixelsChanged,
+			&i.DiffMinX,
+			&i.DiffMinY,
+			&i.DiffMaxX,
+			&i.DiffMaxY,
 		); err != nil {
 			return nil, err
 		}
@@ -405,9 +451,11 @@ func (q *Queries) GetWrappedTiles(ctx context.Context) ([]Tile, error) {
 
 const insertDataHistory = `-- name: InsertDataHistory :one
 INSERT INTO data_histories (
-    time_stamp, block_number, tx, log_index, image, price, url, updated_by, tile_id
+    time_stamp, block_number, tx, log_index, image, price, url, updated_by, tile_id,
+    change_type, previous_image, previous_url, previous_price,
+    pixels_changed, diff_min_x, diff_min_y, diff_max_x, diff_max_y
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
 )
 ON CONFLICT (tile_id, tx) DO UPDATE SET
     time_stamp = COALESCE(EXCLUDED.time_stamp, data_histories.time_stamp),
@@ -416,20 +464,38 @@ ON CONFLICT (tile_id, tx) DO UPDATE SET
     image = COALESCE(EXCLUDED.image, data_histories.image),
     price = COALESCE(EXCLUDED.price, data_histories.price),
     url = COALESCE(EXCLUDED.url, data_histories.url),
-    updated_by = COALESCE(EXCLUDED.updated_by, data_histories.updated_by)
+    updated_by = COALESCE(EXCLUDED.updated_by, data_histories.updated_by),
+    change_type = EXCLUDED.change_type,
+    previous_image = EXCLUDED.previous_image,
+    previous_url = EXCLUDED.previous_url,
+    previous_price = EXCLUDED.previous_price,
+    pixels_changed = EXCLUDED.pixels_changed,
+    diff_min_x = EXCLUDED.diff_min_x,
+    diff_min_y = EXCLUDED.diff_min_y,
+    diff_max_x = EXCLUDED.diff_max_x,
+    diff_max_y = EXCLUDED.diff_max_y
 RETURNING id
 `
 
 type InsertDataHistoryParams struct {
-	TimeStamp   time.Time      `json:"time_stamp"`
-	BlockNumber int64          `json:"block_number"`
-	Tx          string         `json:"tx"`
-	LogIndex    int32          `json:"log_index"`
-	Image       string         `json:"image"`
-	Price       sql.NullString `json:"price"`
-	Url         string         `json:"url"`
-	UpdatedBy   string         `json:"updated_by"`
-	TileID      int32          `json:"tile_id"`
+	TimeStamp     time.Time      `json:"time_stamp"`
+	BlockNumber   int64          `json:"block_number"`
+	Tx            string         `json:"tx"`
+	LogIndex      int32          `json:"log_index"`
+	Image         string         `json:"image"`
+	Price         sql.NullString `json:"price"`
+	Url           string         `json:"url"`
+	UpdatedBy     string         `json:"updated_by"`
+	TileID        int32          `json:"tile_id"`
+	ChangeType    sql.NullString `json:"change_type"`
+	PreviousImage sql.NullString `json:"previous_image"`
+	PreviousUrl   sql.NullString `json:"previous_url"`
+	PreviousPrice sql.NullString `json:"previous_price"`
+	PixelsChanged sql.NullInt32  `json:"pixels_changed"`
+	DiffMinX      sql.NullInt32  `json:"diff_min_x"`
+	DiffMinY      sql.NullInt32  `json:"diff_min_y"`
+	DiffMaxX      sql.NullInt32  `json:"diff_max_x"`
+	DiffMaxY      sql.NullInt32  `json:"diff_max_y"`
 }
 
 func (q *Queries) InsertDataHistory(ctx context.Context, arg InsertDataHistoryParams) (int32, error) {
@@ -443,6 +509,15 @@ func (q *Queries) InsertDataHistory(ctx context.Context, arg InsertDataHistoryPa
 		arg.Url,
 		arg.UpdatedBy,
 		arg.TileID,
+		arg.ChangeType,
+		arg.PreviousImage,
+		arg.PreviousUrl,
+		arg.PreviousPrice,
+		arg.PixelsChanged,
+		arg.DiffMinX,
+		arg.DiffMinY,
+		arg.DiffMaxX,
+		arg.DiffMaxY,
 	)
 	var id int32
 	err := row.Scan(&id)
@@ -453,11 +528,13 @@ const insertPixelMapTransaction = `-- name: InsertPixelMapTransaction :one
 INSERT INTO pixel_map_transaction (
     block_number, time_stamp, hash, nonce, block_hash, transaction_index,
     "from", "to", value, gas, gas_price, is_error, txreceipt_status,
-    input, contract_address, cumulative_gas_used, gas_used, confirmations
+    input, contract_address, cumulative_gas_used, gas_used, confirmations,
+    log_index
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18,
+    $19
 )
-ON CONFLICT (hash, transaction_index) DO UPDATE SET
+ON CONFLICT (hash, transaction_index, log_index) DO UPDATE SET
     block_number = COALESCE(EXCLUDED.block_number, pixel_map_transaction.block_number),
     time_stamp = COALESCE(EXCLUDED.time_stamp, pixel_map_transaction.time_stamp),
     nonce = COALESCE(EXCLUDED.nonce, pixel_map_transaction.nonce),
@@ -496,6 +573,7 @@ type InsertPixelMapTransactionParams struct {
 	CumulativeGasUsed int64        `json:"cumulative_gas_used"`
 	GasUsed           int64        `json:"gas_used"`
 	Confirmations     int64        `json:"confirmations"`
+	LogIndex          int32        `json:"log_index"`
 }
 
 func (q *Queries) InsertPixelMapTransaction(ctx context.Context, arg InsertPixelMapTransactionParams) (int32, error) {
@@ -518,6 +596,7 @@ func (q *Queries) InsertPixelMapTransaction(ctx context.Context, arg InsertPixel
 		arg.CumulativeGasUsed,
 		arg.GasUsed,
 		arg.Confirmations,
+		arg.LogIndex,
 	)
 	var id int32
 	err := row.Scan(&id)
@@ -537,6 +616,7 @@ INSERT INTO purchase_histories (
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8
 )
+ON CONFLICT (tile_id, tx, log_index) DO NOTHING
 RETURNING id
 `
 
@@ -602,27 +682,31 @@ func (q *Queries) InsertTile(ctx context.Context, arg InsertTileParams) (int32,
 
 const insertTransferHistory = `-- name: InsertTransferHistory :one
 INSERT INTO transfer_histories (
-    tile_id, tx, time_stamp, block_number, transferred_from, transferred_to, log_index
+    tile_id, tx, time_stamp, block_number, transferred_from, transferred_to, log_index, transfer_type, is_wrapper_contract
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
 )
 ON CONFLICT (tile_id, tx) DO UPDATE SET
     time_stamp = COALESCE(EXCLUDED.time_stamp, transfer_histories.time_stamp),
     block_number = COALESCE(EXCLUDED.block_number, transfer_histories.block_number),
     transferred_from = COALESCE(EXCLUDED.transferred_from, transfer_histories.transferred_from),
     transferred_to = COALESCE(EXCLUDED.transferred_to, transfer_histories.transferred_to),
-    log_index = COALESCE(EXCLUDED.log_index, transfer_histories.log_index)
+    log_index = COALESCE(EXCLUDED.log_index, transfer_histories.log_index),
+    transfer_type = COALESCE(EXCLUDED.transfer_type, transfer_histories.transfer_type),
+    is_wrapper_contract = EXCLUDED.is_wrapper_contract
 RETURNING id
 `
 
 type InsertTransferHistoryParams struct {
-	TileID          int32     `json:"tile_id"`
-	Tx              string    `json:"tx"`
-	TimeStamp       time.Time `json:"time_stamp"`
-	BlockNumber     int64     `json:"block_number"`
-	TransferredFrom string    `json:"transferred_from"`
-	TransferredTo   string    `json:"transferred_to"`
-	LogIndex        int32     `json:"log_index"`
+	TileID            int32          `json:"tile_id"`
+	Tx                string         `json:"tx"`
+	TimeStamp         time.Time      `json:"time_stamp"`
+	BlockNumber       int64          `json:"block_number"`
+	TransferredFrom   string         `json:"transferred_from"`
+	TransferredTo     string         `json:"transferred_to"`
+	LogIndex          int32          `json:"log_index"`
+	TransferType      sql.NullString `json:"transfer_type"`
+	IsWrapperContract bool           `json:"is_wrapper_contract"`
 }
 
 func (q *Queries) InsertTransferHistory(ctx context.Context, arg InsertTransferHistoryParams) (int32, error) {
@@ -634,6 +718,8 @@ func (q *Queries) InsertTransferHistory(ctx context.Context, arg InsertTransferH
 		arg.TransferredFrom,
 		arg.TransferredTo,
 		arg.LogIndex,
+		arg.TransferType,
+		arg.IsWrapperContract,
 	)
 	var id int32
 	err := row.Scan(&id)
@@ -831,6 +917,23 @@ func (q *Queries) UpdateTileOpenSeaPrice(ctx context.Context, arg UpdateTileOpen
 	return err
 }
 
+const updateTilePrice = `-- name: UpdateTilePrice :exec
+UPDATE tiles
+SET price = $2
+WHERE id = $1
+`
+
+type UpdateTilePriceParams struct {
+	ID    int32  `json:"id"`
+	Price string `json:"price"`
+}
+
+// The asking price in wei on the original contract.
+func (q *Queries) UpdateTilePrice(ctx context.Context, arg UpdateTilePriceParams) error {
+	_, err := q.db.ExecContext(ctx, updateTilePrice, arg.ID, arg.Price)
+	return err
+}
+
 const updateTileOwner = `-- name: UpdateTileOwner :exec
 UPDATE tiles
 SET 
@@ -0,0 +1,73 @@
+-- name: GetTilesByIds :many
+SELECT * FROM tiles
+WHERE id = ANY(sqlc.arg(ids)::INT[])
+ORDER BY id;
+
+-- name: ListTilesPage :many
+-- Keyset page of tiles after after_id. NULL filters match every tile, as does
+-- a NULL or empty ids array.
+SELECT * FROM tiles
+WHERE id > sqlc.arg(after_id)
+  AND (sqlc.narg(owner)::TEXT IS NULL OR owner = sqlc.narg(owner))
+  AND (sqlc.narg(ens)::TEXT IS NULL OR LOWER(ens) = LOWER(sqlc.narg(ens)))
+  AND (sqlc.narg(wrapped)::BOOLEAN IS NULL OR wrapped = sqlc.narg(wrapped))
+  AND (sqlc.narg(has_image)::BOOLEAN IS NULL OR (image <> '') = sqlc.narg(has_image))
+  AND (COALESCE(CARDINALITY(sqlc.arg(ids)::INT[]), 0) = 0 OR id = ANY(sqlc.arg(ids)::INT[]))
+ORDER BY id
+LIMIT sqlc.arg(page_size);
+
+-- name: GetOwnerByENS :one
+SELECT owner FROM tiles
+WHERE LOWER(ens) = LOWER(sqlc.arg(ens))
+LIMIT 1;
+
+-- name: GetDataHistoryByTileIds :many
+SELECT * FROM data_histories
+WHERE tile_id = ANY(sqlc.arg(tile_ids)::INT[])
+ORDER BY tile_id, block_number DESC, log_index DESC;
+
+-- name: GetPurchaseHistoryByTileIds :many
+SELECT * FROM purchase_histories
+WHERE tile_id = ANY(sqlc.arg(tile_ids)::INT[])
+ORDER BY tile_id, block_number DESC, log_index DESC;
+
+-- name: GetTransferHistoryByTileIds :many
+SELECT * FROM transfer_histories
+WHERE tile_id = ANY(sqlc.arg(tile_ids)::INT[])
+ORDER BY tile_id, block_number DESC, log_index DESC;
+
+-- name: GetWrappingHistoryByTileIds :many
+SELECT * FROM wrapping_histories
+WHERE tile_id = ANY(sqlc.arg(tile_ids)::INT[])
+ORDER BY tile_id, block_number DESC, log_index DESC;
+
+-- name: ListPurchasesPage :many
+-- Keyset page of sales in insertion order after after_id.
+SELECT * FROM purchase_histories
+WHERE id > sqlc.arg(after_id)
+  AND (sqlc.narg(tile_id)::INT IS NULL OR tile_id = sqlc.narg(tile_id))
+  AND (sqlc.narg(buyer)::TEXT IS NULL OR purchased_by = sqlc.narg(buyer))
+  AND (sqlc.narg(seller)::TEXT IS NULL OR sold_by = sqlc.narg(seller))
+  AND (sqlc.narg(min_price)::NUMERIC IS NULL OR price >= sqlc.narg(min_price))
+  AND (sqlc.narg(max_price)::NUMERIC IS NULL OR price <= sqlc.narg(max_price))
+  AND (sqlc.narg(since)::TIMESTAMP IS NULL OR time_stamp >= sqlc.narg(since))
+  AND (sqlc.narg(until)::TIMESTAMP IS NULL OR time_stamp < sqlc.narg(until))
+ORDER BY id
+LIMIT sqlc.arg(page_size);
+
+-- name: ListTransfersPage :many
+-- Keyset page of transfers in insertion order after after_id. address matches
+-- either side of the transfer.
+SELECT * FROM transfer_histories
+WHERE id > sqlc.arg(after_id)
+  AND (sqlc.narg(tile_id)::INT IS NULL OR tile_id = sqlc.narg(tile_id))
+  AND (sqlc.narg(from_address)::TEXT IS NULL OR transferred_from = sqlc.narg(from_address))
+  AND (sqlc.narg(to_address)::TEXT IS NULL OR transferred_to = sqlc.narg(to_address))
+  AND (sqlc.narg(address)::TEXT IS NULL
+       OR transferred_from = sqlc.narg(address)
+       OR transferred_to = sqlc.narg(address))
+  AND (sqlc.narg(since)::TIMESTAMP IS NULL OR time_stamp >= sqlc.narg(since))
+  AND (sqlc.narg(until)::TIMESTAMP IS NULL OR time_stamp < sqlc.narg(until))
+  AND (sqlc.narg(transfer_type)::TEXT IS NULL OR transfer_type = sqlc.narg(transfer_type))
+ORDER BY id
+LIMIT sqlc.arg(page_size);
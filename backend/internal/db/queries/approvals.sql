@@ -0,0 +1,38 @@
+-- name: InsertWrapperApproval :exec
+INSERT INTO wrapper_approvals (
+    time_stamp, block_number, tx, log_index, tile_id, approved, approved_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (tx, tile_id) DO NOTHING;
+
+-- name: InsertWrapperOperatorApproval :exec
+INSERT INTO wrapper_operator_approvals (
+    time_stamp, block_number, tx, log_index, owner, operator, approved
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (tx, operator) DO NOTHING;
+
+-- name: InsertContractAdminChange :exec
+INSERT INTO contract_admin_changes (
+    time_stamp, block_number, tx, log_index, contract, action, value, changed_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (tx, contract) DO NOTHING;
+
+-- name: GetTileOperatorsByTileIds :many
+SELECT * FROM wrapped_tile_operators
+WHERE tile_id = ANY(sqlc.arg(tile_ids)::INT[])
+ORDER BY tile_id, block_number DESC, operator;
+
+-- name: GetOperatorsByOwner :many
+SELECT * FROM wrapper_operators
+WHERE owner = sqlc.arg(address)
+ORDER BY block_number DESC, operator;
+
+-- name: ListGovernanceLog :many
+SELECT * FROM governance_log
+ORDER BY block_number DESC, log_index DESC
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,33 @@
+-- name: InsertBackfillChunk :exec
+INSERT INTO backfill_chunks (start_block, end_block)
+VALUES ($1, $2)
+ON CONFLICT (start_block, end_block) DO NOTHING;
+
+-- name: ListBackfillChunks :many
+SELECT * FROM backfill_chunks
+WHERE start_block >= $1 AND end_block <= $2
+ORDER BY start_block;
+
+-- name: MarkBackfillChunkFetched :exec
+UPDATE backfill_chunks
+SET status = 'fetched',
+    transactions = $2,
+    transaction_count = $3,
+    attempts = attempts + 1,
+    last_error = '',
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkBackfillChunkFailed :exec
+UPDATE backfill_chunks
+SET attempts = attempts + 1,
+    last_error = $2,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkBackfillChunkApplied :exec
+UPDATE backfill_chunks
+SET status = 'applied',
+    transactions = '[]',
+    updated_at = NOW()
+WHERE id = $1;
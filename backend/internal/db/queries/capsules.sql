@@ -0,0 +1,17 @@
+-- name: ListFirstImageUpdates :many
+-- Tiles in the order they were first given an image, up to a moment.
+SELECT tile_id, block_number, time_stamp FROM (
+    SELECT DISTINCT ON (tile_id) tile_id, block_number, log_index, time_stamp
+    FROM data_histories
+    WHERE image <> '' AND time_stamp <= $1
+    ORDER BY tile_id, block_number, log_index
+) first_updates
+ORDER BY block_number, log_index, tile_id
+LIMIT $2;
+
+-- name: ListTileImagesAt :many
+-- The image each tile showed at a moment, for tiles that had one.
+SELECT DISTINCT ON (tile_id) tile_id, image, block_number, time_stamp
+FROM data_histories
+WHERE image <> '' AND time_stamp <= $1
+ORDER BY tile_id, block_number DESC, log_index DESC;
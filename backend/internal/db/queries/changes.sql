@@ -0,0 +1,25 @@
+-- name: GetPreviousDataHistory :one
+SELECT * FROM data_histories
+WHERE tile_id = $1
+  AND (block_number < $2 OR (block_number = $2 AND log_index < $3))
+ORDER BY block_number DESC, log_index DESC
+LIMIT 1;
+
+-- name: ListUncomparedDataHistories :many
+SELECT * FROM data_histories
+WHERE change_type IS NULL AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(page_size);
+
+-- name: UpdateDataHistoryChange :exec
+UPDATE data_histories
+SET change_type = $2,
+    previous_image = $3,
+    previous_url = $4,
+    previous_price = $5,
+    pixels_changed = $6,
+    diff_min_x = $7,
+    diff_min_y = $8,
+    diff_max_x = $9,
+    diff_max_y = $10
+WHERE id = $1;
@@ -0,0 +1,44 @@
+-- name: InsertTileUpdateInput :exec
+INSERT INTO tile_update_inputs (
+    tx, tile_id, method, time_stamp, block_number, log_index, image, url, price,
+    image_truncated, url_truncated, invalid_encoding
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (tx) DO UPDATE SET
+    tile_id = EXCLUDED.tile_id,
+    method = EXCLUDED.method,
+    time_stamp = EXCLUDED.time_stamp,
+    block_number = EXCLUDED.block_number,
+    log_index = EXCLUDED.log_index,
+    image = EXCLUDED.image,
+    url = EXCLUDED.url,
+    price = EXCLUDED.price,
+    image_truncated = EXCLUDED.image_truncated,
+    url_truncated = EXCLUDED.url_truncated,
+    invalid_encoding = EXCLUDED.invalid_encoding;
+
+-- name: GetTileUpdateInputsByTileId :many
+SELECT * FROM tile_update_inputs
+WHERE tile_id = $1
+ORDER BY block_number DESC, log_index DESC;
+
+-- name: ListLatestTruncatedTileUpdateInputs :many
+SELECT * FROM (
+    SELECT DISTINCT ON (tile_id) * FROM tile_update_inputs
+    ORDER BY tile_id, block_number DESC, log_index DESC
+) latest
+WHERE image_truncated OR url_truncated;
+
+-- name: ListTileUpdatesWithoutInputs :many
+-- Archived tile updates ingested before tile_update_inputs existed, with the
+-- tile and price their data history recorded.
+SELECT t.id, t.hash, t."to", t.input, t.time_stamp, t.block_number, t.transaction_index,
+    d.tile_id, d.price
+FROM pixel_map_transaction t
+JOIN data_histories d ON d.tx = t.hash
+WHERE t.id > sqlc.arg(after_id)
+  AND t.log_index = -1
+  AND NOT EXISTS (SELECT 1 FROM tile_update_inputs i WHERE i.tx = t.hash)
+ORDER BY t.id
+LIMIT sqlc.arg(page_size);
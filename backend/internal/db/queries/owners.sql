@@ -0,0 +1,17 @@
+-- name: GetPurchasesByBuyer :many
+SELECT * FROM purchase_histories
+WHERE purchased_by = sqlc.arg(address)
+ORDER BY block_number DESC, log_index DESC;
+
+-- name: GetTransfersByRecipient :many
+SELECT * FROM transfer_histories
+WHERE transferred_to = sqlc.arg(address)
+ORDER BY block_number DESC, log_index DESC;
+
+-- name: ListPurchaseHistories :many
+SELECT * FROM purchase_histories
+ORDER BY block_number DESC, log_index DESC;
+
+-- name: ListTransferHistories :many
+SELECT * FROM transfer_histories
+ORDER BY block_number DESC, log_index DESC;
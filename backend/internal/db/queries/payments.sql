@@ -0,0 +1,15 @@
+-- name: InsertPixelMapInternalTransaction :exec
+INSERT INTO pixel_map_internal_transaction (
+    block_number, time_stamp, hash, trace_id, "from", "to", value, is_error
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (hash, trace_id) DO NOTHING;
+
+-- name: UpdatePurchasePayment :exec
+UPDATE purchase_histories
+SET asking_price = $3,
+    seller_payout = $4,
+    creator_fee = $5,
+    price_mismatch = $6
+WHERE tile_id = $1 AND tx = $2;
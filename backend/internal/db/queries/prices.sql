@@ -0,0 +1,18 @@
+-- name: UpsertEthPrice :exec
+INSERT INTO eth_prices (day, price_usd, source)
+VALUES ($1, $2, $3)
+ON CONFLICT (day) DO UPDATE
+SET price_usd = EXCLUDED.price_usd,
+    source = EXCLUDED.source,
+    updated_at = NOW();
+
+-- name: GetLatestEthPriceDay :one
+-- The last day with a price, or the zero date when there are none.
+SELECT COALESCE(MAX(day), '0001-01-01')::DATE AS day FROM eth_prices;
+
+-- name: GetEthPricesBySaleIds :many
+-- The ETH/USD price on the day of each sale that has one.
+SELECT purchase_histories.id AS purchase_id, eth_prices.price_usd
+FROM purchase_histories
+JOIN eth_prices ON eth_prices.day = purchase_histories.time_stamp::DATE
+WHERE purchase_histories.id = ANY(sqlc.arg(ids)::INT[]);
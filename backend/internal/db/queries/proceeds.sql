@@ -0,0 +1,32 @@
+-- name: InsertWrapperProceedsHistory :exec
+INSERT INTO wrapper_proceeds_histories (
+    time_stamp, block_number, tx, log_index, event, seller, buyer, amount, tile_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (tile_id, tx, event) DO NOTHING;
+
+-- name: GetWrapperPendingSale :one
+-- The wrapper's latest listing of a tile and what became of it.
+SELECT * FROM wrapper_pending_sales
+WHERE tile_id = $1;
+
+-- name: GetWrapperPendingSalesBySeller :many
+SELECT * FROM wrapper_pending_sales
+WHERE seller = $1
+ORDER BY listed_at DESC, tile_id;
+
+-- name: GetWrapperProceedsByTileId :many
+SELECT * FROM wrapper_proceeds_histories
+WHERE tile_id = $1
+ORDER BY block_number DESC, log_index DESC, id DESC;
+
+-- name: GetWrapperProceedsBySeller :many
+SELECT * FROM wrapper_proceeds_histories
+WHERE seller = $1
+ORDER BY block_number DESC, log_index DESC, id DESC;
+
+-- name: UpdatePurchaseMarket :exec
+UPDATE purchase_histories
+SET market = $2
+WHERE id = $1;
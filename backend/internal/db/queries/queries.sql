@@ -12,11 +12,13 @@ SET value = COALESCE($2, current_state.value);
 INSERT INTO pixel_map_transaction (
     block_number, time_stamp, hash, nonce, block_hash, transaction_index,
     "from", "to", value, gas, gas_price, is_error, txreceipt_status,
-    input, contract_address, cumulative_gas_used, gas_used, confirmations
+    input, contract_address, cumulative_gas_used, gas_used, confirmations,
+    log_index
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18,
+    $19
 )
-ON CONFLICT (hash, transaction_index) DO UPDATE SET
+ON CONFLICT (hash, transaction_index, log_index) DO UPDATE SET
     block_number = COALESCE(EXCLUDED.block_number, pixel_map_transaction.block_number),
     time_stamp = COALESCE(EXCLUDED.time_stamp, pixel_map_transaction.time_stamp),
     nonce = COALESCE(EXCLUDED.nonce, pixel_map_transaction.nonce),
@@ -49,9 +51,11 @@ ON CONFLICT (state) DO UPDATE SET value = COALESCE($1, current_state.value);
 
 -- name: InsertDataHistory :one
 INSERT INTO data_histories (
-    time_stamp, block_number, tx, log_index, image, price, url, updated_by, tile_id
+    time_stamp, block_number, tx, log_index, image, price, url, updated_by, tile_id,
+    change_type, previous_image, previous_url, previous_price,
+    pixels_changed, diff_min_x, diff_min_y, diff_max_x, diff_max_y
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
 )
 ON CONFLICT (tile_id, tx) DO UPDATE SET
     time_stamp = COALESCE(EXCLUDED.time_stamp, data_histories.time_stamp),
@@ -60,7 +64,16 @@ ON CONFLICT (tile_id, tx) DO UPDATE SET
     image = COALESCE(EXCLUDED.image, data_histories.image),
     price = COALESCE(EXCLUDED.price, data_histories.price),
     url = COALESCE(EXCLUDED.url, data_histories.url),
-    updated_by = COALESCE(EXCLUDED.updated_by, data_histories.updated_by)
+    updated_by = COALESCE(EXCLUDED.updated_by, data_histories.updated_by),
+    change_type = EXCLUDED.change_type,
+    previous_image = EXCLUDED.previous_image,
+    previous_url = EXCLUDED.previous_url,
+    previous_price = EXCLUDED.previous_price,
+    pixels_changed = EXCLUDED.pixels_changed,
+    diff_min_x = EXCLUDED.diff_min_x,
+    diff_min_y = EXCLUDED.diff_min_y,
+    diff_max_x = EXCLUDED.diff_max_x,
+    diff_max_y = EXCLUDED.diff_max_y
 RETURNING id;
 
 -- name: GetDataHistoryByTileId :many
@@ -134,6 +147,12 @@ UPDATE tiles
 SET ens = COALESCE($2, ens)
 WHERE id = $1;
 
+-- name: UpdateTilePrice :exec
+-- The asking price in wei on the original contract.
+UPDATE tiles
+SET price = $2
+WHERE id = $1;
+
 -- name: UpdateTileOpenSeaPrice :exec
 UPDATE tiles
 SET opensea_price = COALESCE($2, opensea_price)
@@ -180,6 +199,7 @@ INSERT INTO purchase_histories (
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8
 )
+ON CONFLICT (tile_id, tx, log_index) DO NOTHING
 RETURNING id;
 
 -- name: GetPurchaseHistoryByTileId :many
@@ -220,14 +240,16 @@ RETURNING id;
 
 -- name: InsertTransferHistory :one
 INSERT INTO transfer_histories (
-    tile_id, tx, time_stamp, block_number, transferred_from, transferred_to, log_index
+    tile_id, tx, time_stamp, block_number, transferred_from, transferred_to, log_index, transfer_type, is_wrapper_contract
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
 )
 ON CONFLICT (tile_id, tx) DO UPDATE SET
     time_stamp = COALESCE(EXCLUDED.time_stamp, transfer_histories.time_stamp),
     block_number = COALESCE(EXCLUDED.block_number, transfer_histories.block_number),
     transferred_from = COALESCE(EXCLUDED.transferred_from, transfer_histories.transferred_from),
     transferred_to = COALESCE(EXCLUDED.transferred_to, transfer_histories.transferred_to),
-    log_index = COALESCE(EXCLUDED.log_index, transfer_histories.log_index)
+    log_index = COALESCE(EXCLUDED.log_index, transfer_histories.log_index),
+    transfer_type = COALESCE(EXCLUDED.transfer_type, transfer_histories.transfer_type),
+    is_wrapper_contract = EXCLUDED.is_wrapper_contract
 RETURNING id;
\ No newline at end of file
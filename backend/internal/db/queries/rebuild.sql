@@ -0,0 +1,34 @@
+-- name: ListArchivedTransactions :many
+SELECT * FROM pixel_map_transaction
+WHERE (block_number, transaction_index, log_index) > (sqlc.arg(after_block)::BIGINT, sqlc.arg(after_index)::INTEGER, sqlc.arg(after_log_index)::INTEGER)
+ORDER BY block_number, transaction_index, log_index
+LIMIT sqlc.arg(page_size);
+
+-- name: ListArchivedInternalTransactions :many
+SELECT * FROM pixel_map_internal_transaction
+WHERE hash = ANY(sqlc.arg(hashes)::VARCHAR[])
+ORDER BY id;
+
+-- name: LockIngestion :exec
+-- Held by a rebuild until it commits, keeping the ingestor's writes out.
+-- The key is per schema, so each network's ingestion is locked on its own.
+SELECT pg_advisory_xact_lock(hashtext('pixelmap_ingestion:' || current_schema()));
+
+-- name: LockIngestionShared :exec
+-- Held by each transaction the ingestor writes, which waits for a rebuild.
+SELECT pg_advisory_xact_lock_shared(hashtext('pixelmap_ingestion:' || current_schema()));
+
+-- name: ResetTiles :execrows
+UPDATE tiles
+SET image = '',
+    price = $1,
+    url = '',
+    owner = $2,
+    wrapped = FALSE;
+
+-- name: TruncateDerivedTables :exec
+TRUNCATE data_histories, purchase_histories, transfer_histories, wrapping_histories,
+    wrapper_proceeds_histories, time_capsule_mints, time_capsule_metadata_changes,
+    wrapper_approvals, wrapper_operator_approvals, contract_admin_changes,
+    tile_update_inputs, current_state
+RESTART IDENTITY;
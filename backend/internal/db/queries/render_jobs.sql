@@ -0,0 +1,109 @@
+-- name: EnqueueRenderJob :exec
+INSERT INTO render_jobs (tile_id, block_number)
+VALUES ($1, $2)
+ON CONFLICT (tile_id) WHERE status = 'pending' DO UPDATE
+SET block_number = GREATEST(render_jobs.block_number, EXCLUDED.block_number),
+    run_after = NOW(),
+    updated_at = NOW();
+
+-- name: ClaimRenderJob :one
+UPDATE render_jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    updated_at = NOW()
+WHERE id = (
+    SELECT j.id FROM render_jobs j
+    WHERE j.status = 'pending'
+      AND j.run_after <= NOW()
+      AND NOT EXISTS (
+          SELECT 1 FROM render_jobs r
+          WHERE r.tile_id = j.tile_id AND r.status = 'running'
+      )
+    ORDER BY j.id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteRenderJob :exec
+UPDATE render_jobs
+SET status = 'done',
+    last_error = '',
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: RetryRenderJob :exec
+-- Puts a failed attempt back in the queue after a delay, or marks it
+-- superseded when a newer pending job for the tile already covers it. A job
+-- enqueued after the check makes it fail on render_jobs_pending_tile, and
+-- the caller supersedes it with SupersedeRenderJob instead.
+UPDATE render_jobs
+SET status = CASE
+        WHEN EXISTS (
+            SELECT 1 FROM render_jobs p
+            WHERE p.tile_id = render_jobs.tile_id AND p.status = 'pending'
+        ) THEN 'superseded'
+        ELSE 'pending'
+    END,
+    last_error = $2,
+    run_after = NOW() + make_interval(secs => sqlc.arg(delay_seconds)::FLOAT8),
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: SupersedeRenderJob :exec
+-- Retires a failed attempt that a job enqueued for the tile since covers.
+UPDATE render_jobs
+SET status = 'superseded',
+    last_error = $2,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: FailRenderJob :exec
+UPDATE render_jobs
+SET status = 'failed',
+    last_error = $2,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: ResetRunningRenderJobs :execrows
+-- Returns jobs left running by a process that stopped without finishing
+-- them to the queue.
+UPDATE render_jobs
+SET status = CASE
+        WHEN EXISTS (
+            SELECT 1 FROM render_jobs p
+            WHERE p.tile_id = render_jobs.tile_id AND p.status = 'pending'
+        ) THEN 'superseded'
+        ELSE 'pending'
+    END,
+    attempts = GREATEST(attempts - 1, 0),
+    updated_at = NOW()
+WHERE status = 'running';
+
+-- name: RequeueFailedRenderJobs :execrows
+UPDATE render_jobs
+SET status = 'pending',
+    attempts = 0,
+    run_after = NOW(),
+    updated_at = NOW()
+WHERE id IN (
+    SELECT DISTINCT ON (tile_id) id FROM render_jobs
+    WHERE status = 'failed'
+    ORDER BY tile_id, id DESC
+)
+  AND NOT EXISTS (
+      SELECT 1 FROM render_jobs p
+      WHERE p.tile_id = render_jobs.tile_id AND p.status = 'pending'
+  );
+
+-- name: CountRenderJobsByStatus :many
+SELECT status, COUNT(*) AS count
+FROM render_jobs
+GROUP BY status
+ORDER BY status;
+
+-- name: ListRenderJobsByStatus :many
+SELECT * FROM render_jobs
+WHERE status = $1
+ORDER BY updated_at DESC
+LIMIT $2;
@@ -0,0 +1,29 @@
+-- name: RefreshMarketStatsDaily :exec
+REFRESH MATERIALIZED VIEW CONCURRENTLY market_stats_daily;
+
+-- name: RefreshMarketStatsMonthly :exec
+REFRESH MATERIALIZED VIEW CONCURRENTLY market_stats_monthly;
+
+-- name: RefreshMarketStatsTotals :exec
+REFRESH MATERIALIZED VIEW CONCURRENTLY market_stats_totals;
+
+-- name: ListMarketStatsDaily :many
+SELECT * FROM market_stats_daily
+ORDER BY period, market;
+
+-- name: ListMarketStatsMonthly :many
+SELECT * FROM market_stats_monthly
+ORDER BY period, market;
+
+-- name: ListMarketStatsTotals :many
+SELECT * FROM market_stats_totals
+ORDER BY market;
+
+-- name: GetListingSummary :one
+-- Tiles for sale on the original contract. Wrapped tiles are held by the
+-- wrapper and cannot be bought there.
+SELECT
+    COUNT(*) FILTER (WHERE price > 0)::INTEGER AS listed_tiles,
+    MIN(price) FILTER (WHERE price > 0)::NUMERIC AS floor_price
+FROM tiles
+WHERE NOT wrapped;
@@ -0,0 +1,36 @@
+-- name: InsertTimeCapsuleMint :exec
+INSERT INTO time_capsule_mints (
+    time_stamp, block_number, tx, log_index, minted_by, amount
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (tx) DO NOTHING;
+
+-- name: InsertTimeCapsuleTransfer :exec
+INSERT INTO time_capsule_transfers (
+    time_stamp, block_number, tx, log_index, token_id, transferred_from, transferred_to
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (tx, log_index) DO NOTHING;
+
+-- name: InsertTimeCapsuleMetadataChange :exec
+INSERT INTO time_capsule_metadata_changes (
+    time_stamp, block_number, tx, log_index, action, value, updated_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (tx) DO NOTHING;
+
+-- name: GetTimeCapsulesByOwner :many
+SELECT * FROM time_capsule_tokens
+WHERE owner = sqlc.arg(address)
+ORDER BY token_id;
+
+-- name: ListTimeCapsuleTokens :many
+SELECT * FROM time_capsule_tokens
+ORDER BY token_id;
+
+-- name: ListTimeCapsuleMetadataChanges :many
+SELECT * FROM time_capsule_metadata_changes
+ORDER BY block_number DESC, log_index DESC;
@@ -0,0 +1,10 @@
+-- name: ListUnclassifiedTransfers :many
+SELECT * FROM transfer_histories
+WHERE transfer_type IS NULL AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(page_size);
+
+-- name: UpdateTransferType :exec
+UPDATE transfer_histories
+SET transfer_type = $2
+WHERE id = $1;
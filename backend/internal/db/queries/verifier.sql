@@ -0,0 +1,23 @@
+-- name: RepairTile :exec
+UPDATE tiles
+SET
+    image = $2,
+    price = $3,
+    url = $4,
+    ens = CASE WHEN owner = $5 THEN ens ELSE '' END,
+    owner = $5,
+    wrapped = $6
+WHERE id = $1;
+
+-- name: InsertTileRepair :one
+INSERT INTO tile_repairs (
+    tile_id, field, old_value, new_value, block_number
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING id;
+
+-- name: GetTileRepairsByTileId :many
+SELECT * FROM tile_repairs
+WHERE tile_id = $1
+ORDER BY id;
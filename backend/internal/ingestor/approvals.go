@@ -0,0 +1,107 @@
+package ingestor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+	utils "pixelmap.io/backend/internal/utils"
+)
+
+// Approvals decide who besides its owner can move a wrapped tile: the one
+// address approved for the token, until the tile next moves, and every
+// operator its owner has approved for all their tokens. The index keeps each
+// call; the current state is left to the views in 012_approvals.sql.
+
+// handleApprove records an approve of a wrapped tile. Approving the zero
+// address clears the tile's approval.
+func (i *Ingestor) handleApprove(ctx context.Context, call *Call) (*ChangeSet, error) {
+	args, err := decode[struct {
+		To      common.Address `abi:"to"`
+		TokenID *big.Int       `abi:"tokenId"`
+	}](call)
+	if err != nil {
+		return nil, err
+	}
+	approval := InsertWrapperApproval{
+		TimeStamp:   call.TimeStamp,
+		BlockNumber: call.BlockNumber,
+		Tx:          call.Tx.Hash,
+		LogIndex:    call.TransactionIndex,
+		TileID:      int32(args.TokenID.Int64()),
+		Approved:    utils.NormalizeAddress(args.To.Hex()),
+		ApprovedBy:  call.Tx.From,
+	}
+
+	i.logger.Info("Tile approval set",
+		zap.Int32("location", approval.TileID),
+		zap.String("approved", approval.Approved),
+		zap.String("tx", call.Tx.Hash),
+		zap.String("from", call.Tx.From))
+	return (&ChangeSet{}).Add(approval), nil
+}
+
+// handleSetApprovalForAll records an owner approving or revoking an operator
+// for all their wrapped tiles.
+func (i *Ingestor) handleSetApprovalForAll(ctx context.Context, call *Call) (*ChangeSet, error) {
+	args, err := decode[struct {
+		Operator common.Address `abi:"operator"`
+		Approved bool           `abi:"approved"`
+	}](call)
+	if err != nil {
+		return nil, err
+	}
+	approval := InsertWrapperOperatorApproval{
+		TimeStamp:   call.TimeStamp,
+		BlockNumber: call.BlockNumber,
+		Tx:          call.Tx.Hash,
+		LogIndex:    call.TransactionIndex,
+		Owner:       call.Tx.From,
+		Operator:    utils.NormalizeAddress(args.Operator.Hex()),
+		Approved:    args.Approved,
+	}
+
+	i.logger.Info("Operator approval set",
+		zap.String("owner", approval.Owner),
+		zap.String("operator", approval.Operator),
+		zap.Bool("approved", approval.Approved),
+		zap.String("tx", call.Tx.Hash))
+	return (&ChangeSet{}).Add(approval), nil
+}
+
+// handleAdminChange records an owner-only call that changes a contract: a
+// URI or extension setter, or a change of owner. Each takes at most one
+// argument, stored as the change's value; renounceOwnership takes none.
+func (i *Ingestor) handleAdminChange(ctx context.Context, call *Call) (*ChangeSet, error) {
+	var value sql.NullString
+	if len(call.Args) > 0 {
+		switch arg := call.Args[0].(type) {
+		case string:
+			value = sql.NullString{String: arg, Valid: true}
+		case common.Address:
+			value = sql.NullString{String: utils.NormalizeAddress(arg.Hex()), Valid: true}
+		default:
+			return nil, fmt.Errorf("unexpected %s argument of type %T", call.Method.Name, arg)
+		}
+	}
+
+	i.logger.Info("Contract admin change",
+		zap.String("contract", call.Contract),
+		zap.String("action", call.Method.Name),
+		zap.String("value", value.String),
+		zap.String("tx", call.Tx.Hash),
+		zap.String("from", call.Tx.From))
+	return (&ChangeSet{}).Add(InsertContractAdminChange{
+		TimeStamp:   call.TimeStamp,
+		BlockNumber: call.BlockNumber,
+		Tx:          call.Tx.Hash,
+		LogIndex:    call.TransactionIndex,
+		Contract:    call.Contract,
+		Action:      call.Method.Name,
+		Value:       value,
+		ChangedBy:   call.Tx.From,
+	}), nil
+}
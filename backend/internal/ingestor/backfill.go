@@ -0,0 +1,274 @@
+package ingestor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	db "pixelmap.io/backend/internal/db"
+)
+
+const defaultBackfillWorkers = 4
+
+// BackfillOptions controls Backfill. Zero values resume from the last
+// processed block, stop at the same safe head IngestTransactions uses, and
+// fetch 10,000-block chunks with four workers.
+type BackfillOptions struct {
+	FromBlock int64
+	ToBlock   int64
+	ChunkSize int64
+	Workers   int
+}
+
+type backfillResult struct {
+	transactions []EtherscanTransaction
+	applied      bool
+	err          error
+}
+
+// Backfill catches up a block range faster than IngestTransactions by
+// fetching chunks concurrently. Every fetch still goes through the shared
+// Etherscan client and its rate budget, so workers only overlap the waiting.
+// Chunks are applied one at a time in block order, and each transaction within
+// a chunk in block and log order, so the resulting state is the same as a
+// sequential run.
+//
+// Progress is kept per chunk in backfill_chunks. Running Backfill again with
+// the same range skips applied chunks and reuses fetched ones.
+func (i *Ingestor) Backfill(ctx context.Context, opts BackfillOptions) error {
+	// getStartBlock also seeds the tiles on an empty database.
+	startBlock, err := i.getStartBlock(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get start block: %w", err)
+	}
+
+	from, to := opts.FromBlock, opts.ToBlock
+	if from == 0 {
+		from = startBlock
+	}
+	if to == 0 {
+		if to, err = i.getEndBlock(); err != nil {
+			return fmt.Errorf("failed to get end block: %w", err)
+		}
+	}
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = blockRangeSize
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultBackfillWorkers
+	}
+
+	if from > to {
+		i.logger.Info("Nothing to backfill", zap.Int64("from", from), zap.Int64("to", to))
+		return nil
+	}
+
+	lastProcessed, err := i.queries.GetLastProcessedBlock(ctx)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get last processed block: %w", err)
+	}
+	// Applying a chunk records its end as the last processed block, which
+	// would mark the blocks between as done without ever fetching them.
+	if lastProcessed > 0 && from > lastProcessed+1 {
+		return fmt.Errorf("blocks %d-%d were never processed; backfill from %d instead",
+			lastProcessed+1, from-1, lastProcessed+1)
+	}
+
+	chunks, err := i.planBackfill(ctx, from, to, chunkSize)
+	if err != nil {
+		return err
+	}
+
+	i.logger.Info("Starting backfill",
+		zap.Int64("from", from),
+		zap.Int64("to", to),
+		zap.Int("chunks", len(chunks)),
+		zap.Int("workers", workers))
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	// ahead bounds how far fetching may run ahead of applying, so a slow
+	// chunk does not leave the rest of the range buffered in memory.
+	ahead := make(chan struct{}, workers*2)
+	jobs := make(chan int)
+	results := make([]chan backfillResult, len(chunks))
+	for n := range results {
+		results[n] = make(chan backfillResult, 1)
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				results[n] <- i.fetchBackfillChunk(ctx, chunks[n])
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		for n := range chunks {
+			select {
+			case ahead <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- n:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Once fetched, a chunk is applied in full even if the backfill is
+	// cancelled, as processBlockRange does with its windows, so a chunk is
+	// never left partly applied.
+	applyCtx := context.WithoutCancel(ctx)
+	for n, chunk := range chunks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var result backfillResult
+		select {
+		case result = <-results[n]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		<-ahead
+
+		if result.err != nil {
+			return fmt.Errorf("failed to fetch blocks %d-%d: %w", chunk.StartBlock, chunk.EndBlock, result.err)
+		}
+		if result.applied {
+			continue
+		}
+
+		switch {
+		case chunk.EndBlock <= lastProcessed:
+			// Already covered, either by the regular ingestor or by a run that
+			// stopped between recording the block and marking the chunk.
+			if err := i.queries.MarkBackfillChunkApplied(applyCtx, chunk.ID); err != nil {
+				return fmt.Errorf("failed to mark chunk applied: %w", err)
+			}
+			continue
+		case chunk.StartBlock <= lastProcessed:
+			return fmt.Errorf("blocks %d-%d overlap already processed block %d; backfill from %d instead",
+				chunk.StartBlock, chunk.EndBlock, lastProcessed, lastProcessed+1)
+		}
+
+		skipped, err := i.applyTransactions(applyCtx, result.transactions, chunk.EndBlock)
+		if err != nil {
+			return err
+		}
+		if err := i.queries.MarkBackfillChunkApplied(applyCtx, chunk.ID); err != nil {
+			return fmt.Errorf("failed to mark chunk applied: %w", err)
+		}
+		lastProcessed = chunk.EndBlock
+
+		i.logger.Info("Backfilled blocks",
+			zap.Int64("from", chunk.StartBlock),
+			zap.Int64("to", chunk.EndBlock),
+			zap.Int("transactions", len(result.transactions)),
+			zap.Int("skippedTransactions", skipped),
+			zap.Int("chunk", n+1),
+			zap.Int("chunks", len(chunks)))
+	}
+
+	i.signalNewData()
+	i.logger.Info("Finished backfill", zap.Int64("endBlock", to))
+	return nil
+}
+
+// planBackfill records a row for every chunk of the range and returns them in
+// block order.
+func (i *Ingestor) planBackfill(ctx context.Context, from, to, chunkSize int64) ([]db.BackfillChunk, error) {
+	ranges := backfillRanges(from, to, chunkSize)
+	for _, r := range ranges {
+		if err := i.queries.InsertBackfillChunk(ctx, db.InsertBackfillChunkParams{StartBlock: r[0], EndBlock: r[1]}); err != nil {
+			return nil, fmt.Errorf("failed to plan backfill chunk %d-%d: %w", r[0], r[1], err)
+		}
+	}
+
+	existing, err := i.queries.ListBackfillChunks(ctx, db.ListBackfillChunksParams{StartBlock: from, EndBlock: to})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backfill chunks: %w", err)
+	}
+	byRange := make(map[[2]int64]db.BackfillChunk, len(existing))
+	for _, chunk := range existing {
+		byRange[[2]int64{chunk.StartBlock, chunk.EndBlock}] = chunk
+	}
+
+	// Rows left over from runs with other boundaries are ignored.
+	chunks := make([]db.BackfillChunk, 0, len(ranges))
+	for _, r := range ranges {
+		chunks = append(chunks, byRange[r])
+	}
+	return chunks, nil
+}
+
+func backfillRanges(from, to, chunkSize int64) [][2]int64 {
+	var ranges [][2]int64
+	for start := from; start <= to; start += chunkSize {
+		end := start + chunkSize - 1
+		if end > to {
+			end = to
+		}
+		ranges = append(ranges, [2]int64{start, end})
+	}
+	return ranges
+}
+
+func (i *Ingestor) fetchBackfillChunk(ctx context.Context, chunk db.BackfillChunk) backfillResult {
+	switch chunk.Status {
+	case "applied":
+		return backfillResult{applied: true}
+	case "fetched":
+		var transactions []EtherscanTransaction
+		if err := json.Unmarshal(chunk.Transactions, &transactions); err == nil {
+			return backfillResult{transactions: transactions}
+		}
+		// Unreadable results are fetched again.
+	}
+
+	transactions, err := i.fetchTransactions(ctx, chunk.StartBlock, chunk.EndBlock)
+	if err != nil {
+		if markErr := i.queries.MarkBackfillChunkFailed(context.Background(), db.MarkBackfillChunkFailedParams{
+			ID:        chunk.ID,
+			LastError: err.Error(),
+		}); markErr != nil {
+			i.logger.Error("Failed to record backfill error", zap.Error(markErr))
+		}
+		return backfillResult{err: err}
+	}
+	if transactions == nil {
+		transactions = []EtherscanTransaction{}
+	}
+
+	encoded, err := json.Marshal(transactions)
+	if err != nil {
+		return backfillResult{err: fmt.Errorf("failed to encode transactions: %w", err)}
+	}
+	if err := i.queries.MarkBackfillChunkFetched(ctx, db.MarkBackfillChunkFetchedParams{
+		ID:               chunk.ID,
+		Transactions:     encoded,
+		TransactionCount: int32(len(transactions)),
+	}); err != nil {
+		return backfillResult{err: fmt.Errorf("failed to save fetched chunk: %w", err)}
+	}
+
+	return backfillResult{transactions: transactions}
+}
@@ -0,0 +1,106 @@
+package ingestor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "pixelmap.io/backend/internal/db"
+)
+
+func TestBackfillRanges(t *testing.T) {
+	assert.Equal(t, [][2]int64{{10, 19}, {20, 29}, {30, 34}}, backfillRanges(10, 34, 10))
+	assert.Equal(t, [][2]int64{{5, 5}}, backfillRanges(5, 5, 10))
+	assert.Empty(t, backfillRanges(6, 5, 10))
+}
+
+func TestSortTransactionsPutsCallsBeforeTheirEvents(t *testing.T) {
+	transactions := []EtherscanTransaction{
+		{Hash: "transfer", BlockNumber: "7", TransactionIndex: "0x1", LogIndex: "4"},
+		{Hash: "mint", BlockNumber: "5", TransactionIndex: "0x2", LogIndex: "3"},
+		{Hash: "wrap", BlockNumber: "5", TransactionIndex: "2"},
+		{Hash: "setTile", BlockNumber: "5", TransactionIndex: "0"},
+		{Hash: "buyTile", BlockNumber: "4", TransactionIndex: "9"},
+	}
+
+	SortTransactions(transactions)
+
+	var order []string
+	for _, tx := range transactions {
+		order = append(order, tx.Hash)
+	}
+	assert.Equal(t, []string{"buyTile", "setTile", "wrap", "mint", "transfer"}, order)
+}
+
+func TestBackfillMatchesSequentialIngest(t *testing.T) {
+	h := newIngestHarness(t)
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 9, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 9, Image: redTile, Price: ether(1)},
+		scriptStep{Method: "wrap", From: alice, Location: 9, Value: ether(1)},
+		scriptStep{Method: "transfer", From: alice, To: bob, Location: 9},
+		scriptStep{Method: "buyTile", From: bob, Location: 10, Value: ether(2)},
+	)
+
+	require.NoError(t, h.ingestor.Backfill(h.ctx, BackfillOptions{ChunkSize: 2, Workers: 3}))
+
+	tile := h.Tile(9)
+	assert.True(t, tile.Wrapped)
+	assert.True(t, strings.EqualFold(bob, tile.Owner), "owner = %s", tile.Owner)
+	assert.True(t, strings.EqualFold(bob, h.Tile(10).Owner))
+	assert.Equal(t, 2, h.Rows("transfer_histories", 9))
+
+	chunks, err := h.queries.ListBackfillChunks(h.ctx, db.ListBackfillChunksParams{StartBlock: 0, EndBlock: 1 << 40})
+	require.NoError(t, err)
+	require.NotEmpty(t, chunks)
+	for _, chunk := range chunks {
+		assert.Equal(t, "applied", chunk.Status, "chunk %d-%d", chunk.StartBlock, chunk.EndBlock)
+	}
+
+	last, err := h.queries.GetLastProcessedBlock(h.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, chunks[len(chunks)-1].EndBlock, last)
+}
+
+func TestBackfillResumesWithoutReapplying(t *testing.T) {
+	h := newIngestHarness(t)
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 1, Value: ether(2)},
+		scriptStep{Method: "buyTile", From: bob, Location: 2, Value: ether(2)},
+		scriptStep{Method: "buyTile", From: alice, Location: 3, Value: ether(2)},
+	)
+
+	// Stop partway, as an interrupted run would, then resume over the range.
+	require.NoError(t, h.ingestor.Backfill(h.ctx, BackfillOptions{ToBlock: 3, ChunkSize: 2}))
+	require.NoError(t, h.ingestor.Backfill(h.ctx, BackfillOptions{ChunkSize: 2}))
+	require.NoError(t, h.ingestor.Backfill(h.ctx, BackfillOptions{ChunkSize: 2}))
+
+	for id := int32(1); id <= 3; id++ {
+		assert.Equal(t, 1, h.Rows("purchase_histories", id), "tile %d", id)
+	}
+}
+
+func TestBackfillRefusesToSkipBlocks(t *testing.T) {
+	h := newIngestHarness(t)
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 1, Value: ether(2)},
+		scriptStep{Method: "buyTile", From: bob, Location: 2, Value: ether(2)},
+		scriptStep{Method: "buyTile", From: alice, Location: 3, Value: ether(2)},
+	)
+
+	require.NoError(t, h.ingestor.Backfill(h.ctx, BackfillOptions{ToBlock: 2, ChunkSize: 2}))
+	last, err := h.queries.GetLastProcessedBlock(h.ctx)
+	require.NoError(t, err)
+
+	err = h.ingestor.Backfill(h.ctx, BackfillOptions{FromBlock: last + 2, ChunkSize: 2})
+	require.ErrorContains(t, err, fmt.Sprintf("backfill from %d instead", last+1))
+
+	after, err := h.queries.GetLastProcessedBlock(h.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, last, after, "the gap is not recorded as processed")
+}
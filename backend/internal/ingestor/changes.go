@@ -0,0 +1,322 @@
+package ingestor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"go.uber.org/zap"
+	db "pixelmap.io/backend/internal/db"
+	utils "pixelmap.io/backend/internal/utils"
+)
+
+// ChangeSet is everything one call changes in the index, in the order the
+// changes are applied.
+type ChangeSet struct {
+	Changes []Change
+}
+
+// Add appends changes to the set.
+func (s *ChangeSet) Add(changes ...Change) *ChangeSet {
+	s.Changes = append(s.Changes, changes...)
+	return s
+}
+
+// Change is one write to the index.
+type Change interface {
+	apply(ctx context.Context, i *Ingestor, q *db.Queries) error
+}
+
+// apply writes the set's changes and archives the call in one database
+// transaction, so a failure leaves neither behind, then publishes its
+// notifications.
+func (s *ChangeSet) apply(ctx context.Context, i *Ingestor, tx *EtherscanTransaction, transaction db.InsertPixelMapTransactionParams) error {
+	if err := i.inTx(ctx, func(q *db.Queries) error {
+		return s.write(ctx, i, q, tx, transaction)
+	}); err != nil {
+		return err
+	}
+	return s.notify(ctx, i)
+}
+
+// inTx runs fn in a transaction holding the ingestion lock shared, so
+// ingestion never interleaves with a rebuild, which holds it exclusively. An
+// ingestor without a connection of its own, as during a rebuild, runs fn
+// with its queries instead.
+func (i *Ingestor) inTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	if i.conn == nil {
+		return fn(i.queries)
+	}
+
+	tx, err := i.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := i.queries.WithTx(tx)
+	if err := q.LockIngestionShared(ctx); err != nil {
+		return fmt.Errorf("failed to lock ingestion: %w", err)
+	}
+	if err := fn(q); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// write applies every change but the notifications and archives the call.
+func (s *ChangeSet) write(ctx context.Context, i *Ingestor, q *db.Queries, tx *EtherscanTransaction, transaction db.InsertPixelMapTransactionParams) error {
+	for _, change := range s.Changes {
+		if _, ok := change.(Notify); ok {
+			continue
+		}
+		if err := change.apply(ctx, i, q); err != nil {
+			return err
+		}
+	}
+	return archive(ctx, q, tx, transaction)
+}
+
+// notify publishes the set's notifications once its changes are written.
+func (s *ChangeSet) notify(ctx context.Context, i *Ingestor) error {
+	for _, change := range s.Changes {
+		if n, ok := change.(Notify); ok {
+			if err := n.apply(ctx, i, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// archive stores a call and the internal transactions it made in
+// pixel_map_transaction and pixel_map_internal_transaction.
+func archive(ctx context.Context, q *db.Queries, tx *EtherscanTransaction, transaction db.InsertPixelMapTransactionParams) error {
+	if _, err := q.InsertPixelMapTransaction(ctx, transaction); err != nil {
+		return fmt.Errorf("failed to insert transaction: %w", err)
+	}
+	for _, itx := range tx.Internal {
+		value, ok := new(big.Int).SetString(itx.Value, 10)
+		if !ok {
+			return fmt.Errorf("invalid internal transaction value %q in %s", itx.Value, tx.Hash)
+		}
+		if err := q.InsertPixelMapInternalTransaction(ctx, db.InsertPixelMapInternalTransactionParams{
+			BlockNumber: transaction.BlockNumber,
+			TimeStamp:   transaction.TimeStamp,
+			Hash:        transaction.Hash,
+			TraceID:     itx.TraceID,
+			From:        utils.NormalizeAddress(itx.From),
+			To:          utils.NormalizeAddress(itx.To),
+			Value:       value.String(),
+			IsError:     itx.IsError == "1",
+		}); err != nil {
+			return fmt.Errorf("failed to insert internal transaction: %w", err)
+		}
+	}
+	return nil
+}
+
+// InsertPurchase records a buyTile. A non-empty Market moves the purchase
+// out of the default market, as for tiles bought from a wrapper listing.
+type InsertPurchase struct {
+	db.InsertPurchaseHistoryParams
+	Market string
+}
+
+func (c InsertPurchase) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	id, err := q.InsertPurchaseHistory(ctx, c.InsertPurchaseHistoryParams)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Already recorded by an earlier run.
+		i.logger.Warn("Duplicate purchase history entry",
+			zap.String("tx", c.Tx),
+			zap.Int32("tileID", c.TileID),
+			zap.Int32("logIndex", c.LogIndex))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert purchase history: %w", err)
+	}
+	if c.Market != "" {
+		if err := q.UpdatePurchaseMarket(ctx, db.UpdatePurchaseMarketParams{ID: id, Market: c.Market}); err != nil {
+			return fmt.Errorf("failed to update purchase market: %w", err)
+		}
+	}
+	return nil
+}
+
+// InsertDataHistory records a tile update and queues its render.
+type InsertDataHistory db.InsertDataHistoryParams
+
+func (c InsertDataHistory) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if _, err := q.InsertDataHistory(ctx, db.InsertDataHistoryParams(c)); err != nil {
+		return fmt.Errorf("failed to insert data history: %w", err)
+	}
+	if err := q.EnqueueRenderJob(ctx, db.EnqueueRenderJobParams{
+		TileID:      c.TileID,
+		BlockNumber: c.BlockNumber,
+	}); err != nil {
+		return fmt.Errorf("failed to enqueue render job: %w", err)
+	}
+	i.signalNewData()
+	return nil
+}
+
+// InsertTileUpdateInput keeps the whole arguments of a tile update.
+type InsertTileUpdateInput db.InsertTileUpdateInputParams
+
+func (c InsertTileUpdateInput) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if err := q.InsertTileUpdateInput(ctx, db.InsertTileUpdateInputParams(c)); err != nil {
+		return fmt.Errorf("failed to insert tile update input: %w", err)
+	}
+	return nil
+}
+
+// UpdatePurchasePayment records what a purchase paid and where the ETH went.
+// It follows InsertPurchase, and also fills in a purchase recorded before.
+type UpdatePurchasePayment db.UpdatePurchasePaymentParams
+
+func (c UpdatePurchasePayment) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if err := q.UpdatePurchasePayment(ctx, db.UpdatePurchasePaymentParams(c)); err != nil {
+		return fmt.Errorf("failed to update purchase payment: %w", err)
+	}
+	return nil
+}
+
+type InsertTransfer db.InsertTransferHistoryParams
+
+func (c InsertTransfer) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if _, err := q.InsertTransferHistory(ctx, db.InsertTransferHistoryParams(c)); err != nil {
+		return fmt.Errorf("failed to insert transfer history: %w", err)
+	}
+	return nil
+}
+
+type InsertWrapping db.InsertWrappingHistoryParams
+
+func (c InsertWrapping) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if _, err := q.InsertWrappingHistory(ctx, db.InsertWrappingHistoryParams(c)); err != nil {
+		return fmt.Errorf("failed to insert wrapping history: %w", err)
+	}
+	return nil
+}
+
+type InsertWrapperProceeds db.InsertWrapperProceedsHistoryParams
+
+func (c InsertWrapperProceeds) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if err := q.InsertWrapperProceedsHistory(ctx, db.InsertWrapperProceedsHistoryParams(c)); err != nil {
+		return fmt.Errorf("failed to record wrapper %s: %w", c.Event, err)
+	}
+	return nil
+}
+
+// UpdateTile sets a tile's image, URL, price and owner after setTile.
+type UpdateTile db.UpdateTileParams
+
+func (c UpdateTile) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if err := q.UpdateTile(ctx, db.UpdateTileParams(c)); err != nil {
+		return fmt.Errorf("failed to update tile %d: %w", c.ID, err)
+	}
+	return nil
+}
+
+type UpdateTileOwner db.UpdateTileOwnerParams
+
+func (c UpdateTileOwner) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if err := q.UpdateTileOwner(ctx, db.UpdateTileOwnerParams(c)); err != nil {
+		return fmt.Errorf("failed to update tile owner: %w", err)
+	}
+	return nil
+}
+
+type UpdateTilePrice db.UpdateTilePriceParams
+
+func (c UpdateTilePrice) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if err := q.UpdateTilePrice(ctx, db.UpdateTilePriceParams(c)); err != nil {
+		return fmt.Errorf("failed to update tile price: %w", err)
+	}
+	return nil
+}
+
+type UpdateWrapped db.UpdateWrappedStatusParams
+
+func (c UpdateWrapped) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if err := q.UpdateWrappedStatus(ctx, db.UpdateWrappedStatusParams(c)); err != nil {
+		return fmt.Errorf("failed to update wrapped status: %w", err)
+	}
+	return nil
+}
+
+type InsertTimeCapsuleMint db.InsertTimeCapsuleMintParams
+
+func (c InsertTimeCapsuleMint) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if err := q.InsertTimeCapsuleMint(ctx, db.InsertTimeCapsuleMintParams(c)); err != nil {
+		return fmt.Errorf("failed to insert time capsule mint: %w", err)
+	}
+	return nil
+}
+
+type InsertTimeCapsuleTransfer db.InsertTimeCapsuleTransferParams
+
+func (c InsertTimeCapsuleTransfer) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if err := q.InsertTimeCapsuleTransfer(ctx, db.InsertTimeCapsuleTransferParams(c)); err != nil {
+		return fmt.Errorf("failed to insert time capsule transfer: %w", err)
+	}
+	return nil
+}
+
+type InsertTimeCapsuleMetadataChange db.InsertTimeCapsuleMetadataChangeParams
+
+func (c InsertTimeCapsuleMetadataChange) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if err := q.InsertTimeCapsuleMetadataChange(ctx, db.InsertTimeCapsuleMetadataChangeParams(c)); err != nil {
+		return fmt.Errorf("failed to insert time capsule metadata change: %w", err)
+	}
+	return nil
+}
+
+type InsertWrapperApproval db.InsertWrapperApprovalParams
+
+func (c InsertWrapperApproval) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if err := q.InsertWrapperApproval(ctx, db.InsertWrapperApprovalParams(c)); err != nil {
+		return fmt.Errorf("failed to insert wrapper approval: %w", err)
+	}
+	return nil
+}
+
+type InsertWrapperOperatorApproval db.InsertWrapperOperatorApprovalParams
+
+func (c InsertWrapperOperatorApproval) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if err := q.InsertWrapperOperatorApproval(ctx, db.InsertWrapperOperatorApprovalParams(c)); err != nil {
+		return fmt.Errorf("failed to insert wrapper operator approval: %w", err)
+	}
+	return nil
+}
+
+type InsertContractAdminChange db.InsertContractAdminChangeParams
+
+func (c InsertContractAdminChange) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	if err := q.InsertContractAdminChange(ctx, db.InsertContractAdminChangeParams(c)); err != nil {
+		return fmt.Errorf("failed to insert %s admin change: %w", c.Contract, err)
+	}
+	return nil
+}
+
+// Notify publishes an event once the rest of its set is committed.
+type Notify struct {
+	Type    string
+	Payload interface{}
+}
+
+func (c Notify) apply(ctx context.Context, i *Ingestor, q *db.Queries) error {
+	payload, err := json.Marshal(c.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", c.Type, err)
+	}
+	i.pubSub.Publish(Event{Type: c.Type, Payload: payload})
+	return nil
+}
@@ -0,0 +1,119 @@
+package ingestor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	db "pixelmap.io/backend/internal/db"
+	utils "pixelmap.io/backend/internal/utils"
+)
+
+// Change types stored in data_histories.change_type.
+const (
+	dataChangeImage    = "image"
+	dataChangeURL      = "url"
+	dataChangePrice    = "price"
+	dataChangeMultiple = "multiple"
+	dataChangeNone     = "none"
+)
+
+// compareDataHistory describes how a tile update setting image, url and price
+// differs from previous, the tile's update before it, or nil for its first.
+// A tile starts with no image, no URL and no price.
+func compareDataHistory(previous *db.DataHistory, image, url string, price sql.NullString) db.UpdateDataHistoryChangeParams {
+	var change db.UpdateDataHistoryChangeParams
+	var before db.DataHistory
+	if previous != nil {
+		before = *previous
+		change.PreviousImage = sql.NullString{String: before.Image, Valid: true}
+		change.PreviousUrl = sql.NullString{String: before.Url, Valid: true}
+		change.PreviousPrice = before.Price
+	}
+
+	var changed []string
+	if image != before.Image {
+		changed = append(changed, dataChangeImage)
+	}
+	if url != before.Url {
+		changed = append(changed, dataChangeURL)
+	}
+	if price != before.Price {
+		changed = append(changed, dataChangePrice)
+	}
+	switch len(changed) {
+	case 0:
+		change.ChangeType = sql.NullString{String: dataChangeNone, Valid: true}
+	case 1:
+		change.ChangeType = sql.NullString{String: changed[0], Valid: true}
+	default:
+		change.ChangeType = sql.NullString{String: dataChangeMultiple, Valid: true}
+	}
+
+	pixels, bounds := utils.DiffTileImages(before.Image, image)
+	change.PixelsChanged = sql.NullInt32{Int32: int32(pixels), Valid: true}
+	if pixels > 0 {
+		change.DiffMinX = sql.NullInt32{Int32: int32(bounds.Min.X), Valid: true}
+		change.DiffMinY = sql.NullInt32{Int32: int32(bounds.Min.Y), Valid: true}
+		change.DiffMaxX = sql.NullInt32{Int32: int32(bounds.Max.X - 1), Valid: true}
+		change.DiffMaxY = sql.NullInt32{Int32: int32(bounds.Max.Y - 1), Valid: true}
+	}
+	return change
+}
+
+// previousDataHistory returns the tile's last update before the given block
+// and index, or nil when there is none.
+func (i *Ingestor) previousDataHistory(ctx context.Context, tileID int32, blockNumber int64, logIndex int32) (*db.DataHistory, error) {
+	previous, err := i.queries.GetPreviousDataHistory(ctx, db.GetPreviousDataHistoryParams{
+		TileID:      tileID,
+		BlockNumber: blockNumber,
+		LogIndex:    logIndex,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous data history: %w", err)
+	}
+	return &previous, nil
+}
+
+const compareDataHistoriesPageSize = 500
+
+// CompareDataHistories fills in the change records of tile updates stored
+// before they were recorded. It returns how many updates were compared.
+func (i *Ingestor) CompareDataHistories(ctx context.Context) (int, error) {
+	var afterID int32
+	compared := 0
+	for {
+		updates, err := i.queries.ListUncomparedDataHistories(ctx, db.ListUncomparedDataHistoriesParams{
+			AfterID:  afterID,
+			PageSize: compareDataHistoriesPageSize,
+		})
+		if err != nil {
+			return compared, fmt.Errorf("failed to list uncompared data histories: %w", err)
+		}
+		if len(updates) == 0 {
+			return compared, nil
+		}
+
+		for _, update := range updates {
+			afterID = update.ID
+			previous, err := i.previousDataHistory(ctx, update.TileID, update.BlockNumber, update.LogIndex)
+			if err != nil {
+				return compared, err
+			}
+			change := compareDataHistory(previous, update.Image, update.Url, update.Price)
+			change.ID = update.ID
+			if err := i.queries.UpdateDataHistoryChange(ctx, change); err != nil {
+				return compared, fmt.Errorf("failed to update data history %d: %w", update.ID, err)
+			}
+			compared++
+		}
+		i.logger.Info("Compared data histories",
+			zap.Int("compared", compared),
+			zap.Int32("lastID", afterID))
+	}
+}
@@ -0,0 +1,44 @@
+package ingestor
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	db "pixelmap.io/backend/internal/db"
+)
+
+func TestCompareDataHistory(t *testing.T) {
+	price := sql.NullString{String: ether(1).String(), Valid: true}
+	previous := &db.DataHistory{Image: redTile, Url: "https://example.com", Price: price}
+
+	// The first update is compared against a blank tile.
+	change := compareDataHistory(nil, redTile, "https://example.com", price)
+	assert.Equal(t, dataChangeMultiple, change.ChangeType.String)
+	assert.False(t, change.PreviousImage.Valid)
+	assert.False(t, change.PreviousPrice.Valid)
+	assert.Equal(t, int32(256), change.PixelsChanged.Int32)
+
+	change = compareDataHistory(previous, redTile, "https://example.org", price)
+	assert.Equal(t, dataChangeURL, change.ChangeType.String)
+	assert.Equal(t, "https://example.com", change.PreviousUrl.String)
+	assert.Equal(t, int32(0), change.PixelsChanged.Int32)
+	assert.False(t, change.DiffMinX.Valid, "no pixel changed")
+
+	change = compareDataHistory(previous, redTile, "https://example.com", sql.NullString{String: "0", Valid: true})
+	assert.Equal(t, dataChangePrice, change.ChangeType.String)
+	assert.Equal(t, price, change.PreviousPrice)
+
+	// One row of pixels, the fourth, turns blue.
+	image := []byte(redTile)
+	copy(image[3*16*3:], strings.Repeat("00f", 16))
+	change = compareDataHistory(previous, string(image), "https://example.com", price)
+	assert.Equal(t, dataChangeImage, change.ChangeType.String)
+	assert.Equal(t, redTile, change.PreviousImage.String)
+	assert.Equal(t, int32(16), change.PixelsChanged.Int32)
+	assert.Equal(t, []int32{0, 3, 15, 3}, []int32{change.DiffMinX.Int32, change.DiffMinY.Int32, change.DiffMaxX.Int32, change.DiffMaxY.Int32})
+
+	change = compareDataHistory(previous, redTile, "https://example.com", price)
+	assert.Equal(t, dataChangeNone, change.ChangeType.String)
+}
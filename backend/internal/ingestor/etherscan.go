@@ -4,25 +4,29 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
+	"math/big"
 	"strings"
 
-	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
+	"github.com/ethereum/go-ethereum/common"
 	"go.uber.org/zap"
-	"golang.org/x/time/rate"
+	"pixelmap.io/backend/internal/metrics"
+	"pixelmap.io/backend/internal/ratebudget"
 )
 
 type EtherscanClient struct {
-	apiKey  string
-	baseURL string
-	chainId int
-	logger  *zap.Logger
-	client  *http.Client
-	limiter *rate.Limiter
+	pixelMapAddress    string
+	wrapperAddress     string
+	timeCapsuleAddress string
+	logger             *zap.Logger
+	scheduler          *ratebudget.Scheduler
+	// Results per call before Etherscan truncates; see fetchBlockRange.
+	txlistLimit  int
+	getLogsLimit int
 }
 
 type EtherscanResponse struct {
@@ -50,6 +54,35 @@ type EtherscanTransaction struct {
 	CumulativeGasUsed string `json:"cumulativeGasUsed"`
 	GasUsed           string `json:"gasUsed"`
 	Confirmations     string `json:"confirmations"`
+	// LogIndex is only set on transactions converted from Transfer events;
+	// txlist results have no log index.
+	LogIndex string `json:"logIndex,omitempty"`
+	// Internal holds the ETH moved by contract code within the transaction,
+	// attached by GetTransactions.
+	Internal []EtherscanInternalTransaction `json:"-"`
+	// Outer is the transaction a Transfer event was emitted in, attached by
+	// GetTransactions to classify the transfer. It is kept with backfill
+	// chunks, since looking it up again costs a request.
+	Outer *EtherscanTransaction `json:"outer,omitempty"`
+}
+
+// EtherscanInternalTransaction is a txlistinternal result: a value transfer
+// made by a contract rather than sent by an account.
+type EtherscanInternalTransaction struct {
+	BlockNumber     string `json:"blockNumber"`
+	TimeStamp       string `json:"timeStamp"`
+	Hash            string `json:"hash"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Value           string `json:"value"`
+	ContractAddress string `json:"contractAddress"`
+	Input           string `json:"input"`
+	Type            string `json:"type"`
+	Gas             string `json:"gas"`
+	GasUsed         string `json:"gasUsed"`
+	TraceID         string `json:"traceId"`
+	IsError         string `json:"isError"`
+	ErrCode         string `json:"errCode"`
 }
 
 type EtherscanTransferEvent struct {
@@ -69,41 +102,56 @@ type EtherscanTransferEvent struct {
 	Topics            []string `json:"topics"`
 }
 
+// NewEtherscanClient returns a client for the mainnet contracts on the given
+// chain. Use NewEtherscanClientForNetwork to follow another deployment.
 func NewEtherscanClient(apiKey string, chainId int, logger *zap.Logger) *EtherscanClient {
-	return &EtherscanClient{
-		apiKey:  apiKey,
-		baseURL: "https://api.etherscan.io/v2/api",
-		chainId: chainId,
-		logger:  logger,
-		client:  &http.Client{Timeout: 10 * time.Second},
-		// Etherscan's free tier allows 3 req/sec. 300ms (~3.3/sec) sat just over
-		// the cap and periodically tripped "NOTOK / Max calls per sec"; 500ms
-		// (2/sec) stays comfortably under even with burst alignment. The indexer
-		// makes only a handful of calls per 30s cycle, so the extra spacing is
-		// immaterial.
-		limiter: rate.NewLimiter(rate.Every(500*time.Millisecond), 1),
-	}
+	network := Mainnet
+	network.ChainID = chainId
+	return NewEtherscanClientForNetwork(apiKey, &network, logger)
 }
 
-func (c *EtherscanClient) GetLatestBlockNumber() (uint64, error) {
-	// Count this call against the shared Etherscan rate budget too; otherwise it
-	// bursts alongside the txlist/getLogs calls and trips the free tier's 3
-	// req/sec cap (surfaced as "NOTOK / Max calls per sec rate limit reached").
-	if err := c.limiter.Wait(context.Background()); err != nil {
-		return 0, fmt.Errorf("rate limiter wait: %w", err)
+// NewEtherscanClientForNetwork returns a client for network's contracts that
+// spreads calls over every key in apiKeys (comma separated) and any fallback
+// providers from the environment; see EtherscanProviders.
+func NewEtherscanClientForNetwork(apiKey string, network *Network, logger *zap.Logger) *EtherscanClient {
+	scheduler, err := ratebudget.New(logger, EtherscanProviders(network, apiKey))
+	if err != nil {
+		logger.Fatal("Invalid API provider configuration", zap.Error(err))
 	}
-	url := fmt.Sprintf("%s?chainid=%d&module=proxy&action=eth_blockNumber&apikey=%s", c.baseURL, c.chainId, c.apiKey)
+	return NewEtherscanClientWithScheduler(network, scheduler, logger)
+}
 
-	resp, err := c.client.Get(url)
-	if err != nil {
-		return 0, fmt.Errorf("HTTP request failed: %w", err)
+// NewEtherscanClientWithScheduler returns a client that sends its calls
+// through an existing scheduler, sharing its budget with other users.
+func NewEtherscanClientWithScheduler(network *Network, scheduler *ratebudget.Scheduler, logger *zap.Logger) *EtherscanClient {
+	return &EtherscanClient{
+		pixelMapAddress:    network.PixelMapAddress,
+		wrapperAddress:     network.WrapperAddress,
+		timeCapsuleAddress: network.TimeCapsuleAddress,
+		logger:             logger,
+		scheduler:          scheduler,
+		txlistLimit:        txlistResultLimit,
+		getLogsLimit:       getLogsResultLimit,
 	}
-	defer resp.Body.Close()
+}
 
-	body, err := io.ReadAll(resp.Body)
+// Usage reports per-key request counters for the client's scheduler.
+func (c *EtherscanClient) Usage() []ratebudget.Usage {
+	return c.scheduler.Usage()
+}
+
+func (c *EtherscanClient) GetLatestBlockNumber() (uint64, error) {
+	// Goes through the scheduler like every other call so it counts against
+	// the same budget as the txlist/getLogs calls.
+	body, err := c.scheduler.Get(context.Background(), map[string]string{
+		"module": "proxy",
+		"action": "eth_blockNumber",
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to read response body: %w", err)
+		metrics.EtherscanRequests.WithLabelValues("eth_blockNumber", "error").Inc()
+		return 0, fmt.Errorf("HTTP request failed: %w", err)
 	}
+	metrics.EtherscanRequests.WithLabelValues("eth_blockNumber", "ok").Inc()
 
 	c.logger.Debug("Etherscan API response", zap.String("body", string(body)))
 
@@ -130,9 +178,12 @@ func (c *EtherscanClient) GetLatestBlockNumber() (uint64, error) {
 }
 
 func (c *EtherscanClient) GetTransactions(ctx context.Context, startBlock, endBlock int64) ([]EtherscanTransaction, error) {
-	addresses := []string{
-		"0x015a06a433353f8db634df4eddf0c109882a15ab",
-		"0x050dc61dFB867E0fE3Cf2948362b6c0F3fAF790b",
+	addresses := []string{c.pixelMapAddress}
+	if c.wrapperAddress != "" {
+		addresses = append(addresses, c.wrapperAddress)
+	}
+	if c.timeCapsuleAddress != "" {
+		addresses = append(addresses, c.timeCapsuleAddress)
 	}
 
 	var allTransactions []EtherscanTransaction
@@ -144,92 +195,48 @@ func (c *EtherscanClient) GetTransactions(ctx context.Context, startBlock, endBl
 			zap.Int64("startBlock", startBlock),
 			zap.Int64("endBlock", endBlock))
 
-		params := map[string]string{
-			"module":     "account",
-			"action":     "txlist",
-			"address":    address,
-			"startblock": strconv.FormatInt(startBlock, 10),
-			"endblock":   strconv.FormatInt(endBlock, 10),
-			"sort":       "asc",
-			"apikey":     c.apiKey,
-		}
-
-		var rawResp json.RawMessage
-		if err := c.makeRequestWithRetry(ctx, params, &rawResp); err != nil {
-			if strings.Contains(err.Error(), "No transactions found") {
-				c.logger.Debug("No transactions found for address",
-					zap.String("address", address))
-				continue // Skip this address and continue with the next one
-			}
-			c.logger.Error("Failed to make request",
-				zap.Error(err),
-				zap.String("address", address))
-			continue // Skip this address and continue with the next one
-		}
-
-		// Try to unmarshal as an array first
-		var transactions []EtherscanTransaction
-		err := json.Unmarshal(rawResp, &transactions)
-		if err == nil {
-			c.logger.Debug("Successfully unmarshaled response as array",
-				zap.Int("transactionCount", len(transactions)),
-				zap.String("address", address))
-			allTransactions = append(allTransactions, transactions...)
-			continue
-		}
-
-		// If array unmarshal fails, try as EtherscanResponse
-		var resp EtherscanResponse
-		if err := json.Unmarshal(rawResp, &resp); err != nil {
-			c.logger.Error("Failed to unmarshal response",
-				zap.Error(err),
-				zap.String("rawResponse", string(rawResp)),
-				zap.String("address", address))
-			continue
-		}
-
-		if resp.Status != "1" {
-			c.logger.Warn("API returned non-success status",
-				zap.String("status", resp.Status),
-				zap.String("message", resp.Message),
-				zap.String("address", address))
-			continue
+		transactions, err := fetchBlockRange[EtherscanTransaction](ctx, c, blockRangeQuery{
+			params: map[string]string{
+				"module":  "account",
+				"action":  "txlist",
+				"address": address,
+				"sort":    "asc",
+			},
+			startKey: "startblock",
+			endKey:   "endblock",
+			limit:    c.txlistLimit,
+		}, startBlock, endBlock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch transactions of %s: %w", address, err)
 		}
-
-		transactions, ok := resp.Result.([]EtherscanTransaction)
-		if !ok {
-			c.logger.Warn("Unexpected result type for transactions",
-				zap.String("type", fmt.Sprintf("%T", resp.Result)),
-				zap.String("address", address))
-			continue
+		if len(transactions) > 0 {
+			c.logger.Info("Transactions found for address",
+				zap.String("address", address),
+				zap.Int("count", len(transactions)))
 		}
 
-		c.logger.Info("Transactions found for address",
-			zap.String("address", address),
-			zap.Int("count", len(transactions)))
-
 		allTransactions = append(allTransactions, transactions...)
 	}
 
+	// buyTile forwards the payment to the seller or the creator; the sale is
+	// recorded from those transfers, so a failed fetch fails the range.
+	internal, err := c.GetInternalTransactions(ctx, startBlock, endBlock)
+	if err != nil {
+		return nil, err
+	}
+	attachInternalTransactions(allTransactions, internal)
+
 	// Fetch Transfer events for the NFT contracts
 	transferEvents, err := c.GetTransferEvents(ctx, startBlock, endBlock)
-
 	if err != nil {
-		c.logger.Info(err.Error())
-		if strings.Contains(err.Error(), "No records found") {
-			c.logger.Debug("No records found for contract")
-		} else {
-			c.logger.Error("Failed to fetch transfer events", zap.Error(err))
-		}
-	} else {
-		c.logger.Debug("Transfer events found", zap.Int("count", len(transferEvents)))
-		c.logger.Debug("Transfer events", zap.Any("events", transferEvents))
-		for _, event := range transferEvents {
-			transaction := ConvertTransferEventToTransaction(event)
-			c.logger.Debug("Transaction", zap.Any("transaction", transaction))
-			allTransactions = append(allTransactions, transaction)
-		}
+		return nil, err
+	}
+	c.logger.Debug("Transfer events found", zap.Int("count", len(transferEvents)))
+	events, err := c.convertTransferEvents(ctx, allTransactions, transferEvents)
+	if err != nil {
+		return nil, err
 	}
+	allTransactions = append(allTransactions, events...)
 
 	c.logger.Debug("Finished processing all transactions",
 		zap.Int("totalTransactions", len(allTransactions)))
@@ -238,80 +245,268 @@ func (c *EtherscanClient) GetTransactions(ctx context.Context, startBlock, endBl
 }
 
 func (c *EtherscanClient) GetTransferEvents(ctx context.Context, startBlock, endBlock int64) ([]EtherscanTransferEvent, error) {
-	contractAddresses := []string{
-		"0x050dc61dFB867E0fE3Cf2948362b6c0F3fAF790b", // OpenSea contract address
-		// Add other relevant contract addresses here
+	var contractAddresses []string
+	if c.wrapperAddress != "" {
+		contractAddresses = append(contractAddresses, c.wrapperAddress) // OpenSea contract address
+	}
+	if c.timeCapsuleAddress != "" {
+		contractAddresses = append(contractAddresses, c.timeCapsuleAddress)
 	}
 
 	var allEvents []EtherscanTransferEvent
 
 	for _, contract := range contractAddresses {
-		params := map[string]string{
-			"module":    "logs",
-			"action":    "getLogs",
-			"fromBlock": strconv.FormatInt(startBlock, 10),
-			"toBlock":   strconv.FormatInt(endBlock, 10),
-			"address":   contract,
-			"topic0":    "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", // Transfer event signature
-			"apikey":    c.apiKey,
+		events, err := fetchBlockRange[EtherscanTransferEvent](ctx, c, blockRangeQuery{
+			params: map[string]string{
+				"module":  "logs",
+				"action":  "getLogs",
+				"address": contract,
+				"topic0":  "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", // Transfer event signature
+			},
+			startKey: "fromBlock",
+			endKey:   "toBlock",
+			limit:    c.getLogsLimit,
+		}, startBlock, endBlock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch transfer events of %s: %w", contract, err)
 		}
+		allEvents = append(allEvents, events...)
+	}
 
-		var rawResp json.RawMessage
-		if err := c.makeRequestWithRetry(ctx, params, &rawResp); err != nil {
-			if strings.Contains(err.Error(), "No records found") {
-				c.logger.Debug("No records found for contract", zap.String("contract", contract))
-				continue
-			} else {
-				c.logger.Error("Failed to fetch transfer events",
-					zap.Error(err),
-					zap.String("contract", contract))
-				continue
-			}
-		}
-		c.logger.Debug("Raw response", zap.String("rawResponse", string(rawResp)))
-
-		var events []EtherscanTransferEvent
-		if err := json.Unmarshal(rawResp, &events); err != nil {
-			c.logger.Error("Failed to unmarshal transfer events",
-				zap.Error(err),
-				zap.String("rawResponse", string(rawResp)),
-				zap.String("contract", contract))
-			continue
+	return allEvents, nil
+}
+
+// GetInternalTransactions returns the internal transactions of the PixelMap
+// contract in the range: the ETH it received and forwarded.
+func (c *EtherscanClient) GetInternalTransactions(ctx context.Context, startBlock, endBlock int64) ([]EtherscanInternalTransaction, error) {
+	internal, err := fetchBlockRange[EtherscanInternalTransaction](ctx, c, blockRangeQuery{
+		params: map[string]string{
+			"module":  "account",
+			"action":  "txlistinternal",
+			"address": c.pixelMapAddress,
+			"sort":    "asc",
+		},
+		startKey: "startblock",
+		endKey:   "endblock",
+		limit:    c.txlistLimit,
+	}, startBlock, endBlock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch internal transactions: %w", err)
+	}
+	return internal, nil
+}
+
+// attachInternalTransactions hands each call the internal transactions it
+// made. Transfer events share their call's hash but are left without them.
+func attachInternalTransactions(transactions []EtherscanTransaction, internal []EtherscanInternalTransaction) {
+	byHash := make(map[string][]EtherscanInternalTransaction)
+	for _, itx := range internal {
+		hash := strings.ToLower(itx.Hash)
+		byHash[hash] = append(byHash[hash], itx)
+	}
+	for n := range transactions {
+		if transactions[n].LogIndex == "" {
+			transactions[n].Internal = byHash[strings.ToLower(transactions[n].Hash)]
 		}
+	}
+}
 
-		allEvents = append(allEvents, events...)
+// convertTransferEvents turns Transfer events into pseudo-transactions and
+// attaches the transaction each was emitted in, which tells a sale from a
+// gift. Calls to the followed contracts are already in calls; a wrapper
+// transfer between owners made through another contract, such as a
+// marketplace, is looked up. Mints and burns need no lookup.
+func (c *EtherscanClient) convertTransferEvents(ctx context.Context, calls []EtherscanTransaction, events []EtherscanTransferEvent) ([]EtherscanTransaction, error) {
+	outer := make(map[string]*EtherscanTransaction, len(calls))
+	for _, call := range calls {
+		call.Internal = nil
+		outer[strings.ToLower(call.Hash)] = &call
 	}
 
-	return allEvents, nil
+	transactions := make([]EtherscanTransaction, 0, len(events))
+	for _, event := range events {
+		tx := ConvertTransferEventToTransaction(event)
+		hash := strings.ToLower(event.TransactionHash)
+		if _, ok := outer[hash]; !ok && c.wrapperAddress != "" && strings.EqualFold(event.ContractAddress, c.wrapperAddress) && !isMintOrBurn(event) {
+			found, err := c.GetTransactionByHash(ctx, event.TransactionHash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up transfer transaction: %w", err)
+			}
+			outer[hash] = found
+		}
+		tx.Outer = outer[hash]
+		transactions = append(transactions, tx)
+	}
+	return transactions, nil
 }
 
-func (c *EtherscanClient) makeRequest(ctx context.Context, params map[string]string, result interface{}) error {
-	if err := c.limiter.Wait(ctx); err != nil {
-		return fmt.Errorf("rate limiter wait: %w", err)
+func isMintOrBurn(event EtherscanTransferEvent) bool {
+	zero := common.Hash{}.Hex()
+	return len(event.Topics) < 3 || event.Topics[1] == zero || event.Topics[2] == zero
+}
+
+// GetTransactionByHash returns a transaction's sender, target, value and
+// input through Etherscan's proxy module.
+func (c *EtherscanClient) GetTransactionByHash(ctx context.Context, hash string) (*EtherscanTransaction, error) {
+	body, err := c.scheduler.Get(ctx, map[string]string{
+		"module": "proxy",
+		"action": "eth_getTransactionByHash",
+		"txhash": hash,
+	})
+	if err != nil {
+		metrics.EtherscanRequests.WithLabelValues("eth_getTransactionByHash", "error").Inc()
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	metrics.EtherscanRequests.WithLabelValues("eth_getTransactionByHash", "ok").Inc()
+
+	var response struct {
+		Result *struct {
+			BlockNumber string `json:"blockNumber"`
+			Hash        string `json:"hash"`
+			From        string `json:"from"`
+			To          string `json:"to"`
+			Value       string `json:"value"`
+			Input       string `json:"input"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", hash, err)
+	}
+	if response.Result == nil {
+		return nil, fmt.Errorf("transaction %s not found", hash)
 	}
+	result := response.Result
 
-	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL, nil)
+	blockNumber, err := strconv.ParseInt(strings.TrimPrefix(result.BlockNumber, "0x"), 16, 64)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("invalid block number %q for %s", result.BlockNumber, hash)
 	}
+	value, ok := new(big.Int).SetString(strings.TrimPrefix(result.Value, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid value %q for %s", result.Value, hash)
+	}
+	return &EtherscanTransaction{
+		BlockNumber: strconv.FormatInt(blockNumber, 10),
+		Hash:        result.Hash,
+		From:        strings.ToLower(result.From),
+		To:          strings.ToLower(result.To),
+		Value:       value.String(),
+		Input:       result.Input,
+	}, nil
+}
 
-	q := req.URL.Query()
-	// Add chainId parameter for V2 API
-	q.Add("chainid", strconv.Itoa(c.chainId))
-	for k, v := range params {
-		q.Add(k, v)
+// Etherscan returns at most this many results per call and drops the rest
+// without an error.
+const (
+	txlistResultLimit  = 10000 // txlist and txlistinternal
+	getLogsResultLimit = 1000
+)
+
+// blockRangeQuery is an Etherscan call over a range of blocks.
+type blockRangeQuery struct {
+	params           map[string]string
+	startKey, endKey string // names of the range parameters
+	limit            int    // results per call before Etherscan truncates
+}
+
+// fetchBlockRange returns every result of q between startBlock and endBlock.
+// A call that comes back full may have been truncated, so its range is split
+// in half and each half fetched again. A single block that is still full is
+// read page by page; Etherscan refuses pages past its result window, so a
+// block with more results than that fails rather than losing any.
+func fetchBlockRange[T any](ctx context.Context, c *EtherscanClient, q blockRangeQuery, startBlock, endBlock int64) ([]T, error) {
+	params := make(map[string]string, len(q.params)+2)
+	for k, v := range q.params {
+		params[k] = v
 	}
-	req.URL.RawQuery = q.Encode()
+	params[q.startKey] = strconv.FormatInt(startBlock, 10)
+	params[q.endKey] = strconv.FormatInt(endBlock, 10)
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("making request: %w", err)
+	results, err := fetchResults[T](ctx, c, params)
+	if err != nil || len(results) < q.limit {
+		return results, err
+	}
+
+	if startBlock < endBlock {
+		mid := startBlock + (endBlock-startBlock)/2
+		c.logger.Info("Result limit reached, splitting block range",
+			zap.String("action", params["action"]),
+			zap.String("address", params["address"]),
+			zap.Int64("startBlock", startBlock),
+			zap.Int64("endBlock", endBlock))
+		metrics.EtherscanRangeSplits.WithLabelValues(params["action"]).Inc()
+		first, err := fetchBlockRange[T](ctx, c, q, startBlock, mid)
+		if err != nil {
+			return nil, err
+		}
+		second, err := fetchBlockRange[T](ctx, c, q, mid+1, endBlock)
+		if err != nil {
+			return nil, err
+		}
+		return append(first, second...), nil
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	c.logger.Info("Result limit reached in a single block, paginating",
+		zap.String("action", params["action"]),
+		zap.String("address", params["address"]),
+		zap.Int64("block", startBlock))
+	params["offset"] = strconv.Itoa(q.limit)
+	results = nil
+	for page := 1; ; page++ {
+		params["page"] = strconv.Itoa(page)
+		pageResults, err := fetchResults[T](ctx, c, params)
+		if err != nil {
+			return nil, fmt.Errorf("block %d, page %d: %w", startBlock, page, err)
+		}
+		results = append(results, pageResults...)
+		if len(pageResults) < q.limit {
+			return results, nil
+		}
+	}
+}
+
+// fetchResults makes one call and decodes its results. Etherscan reports an
+// empty result as an error, which is returned as no results.
+func fetchResults[T any](ctx context.Context, c *EtherscanClient, params map[string]string) ([]T, error) {
+	var rawResp json.RawMessage
+	if err := c.makeRequestWithRetry(ctx, params, &rawResp); err != nil {
+		if strings.Contains(err.Error(), "No transactions found") || strings.Contains(err.Error(), "No records found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var results []T
+	if err := json.Unmarshal(rawResp, &results); err != nil {
+		c.logger.Error("Failed to unmarshal response",
+			zap.Error(err),
+			zap.String("rawResponse", string(rawResp)),
+			zap.String("action", params["action"]))
+		return nil, fmt.Errorf("failed to unmarshal %s results: %w", params["action"], err)
+	}
+	return results, nil
+}
+
+func (c *EtherscanClient) makeRequest(ctx context.Context, params map[string]string, result interface{}) error {
+	err := c.doRequest(ctx, params, result)
+
+	status := "ok"
+	switch {
+	case err == nil:
+	case strings.Contains(err.Error(), "No transactions found"), strings.Contains(err.Error(), "No records found"):
+		status = "empty"
+	default:
+		status = "error"
+	}
+	metrics.EtherscanRequests.WithLabelValues(params["action"], status).Inc()
+
+	return err
+}
+
+func (c *EtherscanClient) doRequest(ctx context.Context, params map[string]string, result interface{}) error {
+	body, err := c.scheduler.Get(ctx, params)
 	if err != nil {
-		return fmt.Errorf("reading response body: %w", err)
+		return fmt.Errorf("making request: %w", err)
 	}
 
 	var ethResp EtherscanResponse
@@ -346,14 +541,16 @@ func (c *EtherscanClient) makeRequestWithRetry(ctx context.Context, params map[s
 	operation := func() error {
 		err := c.makeRequest(ctx, params, result)
 		if err != nil {
-			if strings.Contains(err.Error(), "rate limiter wait:") {
-				// Local rate limiter error, retry immediately
-				return nil
+			// Rate limits are retried by the scheduler on another key; a NOTOK
+			// that reaches here is a provider-side error such as a query
+			// timeout.
+			// Pages past the result window are refused, not rate limited.
+			if strings.Contains(err.Error(), "Result window is too large") {
+				return backoff.Permanent(err)
 			}
-			if strings.Contains(err.Error(), "Max rate limit reached") ||
-				strings.Contains(err.Error(), "API error: NOTOK") {
-				c.logger.Warn("API rate limit or error encountered, retrying", zap.Error(err))
-				return err // Retry for these API errors
+			if strings.Contains(err.Error(), "API error: NOTOK") {
+				c.logger.Warn("API error encountered, retrying", zap.Error(err))
+				return err
 			}
 			return backoff.Permanent(err) // Don't retry for other errors
 		}
@@ -390,7 +587,7 @@ func ConvertTransferEventToTransaction(event EtherscanTransferEvent) EtherscanTr
 		TimeStamp:         strconv.FormatInt(timeStamp, 10), // Convert int64 to string
 		Hash:              event.TransactionHash,
 		From:              from,
-		To:                "0x050dc61dfb867e0fe3cf2948362b6c0f3faf790b",
+		To:                strings.ToLower(event.ContractAddress),
 		Value:             "0", // safeTransferFrom typically has no value transfer
 		ContractAddress:   event.ContractAddress,
 		TransactionIndex:  event.TransactionIndex,
@@ -401,7 +598,40 @@ func ConvertTransferEventToTransaction(event EtherscanTransferEvent) EtherscanTr
 		Nonce:             strconv.FormatInt(nonce, 10),
 		Confirmations:     "0",
 		Input:             inputData, // Set the input data to mimic safeTransferFrom
+		LogIndex:          strconv.FormatInt(nonce, 10),
+	}
+}
+
+// SortTransactions puts transactions in chain order: by block, then position
+// in the block, with a transaction's own call ahead of the Transfer events it
+// emitted, and events by log index. GetTransactions returns each contract's
+// calls and then the events, so without this a wrap and its mint in the same
+// window would be applied out of order.
+func SortTransactions(transactions []EtherscanTransaction) {
+	sort.SliceStable(transactions, func(a, b int) bool {
+		return transactionOrderLess(transactions[a], transactions[b])
+	})
+}
+
+func transactionOrderLess(a, b EtherscanTransaction) bool {
+	ka, kb := transactionOrderKey(a), transactionOrderKey(b)
+	for n := range ka {
+		if ka[n] != kb[n] {
+			return ka[n] < kb[n]
+		}
+	}
+	return false
+}
+
+func transactionOrderKey(tx EtherscanTransaction) [3]int64 {
+	block, _ := strconv.ParseInt(tx.BlockNumber, 10, 64)
+	// txlist reports the index in decimal, getLogs in hex.
+	index, _ := strconv.ParseInt(tx.TransactionIndex, 0, 64)
+	logIndex := int64(-1)
+	if tx.LogIndex != "" {
+		logIndex, _ = strconv.ParseInt(tx.LogIndex, 10, 64)
 	}
+	return [3]int64{block, index, logIndex}
 }
 
 // Helper function to parse hex string to uint64
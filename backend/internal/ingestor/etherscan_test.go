@@ -1,13 +1,20 @@
 package ingestor
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"pixelmap.io/backend/internal/ratebudget"
 )
 
 func TestNewEtherscanClient(t *testing.T) {
@@ -15,12 +22,16 @@ func TestNewEtherscanClient(t *testing.T) {
 	client := NewEtherscanClient("test_api_key", 1, logger)
 
 	assert.NotNil(t, client)
-	assert.Equal(t, "test_api_key", client.apiKey)
-	assert.Equal(t, "https://api.etherscan.io/v2/api", client.baseURL)
-	assert.Equal(t, 1, client.chainId)
+	assert.Equal(t, Mainnet.PixelMapAddress, client.pixelMapAddress)
+	assert.Equal(t, Mainnet.WrapperAddress, client.wrapperAddress)
+	assert.Equal(t, Mainnet.TimeCapsuleAddress, client.timeCapsuleAddress)
 	assert.NotNil(t, client.logger)
-	assert.NotNil(t, client.client)
-	assert.NotNil(t, client.limiter)
+	assert.NotNil(t, client.scheduler)
+
+	usage := client.Usage()
+	require.Len(t, usage, 1)
+	assert.Equal(t, "api.etherscan.io", usage[0].Provider)
+	assert.Equal(t, "…_key", usage[0].Key)
 }
 
 func TestGetLatestBlockNumber(t *testing.T) {
@@ -37,10 +48,212 @@ func TestGetLatestBlockNumber(t *testing.T) {
 	defer server.Close()
 
 	logger, _ := zap.NewDevelopment()
-	client := NewEtherscanClient("test_api_key", 1, logger)
-	client.baseURL = server.URL + "/api"
+	network := Mainnet
+	network.EtherscanURL = server.URL + "/api"
+	client := NewEtherscanClientForNetwork("test_api_key", &network, logger)
 
 	blockNumber, err := client.GetLatestBlockNumber()
 	require.NoError(t, err)
 	assert.Equal(t, uint64(256), blockNumber) // 0x100 in decimal
 }
+
+func TestEtherscanClientRotatesKeys(t *testing.T) {
+	var mu sync.Mutex
+	keys := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys[r.URL.Query().Get("apikey")]++
+		mu.Unlock()
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x100"}`))
+	}))
+	defer server.Close()
+
+	network := Mainnet
+	network.EtherscanURL = server.URL + "/api"
+	client := NewEtherscanClientForNetwork("key-one, key-two", &network, zap.NewNop())
+
+	for n := 0; n < 4; n++ {
+		_, err := client.GetLatestBlockNumber()
+		require.NoError(t, err)
+	}
+	assert.Equal(t, map[string]int{"key-one": 2, "key-two": 2}, keys)
+}
+
+// cappedExplorer serves txlist and getLogs the way Etherscan does: results
+// in block order, truncated at the limit without an error, and page/offset
+// pagination that refuses pages past the result window.
+type cappedExplorer struct {
+	mu       sync.Mutex
+	limits   map[string]int // results per call, by action
+	windows  map[string]int // page * offset allowed, by action; 0 for none
+	txs      []EtherscanTransaction
+	logs     []EtherscanTransferEvent
+	requests map[string]int
+}
+
+func (e *cappedExplorer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	q := r.URL.Query()
+	action := q.Get("action")
+	e.requests[action]++
+
+	limit := e.limits[action]
+	page, _ := strconv.Atoi(q.Get("page"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	if page > 0 {
+		if window := e.windows[action]; window > 0 && page*offset > window {
+			w.Write([]byte(`{"status":"0","message":"NOTOK","result":"Result window is too large, PageNo x Offset size must be less than or equal to 10000"}`))
+			return
+		}
+		limit = offset
+	}
+	skip := 0
+	if page > 0 {
+		skip = (page - 1) * offset
+	}
+
+	switch action {
+	case "txlist", "txlistinternal":
+		from, _ := strconv.ParseInt(q.Get("startblock"), 10, 64)
+		to, _ := strconv.ParseInt(q.Get("endblock"), 10, 64)
+		var result []EtherscanTransaction
+		for _, tx := range e.txs {
+			block, _ := strconv.ParseInt(tx.BlockNumber, 10, 64)
+			if action == "txlist" && strings.EqualFold(tx.To, q.Get("address")) && block >= from && block <= to {
+				result = append(result, tx)
+			}
+		}
+		writeEtherscanResult(w, capResults(result, skip, limit), "No transactions found")
+	case "getLogs":
+		from, _ := strconv.ParseInt(q.Get("fromBlock"), 10, 64)
+		to, _ := strconv.ParseInt(q.Get("toBlock"), 10, 64)
+		var result []EtherscanTransferEvent
+		for _, log := range e.logs {
+			block, _ := strconv.ParseInt(log.BlockNumber[2:], 16, 64)
+			if strings.EqualFold(log.ContractAddress, q.Get("address")) && block >= from && block <= to {
+				result = append(result, log)
+			}
+		}
+		writeEtherscanResult(w, capResults(result, skip, limit), "No records found")
+	default:
+		http.Error(w, "unsupported call", http.StatusBadRequest)
+	}
+}
+
+func capResults[T any](results []T, skip, limit int) []T {
+	if skip >= len(results) {
+		return nil
+	}
+	results = results[skip:]
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func newCappedExplorer(t *testing.T, network *Network, explorer *cappedExplorer) *EtherscanClient {
+	explorer.requests = map[string]int{}
+	server := httptest.NewServer(explorer)
+	t.Cleanup(server.Close)
+
+	logger := zap.NewNop()
+	scheduler, err := ratebudget.New(logger, []ratebudget.Provider{{
+		Name:          "capped",
+		BaseURL:       server.URL + "/api",
+		Keys:          []string{"test-key"},
+		RatePerSecond: 1e6,
+		ChainID:       network.ChainID,
+		Proxy:         true,
+	}})
+	require.NoError(t, err)
+	client := NewEtherscanClientWithScheduler(network, scheduler, logger)
+	client.txlistLimit = explorer.limits["txlist"]
+	client.getLogsLimit = explorer.limits["getLogs"]
+	return client
+}
+
+func TestGetTransactionsRetrievesTruncatedWindows(t *testing.T) {
+	network := Mainnet
+	network.TimeCapsuleAddress = ""
+	explorer := &cappedExplorer{
+		limits:  map[string]int{"txlist": 10, "txlistinternal": 10, "getLogs": 4},
+		windows: map[string]int{"txlist": 10, "txlistinternal": 10},
+	}
+
+	// Up to 4 calls a block, 150 in all: every full window can be split
+	// down to blocks that fit.
+	for n := 0; n < 150; n++ {
+		explorer.txs = append(explorer.txs, EtherscanTransaction{
+			BlockNumber: strconv.Itoa(1000 + n*n%97),
+			Hash:        fmt.Sprintf("0x%064x", n),
+			To:          network.PixelMapAddress,
+		})
+	}
+	// 30 Transfer events, 11 of them in block 1050: more than a call
+	// returns, so that block has to be paged through.
+	for n := 0; n < 30; n++ {
+		block := 1000 + 3*n
+		if n >= 19 {
+			block = 1050
+		}
+		explorer.logs = append(explorer.logs, EtherscanTransferEvent{
+			BlockNumber:      fmt.Sprintf("0x%x", block),
+			TimeStamp:        "0x0",
+			TransactionHash:  fmt.Sprintf("0x%064x", 1000+n),
+			LogIndex:         fmt.Sprintf("0x%x", n),
+			ContractAddress:  network.WrapperAddress,
+			TransactionIndex: "0x0",
+			GasUsed:          "0x0",
+			Topics: []string{
+				"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+				fmt.Sprintf("0x%064x", 0),
+				fmt.Sprintf("0x%064x", 1),
+				fmt.Sprintf("0x%064x", n),
+			},
+		})
+	}
+	sort.SliceStable(explorer.txs, func(a, b int) bool {
+		return explorer.txs[a].BlockNumber < explorer.txs[b].BlockNumber
+	})
+	sort.SliceStable(explorer.logs, func(a, b int) bool {
+		return explorer.logs[a].BlockNumber < explorer.logs[b].BlockNumber
+	})
+	client := newCappedExplorer(t, &network, explorer)
+
+	transactions, err := client.GetTransactions(context.Background(), 1000, 1099)
+	require.NoError(t, err)
+
+	seen := map[string]int{}
+	for _, tx := range transactions {
+		seen[tx.Hash+"/"+tx.LogIndex]++
+	}
+	assert.Len(t, transactions, 180, "every call and event is retrieved")
+	assert.Len(t, seen, 180, "and none twice")
+	assert.Greater(t, explorer.requests["txlist"], 1, "full windows are split")
+	assert.Greater(t, explorer.requests["getLogs"], 1)
+}
+
+func TestGetTransactionsFailsOnAnOverfullBlock(t *testing.T) {
+	network := Mainnet
+	network.WrapperAddress = ""
+	network.TimeCapsuleAddress = ""
+	explorer := &cappedExplorer{
+		limits:  map[string]int{"txlist": 10, "txlistinternal": 10, "getLogs": 4},
+		windows: map[string]int{"txlist": 10, "txlistinternal": 10},
+	}
+	for n := 0; n < 12; n++ {
+		explorer.txs = append(explorer.txs, EtherscanTransaction{
+			BlockNumber: "1000",
+			Hash:        fmt.Sprintf("0x%064x", n),
+			To:          network.PixelMapAddress,
+		})
+	}
+	client := newCappedExplorer(t, &network, explorer)
+
+	// Better to stop than to carry on without the calls that did not fit.
+	_, err := client.GetTransactions(context.Background(), 1000, 1099)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Result window is too large")
+}
@@ -0,0 +1,318 @@
+package ingestor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+	db "pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/stats"
+	utils "pixelmap.io/backend/internal/utils"
+)
+
+// Handlers for the OG PixelMap contract and the wrapper. Argument structs are
+// tagged with the ABI's argument names.
+
+type locationArgs struct {
+	Location *big.Int `abi:"location"`
+}
+
+type wrapperLocationArgs struct {
+	Location *big.Int `abi:"_locationID"`
+}
+
+type transferArgs struct {
+	From    common.Address `abi:"from"`
+	To      common.Address `abi:"to"`
+	TokenID *big.Int       `abi:"tokenId"`
+}
+
+// handleNothing archives calls that change nothing the index follows.
+func (i *Ingestor) handleNothing(ctx context.Context, call *Call) (*ChangeSet, error) {
+	i.logger.Debug("Call archived",
+		zap.String("contract", call.Contract),
+		zap.String("method", call.Method.Name),
+		zap.String("tx", call.Tx.Hash),
+		zap.String("from", call.Tx.From))
+	return &ChangeSet{}, nil
+}
+
+// handleBuyTile records a purchase at the price paid and hands the tile to
+// the buyer, taking it off the market until they list it.
+func (i *Ingestor) handleBuyTile(ctx context.Context, call *Call) (*ChangeSet, error) {
+	args, err := decode[locationArgs](call)
+	if err != nil {
+		return nil, err
+	}
+	tileID := int32(args.Location.Int64())
+	if call.Tx.From == "" {
+		return nil, fmt.Errorf("buyTile of tile %d has no sender", tileID)
+	}
+
+	tile, err := i.queries.GetTileById(ctx, tileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tile data: %w", err)
+	}
+	// A tile the wrapper listed on unwrap is sold on behalf of the
+	// unwrapper, who can withdraw the proceeds from the wrapper.
+	wrapperListing, err := i.openWrapperListing(ctx, tileID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The original contract pays whoever owns the tile there, which for a
+	// wrapper listing is the wrapper.
+	seller, payee := utils.NormalizeAddress(tile.Owner), utils.NormalizeAddress(tile.Owner)
+	if wrapperListing != nil {
+		seller, payee = wrapperListing.Seller, i.network.WrapperAddress
+	}
+	paid, payment := i.salePayment(call, tileID, tile.Price, payee)
+
+	purchase := InsertPurchase{InsertPurchaseHistoryParams: db.InsertPurchaseHistoryParams{
+		TileID:      tileID,
+		SoldBy:      seller,
+		PurchasedBy: call.Tx.From,
+		Price:       paid,
+		Tx:          call.Tx.Hash,
+		TimeStamp:   call.TimeStamp,
+		BlockNumber: call.BlockNumber,
+		LogIndex:    call.TransactionIndex,
+	}}
+	if purchase.Price == "" {
+		purchase.Price = "0"
+	}
+
+	changes := &ChangeSet{}
+	if wrapperListing != nil {
+		purchase.Market = stats.MarketWrapper
+		changes.Add(purchase, payment, i.wrapperSale(wrapperListing, call))
+	} else {
+		changes.Add(purchase, payment)
+	}
+
+	i.logger.Info("Tile purchased",
+		zap.Int32("location", tileID),
+		zap.String("newOwner", call.Tx.From),
+		zap.String("transaction", call.Tx.Hash))
+	return changes.Add(
+		UpdateTileOwner{ID: tileID, Owner: call.Tx.From},
+		UpdateTilePrice{ID: tileID, Price: "0"},
+	), nil
+}
+
+type setTileArgs struct {
+	Location *big.Int `abi:"location"`
+	Image    string   `abi:"image"`
+	URL      string   `abi:"url"`
+	Price    *big.Int `abi:"price"`
+}
+
+type setTileDataArgs struct {
+	Location *big.Int `abi:"_locationID"`
+	Image    string   `abi:"_image"`
+	URL      string   `abi:"_url"`
+}
+
+func (i *Ingestor) handleSetTile(ctx context.Context, call *Call) (*ChangeSet, error) {
+	args, err := decode[setTileArgs](call)
+	if err != nil {
+		return nil, err
+	}
+	return i.tileUpdate(ctx, call, args.Location, args.Image, args.URL, args.Price)
+}
+
+// handleSetTileData updates a wrapped tile, which keeps its price.
+func (i *Ingestor) handleSetTileData(ctx context.Context, call *Call) (*ChangeSet, error) {
+	args, err := decode[setTileDataArgs](call)
+	if err != nil {
+		return nil, err
+	}
+	return i.tileUpdate(ctx, call, args.Location, args.Image, args.URL, nil)
+}
+
+// tileUpdate records a new image and URL for a tile and queues its render,
+// along with what changed since the tile's previous update. A nil priceWei
+// keeps the current price.
+func (i *Ingestor) tileUpdate(ctx context.Context, call *Call, location *big.Int, image, url string, priceWei *big.Int) (*ChangeSet, error) {
+	tileID := int32(location.Int64())
+
+	// Prices are stored in wei, exactly as the contract holds them.
+	var price string
+	if priceWei == nil {
+		currentTile, err := i.queries.GetTileById(ctx, tileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current tile data: %w", err)
+		}
+		price = currentTile.Price
+	} else {
+		price = priceWei.String()
+	}
+
+	i.logger.Info("Tile update",
+		zap.Int32("location", tileID),
+		zap.String("price", price),
+		zap.String("url", url),
+		zap.String("tx", call.Tx.Hash),
+		zap.String("from", call.Tx.From))
+
+	// The arguments are kept whole in tile_update_inputs, which flags what
+	// tiles and data_histories could not hold.
+	priceValue := sql.NullString{String: price, Valid: price != ""}
+	input := newTileUpdateInput(call, tileID, image, url, priceValue)
+	url = input.Url
+	image, _ = truncateText(input.Image, maxImageLength)
+	tileURL, _ := truncateText(url, maxTileURLLength)
+	if input.ImageTruncated || input.UrlTruncated || input.InvalidEncoding {
+		i.logger.Warn("Tile update arguments stored in part",
+			zap.Int32("location", tileID),
+			zap.Bool("imageTruncated", input.ImageTruncated),
+			zap.Bool("urlTruncated", input.UrlTruncated),
+			zap.Bool("invalidEncoding", input.InvalidEncoding),
+			zap.String("tx", call.Tx.Hash))
+	}
+
+	updatedBy := call.Tx.From
+	if ensName := i.resolveENS(call.Tx.From); ensName != "" {
+		updatedBy = ensName
+	}
+
+	previous, err := i.previousDataHistory(ctx, tileID, call.BlockNumber, call.TransactionIndex)
+	if err != nil {
+		return nil, err
+	}
+	change := compareDataHistory(previous, image, url, priceValue)
+
+	return (&ChangeSet{}).Add(
+		input,
+		InsertDataHistory{
+			TileID:        tileID,
+			Price:         priceValue,
+			Url:           url,
+			Tx:            call.Tx.Hash,
+			TimeStamp:     call.TimeStamp,
+			BlockNumber:   call.BlockNumber,
+			Image:         image,
+			UpdatedBy:     updatedBy,
+			LogIndex:      call.TransactionIndex,
+			ChangeType:    change.ChangeType,
+			PreviousImage: change.PreviousImage,
+			PreviousUrl:   change.PreviousUrl,
+			PreviousPrice: change.PreviousPrice,
+			PixelsChanged: change.PixelsChanged,
+			DiffMinX:      change.DiffMinX,
+			DiffMinY:      change.DiffMinY,
+			DiffMaxX:      change.DiffMaxX,
+			DiffMaxY:      change.DiffMaxY,
+		},
+		UpdateTile{
+			ID:    tileID,
+			Price: price,
+			Url:   tileURL,
+			Image: image,
+			Owner: call.Tx.From,
+		},
+		Notify{Type: EventTypeDiscordNotification, Payload: map[string]interface{}{
+			"message": fmt.Sprintf("Tile %d updated by %s", tileID, call.Tx.From),
+			"url":     url,
+		}},
+	), nil
+}
+
+// handleWrap marks a tile wrapped. The wrapper buys the tile, which leaves it
+// unlisted.
+func (i *Ingestor) handleWrap(ctx context.Context, call *Call) (*ChangeSet, error) {
+	args, err := decode[wrapperLocationArgs](call)
+	if err != nil {
+		return nil, err
+	}
+	tileID := int32(args.Location.Int64())
+
+	i.logger.Info("Tile wrapped",
+		zap.Int32("location", tileID),
+		zap.String("tx", call.Tx.Hash),
+		zap.String("from", call.Tx.From))
+	return (&ChangeSet{}).Add(
+		i.wrapping(call, tileID, true),
+		UpdateWrapped{ID: tileID, Wrapped: true},
+		UpdateTilePrice{ID: tileID, Price: "0"},
+	), nil
+}
+
+// handleUnwrap marks a tile unwrapped. The wrapper lists it on the original
+// contract at the sale price, holding the proceeds for the unwrapper.
+func (i *Ingestor) handleUnwrap(ctx context.Context, call *Call) (*ChangeSet, error) {
+	args, err := decode[struct {
+		Location  *big.Int `abi:"_locationID"`
+		SalePrice *big.Int `abi:"_salePrice"`
+	}](call)
+	if err != nil {
+		return nil, err
+	}
+	tileID := int32(args.Location.Int64())
+	salePrice := args.SalePrice
+	if salePrice == nil {
+		salePrice = new(big.Int)
+	}
+
+	i.logger.Info("Tile unwrapped",
+		zap.Int32("location", tileID),
+		zap.String("salePrice", salePrice.String()),
+		zap.String("tx", call.Tx.Hash),
+		zap.String("from", call.Tx.From))
+	return (&ChangeSet{}).Add(
+		i.wrapping(call, tileID, false),
+		UpdateWrapped{ID: tileID, Wrapped: false},
+		UpdateTilePrice{ID: tileID, Price: salePrice.String()},
+		i.wrapperListing(call, tileID, salePrice),
+	), nil
+}
+
+func (i *Ingestor) wrapping(call *Call, tileID int32, wrapped bool) InsertWrapping {
+	return InsertWrapping{
+		TileID:      tileID,
+		Wrapped:     wrapped,
+		Tx:          call.Tx.Hash,
+		TimeStamp:   call.TimeStamp,
+		BlockNumber: call.BlockNumber,
+		UpdatedBy:   call.Tx.From,
+		LogIndex:    call.TransactionIndex,
+	}
+}
+
+// handleTileTransfer moves a wrapped tile to its new owner and records what
+// kind of transfer it was.
+func (i *Ingestor) handleTileTransfer(ctx context.Context, call *Call) (*ChangeSet, error) {
+	args, err := decode[transferArgs](call)
+	if err != nil {
+		return nil, err
+	}
+	tileID := int32(args.TokenID.Int64())
+	from := utils.NormalizeAddress(args.From.Hex())
+	to := utils.NormalizeAddress(args.To.Hex())
+	transferType := classifyTransfer(i.network, call.Tx.To, from, to, transferContext(call))
+
+	i.logger.Info("Transfer processed",
+		zap.String("from", from),
+		zap.String("to", to),
+		zap.String("type", transferType),
+		zap.Int32("location", tileID),
+		zap.String("tx", call.Tx.Hash),
+		zap.String("caller", call.Tx.From))
+	return (&ChangeSet{}).Add(
+		InsertTransfer{
+			TileID:            tileID,
+			Tx:                call.Tx.Hash,
+			TimeStamp:         call.TimeStamp,
+			BlockNumber:       call.BlockNumber,
+			TransferredFrom:   from,
+			TransferredTo:     to,
+			LogIndex:          call.TransactionIndex,
+			TransferType:      sql.NullString{String: transferType, Valid: transferType != ""},
+			IsWrapperContract: i.network.IsWrapper(call.Tx.To),
+		},
+		UpdateTileOwner{ID: tileID, Owner: to, Ens: i.resolveENS(to)},
+	), nil
+}
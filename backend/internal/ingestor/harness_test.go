@@ -0,0 +1,947 @@
+package ingestor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	pixelmap "pixelmap.io/backend/internal/contracts/pixelmap"
+	pixelmapWrapper "pixelmap.io/backend/internal/contracts/pixelmapWrapper"
+	"pixelmap.io/backend/internal/contracts/timecapsule"
+	db "pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/db/dbtest"
+	"pixelmap.io/backend/internal/metrics"
+	"pixelmap.io/backend/internal/portfolio"
+	"pixelmap.io/backend/internal/ratebudget"
+	"pixelmap.io/backend/internal/stats"
+)
+
+const transferEventTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// scriptStep is one call in a scripted PixelMap history.
+type scriptStep struct {
+	Method   string // buyTile, setTile, truncatedSetTile, wrap, transfer, unwrap, withdrawETH, approve, setApprovalForAll, mintCapsules or transferCapsule
+	From     string
+	To       string // recipient, for transfer and transferCapsule; approved address or operator, for approvals
+	Location int64  // tile, time capsule token, or how many capsules to mint
+	Image    string
+	URL      string
+	Price    *big.Int // listing price, for setTile and unwrap
+	Value    *big.Int // ETH sent, for buyTile, wrap and transfer
+	Approved bool     // for setApprovalForAll
+	Via      string   // contract a transfer is made through; transferProxy when empty
+}
+
+// transferProxy stands in for a contract, such as a multisig, that makes
+// transfers on its owner's behalf without being a marketplace.
+const transferProxy = "0x8464135c8f25da09e49bc8782676a84730c318bc"
+
+// simulatedChain serves a scripted history through the same Etherscan API the
+// real client talks to, so the production fetch and decode paths run
+// unchanged. Every step is mined into its own block.
+type simulatedChain struct {
+	t       *testing.T
+	network *Network
+	server  *httptest.Server
+
+	mu       sync.Mutex
+	head     int64
+	txs      []EtherscanTransaction
+	logs     []EtherscanTransferEvent
+	internal []EtherscanInternalTransaction
+	external []EtherscanTransaction // calls to contracts the index does not follow
+	owners   map[int64]string       // tile owners on the original contract
+	capsules int64                  // time capsules minted so far
+}
+
+func newSimulatedChain(t *testing.T, network *Network) *simulatedChain {
+	chain := &simulatedChain{t: t, network: network, head: network.StartBlock, owners: map[int64]string{}}
+	chain.server = httptest.NewServer(chain)
+	t.Cleanup(chain.server.Close)
+	return chain
+}
+
+// Client returns an EtherscanClient pointed at the simulated chain, without
+// the production rate limit.
+func (c *simulatedChain) Client(logger *zap.Logger) *EtherscanClient {
+	scheduler, err := ratebudget.New(logger, []ratebudget.Provider{{
+		Name:          "simulated",
+		BaseURL:       c.server.URL + "/api",
+		Keys:          []string{"test-key"},
+		RatePerSecond: 1e6,
+		ChainID:       c.network.ChainID,
+		Proxy:         true,
+	}})
+	if err != nil {
+		panic(err)
+	}
+	return NewEtherscanClientWithScheduler(c.network, scheduler, logger)
+}
+
+func (c *simulatedChain) Replay(steps ...scriptStep) {
+	for _, step := range steps {
+		c.apply(step)
+	}
+}
+
+func (c *simulatedChain) apply(step scriptStep) {
+	c.t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pixelMapABI, err := pixelmap.PixelMapMetaData.GetAbi()
+	require.NoError(c.t, err)
+	wrapperABI, err := pixelmapWrapper.PixelMapWrapperMetaData.GetAbi()
+	require.NoError(c.t, err)
+	capsuleABI, err := timecapsule.PixelMapTimeCapsuleMetaData.GetAbi()
+	require.NoError(c.t, err)
+
+	location := big.NewInt(step.Location)
+	zero := common.Address{}.Hex()
+
+	c.head++
+	switch step.Method {
+	case "buyTile":
+		hash := c.call(step.From, c.network.PixelMapAddress, step.Value, pixelMapABI, "buyTile", location)
+		c.sell(hash, step.Location, step.From, step.Value)
+	case "setTile":
+		c.call(step.From, c.network.PixelMapAddress, nil, pixelMapABI, "setTile", location, step.Image, step.URL, orZero(step.Price))
+	case "truncatedSetTile":
+		c.call(step.From, c.network.PixelMapAddress, nil, pixelMapABI, "setTile", location, step.Image, step.URL, orZero(step.Price))
+		last := &c.txs[len(c.txs)-1]
+		last.Input = last.Input[:len(last.Input)-64]
+	case "wrap":
+		hash := c.call(step.From, c.network.WrapperAddress, step.Value, wrapperABI, "wrap", location)
+		c.pay(hash, c.network.WrapperAddress, c.network.PixelMapAddress, step.Value)
+		c.sell(hash, step.Location, c.network.WrapperAddress, step.Value)
+		c.transferLog(c.network.WrapperAddress, hash, zero, step.From, step.Location)
+	case "unwrap":
+		hash := c.call(step.From, c.network.WrapperAddress, nil, wrapperABI, "unwrap", location, orZero(step.Price))
+		c.transferLog(c.network.WrapperAddress, hash, step.From, zero, step.Location)
+	case "transfer":
+		via := step.Via
+		if via == "" {
+			via = transferProxy
+		}
+		hash := c.hash()
+		c.external = append(c.external, EtherscanTransaction{
+			BlockNumber: strconv.FormatInt(c.head, 10),
+			Hash:        hash,
+			From:        strings.ToLower(step.From),
+			To:          strings.ToLower(via),
+			Value:       orZero(step.Value).String(),
+			Input:       "0x",
+		})
+		c.transferLog(c.network.WrapperAddress, hash, step.From, step.To, step.Location)
+	case "withdrawETH":
+		c.call(step.From, c.network.WrapperAddress, nil, wrapperABI, "withdrawETH", location)
+	case "approve":
+		c.call(step.From, c.network.WrapperAddress, nil, wrapperABI, "approve", common.HexToAddress(step.To), location)
+	case "setApprovalForAll":
+		c.call(step.From, c.network.WrapperAddress, nil, wrapperABI, "setApprovalForAll", common.HexToAddress(step.To), step.Approved)
+	case "mintCapsules":
+		hash := c.call(step.From, c.network.TimeCapsuleAddress, nil, capsuleABI, "MultiMintOwner", location)
+		for n := int64(0); n < step.Location; n++ {
+			c.capsules++
+			c.transferLog(c.network.TimeCapsuleAddress, hash, zero, step.From, c.capsules)
+		}
+	case "transferCapsule":
+		hash := c.call(step.From, c.network.TimeCapsuleAddress, nil, capsuleABI, "transferFrom", common.HexToAddress(step.From), common.HexToAddress(step.To), location)
+		c.transferLog(c.network.TimeCapsuleAddress, hash, step.From, step.To, step.Location)
+	default:
+		c.t.Fatalf("unknown script method %q", step.Method)
+	}
+}
+
+func (c *simulatedChain) call(from, to string, value *big.Int, contractABI *abi.ABI, method string, args ...interface{}) string {
+	input, err := contractABI.Pack(method, args...)
+	require.NoError(c.t, err)
+
+	hash := c.hash()
+	c.txs = append(c.txs, EtherscanTransaction{
+		BlockNumber:       strconv.FormatInt(c.head, 10),
+		TimeStamp:         strconv.FormatInt(c.timestamp(), 10),
+		Hash:              hash,
+		Nonce:             strconv.Itoa(len(c.txs)),
+		BlockHash:         fmt.Sprintf("0x%064x", c.head),
+		TransactionIndex:  "0",
+		From:              strings.ToLower(from),
+		To:                strings.ToLower(to),
+		Value:             orZero(value).String(),
+		Gas:               "300000",
+		GasPrice:          "1000000000",
+		IsError:           "0",
+		TxreceiptStatus:   "1",
+		Input:             "0x" + common.Bytes2Hex(input),
+		CumulativeGasUsed: "100000",
+		GasUsed:           "100000",
+		Confirmations:     "1",
+	})
+	return hash
+}
+
+func (c *simulatedChain) transferLog(contract, hash, from, to string, location int64) {
+	c.logs = append(c.logs, EtherscanTransferEvent{
+		BlockNumber:      fmt.Sprintf("0x%x", c.head),
+		TimeStamp:        fmt.Sprintf("0x%x", c.timestamp()),
+		TransactionHash:  hash,
+		LogIndex:         fmt.Sprintf("0x%x", len(c.logs)),
+		ContractAddress:  strings.ToLower(contract),
+		TransactionIndex: "0x0",
+		GasUsed:          "0x186a0",
+		BlockHash:        fmt.Sprintf("0x%064x", c.head),
+		Data:             "0x",
+		Topics: []string{
+			transferEventTopic,
+			common.BytesToHash(common.HexToAddress(from).Bytes()).Hex(),
+			common.BytesToHash(common.HexToAddress(to).Bytes()).Hex(),
+			common.BigToHash(big.NewInt(location)).Hex(),
+		},
+	})
+}
+
+// sell pays a tile's owner on the original contract, the creator until
+// someone has bought it, and hands the tile to buyer.
+func (c *simulatedChain) sell(hash string, location int64, buyer string, value *big.Int) {
+	owner, ok := c.owners[location]
+	if !ok {
+		owner = c.network.CreatorAddress
+	}
+	c.pay(hash, c.network.PixelMapAddress, owner, value)
+	c.owners[location] = strings.ToLower(buyer)
+}
+
+func (c *simulatedChain) pay(hash, from, to string, value *big.Int) {
+	c.internal = append(c.internal, EtherscanInternalTransaction{
+		BlockNumber: strconv.FormatInt(c.head, 10),
+		TimeStamp:   strconv.FormatInt(c.timestamp(), 10),
+		Hash:        hash,
+		From:        strings.ToLower(from),
+		To:          strings.ToLower(to),
+		Value:       orZero(value).String(),
+		Type:        "call",
+		TraceID:     strconv.Itoa(len(c.internal)),
+		IsError:     "0",
+	})
+}
+
+func (c *simulatedChain) hash() string {
+	return fmt.Sprintf("0x%064x", len(c.txs)+len(c.logs)+len(c.external)+1)
+}
+
+func (c *simulatedChain) timestamp() int64 {
+	return 1500000000 + c.head*15
+}
+
+func (c *simulatedChain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	q := r.URL.Query()
+	switch q.Get("module") + "/" + q.Get("action") {
+	case "proxy/eth_blockNumber":
+		// Report enough confirmations that the last scripted block is inside
+		// the ingestor's safety window.
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"result":  fmt.Sprintf("0x%x", c.head+safetyBlockOffset),
+		})
+
+	case "account/txlist":
+		from, _ := strconv.ParseInt(q.Get("startblock"), 10, 64)
+		to, _ := strconv.ParseInt(q.Get("endblock"), 10, 64)
+		var result []EtherscanTransaction
+		for _, tx := range c.txs {
+			block, _ := strconv.ParseInt(tx.BlockNumber, 10, 64)
+			if strings.EqualFold(tx.To, q.Get("address")) && block >= from && block <= to {
+				result = append(result, tx)
+			}
+		}
+		writeEtherscanResult(w, result, "No transactions found")
+
+	case "account/txlistinternal":
+		from, _ := strconv.ParseInt(q.Get("startblock"), 10, 64)
+		to, _ := strconv.ParseInt(q.Get("endblock"), 10, 64)
+		var result []EtherscanInternalTransaction
+		for _, itx := range c.internal {
+			block, _ := strconv.ParseInt(itx.BlockNumber, 10, 64)
+			involved := strings.EqualFold(itx.From, q.Get("address")) || strings.EqualFold(itx.To, q.Get("address"))
+			if involved && block >= from && block <= to {
+				result = append(result, itx)
+			}
+		}
+		writeEtherscanResult(w, result, "No transactions found")
+
+	case "logs/getLogs":
+		from, _ := strconv.ParseInt(q.Get("fromBlock"), 10, 64)
+		to, _ := strconv.ParseInt(q.Get("toBlock"), 10, 64)
+		var result []EtherscanTransferEvent
+		for _, log := range c.logs {
+			block, _ := strconv.ParseInt(log.BlockNumber[2:], 16, 64)
+			if strings.EqualFold(log.ContractAddress, q.Get("address")) && block >= from && block <= to {
+				result = append(result, log)
+			}
+		}
+		writeEtherscanResult(w, result, "No records found")
+
+	case "proxy/eth_getTransactionByHash":
+		result := map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": nil}
+		for _, tx := range slices.Concat(c.txs, c.external) {
+			if tx.Hash != q.Get("txhash") {
+				continue
+			}
+			block, _ := strconv.ParseInt(tx.BlockNumber, 10, 64)
+			value, _ := new(big.Int).SetString(tx.Value, 10)
+			result["result"] = map[string]string{
+				"blockNumber": fmt.Sprintf("0x%x", block),
+				"hash":        tx.Hash,
+				"from":        tx.From,
+				"to":          tx.To,
+				"value":       "0x" + value.Text(16),
+				"input":       tx.Input,
+			}
+		}
+		json.NewEncoder(w).Encode(result)
+
+	default:
+		http.Error(w, "unsupported call", http.StatusBadRequest)
+	}
+}
+
+func writeEtherscanResult[T any](w http.ResponseWriter, result []T, emptyMessage string) {
+	if len(result) == 0 {
+		json.NewEncoder(w).Encode(EtherscanResponse{Status: "0", Message: emptyMessage, Result: []T{}})
+		return
+	}
+	json.NewEncoder(w).Encode(EtherscanResponse{Status: "1", Message: "OK", Result: result})
+}
+
+func orZero(value *big.Int) *big.Int {
+	if value == nil {
+		return new(big.Int)
+	}
+	return value
+}
+
+// ingestHarness runs the real Ingestor against a simulatedChain and a
+// throwaway Postgres, rendering into a temporary cache directory.
+type ingestHarness struct {
+	t        *testing.T
+	ctx      context.Context
+	chain    *simulatedChain
+	ingestor *Ingestor
+	conn     *sql.DB
+	queries  *db.Queries
+	network  *Network
+}
+
+func newIngestHarness(t *testing.T) *ingestHarness {
+	conn := dbtest.Open(t)
+
+	network := Devnet
+	network.PixelMapAddress = "0x5fbdb2315678afecb367f032d93f642f64180aa3"
+	network.WrapperAddress = "0xe7f1725e7734ce288f8367e1bb143e90bb3f0512"
+	network.TimeCapsuleAddress = "0x9fe46736679d2d9a65f0992f2272de9f3c7fa6e0"
+	network.CreatorAddress = "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266"
+	network.StartBlock = 1
+	network.CacheDir = t.TempDir()
+	network.AssetBaseURL = "https://assets.test"
+	require.NoError(t, network.Validate())
+
+	chain := newSimulatedChain(t, &network)
+
+	logger := zap.NewNop()
+	client := chain.Client(logger)
+
+	queries := db.New(conn)
+	ingestor := newIngestor(logger, queries, client, &network)
+	ingestor.conn = conn
+	return &ingestHarness{
+		t:        t,
+		ctx:      context.Background(),
+		chain:    chain,
+		ingestor: ingestor,
+		conn:     conn,
+		queries:  queries,
+		network:  &network,
+	}
+}
+
+// Sync ingests everything mined so far and runs the renderer to completion.
+func (h *ingestHarness) Sync() {
+	h.t.Helper()
+	require.NoError(h.t, h.ingestor.IngestTransactions(h.ctx))
+	_, err := h.ingestor.drainRenderQueue(h.ctx)
+	require.NoError(h.t, err)
+}
+
+func (h *ingestHarness) Tile(id int32) db.Tile {
+	h.t.Helper()
+	tile, err := h.queries.GetTileById(h.ctx, id)
+	require.NoError(h.t, err)
+	return tile
+}
+
+// Rows counts a tile's entries in one of the history tables.
+func (h *ingestHarness) Rows(table string, id int32) int {
+	h.t.Helper()
+	var count int
+	err := h.conn.QueryRowContext(h.ctx, "SELECT COUNT(*) FROM "+table+" WHERE tile_id = $1", id).Scan(&count)
+	require.NoError(h.t, err)
+	return count
+}
+
+func (h *ingestHarness) TileJSON(id int32) MetadataPixelMapTile {
+	h.t.Helper()
+	contents, err := os.ReadFile(filepath.Join(h.network.CacheDir, "tile", fmt.Sprintf("%d.json", id)))
+	require.NoError(h.t, err)
+
+	var tile MetadataPixelMapTile
+	require.NoError(h.t, json.Unmarshal(contents, &tile))
+	return tile
+}
+
+func (h *ingestHarness) RequireFile(parts ...string) {
+	h.t.Helper()
+	path := filepath.Join(append([]string{h.network.CacheDir}, parts...)...)
+	info, err := os.Stat(path)
+	require.NoError(h.t, err, "expected %s to exist", path)
+	require.NotZero(h.t, info.Size(), "expected %s to be non-empty", path)
+}
+
+const (
+	alice = "0x70997970c51812dc3a010c7d01b50e0d17dc79c8"
+	bob   = "0x3c44cdddb6a900fa2b585dd299e03d12fa4293bc"
+)
+
+var redTile = strings.Repeat("f00", 256)
+
+func ether(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), big.NewInt(1e18))
+}
+
+func TestSimulatedChainServesScriptedHistory(t *testing.T) {
+	network := Devnet
+	network.PixelMapAddress = "0x5fbdb2315678afecb367f032d93f642f64180aa3"
+	network.WrapperAddress = "0xe7f1725e7734ce288f8367e1bb143e90bb3f0512"
+	network.TimeCapsuleAddress = "0x9fe46736679d2d9a65f0992f2272de9f3c7fa6e0"
+	network.StartBlock = 1
+
+	chain := newSimulatedChain(t, &network)
+	chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 9, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 9, Image: redTile, Price: ether(1)},
+		scriptStep{Method: "wrap", From: alice, Location: 9, Value: ether(1)},
+		scriptStep{Method: "transfer", From: alice, To: bob, Location: 9},
+	)
+	client := chain.Client(zap.NewNop())
+	ctx := context.Background()
+
+	latest, err := client.GetLatestBlockNumber()
+	require.NoError(t, err)
+	require.Equal(t, uint64(5+safetyBlockOffset), latest)
+
+	txs, err := client.GetTransactions(ctx, 1, 5)
+	require.NoError(t, err)
+	// Transfer events come back merged in as pseudo safeTransferFrom calls.
+	require.Len(t, txs, 5)
+
+	pixelMapABI, err := pixelmap.PixelMapMetaData.GetAbi()
+	require.NoError(t, err)
+	method, err := pixelMapABI.MethodById(common.FromHex(txs[1].Input))
+	require.NoError(t, err)
+	require.Equal(t, "setTile", method.Name)
+
+	events, err := client.GetTransferEvents(ctx, 1, 5)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	transfer := ConvertTransferEventToTransaction(events[1])
+	require.Equal(t, "5", transfer.BlockNumber)
+	require.True(t, network.IsWrapper(transfer.To))
+
+	txs, err = client.GetTransactions(ctx, 6, 10)
+	require.NoError(t, err)
+	require.Empty(t, txs)
+}
+
+func TestHarnessBuyAndSetTile(t *testing.T) {
+	h := newIngestHarness(t)
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 7, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 7, Image: redTile, URL: "https://example.com"},
+	)
+	h.Sync()
+
+	tile := h.Tile(7)
+	require.True(t, strings.EqualFold(alice, tile.Owner), "owner = %s", tile.Owner)
+	require.Equal(t, redTile, tile.Image)
+	require.Equal(t, "https://example.com", tile.Url)
+	require.False(t, tile.Wrapped)
+
+	purchases, err := h.queries.GetPurchaseHistoryByTileId(h.ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, purchases, 1)
+	require.True(t, strings.EqualFold(h.network.CreatorAddress, purchases[0].SoldBy))
+	require.True(t, strings.EqualFold(alice, purchases[0].PurchasedBy))
+	require.Equal(t, ether(2).String(), purchases[0].Price)
+	require.Equal(t, ether(2).String(), purchases[0].CreatorFee.String, "unclaimed tiles pay the creator")
+	require.Equal(t, "0", purchases[0].SellerPayout.String)
+	require.False(t, purchases[0].PriceMismatch)
+
+	var internal int
+	require.NoError(t, h.conn.QueryRowContext(h.ctx, "SELECT COUNT(*) FROM pixel_map_internal_transaction WHERE hash = $1", purchases[0].Tx).Scan(&internal))
+	require.Equal(t, 1, internal, "the payment is archived with the call")
+
+	history, err := h.queries.GetDataHistoryByTileId(h.ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+
+	h.RequireFile("7", fmt.Sprintf("%d.png", history[0].BlockNumber))
+	h.RequireFile("7", "latest.png")
+	h.RequireFile("tiledata.json")
+
+	require.Equal(t, float64(h.chain.head), testutil.ToFloat64(metrics.LastProcessedBlock))
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.RenderQueueDepth))
+	require.NoError(t, h.ingestor.Health().Ready(h.ctx))
+
+	metadata := h.TileJSON(7)
+	require.True(t, strings.EqualFold(alice, metadata.Owner))
+	require.Len(t, metadata.PurchaseHistory, 1)
+	require.Len(t, metadata.DataHistory, 1)
+}
+
+func TestHarnessDataChanges(t *testing.T) {
+	h := newIngestHarness(t)
+
+	blueCorner := []byte(redTile)
+	copy(blueCorner, "00f00f")
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 7, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 7, Image: redTile, URL: "https://example.com"},
+		scriptStep{Method: "setTile", From: alice, Location: 7, Image: string(blueCorner), URL: "https://example.com"},
+		scriptStep{Method: "setTile", From: alice, Location: 7, Image: string(blueCorner), URL: "https://example.org", Price: ether(3)},
+	)
+	h.Sync()
+
+	history, err := h.queries.GetDataHistoryByTileId(h.ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	latest, image, first := history[0], history[1], history[2]
+
+	require.Equal(t, "multiple", first.ChangeType.String)
+	require.False(t, first.PreviousImage.Valid, "a tile's first update has nothing before it")
+	require.Equal(t, int32(256), first.PixelsChanged.Int32)
+
+	require.Equal(t, "image", image.ChangeType.String)
+	require.Equal(t, redTile, image.PreviousImage.String)
+	require.Equal(t, int32(2), image.PixelsChanged.Int32)
+	require.Equal(t, []int32{0, 0, 1, 0}, []int32{image.DiffMinX.Int32, image.DiffMinY.Int32, image.DiffMaxX.Int32, image.DiffMaxY.Int32})
+
+	require.Equal(t, "multiple", latest.ChangeType.String)
+	require.Equal(t, "https://example.com", latest.PreviousUrl.String)
+	require.Equal(t, "0", latest.PreviousPrice.String)
+	require.Equal(t, ether(3).String(), latest.Price.String)
+	require.Equal(t, int32(0), latest.PixelsChanged.Int32)
+
+	// Updates recorded before change records are filled in by the backfill.
+	_, err = h.conn.ExecContext(h.ctx, "UPDATE data_histories SET change_type = NULL, previous_url = NULL")
+	require.NoError(t, err)
+	compared, err := h.ingestor.CompareDataHistories(h.ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, compared)
+
+	backfilled, err := h.queries.GetDataHistoryByTileId(h.ctx, 7)
+	require.NoError(t, err)
+	require.Equal(t, history, backfilled)
+}
+
+func TestHarnessKeepsWholeTileInputs(t *testing.T) {
+	h := newIngestHarness(t)
+
+	longURL := "https://example.com/" + strings.Repeat("a", 300)
+	longImage := redTile + strings.Repeat("0", 100)
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 7, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 7, Image: longImage, URL: longURL},
+	)
+	h.Sync()
+
+	tile := h.Tile(7)
+	require.Equal(t, longURL[:255], tile.Url)
+	require.Equal(t, longImage[:800], tile.Image)
+
+	inputs, err := h.queries.GetTileUpdateInputsByTileId(h.ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, inputs, 1)
+	require.Equal(t, "setTile", inputs[0].Method)
+	require.Equal(t, longURL, inputs[0].Url)
+	require.Equal(t, longImage, inputs[0].Image)
+	require.True(t, inputs[0].ImageTruncated)
+	require.True(t, inputs[0].UrlTruncated)
+	require.False(t, inputs[0].InvalidEncoding)
+
+	history, err := h.queries.GetDataHistoryByTileId(h.ctx, 7)
+	require.NoError(t, err)
+	require.Equal(t, longURL, history[0].Url, "data_histories holds any URL")
+
+	// The metadata reads the whole arguments.
+	metadata := h.TileJSON(7)
+	require.Equal(t, longURL, metadata.URL)
+	require.Equal(t, longImage, metadata.Image)
+	require.Equal(t, longImage, metadata.DataHistory[0].Image)
+}
+
+func TestHarnessBackfillsTileInputs(t *testing.T) {
+	h := newIngestHarness(t)
+
+	longURL := "https://example.com/" + strings.Repeat("a", 300)
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 7, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 7, Image: redTile, URL: longURL, Price: ether(3)},
+	)
+	h.Sync()
+
+	// As if the update had been ingested before tile_update_inputs existed.
+	_, err := h.conn.ExecContext(h.ctx, "DELETE FROM tile_update_inputs")
+	require.NoError(t, err)
+
+	recorded, err := h.ingestor.BackfillTileUpdateInputs(h.ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, recorded)
+
+	inputs, err := h.queries.GetTileUpdateInputsByTileId(h.ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, inputs, 1)
+	require.Equal(t, "setTile", inputs[0].Method)
+	require.Equal(t, longURL, inputs[0].Url)
+	require.Equal(t, redTile, inputs[0].Image)
+	require.Equal(t, ether(3).String(), inputs[0].Price.String)
+	require.True(t, inputs[0].UrlTruncated)
+	require.False(t, inputs[0].ImageTruncated)
+
+	recorded, err = h.ingestor.BackfillTileUpdateInputs(h.ctx)
+	require.NoError(t, err)
+	require.Zero(t, recorded, "recorded calls are left alone")
+}
+
+func TestHarnessWrapTransferUnwrap(t *testing.T) {
+	h := newIngestHarness(t)
+
+	// Wrapping buys the tile through the wrapper at its listed price, so the
+	// owner lists it for the value they then send.
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 9, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 9, Image: redTile, Price: ether(1)},
+		scriptStep{Method: "wrap", From: alice, Location: 9, Value: ether(1)},
+	)
+	h.Sync()
+
+	tile := h.Tile(9)
+	require.True(t, tile.Wrapped)
+	require.True(t, strings.EqualFold(alice, tile.Owner), "owner = %s", tile.Owner)
+	require.Equal(t, 1, h.Rows("wrapping_histories", 9))
+
+	h.chain.Replay(scriptStep{Method: "transfer", From: alice, To: bob, Location: 9})
+	h.Sync()
+
+	tile = h.Tile(9)
+	require.True(t, strings.EqualFold(bob, tile.Owner), "owner = %s", tile.Owner)
+	require.Equal(t, 2, h.Rows("transfer_histories", 9))
+
+	h.chain.Replay(scriptStep{Method: "unwrap", From: bob, Location: 9, Price: ether(3)})
+	h.Sync()
+
+	tile = h.Tile(9)
+	require.False(t, tile.Wrapped)
+	require.Equal(t, ether(3).String(), tile.Price, "unwrap lists the tile at the sale price, in wei")
+	require.Equal(t, 2, h.Rows("wrapping_histories", 9))
+
+	transfers, err := h.queries.GetTransferHistoryByTileIds(h.ctx, []int32{9})
+	require.NoError(t, err)
+	var types []string
+	for _, transfer := range transfers {
+		require.True(t, transfer.IsWrapperContract)
+		types = append(types, transfer.TransferType.String)
+	}
+	require.Equal(t, []string{transferUnwrap, transferGift, transferWrap}, types)
+}
+
+func TestHarnessWrapperApprovals(t *testing.T) {
+	h := newIngestHarness(t)
+	const marketplace = "0x90f79bf6eb2c4f870365e785982e1f101e93b906"
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 9, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 9, Image: redTile, Price: ether(1)},
+		scriptStep{Method: "wrap", From: alice, Location: 9, Value: ether(1)},
+		scriptStep{Method: "approve", From: alice, To: bob, Location: 9},
+		scriptStep{Method: "setApprovalForAll", From: alice, To: marketplace, Approved: true},
+	)
+	h.Sync()
+
+	operators, err := h.queries.GetTileOperatorsByTileIds(h.ctx, []int32{9})
+	require.NoError(t, err)
+	require.Len(t, operators, 2)
+	kinds := map[string]string{}
+	for _, o := range operators {
+		kinds[o.Operator] = o.Kind
+	}
+	require.Equal(t, map[string]string{bob: "token", marketplace: "operator"}, kinds)
+
+	// The transfer clears the token approval, and the new owner has not
+	// approved the marketplace.
+	h.chain.Replay(scriptStep{Method: "transfer", From: alice, To: bob, Location: 9})
+	h.Sync()
+
+	operators, err = h.queries.GetTileOperatorsByTileIds(h.ctx, []int32{9})
+	require.NoError(t, err)
+	require.Empty(t, operators)
+
+	owned, err := h.queries.GetOperatorsByOwner(h.ctx, alice)
+	require.NoError(t, err)
+	require.Len(t, owned, 1, "the stale approval outlives the tile")
+
+	h.chain.Replay(scriptStep{Method: "setApprovalForAll", From: alice, To: marketplace, Approved: false})
+	h.Sync()
+
+	owned, err = h.queries.GetOperatorsByOwner(h.ctx, alice)
+	require.NoError(t, err)
+	require.Empty(t, owned)
+}
+
+func TestHarnessWrapperSaleProceeds(t *testing.T) {
+	h := newIngestHarness(t)
+	const carol = "0x90f79bf6eb2c4f870365e785982e1f101e93b906"
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 9, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 9, Image: redTile, Price: ether(1)},
+		scriptStep{Method: "wrap", From: alice, Location: 9, Value: ether(1)},
+		scriptStep{Method: "unwrap", From: alice, Location: 9, Price: ether(3)},
+		scriptStep{Method: "buyTile", From: carol, Location: 9, Value: ether(3)},
+	)
+	h.Sync()
+
+	pending, err := h.queries.GetWrapperPendingSale(h.ctx, 9)
+	require.NoError(t, err)
+	require.Equal(t, alice, pending.Seller)
+	require.Equal(t, carol, pending.Buyer.String)
+	require.Equal(t, ether(3).String(), pending.Withdrawable, "the wrapper holds the proceeds for the unwrapper")
+
+	sales, err := h.queries.GetPurchasesByBuyer(h.ctx, carol)
+	require.NoError(t, err)
+	require.Len(t, sales, 1)
+	require.Equal(t, "wrapper", sales[0].Market)
+	require.Equal(t, alice, sales[0].SoldBy)
+	require.Equal(t, ether(3).String(), sales[0].Price)
+	require.Equal(t, ether(3).String(), sales[0].SellerPayout.String, "paid to the wrapper, which holds it for the seller")
+	require.Equal(t, "0", sales[0].CreatorFee.String)
+
+	h.chain.Replay(scriptStep{Method: "withdrawETH", From: alice, Location: 9})
+	h.Sync()
+
+	pending, err = h.queries.GetWrapperPendingSale(h.ctx, 9)
+	require.NoError(t, err)
+	require.Equal(t, "0", pending.Withdrawable)
+	require.True(t, pending.WithdrawnTx.Valid)
+	require.Equal(t, 3, h.Rows("wrapper_proceeds_histories", 9), "listed, sold and withdrawn")
+}
+
+func TestHarnessOwnerPortfolios(t *testing.T) {
+	h := newIngestHarness(t)
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 9, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 9, Image: redTile, Price: ether(1)},
+		scriptStep{Method: "wrap", From: alice, Location: 9, Value: ether(1)},
+		scriptStep{Method: "transfer", From: alice, To: bob, Location: 9},
+	)
+	h.Sync()
+
+	// Transfers are decoded from logs as checksummed addresses; everything
+	// is stored lowercase.
+	var mixedCase int
+	require.NoError(t, h.conn.QueryRowContext(h.ctx, `SELECT COUNT(*) FROM transfer_histories
+		WHERE transferred_from <> LOWER(transferred_from) OR transferred_to <> LOWER(transferred_to)`).Scan(&mixedCase))
+	require.Zero(t, mixedCase)
+	require.Equal(t, bob, h.Tile(9).Owner)
+
+	var bobs portfolio.Portfolio
+	contents, err := os.ReadFile(filepath.Join(h.network.CacheDir, "owner", bob+".json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(contents, &bobs))
+	require.Len(t, bobs.Tiles, 1)
+	require.True(t, bobs.Tiles[0].Wrapped)
+	require.Len(t, bobs.Acquisitions, 1)
+	require.Equal(t, portfolio.AcquisitionTransfer, bobs.Acquisitions[0].Type)
+	require.Equal(t, alice, bobs.Acquisitions[0].From)
+
+	// Alice no longer owns anything but keeps her history.
+	var alices portfolio.Portfolio
+	contents, err = os.ReadFile(filepath.Join(h.network.CacheDir, "owner", alice+".json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(contents, &alices))
+	require.Empty(t, alices.Tiles)
+	require.NotEmpty(t, alices.Acquisitions)
+	require.Equal(t, portfolio.AcquisitionPurchase, alices.Acquisitions[len(alices.Acquisitions)-1].Type)
+}
+
+func TestHarnessTimeCapsules(t *testing.T) {
+	h := newIngestHarness(t)
+
+	h.chain.Replay(
+		scriptStep{Method: "mintCapsules", From: alice, Location: 3},
+		scriptStep{Method: "transferCapsule", From: alice, To: bob, Location: 2},
+		scriptStep{Method: "buyTile", From: bob, Location: 9, Value: ether(2)},
+	)
+	h.Sync()
+
+	count := func(query string) int {
+		var n int
+		require.NoError(t, h.conn.QueryRowContext(h.ctx, query).Scan(&n))
+		return n
+	}
+	require.Equal(t, 1, count("SELECT COUNT(*) FROM time_capsule_mints WHERE amount = 3"))
+	require.Equal(t, 4, count("SELECT COUNT(*) FROM time_capsule_transfers"))
+	require.Equal(t, 2, count("SELECT COUNT(*) FROM pixel_map_transaction WHERE log_index = -1 AND \"to\" = '"+h.network.TimeCapsuleAddress+"'"))
+	require.Equal(t, 4, count("SELECT COUNT(*) FROM pixel_map_transaction WHERE log_index >= 0 AND \"to\" = '"+h.network.TimeCapsuleAddress+"'"),
+		"the events are archived next to the calls that emitted them")
+	require.Zero(t, count("SELECT COUNT(*) FROM transfer_histories"), "capsules are not tiles")
+
+	tokens, err := h.queries.GetTimeCapsulesByOwner(h.ctx, alice)
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	require.Equal(t, []int32{1, 3}, []int32{tokens[0].TokenID, tokens[1].TokenID})
+
+	var bobs portfolio.Portfolio
+	contents, err := os.ReadFile(filepath.Join(h.network.CacheDir, "owner", bob+".json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(contents, &bobs))
+	require.Len(t, bobs.Tiles, 1)
+	require.Len(t, bobs.TimeCapsules, 1)
+	require.Equal(t, int32(2), bobs.TimeCapsules[0].TokenID)
+
+	// Alice holds no tile, only capsules, and still gets a profile.
+	contents, err = os.ReadFile(filepath.Join(h.network.CacheDir, "owner", alice+".json"))
+	require.NoError(t, err)
+	var alices portfolio.Portfolio
+	require.NoError(t, json.Unmarshal(contents, &alices))
+	require.Empty(t, alices.Tiles)
+	require.Len(t, alices.TimeCapsules, 2)
+}
+
+func TestHarnessStatsJSON(t *testing.T) {
+	h := newIngestHarness(t)
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 3, Value: ether(2)},
+		scriptStep{Method: "buyTile", From: bob, Location: 4, Value: ether(2)},
+		scriptStep{Method: "setTile", From: bob, Location: 4, Image: redTile, Price: ether(1)},
+	)
+	h.Sync()
+
+	var exported stats.Stats
+	contents, err := os.ReadFile(filepath.Join(h.network.CacheDir, "stats.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(contents, &exported))
+
+	all := exported.Markets[stats.MarketAll]
+	require.NotNil(t, all)
+	require.Equal(t, 2, all.Totals.Sales)
+	require.Equal(t, "4", all.Totals.Volume)
+	require.Equal(t, 2, all.Totals.UniqueBuyers)
+	require.NotEmpty(t, all.Daily)
+	require.Equal(t, 2, exported.Markets[stats.MarketOriginal].Totals.Sales)
+	require.Zero(t, exported.Markets[stats.MarketWrapper].Totals.Sales)
+	require.Equal(t, "1", exported.Listings.FloorPrice, "the setTile listing undercuts the unsold tiles")
+}
+
+func TestHarnessResumesFromLastProcessedBlock(t *testing.T) {
+	h := newIngestHarness(t)
+
+	h.chain.Replay(scriptStep{Method: "buyTile", From: alice, Location: 1, Value: ether(2)})
+	h.Sync()
+	h.chain.Replay(scriptStep{Method: "buyTile", From: bob, Location: 2, Value: ether(2)})
+	h.Sync()
+	h.Sync()
+
+	for id, owner := range map[int32]string{1: alice, 2: bob} {
+		purchases, err := h.queries.GetPurchaseHistoryByTileId(h.ctx, id)
+		require.NoError(t, err)
+		require.Len(t, purchases, 1, "tile %d", id)
+		require.True(t, strings.EqualFold(owner, h.Tile(id).Owner))
+	}
+}
+
+func TestHarnessChangeSetIsAtomic(t *testing.T) {
+	h := newIngestHarness(t)
+	h.chain.Replay(scriptStep{Method: "buyTile", From: alice, Location: 4, Value: ether(2)})
+	h.Sync()
+	events := h.ingestor.pubSub.Subscribe("tileOwnerChanged")
+
+	tx := &EtherscanTransaction{Hash: "0xfeed"}
+	changes := (&ChangeSet{}).Add(
+		UpdateTileOwner{ID: 4, Owner: bob},
+		Notify{Type: "tileOwnerChanged", Payload: 4},
+		InsertTransfer{TileID: 99999, Tx: tx.Hash, TimeStamp: time.Unix(1, 0)},
+	)
+	err := changes.apply(h.ctx, h.ingestor, tx, db.InsertPixelMapTransactionParams{Hash: tx.Hash, TimeStamp: time.Unix(1, 0), LogIndex: -1})
+	require.Error(t, err, "no tile 99999")
+
+	require.True(t, strings.EqualFold(alice, h.Tile(4).Owner), "the owner change is rolled back")
+	var archived int
+	require.NoError(t, h.conn.QueryRowContext(h.ctx, "SELECT COUNT(*) FROM pixel_map_transaction WHERE hash = $1", tx.Hash).Scan(&archived))
+	require.Zero(t, archived)
+	select {
+	case event := <-events:
+		t.Fatalf("published %s for a rolled back change", event.Type)
+	default:
+	}
+}
+
+func TestHarnessArchivesMalformedCalls(t *testing.T) {
+	h := newIngestHarness(t)
+	selector := "0x" + common.Bytes2Hex(setTileSelector(t))
+	malformed := testutil.ToFloat64(metrics.MalformedCalls.WithLabelValues(contractPixelMap, selector))
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 3, Value: ether(2)},
+		scriptStep{Method: "truncatedSetTile", From: alice, Location: 3, Image: redTile},
+		scriptStep{Method: "setTile", From: alice, Location: 3, Image: redTile, URL: "https://example.com"},
+	)
+	h.Sync()
+
+	require.Equal(t, "https://example.com", h.Tile(3).Url, "ingestion goes on past the malformed call")
+	require.Equal(t, 1, h.Rows("data_histories", 3))
+	require.Equal(t, malformed+1, testutil.ToFloat64(metrics.MalformedCalls.WithLabelValues(contractPixelMap, selector)))
+	var archived int
+	require.NoError(t, h.conn.QueryRowContext(h.ctx, "SELECT COUNT(*) FROM pixel_map_transaction WHERE left(input, 10) = $1", selector).Scan(&archived))
+	require.Equal(t, 2, archived, "the malformed call is archived with the good one")
+}
+
+func setTileSelector(t *testing.T) []byte {
+	t.Helper()
+	pixelMapABI, err := pixelmap.PixelMapMetaData.GetAbi()
+	require.NoError(t, err)
+	return pixelMapABI.Methods["setTile"].ID
+}
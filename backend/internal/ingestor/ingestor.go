@@ -8,21 +8,21 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/lib/pq"
 	ens "github.com/wealdtech/go-ens/v3"
 	"go.uber.org/zap"
-	pixelmap "pixelmap.io/backend/internal/contracts/pixelmap"
-	pixelmapWrapper "pixelmap.io/backend/internal/contracts/pixelmapWrapper"
 	db "pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/metrics"
+	"pixelmap.io/backend/internal/ratebudget"
+	"pixelmap.io/backend/internal/stats"
+	"pixelmap.io/backend/internal/traits"
 	utils "pixelmap.io/backend/internal/utils"
 )
 
@@ -78,26 +78,55 @@ func (ps *PubSub) Publish(event Event) {
 type Ingestor struct {
 	logger          *zap.Logger
 	queries         *db.Queries
+	conn            *sql.DB
 	etherscanClient *EtherscanClient
 	pubSub          *PubSub
 	renderSignal    chan struct{}
-	isRendering     atomic.Bool
+	mapSignal       chan struct{}
+	syncSignal      chan struct{}
 	maxRetries      int
 	baseDelay       time.Duration
 	s3Syncer        *S3Syncer
 	ethClient       *ethclient.Client
+	network         *Network
+	handlers        *Registry
+	health          *metrics.Health
+
+	pollInterval       time.Duration
+	ensRefreshInterval time.Duration
+	restartDelay       time.Duration
+	renderWorkers      int
+	renderPollInterval time.Duration
+	mapDebounce        time.Duration
+	mapMaxDelay        time.Duration
+
+	lifecycle lifecycle
 }
 
-func NewIngestor(logger *zap.Logger, sqlDB *sql.DB, apiKey string) *Ingestor {
+func NewIngestor(logger *zap.Logger, sqlDB *sql.DB, apiKey string, network *Network) *Ingestor {
 	pubSub := NewPubSub()
 	var s3Syncer *S3Syncer
 
+	if path := os.Getenv("TRAIT_RULES"); path != "" {
+		rules, err := traits.Load(path)
+		if err != nil {
+			logger.Error("Failed to load trait rules, using the defaults", zap.Error(err))
+		} else {
+			UseTraitRules(rules)
+		}
+	}
+
 	// Check if SYNC_TO_AWS environment variable is set
 	if os.Getenv("SYNC_TO_AWS") == "true" {
-		var err error
-		s3Syncer, err = NewS3Syncer(logger, "cache")
-		if err != nil {
-			logger.Error("Failed to create S3Syncer", zap.Error(err))
+		if network.S3Bucket == "" {
+			logger.Warn("SYNC_TO_AWS is set but the network has no S3 bucket, skipping sync",
+				zap.String("network", network.Name))
+		} else {
+			var err error
+			s3Syncer, err = NewS3Syncer(logger, network.CacheDir, network.S3Bucket)
+			if err != nil {
+				logger.Error("Failed to create S3Syncer", zap.Error(err))
+			}
 		}
 	}
 
@@ -110,43 +139,86 @@ func NewIngestor(logger *zap.Logger, sqlDB *sql.DB, apiKey string) *Ingestor {
 		logger.Error("Failed to create ENS resolver", zap.Error(err))
 	}
 
-	ingestor := &Ingestor{
+	ingestor := newIngestor(logger, db.New(sqlDB), NewEtherscanClientForNetwork(apiKey, network, logger), network)
+	ingestor.conn = sqlDB
+	ingestor.pubSub = pubSub
+	ingestor.s3Syncer = s3Syncer
+	ingestor.ethClient = ethClient
+	if stallAfter, err := time.ParseDuration(os.Getenv("INGEST_STALL_AFTER")); err == nil {
+		ingestor.health = metrics.NewHealth(stallAfter)
+	}
+	if workers, err := strconv.Atoi(os.Getenv("RENDER_WORKERS")); err == nil && workers > 0 {
+		ingestor.renderWorkers = workers
+	}
+
+	return ingestor
+}
+
+// newIngestor wires an Ingestor without any of the side effects of
+// NewIngestor (no S3, no Ethereum client), which lets tests drive ingestion
+// and rendering step by step. Nothing runs in the background until Run.
+func newIngestor(logger *zap.Logger, queries *db.Queries, etherscanClient *EtherscanClient, network *Network) *Ingestor {
+	i := &Ingestor{
 		logger:          logger,
-		queries:         db.New(sqlDB),
-		etherscanClient: NewEtherscanClient(apiKey, 1, logger), // chainId 1 for Ethereum mainnet
-		pubSub:          pubSub,
+		queries:         queries,
+		etherscanClient: etherscanClient,
+		pubSub:          NewPubSub(),
 		renderSignal:    make(chan struct{}, 1),
+		mapSignal:       make(chan struct{}, 1),
+		syncSignal:      make(chan struct{}, 1),
 		maxRetries:      5,
 		baseDelay:       time.Second,
-		s3Syncer:        s3Syncer,
-		ethClient:       ethClient,
+		network:         network,
+		health:          metrics.NewHealth(metrics.DefaultStallAfter),
+
+		pollInterval:       30 * time.Second,
+		ensRefreshInterval: 6 * time.Hour,
+		restartDelay:       5 * time.Second,
+		renderWorkers:      defaultRenderWorkers,
+		renderPollInterval: 5 * time.Second,
+		mapDebounce:        5 * time.Second,
+		mapMaxDelay:        30 * time.Second,
 	}
 
-	// Start the continuous rendering process
-	go ingestor.continuousRenderProcess()
+	handlers, err := i.newRegistry()
+	if err != nil {
+		logger.Fatal("Invalid contract handlers", zap.Error(err))
+	}
+	i.handlers = handlers
+	return i
+}
 
-	// Trigger an initial render
-	ingestor.signalNewData()
+// Health reports ingestion progress for the admin server's health checks.
+func (i *Ingestor) Health() *metrics.Health {
+	return i.health
+}
 
-	return ingestor
+// EtherscanUsage reports per-key counters for the explorer API budget.
+func (i *Ingestor) EtherscanUsage() []ratebudget.Usage {
+	return i.etherscanClient.Usage()
 }
 
 func (i *Ingestor) StartContinuousIngestion(ctx context.Context) error {
 	for {
 		err := i.IngestTransactions(ctx)
 		if err != nil {
+			metrics.IngestCycles.WithLabelValues("error").Inc()
 			i.logger.Error("Error during transaction ingestion", zap.Error(err))
 			// Optionally, you might want to return the error here if you want to stop the process on errors
 			// return err
+		} else {
+			metrics.IngestCycles.WithLabelValues("ok").Inc()
+			i.health.Progress(0)
+			i.updateRenderQueueMetrics(ctx)
 		}
 
-		i.logger.Info("Finished ingestion cycle, waiting for 30 seconds before next check")
+		i.logger.Info("Finished ingestion cycle, waiting before next check", zap.Duration("interval", i.pollInterval))
 		// Start up the render process
 		i.signalNewData()
 
 		select {
-		case <-time.After(30 * time.Second):
-			// Continue to the next iteration after 30 seconds
+		case <-time.After(i.pollInterval):
+			// Continue to the next iteration
 		case <-ctx.Done():
 			// Exit if the context is cancelled
 			return ctx.Err()
@@ -170,13 +242,16 @@ func (i *Ingestor) IngestTransactions(ctx context.Context) error {
 		zap.Int64("endBlock", endBlock))
 
 	for currentBlock := startBlock; currentBlock <= endBlock; currentBlock += blockRangeSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if err := i.processBlockRange(ctx, currentBlock, endBlock); err != nil {
 			return err
 		}
 	}
 
 	i.logger.Info("Finished ingesting transactions", zap.Int64("endBlock", endBlock))
-	return nil
+	return i.refreshStats(ctx)
 }
 
 func (i *Ingestor) getStartBlock(ctx context.Context) (int64, error) {
@@ -191,13 +266,13 @@ func (i *Ingestor) getStartBlock(ctx context.Context) (int64, error) {
 		if err := i.initializeTiles(ctx); err != nil {
 			return 0, fmt.Errorf("failed to initialize tiles: %w", err)
 		}
-		return startBlockNumber, nil
+		return i.network.StartBlock, nil
 	}
 
-	if lastProcessedBlock > startBlockNumber {
+	if lastProcessedBlock > i.network.StartBlock {
 		return lastProcessedBlock + 1, nil
 	}
-	return startBlockNumber, nil
+	return i.network.StartBlock, nil
 }
 
 func (i *Ingestor) initializeTiles(ctx context.Context) error {
@@ -206,10 +281,10 @@ func (i *Ingestor) initializeTiles(ctx context.Context) error {
 		i.logger.Debug("Initializing tile", zap.Int("tileID", tileID))
 		tile := db.InsertTileParams{
 			ID:      int32(tileID),
-			Price:   "2.00",
+			Price:   unclaimedPriceWei,
 			Url:     "",
 			Image:   "",
-			Owner:   "0x4f4b7e7edf5ec41235624ce207a6ef352aca7050", // Creator of Pixelmap
+			Owner:   i.network.CreatorAddress, // Creator of Pixelmap
 			Wrapped: false,
 		}
 
@@ -227,6 +302,7 @@ func (i *Ingestor) getEndBlock() (int64, error) {
 		i.logger.Error("Failed to get latest block number", zap.Error(err))
 		return 0, fmt.Errorf("failed to get latest block number: %w", err)
 	}
+	metrics.SetChainHead(int64(latestBlock))
 	return int64(latestBlock) - safetyBlockOffset, nil
 }
 
@@ -241,27 +317,43 @@ func (i *Ingestor) processBlockRange(ctx context.Context, currentBlock, endBlock
 		return err
 	}
 
+	// Once fetched, a window is applied in full even if shutdown starts, so
+	// the last processed block never points into a partly applied window.
+	skippedCount, err := i.applyTransactions(context.WithoutCancel(ctx), transactions, blockEnd)
+	if err != nil {
+		return err
+	}
+
+	i.logger.Info("Processed blocks",
+		zap.Int64("from", currentBlock),
+		zap.Int64("to", blockEnd),
+		zap.Int("skippedTransactions", skippedCount))
+	return nil
+}
+
+// applyTransactions processes a window's transactions in chain order and then
+// records blockEnd as the last processed block. It returns how many
+// transactions were skipped as failed calls.
+func (i *Ingestor) applyTransactions(ctx context.Context, transactions []EtherscanTransaction, blockEnd int64) (int, error) {
+	SortTransactions(transactions)
+
 	skippedCount := 0
 	for _, tx := range transactions {
 		if err := i.processTransaction(ctx, &tx); err != nil {
 			if strings.Contains(err.Error(), "bad jump destination") {
+				metrics.SkippedTransactions.WithLabelValues("bad_jump").Inc()
 				skippedCount++
 				continue
 			}
 			i.logger.Error("Failed to process transaction", zap.Error(err), zap.String("hash", tx.Hash))
-			return fmt.Errorf("failed to process transaction %s: %w", tx.Hash, err)
+			return skippedCount, fmt.Errorf("failed to process transaction %s: %w", tx.Hash, err)
 		}
 	}
 
 	if err := i.updateLastProcessedBlock(ctx, blockEnd); err != nil {
-		return err
+		return skippedCount, err
 	}
-
-	i.logger.Info("Processed blocks",
-		zap.Int64("from", currentBlock),
-		zap.Int64("to", blockEnd),
-		zap.Int("skippedTransactions", skippedCount))
-	return nil
+	return skippedCount, nil
 }
 
 func (i *Ingestor) processTransaction(ctx context.Context, tx *EtherscanTransaction) error {
@@ -269,9 +361,15 @@ func (i *Ingestor) processTransaction(ctx context.Context, tx *EtherscanTransact
 	if tx.IsError == "1" {
 		i.logger.Debug("Skipping bad transaction",
 			zap.String("hash", tx.Hash))
+		metrics.SkippedTransactions.WithLabelValues("reverted").Inc()
 		return nil
 	}
 
+	// Addresses are stored lowercase in every table.
+	tx.From = utils.NormalizeAddress(tx.From)
+	tx.To = utils.NormalizeAddress(tx.To)
+	tx.ContractAddress = utils.NormalizeAddress(tx.ContractAddress)
+
 	// Convert types and insert into database
 	blockNumber, _ := new(big.Int).SetString(tx.BlockNumber, 10)
 	timeStamp, _ := new(big.Int).SetString(tx.TimeStamp, 10)
@@ -284,10 +382,18 @@ func (i *Ingestor) processTransaction(ctx context.Context, tx *EtherscanTransact
 	confirmations, _ := new(big.Int).SetString(tx.Confirmations, 10)
 
 	transactionIndex, _ := strconv.Atoi(tx.TransactionIndex)
+	// The archive keeps the index getLogs reports in hex, and the log index
+	// of events, so each event has a row of its own next to its call.
+	archivedIndex, _ := strconv.ParseInt(tx.TransactionIndex, 0, 32)
+	logIndex := int64(-1)
+	if tx.LogIndex != "" {
+		logIndex, _ = strconv.ParseInt(tx.LogIndex, 10, 32)
+	}
 	// Make sure nonce is valid
 	if tx.Nonce == "" {
 		i.logger.Warn("Skipping transaction with invalid nonce", zap.String("hash", tx.Hash))
 		i.logger.Info("Transaction", zap.Any("transaction", tx))
+		metrics.SkippedTransactions.WithLabelValues("invalid_nonce").Inc()
 		return nil
 	}
 	transaction := db.InsertPixelMapTransactionParams{
@@ -296,7 +402,7 @@ func (i *Ingestor) processTransaction(ctx context.Context, tx *EtherscanTransact
 		Hash:              tx.Hash,
 		Nonce:             nonce.Int64(),
 		BlockHash:         tx.BlockHash,
-		TransactionIndex:  int32(transactionIndex),
+		TransactionIndex:  int32(archivedIndex),
 		From:              tx.From,
 		To:                tx.To,
 		Value:             value.String(),
@@ -309,255 +415,73 @@ func (i *Ingestor) processTransaction(ctx context.Context, tx *EtherscanTransact
 		CumulativeGasUsed: cumulativeGasUsed.Int64(),
 		GasUsed:           gasUsed.Int64(),
 		Confirmations:     confirmations.Int64(),
+		LogIndex:          int32(logIndex),
 	}
 
 	// fmt.Printf("transaction: %+v\n", transaction)
 
-	// Decode the input data
-	// If tx involves contract at 0x015A06a433353f8db634dF4eDdF0C109882A15AB, use non wrapper ABI
-	var abi *abi.ABI
-	if tx.To == "0x015a06a433353f8db634df4eddf0c109882a15ab" {
-		abi, _ = pixelmap.PixelMapMetaData.GetAbi()
-
-	} else if tx.To == "0x050dc61dfb867e0fe3cf2948362b6c0f3faf790b" {
-		abi, _ = pixelmapWrapper.PixelMapWrapperMetaData.GetAbi()
-	} else {
-		// It's a transfer
-		fmt.Println("Unknown contract address", tx.To)
-		fmt.Printf("transaction: %+v\n", tx)
+	contract := i.handlers.Contract(tx.To)
+	if contract == "" {
+		i.logger.Warn("Unknown contract address",
+			zap.String("to", tx.To),
+			zap.String("hash", tx.Hash))
+		metrics.SkippedTransactions.WithLabelValues("unknown_contract").Inc()
 		return nil
 	}
 
-	// Check if the input data is long enough to contain a method ID
+	// Check if the input data is long enough to contain a method ID. Such
+	// calls, like ETH sent to the wrapper's fallback, are archived so a
+	// rebuild sees them too.
 	if len(tx.Input) < 10 {
 		i.logger.Warn("Transaction input too short to contain method ID",
 			zap.String("hash", tx.Hash),
 			zap.String("input", tx.Input))
-		return nil
+		return (&ChangeSet{}).apply(ctx, i, tx, transaction)
 	}
-
-	// The first 4 bytes of the input data represent the method ID
-	methodID := tx.Input[:10]
-	if string(methodID) == constructorMethodID {
-		fmt.Println("Constructor called")
+	if tx.Input[:10] == constructorMethodID {
 		return nil
 	}
-	method, err := abi.MethodById(common.FromHex(methodID))
+
+	method, args, handler, err := i.handlers.Lookup(tx.To, tx.Input)
 	if err != nil {
-		return fmt.Errorf("failed to get method: %w", err)
-	}
-	// Decode the parameters
-	args, err := method.Inputs.Unpack(common.FromHex(tx.Input)[4:])
+		// The call succeeded on chain, so there is nothing to apply from
+		// input the ABI can't decode; archive it and move on.
+		i.logger.Warn("Malformed call",
+			zap.String("contract", contract),
+			zap.String("selector", tx.Input[:10]),
+			zap.String("hash", tx.Hash),
+			zap.Error(err))
+		metrics.MalformedCalls.WithLabelValues(contract, tx.Input[:10]).Inc()
+		return (&ChangeSet{}).apply(ctx, i, tx, transaction)
+	}
+	if handler == nil {
+		// Archive the call so it can be replayed once it is handled.
+		name := ""
+		if method != nil {
+			name = method.Name
+		}
+		i.logger.Warn("No handler for call",
+			zap.String("contract", contract),
+			zap.String("selector", tx.Input[:10]),
+			zap.String("method", name),
+			zap.String("hash", tx.Hash))
+		metrics.UnhandledCalls.WithLabelValues(contract, tx.Input[:10]).Inc()
+		return (&ChangeSet{}).apply(ctx, i, tx, transaction)
+	}
+
+	changes, err := handler(ctx, &Call{
+		Tx:               tx,
+		Contract:         contract,
+		Method:           method,
+		Args:             args,
+		TimeStamp:        transaction.TimeStamp,
+		BlockNumber:      transaction.BlockNumber,
+		TransactionIndex: int32(transactionIndex),
+	})
 	if err != nil {
-		return fmt.Errorf("failed to unpack inputs: %w", err)
-	}
-
-	switch method.Name {
-	case "buyTile":
-		if len(args) > 0 {
-			location, ok := args[0].(*big.Int)
-			if ok {
-				i.logger.Debug("buyTile called",
-					zap.String("location", location.String()),
-					zap.String("tx", tx.Hash),
-					zap.String("from", tx.From))
-
-				// Fetch the current tile data
-				tile, err := i.queries.GetTileById(ctx, int32(location.Int64()))
-				if err != nil {
-					return fmt.Errorf("failed to get tile data: %w", err)
-				}
-
-				// Insert purchase history
-				purchaseHistory := db.InsertPurchaseHistoryParams{
-					TileID:      int32(location.Int64()),
-					SoldBy:      tile.Owner,
-					PurchasedBy: tx.From,
-					Price:       "0",
-					Tx:          tx.Hash,
-					TimeStamp:   time.Unix(timeStamp.Int64(), 0),
-					BlockNumber: blockNumber.Int64(),
-					LogIndex:    int32(transactionIndex),
-				}
-				if tile.Price != "" {
-					purchaseHistory.Price = tile.Price
-				}
-
-				_, err = i.queries.InsertPurchaseHistory(ctx, purchaseHistory)
-				if err != nil {
-					// Check if it's a duplicate key error
-					if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-						// This is a duplicate entry, log it and continue
-						i.logger.Warn("Duplicate purchase history entry",
-							zap.String("tx", tx.Hash),
-							zap.Int32("tileID", purchaseHistory.TileID),
-							zap.Int32("logIndex", purchaseHistory.LogIndex))
-					} else {
-						// If it's not a duplicate key error, return the error
-						return fmt.Errorf("failed to insert purchase history: %w", err)
-					}
-				}
-
-				// Update tile owner
-				if tx.From == "" {
-					i.logger.Warn("Transaction has no Owner address?",
-						zap.String("tx", tx.Hash))
-					os.Exit(1)
-				}
-				err = i.queries.UpdateTileOwner(ctx, db.UpdateTileOwnerParams{
-					ID:    int32(location.Int64()),
-					Owner: tx.From,
-				})
-				if err != nil {
-					return fmt.Errorf("failed to update tile owner: %w", err)
-				}
-
-				i.logger.Info("Tile purchased",
-					zap.String("location", location.String()),
-					zap.String("newOwner", tx.From),
-					zap.String("transaction", tx.Hash))
-			}
-		}
-
-	case "setTile":
-		if len(args) >= 4 {
-			location, _ := args[0].(*big.Int)
-			image, _ := args[1].(string)
-			url, _ := args[2].(string)
-			priceWei, _ := args[3].(*big.Int)
-
-			if err := i.processTileUpdate(ctx, location, image, url, priceWei, tx, timeStamp.Int64(), blockNumber.Int64(), int32(transactionIndex)); err != nil {
-				return err
-			}
-		}
-
-	case "setTileData":
-		if len(args) >= 3 {
-			location, _ := args[0].(*big.Int)
-			image, _ := args[1].(string)
-			url, _ := args[2].(string)
-
-			// For setTileData, we don't change the price, so we pass nil for priceWei
-			if err := i.processTileUpdate(ctx, location, image, url, nil, tx, timeStamp.Int64(), blockNumber.Int64(), int32(transactionIndex)); err != nil {
-				return err
-			}
-		}
-
-	case "getTile":
-		if len(args) >= 1 {
-			location, _ := args[0].(*big.Int)
-			i.logger.Debug("getTile called",
-				zap.String("location", location.String()),
-				zap.String("tx", tx.Hash),
-				zap.String("from", tx.From))
-		}
-	case "setBaseTokenURI":
-		i.logger.Info("setBaseTokenURI called",
-			zap.String("tx", tx.Hash),
-			zap.String("from", tx.From))
-	case "setTokenExtension":
-		i.logger.Info("setTokenExtension called",
-			zap.String("tx", tx.Hash),
-			zap.String("from", tx.From))
-	case "setApprovalForAll":
-		i.logger.Info("setApprovalForAll called",
-			zap.String("tx", tx.Hash),
-			zap.String("from", tx.From))
-	case "wrap":
-		i.logger.Info("wrap called",
-			zap.String("tx", tx.Hash),
-			zap.String("from", tx.From))
-
-		location, _ := args[0].(*big.Int)
-		i.logger.Info("wrap called",
-			zap.String("location", location.String()),
-			zap.String("wrapped", "true"),
-			zap.String("tx", tx.Hash),
-			zap.String("from", tx.From))
-		wrappingHistory := db.InsertWrappingHistoryParams{
-			TileID:      int32(location.Int64()),
-			Wrapped:     true,
-			Tx:          tx.Hash,
-			TimeStamp:   time.Unix(timeStamp.Int64(), 0),
-			BlockNumber: blockNumber.Int64(),
-			UpdatedBy:   tx.From,
-			LogIndex:    int32(transactionIndex),
-		}
-		_, err := i.queries.InsertWrappingHistory(ctx, wrappingHistory)
-		if err != nil {
-			return fmt.Errorf("failed to insert wrapping history: %w", err)
-		}
-		if tx.From == "" {
-			i.logger.Warn("Transaction has no Owner address?",
-				zap.String("tx", tx.Hash))
-			os.Exit(1)
-		}
-		err = i.queries.UpdateWrappedStatus(ctx, db.UpdateWrappedStatusParams{
-			ID:      int32(location.Int64()),
-			Wrapped: true,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to update tile owner: %w", err)
-		}
-
-	case "unwrap":
-		i.logger.Info("wrap called",
-			zap.String("tx", tx.Hash),
-			zap.String("from", tx.From))
-
-		location, _ := args[0].(*big.Int)
-		wrapped, _ := args[1].(string)
-		i.logger.Info("unwrap called",
-			zap.String("location", location.String()),
-			zap.String("unwrapped", wrapped),
-			zap.String("tx", tx.Hash),
-			zap.String("from", tx.From))
-		wrappingHistory := db.InsertWrappingHistoryParams{
-			TileID:      int32(location.Int64()),
-			Wrapped:     false,
-			Tx:          tx.Hash,
-			TimeStamp:   time.Unix(timeStamp.Int64(), 0),
-			BlockNumber: blockNumber.Int64(),
-			UpdatedBy:   tx.From,
-			LogIndex:    int32(transactionIndex),
-		}
-		_, err := i.queries.InsertWrappingHistory(ctx, wrappingHistory)
-		if err != nil {
-			return fmt.Errorf("failed to insert wrapping history: %w", err)
-		}
-		if tx.From == "" {
-			i.logger.Warn("Transaction has no Owner address?",
-				zap.String("tx", tx.Hash))
-			os.Exit(1)
-		}
-		err = i.queries.UpdateWrappedStatus(ctx, db.UpdateWrappedStatusParams{
-			ID:      int32(location.Int64()),
-			Wrapped: false,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to update tile owner: %w", err)
-		}
-
-	case "transferFrom", "safeTransferFrom", "safeTransferFrom0":
-		if err := i.processTransfer(ctx, args, tx, timeStamp.Int64(), blockNumber.Int64(), int32(transactionIndex)); err != nil {
-			return err
-		}
-	case "withdrawETH":
-		i.logger.Info("withdrawETH called",
-			zap.String("tx", tx.Hash),
-			zap.String("from", tx.From))
-	case "approve":
-		i.logger.Info("approve called",
-			zap.String("tx", tx.Hash),
-			zap.String("from", tx.From))
-	default:
-		fmt.Printf("Unknown method called: %s\n", method.Name)
-		os.Exit(2)
+		return fmt.Errorf("%s %s: %w", contract, method.Name, err)
 	}
-
-	_, err = i.queries.InsertPixelMapTransaction(ctx, transaction)
-
-	return err
+	return changes.apply(ctx, i, tx, transaction)
 }
 
 func (i *Ingestor) fetchTransactions(ctx context.Context, fromBlock, toBlock int64) ([]EtherscanTransaction, error) {
@@ -604,114 +528,30 @@ func (i *Ingestor) fetchTransactions(ctx context.Context, fromBlock, toBlock int
 }
 
 func (i *Ingestor) updateLastProcessedBlock(ctx context.Context, blockNumber int64) error {
-	if err := i.queries.UpdateLastProcessedBlock(ctx, blockNumber); err != nil {
+	if err := i.inTx(ctx, func(q *db.Queries) error {
+		return q.UpdateLastProcessedBlock(ctx, blockNumber)
+	}); err != nil {
 		i.logger.Error("Failed to update last processed block", zap.Error(err), zap.Int64("block", blockNumber))
 		return fmt.Errorf("failed to update last processed block: %w", err)
 	}
+	metrics.SetLastProcessedBlock(blockNumber)
+	i.health.Progress(blockNumber)
 	return nil
 }
 
-func (i *Ingestor) signalNewData() {
+func (i *Ingestor) signalSync() {
 	select {
-	case i.renderSignal <- struct{}{}:
+	case i.syncSignal <- struct{}{}:
 	default:
-		// Channel already has a signal, no need to send another
 	}
 }
 
-func (i *Ingestor) continuousRenderProcess() {
-	for {
-		// Wait for a signal that new data is available
-		<-i.renderSignal
-
-		// Set the rendering flag
-		if !i.isRendering.CompareAndSwap(false, true) {
-			// If already rendering, continue waiting
-			continue
-		}
-
-		// Start rendering in a separate goroutine
-		go func() {
-			defer i.isRendering.Store(false)
-
-			for {
-				if err := i.processDataHistory(context.Background()); err != nil {
-					i.logger.Error("Failed to process data history", zap.Error(err))
-					return
-				}
-
-				// Check if there's more data to process
-				select {
-				case <-i.renderSignal:
-					// More data available, continue processing
-					continue
-				default:
-					// No more data, exit the rendering loop
-					return
-				}
-			}
-		}()
-	}
-}
-
-func (i *Ingestor) processDataHistory(ctx context.Context) error {
-	lastProcessedID, err := i.queries.GetLastProcessedDataHistoryID(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get last processed data history ID: %w", err)
-	}
-
-	// Fetch a batch of unprocessed data history entries
-	history, err := i.queries.GetUnprocessedDataHistory(ctx, lastProcessedID)
-	if err != nil {
-		return fmt.Errorf("failed to get unprocessed data history: %w", err)
-	}
-
-	if len(history) == 0 {
-		// No more data to process
-		return nil
-	}
-
-	for _, row := range history {
-		location := big.NewInt(int64(row.TileID))
-		if err := i.renderAndSaveImage(location, row.Image, row.BlockNumber); err != nil {
-			return fmt.Errorf("failed to render and save image: %w", err)
-		}
-
-		// Update metadata
-		tile, err := i.queries.GetTileById(ctx, int32(location.Int64()))
-		if err != nil {
-			return fmt.Errorf("failed to get tile data: %w", err)
-		}
-		// Get all data history for the tile
-		dataHistory, err := i.queries.GetDataHistoryByTileId(ctx, int32(location.Int64()))
-		if err != nil {
-			return fmt.Errorf("failed to get data history: %w", err)
-		}
-		if err := UpdateTileMetadata(tile, dataHistory, i.queries, ctx); err != nil {
-			return fmt.Errorf("failed to update metadata: %w", err)
-		}
-
-		// Update the last processed ID
-		if err := i.queries.UpdateLastProcessedDataHistoryID(ctx, row.ID); err != nil {
-			return fmt.Errorf("failed to update last processed data history ID: %w", err)
-		}
-	}
-
-	// Redraw the full map
-	tiles, err := i.getLatestTileImages(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get latest tile images: %w", err)
-	}
-	utils.RenderFullMap(tiles, "cache/tilemap.png")
-
-	// Call the reusable function
-	if err := i.updateTileDataAndSync(ctx); err != nil {
-		return err
+func (i *Ingestor) signalNewData() {
+	select {
+	case i.renderSignal <- struct{}{}:
+	default:
+		// Channel already has a signal, no need to send another
 	}
-
-	i.logger.Info("Finished processing data history", zap.Int("count", len(history)))
-
-	return nil
 }
 
 func (i *Ingestor) getLatestTileImages(ctx context.Context) ([]string, error) {
@@ -723,12 +563,22 @@ func (i *Ingestor) getLatestTileImages(ctx context.Context) ([]string, error) {
 	for _, img := range latestImages {
 		tiles[img.TileID] = img.Image
 	}
+
+	inputs, err := i.queries.ListLatestTruncatedTileUpdateInputs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list truncated tile update inputs: %w", err)
+	}
+	for _, input := range inputs {
+		if input.ImageTruncated {
+			tiles[input.TileID] = input.Image
+		}
+	}
 	return tiles, nil
 }
 
 func (i *Ingestor) renderAndSaveImage(location *big.Int, imageData string, blockNumber int64) error {
 	// Create the directory if it doesn't exist
-	dirPath := fmt.Sprintf("cache/%s", location.String())
+	dirPath := filepath.Join(i.network.CacheDir, location.String())
 	if err := os.MkdirAll(dirPath, 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
@@ -776,194 +626,42 @@ func (i *Ingestor) renderAndSaveImage(location *big.Int, imageData string, block
 	return nil
 }
 
-func (i *Ingestor) processTileUpdate(ctx context.Context, location *big.Int, image, url string, priceWei *big.Int, tx *EtherscanTransaction, timestamp, blockNumber int64, transactionIndex int32) error {
-	var priceEthStr string
-	if priceWei == nil {
-		// Fetch the current price from the database
-		currentTile, err := i.queries.GetTileById(ctx, int32(location.Int64()))
-		if err != nil {
-			return fmt.Errorf("failed to get current tile data: %w", err)
-		}
-		priceEthStr = currentTile.Price
-	} else if priceWei.Sign() > 0 {
-		// Convert Wei to Ether
-		priceEth := new(big.Float).Quo(new(big.Float).SetInt(priceWei), big.NewFloat(1e18))
-
-		if priceEth.Cmp(big.NewFloat(maxPostgresNumeric)) > 0 {
-			i.logger.Warn("Price exceeds maximum supported value, capping at max",
-				zap.String("location", location.String()),
-				zap.String("originalPrice", priceEth.Text('f', 18)),
-				zap.Float64("maxPrice", maxPostgresNumeric))
-			priceEthStr = fmt.Sprintf("%.2f", maxPostgresNumeric)
-		} else {
-			// Format the price with up to 18 decimal places, removing trailing zeros
-			priceEthStr = strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.18f", priceEth), "0"), ".")
-		}
-	} else {
-		priceEthStr = "0"
-	}
-
-	i.logger.Info("Tile update",
-		zap.String("location", location.String()),
-		zap.String("price", priceEthStr),
-		zap.String("url", url),
-		zap.String("tx", tx.Hash),
-		zap.String("from", tx.From))
-
-	// If length of image is greater than 800, truncate it
-	if len(image) > 800 {
-		image = image[:800]
+// resolveENS returns the reverse ENS name for address, or "" when the network
+// has no ENS, the address has no name, or the lookup fails.
+func (i *Ingestor) resolveENS(address string) string {
+	if !i.network.ResolveENS || i.ethClient == nil {
+		return ""
 	}
 
-	// Try to lookup tx.From ENS
-	// Lookup ENS
-	ensName, err := lookupENS(i.ethClient, tx.From)
+	ensName, err := lookupENS(i.ethClient, address)
 	if err != nil {
 		if strings.Contains(err.Error(), "not a resolver") {
 			// This is an expected error for addresses without ENS names
-			i.logger.Debug("Address does not have an ENS name", zap.String("address", tx.From))
+			i.logger.Debug("Address does not have an ENS name", zap.String("address", address))
 		} else {
 			// Log other errors as warnings
-			i.logger.Warn("Failed to lookup ENS", zap.Error(err), zap.String("address", tx.From))
+			i.logger.Warn("Failed to lookup ENS", zap.Error(err), zap.String("address", address))
 		}
-		ensName = "" // Ensure ensName is empty if lookup failed
-	}
-	i.logger.Debug("ENS lookup result", zap.String("ens", ensName), zap.String("address", tx.From))
-	updatedBy := tx.From
-	if ensName != "" {
-		updatedBy = ensName
-	}
-	// Add to dataHistory
-	dataHistory := db.InsertDataHistoryParams{
-		TileID:      int32(location.Int64()),
-		Price:       sql.NullString{String: priceEthStr, Valid: priceEthStr != ""}, // Use sql.NullString
-		Url:         url,
-		Tx:          tx.Hash,
-		TimeStamp:   time.Unix(timestamp, 0),
-		BlockNumber: blockNumber,
-		Image:       image,
-		UpdatedBy:   updatedBy,
-		LogIndex:    transactionIndex,
-	}
-
-	if _, err := i.queries.InsertDataHistory(ctx, dataHistory); err != nil {
-		return fmt.Errorf("failed to insert data history: %w", err)
-	}
-
-	// Update the tile in the database
-	err = i.queries.UpdateTile(ctx, db.UpdateTileParams{
-		ID:    int32(location.Int64()),
-		Price: priceEthStr,
-		Url:   url,
-		Image: image,
-		Owner: tx.From,
-	})
-	if err != nil {
-		i.logger.Error("Failed to update tile", zap.Error(err), zap.String("location", location.String()))
-		os.Exit(1)
-		return fmt.Errorf("failed to update tile: %w", err)
+		return ""
 	}
-
-	// Signal that new data is available to render
-	i.signalNewData()
-
-	// Keep the Discord notification
-	discordPayload, _ := json.Marshal(map[string]interface{}{
-		"message": fmt.Sprintf("Tile %s updated by %s", location.String(), tx.From),
-		"url":     url,
-	})
-	i.pubSub.Publish(Event{Type: EventTypeDiscordNotification, Payload: discordPayload})
-
-	return nil
+	i.logger.Debug("ENS lookup result", zap.String("ens", ensName), zap.String("address", address))
+	return ensName
 }
 
-func (i *Ingestor) processTransfer(ctx context.Context, args []interface{}, tx *EtherscanTransaction, timestamp, blockNumber int64, transactionIndex int32) error {
-	if len(args) < 3 {
-		i.logger.Error("Insufficient arguments for transfer")
-		os.Exit(1)
-		return fmt.Errorf("insufficient arguments for transfer")
-	}
-
-	from, ok := args[0].(common.Address)
-	if !ok {
-		i.logger.Error("Invalid 'from' address")
-		os.Exit(1)
-		return fmt.Errorf("invalid 'from' address")
-	}
-
-	to, ok := args[1].(common.Address)
-	if !ok {
-		i.logger.Error("Invalid 'to' address")
-		os.Exit(1)
-		return fmt.Errorf("invalid 'to' address")
-	}
-
-	location, ok := args[2].(*big.Int)
-	if !ok {
-		i.logger.Error("Invalid location")
-		os.Exit(1)
-		return fmt.Errorf("invalid location")
-	}
-
-	i.logger.Info("Transfer processed",
-		zap.String("from", from.Hex()),
-		zap.String("to", to.Hex()),
-		zap.String("location", location.String()),
-		zap.String("tx", tx.Hash),
-		zap.String("caller", tx.From))
-
-	transferHistory := db.InsertTransferHistoryParams{
-		TileID:          int32(location.Int64()),
-		Tx:              tx.Hash,
-		TimeStamp:       time.Unix(timestamp, 0),
-		BlockNumber:     blockNumber,
-		TransferredFrom: from.Hex(),
-		TransferredTo:   to.Hex(),
-		LogIndex:        transactionIndex,
-	}
-
-	_, err := i.queries.InsertTransferHistory(ctx, transferHistory)
-	if err != nil {
-		return fmt.Errorf("failed to insert transfer history: %w", err)
-	}
-
-	// Update tile owner
-	if to.Hex() == "" {
-		i.logger.Warn("Transaction has no Owner address?",
-			zap.String("tx", tx.Hash))
-		os.Exit(1)
-	}
-
-	// Lookup ENS
-	ensName, err := lookupENS(i.ethClient, to.Hex())
-	if err != nil {
-		if strings.Contains(err.Error(), "not a resolver") {
-			// This is an expected error for addresses without ENS names
-			i.logger.Debug("Address does not have an ENS name", zap.String("address", to.Hex()))
-		} else {
-			// Log other errors as warnings
-			i.logger.Warn("Failed to lookup ENS", zap.Error(err), zap.String("address", to.Hex()))
-		}
-		ensName = "" // Ensure ensName is empty if lookup failed
+// refreshStats recomputes the marketplace aggregates from the sales ingested
+// so far and exports them as stats.json.
+func (i *Ingestor) refreshStats(ctx context.Context) error {
+	if err := stats.Refresh(ctx, i.queries); err != nil {
+		return err
 	}
-	i.logger.Debug("ENS lookup result", zap.String("ens", ensName), zap.String("address", to.Hex()))
-
-	err = i.queries.UpdateTileOwner(ctx, db.UpdateTileOwnerParams{
-		ID:    int32(location.Int64()),
-		Owner: to.Hex(),
-		Ens:   ensName,
-	})
+	marketStats, err := stats.Load(ctx, i.queries)
 	if err != nil {
-		i.logger.Error("Failed to update tile owner", zap.Error(err), zap.String("location", location.String()))
-		os.Exit(1)
-		return fmt.Errorf("failed to update tile owner: %w", err)
-	}
-
-	// Call the reusable function
-	if err := i.updateTileDataAndSync(ctx); err != nil {
 		return err
 	}
-
+	if err := GenerateStatsJSON(i.network, marketStats); err != nil {
+		return fmt.Errorf("failed to generate stats.json: %w", err)
+	}
+	i.signalSync()
 	return nil
 }
 
@@ -977,20 +675,22 @@ func (i *Ingestor) updateTileDataAndSync(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to get all tiles: %w", err)
 	}
+	if err := i.preferLatestTileInputs(ctx, allTiles); err != nil {
+		return err
+	}
 
 	// Generate tiledata.json
-	if err := GenerateTiledataJSON(allTiles, i.queries, ctx); err != nil {
+	if err := GenerateTiledataJSON(i.network, allTiles, i.queries, ctx); err != nil {
 		return fmt.Errorf("failed to generate tiledata.json: %w", err)
 	}
 
-	// Sync with S3 if s3Syncer is initialized
-	if i.s3Syncer != nil {
-		err := i.s3Syncer.SyncWithS3(ctx)
-		if err != nil {
-			i.logger.Error("Failed to sync with S3", zap.Error(err))
-			// Note: We're not returning this error as it shouldn't stop the main process
-		}
+	if err := GenerateOwnerJSON(i.network, allTiles, i.queries, ctx); err != nil {
+		return fmt.Errorf("failed to generate owner JSON: %w", err)
 	}
 
+	// The S3 worker uploads whatever changed; a failed sync shouldn't stop
+	// rendering.
+	i.signalSync()
+
 	return nil
 }
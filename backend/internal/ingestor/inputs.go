@@ -0,0 +1,193 @@
+package ingestor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	db "pixelmap.io/backend/internal/db"
+)
+
+// What tiles and data_histories hold of a tile update, in characters. The
+// whole arguments go into tile_update_inputs.
+const (
+	maxImageLength   = 800
+	maxTileURLLength = 255
+)
+
+// storableText returns s as Postgres can store it: valid UTF-8 without NUL
+// bytes. It reports whether anything had to be replaced.
+func storableText(s string) (string, bool) {
+	if utf8.ValidString(s) && !strings.ContainsRune(s, 0) {
+		return s, false
+	}
+	s = strings.ToValidUTF8(s, string(utf8.RuneError))
+	return strings.ReplaceAll(s, "\x00", string(utf8.RuneError)), true
+}
+
+// truncateText cuts s to at most n characters, as a VARCHAR(n) column counts
+// them, and reports whether it had to.
+func truncateText(s string, n int) (string, bool) {
+	if utf8.RuneCountInString(s) <= n {
+		return s, false
+	}
+	return string([]rune(s)[:n]), true
+}
+
+// StoredTileText returns a tile's image and URL as the tiles table holds them
+// after an update sets them, for comparing against the chain.
+func StoredTileText(image, url string) (string, string) {
+	image, _ = storableText(image)
+	url, _ = storableText(url)
+	image, _ = truncateText(image, maxImageLength)
+	url, _ = truncateText(url, maxTileURLLength)
+	return image, url
+}
+
+// PreferTileInputs replaces the truncated images and URLs of a tile and its
+// data history with the whole arguments of the calls that set them.
+func PreferTileInputs(ctx context.Context, queries *db.Queries, tile *db.Tile, history []db.DataHistory) error {
+	inputs, err := queries.GetTileUpdateInputsByTileId(ctx, tile.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get tile update inputs: %w", err)
+	}
+	byTx := make(map[string]db.TileUpdateInput, len(inputs))
+	for _, input := range inputs {
+		byTx[input.Tx] = input
+	}
+
+	var latest *db.DataHistory
+	for n := range history {
+		row := &history[n]
+		if input, ok := byTx[row.Tx]; ok && input.ImageTruncated {
+			row.Image = input.Image
+		}
+		if latest == nil || row.BlockNumber > latest.BlockNumber ||
+			(row.BlockNumber == latest.BlockNumber && row.LogIndex > latest.LogIndex) {
+			latest = row
+		}
+	}
+	if latest != nil {
+		if input, ok := byTx[latest.Tx]; ok {
+			preferTileInput(tile, input)
+		}
+	}
+	return nil
+}
+
+// preferTileInput gives tile the image and URL of input, its latest update,
+// where the tiles table had to truncate them.
+func preferTileInput(tile *db.Tile, input db.TileUpdateInput) {
+	if input.ImageTruncated {
+		tile.Image = input.Image
+	}
+	if input.UrlTruncated {
+		tile.Url = input.Url
+	}
+}
+
+// preferLatestTileInputs does the same as PreferTileInputs for every tile,
+// without their history.
+func (i *Ingestor) preferLatestTileInputs(ctx context.Context, tiles []db.Tile) error {
+	inputs, err := i.queries.ListLatestTruncatedTileUpdateInputs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list truncated tile update inputs: %w", err)
+	}
+	byTile := make(map[int32]db.TileUpdateInput, len(inputs))
+	for _, input := range inputs {
+		byTile[input.TileID] = input
+	}
+	for n := range tiles {
+		if input, ok := byTile[tiles[n].ID]; ok {
+			preferTileInput(&tiles[n], input)
+		}
+	}
+	return nil
+}
+
+// newTileUpdateInput keeps the whole arguments of a tile update, made
+// storable, and flags what tiles and data_histories cannot hold of them.
+func newTileUpdateInput(call *Call, tileID int32, image, url string, price sql.NullString) InsertTileUpdateInput {
+	image, imageReplaced := storableText(image)
+	url, urlReplaced := storableText(url)
+	_, imageTruncated := truncateText(image, maxImageLength)
+	_, urlTruncated := truncateText(url, maxTileURLLength)
+	return InsertTileUpdateInput{
+		Tx:              call.Tx.Hash,
+		TileID:          tileID,
+		Method:          call.Method.Name,
+		TimeStamp:       call.TimeStamp,
+		BlockNumber:     call.BlockNumber,
+		LogIndex:        call.TransactionIndex,
+		Image:           image,
+		Url:             url,
+		Price:           price,
+		ImageTruncated:  imageTruncated,
+		UrlTruncated:    urlTruncated,
+		InvalidEncoding: imageReplaced || urlReplaced,
+	}
+}
+
+// backfillPageSize is how many archived calls BackfillTileUpdateInputs
+// decodes at a time.
+const backfillPageSize = 500
+
+// BackfillTileUpdateInputs decodes the arguments of tile updates ingested
+// before tile_update_inputs existed from their archived calldata, and returns
+// how many it recorded. Calls already recorded are left alone, so it is safe
+// to run on every start.
+func (i *Ingestor) BackfillTileUpdateInputs(ctx context.Context) (int, error) {
+	var afterID int32
+	recorded := 0
+	for {
+		rows, err := i.queries.ListTileUpdatesWithoutInputs(ctx, db.ListTileUpdatesWithoutInputsParams{
+			AfterID:  afterID,
+			PageSize: backfillPageSize,
+		})
+		if err != nil {
+			return recorded, fmt.Errorf("failed to list tile updates without inputs: %w", err)
+		}
+		for _, row := range rows {
+			afterID = row.ID
+			method, args, _, err := i.handlers.Lookup(row.To, row.Input)
+			if err != nil || method == nil {
+				continue
+			}
+			call := &Call{
+				Tx:               &EtherscanTransaction{Hash: row.Hash},
+				Method:           method,
+				Args:             args,
+				TimeStamp:        row.TimeStamp,
+				BlockNumber:      row.BlockNumber,
+				TransactionIndex: row.TransactionIndex,
+			}
+			var image, url string
+			switch method.Name {
+			case "setTile":
+				decoded, err := decode[setTileArgs](call)
+				if err != nil {
+					continue
+				}
+				image, url = decoded.Image, decoded.URL
+			case "setTileData":
+				decoded, err := decode[setTileDataArgs](call)
+				if err != nil {
+					continue
+				}
+				image, url = decoded.Image, decoded.URL
+			default:
+				continue
+			}
+			input := newTileUpdateInput(call, row.TileID, image, url, row.Price)
+			if err := i.queries.InsertTileUpdateInput(ctx, db.InsertTileUpdateInputParams(input)); err != nil {
+				return recorded, fmt.Errorf("failed to insert tile update input for %s: %w", row.Hash, err)
+			}
+			recorded++
+		}
+		if len(rows) < backfillPageSize {
+			return recorded, nil
+		}
+	}
+}
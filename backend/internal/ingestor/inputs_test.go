@@ -0,0 +1,35 @@
+package ingestor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorableText(t *testing.T) {
+	text, replaced := storableText("https://example.com/ü")
+	assert.Equal(t, "https://example.com/ü", text)
+	assert.False(t, replaced)
+
+	text, replaced = storableText("a\x00b\xffc")
+	assert.Equal(t, "a�b�c", text)
+	assert.True(t, replaced)
+}
+
+func TestTruncateText(t *testing.T) {
+	text, truncated := truncateText(strings.Repeat("a", 255), maxTileURLLength)
+	assert.Len(t, text, 255)
+	assert.False(t, truncated)
+
+	// Characters, not bytes, as VARCHAR counts them.
+	text, truncated = truncateText(strings.Repeat("ü", 256), maxTileURLLength)
+	assert.Equal(t, strings.Repeat("ü", 255), text)
+	assert.True(t, truncated)
+}
+
+func TestStoredTileText(t *testing.T) {
+	image, url := StoredTileText(strings.Repeat("ü", 801), "https://example.com/"+strings.Repeat("a", 300)+"\x00")
+	assert.Equal(t, strings.Repeat("ü", 800), image)
+	assert.Equal(t, "https://example.com/"+strings.Repeat("a", 235), url)
+}
@@ -0,0 +1,256 @@
+package ingestor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	db "pixelmap.io/backend/internal/db"
+)
+
+// lifecycle tracks the workers started by Run so Close can stop them.
+type lifecycle struct {
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	done    chan struct{}
+	running bool
+	closed  bool
+}
+
+// ErrClosed is returned by Run after Close.
+var ErrClosed = errors.New("ingestor closed")
+
+type worker struct {
+	name string
+	run  func(context.Context) error
+}
+
+// Run starts the ingestion, render, map, ENS refresh and S3 sync workers and
+// blocks until ctx is cancelled or Close is called. Each worker is
+// supervised: a worker that fails or panics is logged and restarted after a
+// short delay.
+//
+// Shutdown happens in stages so that nothing is lost between workers:
+// ingestion, rendering and ENS refresh stop first, each finishing its current
+// unit of work (a block window, a tile render, an ENS lookup); then the map
+// worker regenerates anything rendered since its last run; then the S3 worker
+// uploads the result.
+func (i *Ingestor) Run(ctx context.Context) error {
+	i.lifecycle.mu.Lock()
+	if i.lifecycle.closed {
+		i.lifecycle.mu.Unlock()
+		return ErrClosed
+	}
+	if i.lifecycle.running {
+		i.lifecycle.mu.Unlock()
+		return errors.New("ingestor is already running")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	i.lifecycle.cancel = cancel
+	i.lifecycle.done = done
+	i.lifecycle.running = true
+	i.lifecycle.mu.Unlock()
+
+	defer func() {
+		i.lifecycle.mu.Lock()
+		i.lifecycle.running = false
+		i.lifecycle.mu.Unlock()
+		cancel()
+		close(done)
+	}()
+
+	if reset, err := i.queries.ResetRunningRenderJobs(ctx); err != nil {
+		i.logger.Warn("Failed to requeue interrupted render jobs", zap.Error(err))
+	} else if reset > 0 {
+		i.logger.Info("Requeued interrupted render jobs", zap.Int64("count", reset))
+	}
+	if recorded, err := i.BackfillTileUpdateInputs(ctx); err != nil {
+		i.logger.Warn("Failed to backfill tile update inputs", zap.Error(err))
+	} else if recorded > 0 {
+		i.logger.Info("Backfilled tile update inputs", zap.Int("count", recorded))
+	}
+
+	producers := []worker{{"ingest", i.StartContinuousIngestion}}
+	for n := 0; n < i.renderWorkers; n++ {
+		producers = append(producers, worker{fmt.Sprintf("render-%d", n+1), i.renderWorker})
+	}
+	if i.network.ResolveENS && i.ethClient != nil {
+		producers = append(producers, worker{"ens", i.ensRefreshWorker})
+	}
+	stages := [][]worker{producers, {{"map", i.mapWorker}}}
+	if i.s3Syncer != nil {
+		stages = append(stages, []worker{{"s3", i.s3SyncWorker}})
+	}
+
+	// Each stage has its own context so it can be stopped after the stages
+	// feeding it have drained.
+	cancels := make([]context.CancelFunc, len(stages))
+	groups := make([]*sync.WaitGroup, len(stages))
+	for n, stage := range stages {
+		stageCtx, stageCancel := context.WithCancel(context.WithoutCancel(ctx))
+		cancels[n] = stageCancel
+		groups[n] = &sync.WaitGroup{}
+		for _, w := range stage {
+			i.supervise(stageCtx, groups[n], w.name, w.run)
+		}
+	}
+
+	// Render anything left over from a previous run.
+	i.signalNewData()
+
+	<-ctx.Done()
+	i.logger.Info("Shutting down ingestor, waiting for workers to finish")
+	for n := range stages {
+		cancels[n]()
+		groups[n].Wait()
+	}
+
+	i.logger.Info("Ingestor stopped")
+	return nil
+}
+
+// Close stops the workers started by Run, waits for them to drain and
+// releases the Ethereum client. It is safe to call more than once and without
+// Run.
+func (i *Ingestor) Close() error {
+	i.lifecycle.mu.Lock()
+	if i.lifecycle.closed {
+		i.lifecycle.mu.Unlock()
+		return nil
+	}
+	i.lifecycle.closed = true
+	cancel, done := i.lifecycle.cancel, i.lifecycle.done
+	i.lifecycle.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+
+	if i.ethClient != nil {
+		i.ethClient.Close()
+	}
+	return nil
+}
+
+// supervise runs work on wg until ctx is cancelled, restarting it after
+// restartDelay whenever it returns early or panics.
+func (i *Ingestor) supervise(ctx context.Context, wg *sync.WaitGroup, name string, work func(context.Context) error) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		logger := i.logger.With(zap.String("worker", name))
+
+		for {
+			err := runWorker(ctx, work)
+			if ctx.Err() != nil {
+				logger.Debug("Worker stopped")
+				return
+			}
+			logger.Error("Worker exited, restarting", zap.Error(err), zap.Duration("delay", i.restartDelay))
+
+			select {
+			case <-time.After(i.restartDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func runWorker(ctx context.Context, work func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	if err := work(ctx); err != nil {
+		return err
+	}
+	return errors.New("worker returned")
+}
+
+// s3SyncWorker uploads changed cache files after each map regeneration. A
+// sync requested during shutdown is still carried out so the bucket matches
+// the last render.
+func (i *Ingestor) s3SyncWorker(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			select {
+			case <-i.syncSignal:
+				i.syncS3(context.WithoutCancel(ctx))
+			default:
+			}
+			return ctx.Err()
+		case <-i.syncSignal:
+		}
+
+		i.syncS3(ctx)
+	}
+}
+
+func (i *Ingestor) syncS3(ctx context.Context) {
+	if err := i.s3Syncer.SyncWithS3(ctx); err != nil && ctx.Err() == nil {
+		i.logger.Error("Failed to sync with S3", zap.Error(err))
+	}
+}
+
+// ensRefreshWorker periodically re-resolves the ENS name of every tile owner,
+// since names change without any PixelMap transaction.
+func (i *Ingestor) ensRefreshWorker(ctx context.Context) error {
+	for {
+		if err := i.refreshENS(ctx); err != nil && ctx.Err() == nil {
+			i.logger.Error("Failed to refresh ENS names", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(i.ensRefreshInterval):
+		}
+	}
+}
+
+func (i *Ingestor) refreshENS(ctx context.Context) error {
+	tiles, err := i.queries.ListTiles(ctx, db.ListTilesParams{Limit: 3970, Offset: 0})
+	if err != nil {
+		return fmt.Errorf("failed to list tiles: %w", err)
+	}
+
+	byOwner := make(map[string][]db.Tile)
+	var owners []string
+	for _, tile := range tiles {
+		if _, ok := byOwner[tile.Owner]; !ok {
+			owners = append(owners, tile.Owner)
+		}
+		byOwner[tile.Owner] = append(byOwner[tile.Owner], tile)
+	}
+
+	updated := 0
+	for _, owner := range owners {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		name := i.resolveENS(owner)
+		for _, tile := range byOwner[owner] {
+			if tile.Ens == name {
+				continue
+			}
+			// Finish the owner's tiles even if shutdown starts meanwhile.
+			if err := i.queries.UpdateTileENS(context.WithoutCancel(ctx), db.UpdateTileENSParams{ID: tile.ID, Ens: name}); err != nil {
+				return fmt.Errorf("failed to update ENS for tile %d: %w", tile.ID, err)
+			}
+			updated++
+		}
+	}
+
+	i.logger.Info("Refreshed ENS names", zap.Int("owners", len(owners)), zap.Int("tilesUpdated", updated))
+	return nil
+}
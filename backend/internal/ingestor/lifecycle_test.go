@@ -0,0 +1,124 @@
+package ingestor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/zap"
+	db "pixelmap.io/backend/internal/db"
+)
+
+func TestSuperviseRestartsFailedWorkers(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	network := Devnet
+	i := newIngestor(zap.NewNop(), nil, nil, &network)
+	i.restartDelay = time.Millisecond
+
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	i.supervise(ctx, &wg, "flaky", func(ctx context.Context) error {
+		switch calls.Add(1) {
+		case 1:
+			panic("boom")
+		case 2:
+			return errors.New("failed")
+		case 3:
+			return nil
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	require.Eventually(t, func() bool { return calls.Load() == 4 }, time.Second, time.Millisecond)
+	cancel()
+	wg.Wait()
+	assert.Equal(t, int32(4), calls.Load(), "a stopped worker is not restarted")
+}
+
+func TestRunAndCloseDoNotLeak(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	// Nothing listens on port 1, so every query fails fast and the workers
+	// sit in their retry and wait loops.
+	conn, err := sql.Open("postgres", "postgres://127.0.0.1:1/pixelmap?sslmode=disable&connect_timeout=1")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	network := Devnet
+	network.CacheDir = t.TempDir()
+	i := newIngestor(zap.NewNop(), db.New(conn), nil, &network)
+	i.restartDelay = time.Millisecond
+
+	done := make(chan error, 1)
+	go func() { done <- i.Run(context.Background()) }()
+
+	require.Eventually(t, func() bool {
+		i.lifecycle.mu.Lock()
+		defer i.lifecycle.mu.Unlock()
+		return i.lifecycle.running
+	}, time.Second, time.Millisecond)
+	require.ErrorContains(t, i.Run(context.Background()), "already running")
+
+	require.NoError(t, i.Close())
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after Close")
+	}
+
+	require.NoError(t, i.Close(), "Close is idempotent")
+	require.ErrorIs(t, i.Run(context.Background()), ErrClosed)
+}
+
+func TestRunRendersAndDrainsOnShutdown(t *testing.T) {
+	h := newIngestHarness(t)
+	h.ingestor.pollInterval = 10 * time.Millisecond
+	h.ingestor.mapDebounce = 10 * time.Millisecond
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 9, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 9, Image: redTile, URL: "https://example.com"},
+	)
+
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithCancel(h.ctx)
+	done := make(chan error, 1)
+	go func() { done <- h.ingestor.Run(ctx) }()
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(h.network.CacheDir, "tiledata.json"))
+		return err == nil
+	}, 30*time.Second, 10*time.Millisecond)
+	h.RequireFile("9", "latest.png")
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(30 * time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+	require.NoError(t, h.ingestor.Close())
+
+	lastProcessed, err := h.queries.GetLastProcessedBlock(h.ctx)
+	require.NoError(t, err)
+	require.Equal(t, h.chain.head, lastProcessed)
+
+	var pending int
+	require.NoError(t, h.conn.QueryRow(`SELECT COUNT(*) FROM render_jobs WHERE status <> 'done'`).Scan(&pending))
+	require.Zero(t, pending)
+}
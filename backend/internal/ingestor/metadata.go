@@ -11,16 +11,28 @@ import (
 	"time"
 
 	"pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/portfolio"
+	"pixelmap.io/backend/internal/stats"
+	"pixelmap.io/backend/internal/traits"
 	utils "pixelmap.io/backend/internal/utils"
 )
 
 var logger = log.New(os.Stdout, "metadata", log.LstdFlags)
 
+// traitRules decide the attributes written to token metadata and tiledata.json.
+var traitRules = traits.Default()
+
+// UseTraitRules replaces the embedded trait rules.
+func UseTraitRules(rules *traits.Rules) {
+	traitRules = rules
+}
+
 type MetadataPixelMapTile struct {
 	ID               int                   `json:"id"`
 	Image            string                `json:"image"`
 	URL              string                `json:"url"`
 	Price            string                `json:"price"`
+	PriceWei         string                `json:"price_wei"`
 	Owner            string                `json:"owner"`
 	Wrapped          bool                  `json:"wrapped"`
 	OpenseaPrice     string                `json:"opensea_price"`
@@ -40,6 +52,7 @@ type PurchaseHistoryItem struct {
 	SoldBy      string    `json:"sold_by"`
 	PurchasedBy string    `json:"purchased_by"`
 	Price       string    `json:"price"`
+	PriceWei    string    `json:"price_wei"`
 }
 
 type TransferHistoryItem struct {
@@ -68,20 +81,50 @@ type DataHistoryItem struct {
 	Image       string    `json:"image,omitempty"`
 	URL         string    `json:"url,omitempty"`
 	Price       string    `json:"price,omitempty"`
+	PriceWei    string    `json:"price_wei,omitempty"`
 	UpdatedBy   string    `json:"updated_by"`
+	// What the update changed against the one before it; see
+	// compareDataHistory.
+	ChangeType       string  `json:"change_type,omitempty"`
+	PreviousImage    string  `json:"previous_image,omitempty"`
+	PreviousURL      string  `json:"previous_url,omitempty"`
+	PreviousPrice    string  `json:"previous_price,omitempty"`
+	PreviousPriceWei string  `json:"previous_price_wei,omitempty"`
+	PixelsChanged    *int32  `json:"pixels_changed,omitempty"`
+	DiffBounds       []int32 `json:"diff_bounds,omitempty"`
 }
 
 // GenerateTiledataJSON generates the tiledata.json file
-func GenerateTiledataJSON(tiles []db.Tile, queries *db.Queries, ctx context.Context) error {
+func GenerateTiledataJSON(network *Network, tiles []db.Tile, queries *db.Queries, ctx context.Context) error {
 	logger.Println("Generating tiledata.json")
 	tiledataJSON := make([]map[string]interface{}, len(tiles))
 
+	ids := make([]int32, len(tiles))
+	for i, tile := range tiles {
+		ids[i] = tile.ID
+	}
+	purchases, err := queries.GetPurchaseHistoryByTileIds(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("error fetching purchase history: %w", err)
+	}
+	purchasesByTile := make(map[int32][]db.PurchaseHistory)
+	for _, p := range purchases {
+		purchasesByTile[p.TileID] = append(purchasesByTile[p.TileID], p)
+	}
+	attributes := make(map[int32][]traits.Attribute, len(tiles))
+
 	for i, tile := range tiles {
 		// Fetch data history for the tile
 		dataHistory, err := queries.GetDataHistoryByTileId(ctx, tile.ID)
 		if err != nil {
 			return fmt.Errorf("error fetching data history for tile %d: %w", tile.ID, err)
 		}
+		attributes[tile.ID] = traitRules.Evaluate(traits.Tile{
+			ID:          tile.ID,
+			Image:       tile.Image,
+			DataHistory: dataHistory,
+			Purchases:   purchasesByTile[tile.ID],
+		})
 
 		// Convert data history to a format suitable for JSON
 		historicalImages := make([]map[string]interface{}, len(dataHistory))
@@ -90,7 +133,7 @@ func GenerateTiledataJSON(tiles []db.Tile, queries *db.Queries, ctx context.Cont
 				"blockNumber": history.BlockNumber,
 				"date":        history.TimeStamp,
 				"image":       history.Image,
-				"image_url":   fmt.Sprintf("https://pixelmap.art/%d/%d.png", tile.ID, history.BlockNumber),
+				"image_url":   fmt.Sprintf("%s/%d/%d.png", network.assetBaseURL(), tile.ID, history.BlockNumber),
 				"updatedBy":   history.UpdatedBy,
 			}
 		}
@@ -100,33 +143,89 @@ func GenerateTiledataJSON(tiles []db.Tile, queries *db.Queries, ctx context.Cont
 			"url":               tile.Url,
 			"image":             tile.Image,
 			"owner":             tile.Owner,
-			"price":             tile.Price,
+			"price":             utils.FormatWei(tile.Price),
+			"priceWei":          tile.Price,
 			"wrapped":           tile.Wrapped,
 			"openseaPrice":      tile.OpenseaPrice,
 			"lastUpdated":       time.Date(2021, time.December, 13, 1, 1, 0, 0, time.UTC),
 			"ens":               tile.Ens,
 			"historical_images": historicalImages, // Add historical images here
+			"attributes":        attributes[tile.ID],
 		}
 	}
 
+	// Rarity needs every tile's traits, so it is filled in afterwards.
+	collection := traits.Score(attributes)
+	for _, entry := range tiledataJSON {
+		entry["rarity"] = collection.Rarity[entry["id"].(int32)]
+	}
+
 	jsonData, err := json.MarshalIndent(tiledataJSON, "", "  ")
 	if err != nil {
 		return fmt.Errorf("error marshaling tiledata JSON: %w", err)
 	}
 
-	if err := os.MkdirAll(filepath.Dir("cache/tiledata.json"), os.ModePerm); err != nil {
+	tiledataPath := filepath.Join(network.CacheDir, "tiledata.json")
+	if err := os.MkdirAll(filepath.Dir(tiledataPath), os.ModePerm); err != nil {
 		return fmt.Errorf("error creating cache directory: %w", err)
 	}
 
-	if err := os.WriteFile("cache/tiledata.json", jsonData, 0644); err != nil {
+	if err := os.WriteFile(tiledataPath, jsonData, 0644); err != nil {
 		return fmt.Errorf("error writing tiledata.json file: %w", err)
 	}
 
+	traitsJSON, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling traits JSON: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(network.CacheDir, "traits.json"), traitsJSON, 0644); err != nil {
+		return fmt.Errorf("error writing traits.json file: %w", err)
+	}
+
+	return nil
+}
+
+// GenerateOwnerJSON writes owner/{address}.json for every address that owns
+// a tile or time capsule or has ever bought or received a tile, for the
+// frontend's profile pages.
+func GenerateOwnerJSON(network *Network, tiles []db.Tile, queries *db.Queries, ctx context.Context) error {
+	portfolios, err := portfolio.LoadAll(ctx, queries, tiles)
+	if err != nil {
+		return err
+	}
+
+	ownerDir := filepath.Join(network.CacheDir, "owner")
+	if err := os.MkdirAll(ownerDir, os.ModePerm); err != nil {
+		return fmt.Errorf("error creating owner directory: %w", err)
+	}
+
+	for address, p := range portfolios {
+		jsonData, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling portfolio for %s: %w", address, err)
+		}
+		if err := os.WriteFile(filepath.Join(ownerDir, address+".json"), jsonData, 0644); err != nil {
+			return fmt.Errorf("error writing portfolio for %s: %w", address, err)
+		}
+	}
+
 	return nil
 }
 
 // UpdateTileMetadata updates the metadata for a given tile (exported for regeneration scripts)
-func UpdateTileMetadata(tile db.Tile, dataHistory []db.DataHistory, queries *db.Queries, ctx context.Context) error {
+// GenerateStatsJSON writes the marketplace stats to stats.json.
+func GenerateStatsJSON(network *Network, marketStats *stats.Stats) error {
+	jsonData, err := json.MarshalIndent(marketStats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling stats: %w", err)
+	}
+	if err := os.MkdirAll(network.CacheDir, os.ModePerm); err != nil {
+		return fmt.Errorf("error creating cache directory: %w", err)
+	}
+	return os.WriteFile(filepath.Join(network.CacheDir, "stats.json"), jsonData, 0644)
+}
+
+func UpdateTileMetadata(network *Network, tile db.Tile, dataHistory []db.DataHistory, queries *db.Queries, ctx context.Context) error {
 	tileMetaData := map[string]interface{}{
 		"description": "Official PixelMap Wrapped Tile. Created in 2016, PixelMap is considered the second oldest NFT, the " +
 			"oldest verified collection on OpenSea, and provides the ability to create, display, and immortalize artwork " +
@@ -136,62 +235,22 @@ func UpdateTileMetadata(tile db.Tile, dataHistory []db.DataHistory, queries *db.
 			"Discord, at https://discord.pixelmap.io",
 		"external_url": tile.Url,
 		"name":         fmt.Sprintf("Tile #%d", tile.ID),
-		"attributes":   []map[string]string{},
+		"attributes":   []traits.Attribute{},
 		"image":        "",
 	}
 
-	// Invisible (The very last tile)
-	if tile.ID == 3969 {
-		tileMetaData["attributes"] = append(tileMetaData["attributes"].([]map[string]string), map[string]string{"value": "Invisible"})
-	}
-
-	// Genesis Tile
-	if tile.ID == 1984 {
-		tileMetaData["attributes"] = append(tileMetaData["attributes"].([]map[string]string), map[string]string{"value": "Genesis"})
-	}
-
-	// Center ( tiles within 5 spaces of center tile aka the spider )
-	if tileIsInCenter(int(tile.ID)) {
-		tileMetaData["attributes"] = append(tileMetaData["attributes"].([]map[string]string), map[string]string{"value": "Center"})
-	}
-
-	// Edge
-	if tileIsOnEdge(int(tile.ID)) {
-		tileMetaData["attributes"] = append(tileMetaData["attributes"].([]map[string]string), map[string]string{"value": "Edge"})
-	}
-
-	// Corner
-	if tile.ID == 0 || tile.ID == 80 || tile.ID == 3888 || tile.ID == 3968 {
-		tileMetaData["attributes"] = append(tileMetaData["attributes"].([]map[string]string), map[string]string{"value": "Corner"})
-	}
-
-	// Year Image first Updated
-	ogTiles := []int{0, 80, 574, 868, 1317, 1416, 1661, 1822, 1901, 1902, 1903, 1904, 1905,
-		1906, 1920, 1983, 1984, 1985, 1986, 1987, 2063, 2064, 2065, 2066, 2067,
-		2068, 2145, 2146, 2147, 2226, 3968}
-	// If the tile is in the ogTiles array, add it to the attributes
-	if contains(ogTiles, int(tile.ID)) {
-		tileMetaData["attributes"] = append(tileMetaData["attributes"].([]map[string]string), map[string]string{"value": "OG"})
-	}
-
 	image, err := utils.DecompressTileCode(tile.Image)
 	if err != nil {
-		tileMetaData["image"] = "https://pixelmap.art/blank.png"
+		tileMetaData["image"] = network.assetBaseURL() + "/blank.png"
 	}
 
 	if len(image) >= 768 {
-		tileMetaData["image"] = fmt.Sprintf("https://pixelmap.art/%d/latest.png", tile.ID)
+		tileMetaData["image"] = fmt.Sprintf("%s/%d/latest.png", network.assetBaseURL(), tile.ID)
 	} else {
-		tileMetaData["image"] = "https://pixelmap.art/blank.png"
+		tileMetaData["image"] = network.assetBaseURL() + "/blank.png"
 	}
 
-	// Write metadata for OpenSea
-	jsonMetaData, err := json.MarshalIndent(tileMetaData, "", "  ")
-	if err != nil {
-		return fmt.Errorf("error marshaling tile metadata: %w", err)
-	}
-
-	historicalImages := GetHistoricalImages(tile, dataHistory)
+	historicalImages := GetHistoricalImages(network, tile, dataHistory)
 	
 	// Initialize empty slices
 	purchaseItems := []PurchaseHistoryItem{}
@@ -199,10 +258,12 @@ func UpdateTileMetadata(tile db.Tile, dataHistory []db.DataHistory, queries *db.
 	wrappingItems := []WrappingHistoryItem{}
 	dataItems := []DataHistoryItem{}
 	
+	var purchaseHistory []db.PurchaseHistory
+
 	// Only fetch history if queries is not nil (for testing)
 	if queries != nil {
 		// Fetch purchase history
-		purchaseHistory, err := queries.GetPurchaseHistoryByTileId(ctx, tile.ID)
+		purchaseHistory, err = queries.GetPurchaseHistoryByTileId(ctx, tile.ID)
 		if err != nil {
 			logger.Printf("Error fetching purchase history for tile %d: %v", tile.ID, err)
 			purchaseHistory = []db.PurchaseHistory{}
@@ -218,7 +279,8 @@ func UpdateTileMetadata(tile db.Tile, dataHistory []db.DataHistory, queries *db.
 				Tx:          p.Tx,
 				SoldBy:      p.SoldBy,
 				PurchasedBy: p.PurchasedBy,
-				Price:       p.Price,
+				Price:       utils.FormatWei(p.Price),
+				PriceWei:    p.Price,
 			}
 		}
 		
@@ -227,22 +289,49 @@ func UpdateTileMetadata(tile db.Tile, dataHistory []db.DataHistory, queries *db.
 		// TODO: Add GetTransferHistoryByTileId and GetWrappingHistoryByTileId queries
 	}
 	
+	tileMetaData["attributes"] = traitRules.Evaluate(traits.Tile{
+		ID:          tile.ID,
+		Image:       tile.Image,
+		DataHistory: dataHistory,
+		Purchases:   purchaseHistory,
+	})
+
+	// Write metadata for OpenSea
+	jsonMetaData, err := json.MarshalIndent(tileMetaData, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling tile metadata: %w", err)
+	}
+
 	// Convert data history to API format
 	dataItems = make([]DataHistoryItem, len(dataHistory))
 	for i, d := range dataHistory {
-		price := ""
+		price, priceWei := "", ""
 		if d.Price.Valid {
-			price = d.Price.String
+			price, priceWei = utils.FormatWei(d.Price.String), d.Price.String
 		}
 		dataItems[i] = DataHistoryItem{
-			ID:          d.ID,
-			Timestamp:   d.TimeStamp,
-			BlockNumber: d.BlockNumber,
-			Tx:          d.Tx,
-			Image:       d.Image,
-			URL:         d.Url,
-			Price:       price,
-			UpdatedBy:   d.UpdatedBy,
+			ID:            d.ID,
+			Timestamp:     d.TimeStamp,
+			BlockNumber:   d.BlockNumber,
+			Tx:            d.Tx,
+			Image:         d.Image,
+			URL:           d.Url,
+			Price:         price,
+			PriceWei:      priceWei,
+			UpdatedBy:     d.UpdatedBy,
+			ChangeType:    d.ChangeType.String,
+			PreviousImage: d.PreviousImage.String,
+			PreviousURL:   d.PreviousUrl.String,
+		}
+		if d.PreviousPrice.Valid {
+			dataItems[i].PreviousPrice = utils.FormatWei(d.PreviousPrice.String)
+			dataItems[i].PreviousPriceWei = d.PreviousPrice.String
+		}
+		if d.PixelsChanged.Valid {
+			dataItems[i].PixelsChanged = &d.PixelsChanged.Int32
+		}
+		if d.DiffMinX.Valid {
+			dataItems[i].DiffBounds = []int32{d.DiffMinX.Int32, d.DiffMinY.Int32, d.DiffMaxX.Int32, d.DiffMaxY.Int32}
 		}
 	}
 	
@@ -251,7 +340,8 @@ func UpdateTileMetadata(tile db.Tile, dataHistory []db.DataHistory, queries *db.
 		ID:               int(tile.ID),
 		Image:            tile.Image,
 		URL:              tile.Url,
-		Price:            tile.Price,
+		Price:            utils.FormatWei(tile.Price),
+		PriceWei:         tile.Price,
 		Owner:            tile.Owner,
 		Wrapped:          tile.Wrapped,
 		OpenseaPrice:     tile.OpenseaPrice,
@@ -263,10 +353,10 @@ func UpdateTileMetadata(tile db.Tile, dataHistory []db.DataHistory, queries *db.
 		DataHistory:      dataItems,
 	}
 
-	if err := os.MkdirAll(filepath.Dir("cache/metadata/"), os.ModePerm); err != nil {
+	if err := os.MkdirAll(filepath.Join(network.CacheDir, "metadata"), os.ModePerm); err != nil {
 		return fmt.Errorf("error creating metadata directory: %w", err)
 	}
-	if err := os.WriteFile(fmt.Sprintf("cache/metadata/%d.json", tile.ID), jsonMetaData, 0644); err != nil {
+	if err := os.WriteFile(filepath.Join(network.CacheDir, "metadata", fmt.Sprintf("%d.json", tile.ID)), jsonMetaData, 0644); err != nil {
 		return fmt.Errorf("error writing metadata file: %w", err)
 	}
 
@@ -275,114 +365,16 @@ func UpdateTileMetadata(tile db.Tile, dataHistory []db.DataHistory, queries *db.
 		return fmt.Errorf("error marshaling pixel map tile: %w", err)
 	}
 
-	if err := os.MkdirAll(filepath.Dir("cache/tile/"), os.ModePerm); err != nil {
+	if err := os.MkdirAll(filepath.Join(network.CacheDir, "tile"), os.ModePerm); err != nil {
 		return fmt.Errorf("error creating tile directory: %w", err)
 	}
-	if err := os.WriteFile(fmt.Sprintf("cache/tile/%d.json", tile.ID), pixelMapTileJSON, 0644); err != nil {
+	if err := os.WriteFile(filepath.Join(network.CacheDir, "tile", fmt.Sprintf("%d.json", tile.ID)), pixelMapTileJSON, 0644); err != nil {
 		return fmt.Errorf("error writing tile file: %w", err)
 	}
 
 	return nil
 }
 
-// tileIsInCenter checks if a given tile number is in the center of the PixelMap
-func tileIsInCenter(tileNumber int) bool {
-	// Top Row
-	if tileNumber >= 1574 && tileNumber <= 1584 {
-		return true
-	}
-	// Second Row
-	if tileNumber >= 1655 && tileNumber <= 1665 {
-		return true
-	}
-	// Third Row
-	if tileNumber >= 1736 && tileNumber <= 1746 {
-		return true
-	}
-	// Fourth Row
-	if tileNumber >= 1817 && tileNumber <= 1827 {
-		return true
-	}
-	// Fifth Row
-	if tileNumber >= 1898 && tileNumber <= 1908 {
-		return true
-	}
-	// Sixth Row
-	if tileNumber >= 1979 && tileNumber <= 1989 {
-		return true
-	}
-	// Seventh Row
-	if tileNumber >= 2060 && tileNumber <= 2070 {
-		return true
-	}
-	// Eighth Row
-	if tileNumber >= 2141 && tileNumber <= 2151 {
-		return true
-	}
-	// Ninth Row
-	if tileNumber >= 2222 && tileNumber <= 2232 {
-		return true
-	}
-	// Tenth Row
-	if tileNumber >= 2303 && tileNumber <= 2313 {
-		return true
-	}
-	// Bottom Row
-	if tileNumber >= 2384 && tileNumber <= 2394 {
-		return true
-	}
-
-	// Clearly not in the center
-	return false
-}
-
-// tileIsOnEdge checks if a given tile number is on the edge of the PixelMap
-func tileIsOnEdge(tileNumber int) bool {
-	// Top Row
-	if tileNumber >= 0 && tileNumber <= 80 {
-		return true
-	}
-	// Bottom Row
-	if tileNumber >= 3888 && tileNumber <= 3969 {
-		return true
-	}
-
-	// Left Side
-	leftSide := []int{
-		81, 162, 243, 324, 405, 486, 567, 648, 729, 810, 891, 972, 1053, 1134, 1215, 1296, 1377, 1458, 1539, 1620, 1701,
-		1782, 1863, 1944, 2025, 2106, 2187, 2268, 2349, 2430, 2511, 2592, 2673, 2754, 2835, 2916, 2997, 3078, 3159, 3240,
-		3321, 3402, 3483, 3564, 3645, 3726, 3807, 3888, 3969,
-	}
-	for _, v := range leftSide {
-		if v == tileNumber {
-			return true
-		}
-	}
-
-	// Right Side
-	rightSide := []int{
-		80, 161, 242, 323, 404, 485, 566, 647, 728, 809, 890, 971, 1052, 1133, 1214, 1295, 1376, 1457, 1538, 1619, 1700,
-		1781, 1862, 1943, 2024, 2105, 2186, 2267, 2348, 2429, 2510, 2591, 2672, 2753, 2834, 2915, 2996, 3077, 3158, 3239,
-		3320, 3401, 3482, 3563, 3644, 3725, 3806, 3887, 3968,
-	}
-	for _, v := range rightSide {
-		if v == tileNumber {
-			return true
-		}
-	}
-
-	// Clearly not on the edge
-	return false
-}
-func contains(s []int, e int) bool {
-	for _, a := range s {
-		if a == e {
-			return true
-		}
-	}
-	return false
-}
-
 // PixelMapImage represents the structure of a historical image
 type PixelMapImage struct {
 	BlockNumber int64     `json:"blockNumber"`
@@ -392,7 +384,7 @@ type PixelMapImage struct {
 }
 
 // GetHistoricalImages processes the data history of a tile and returns unique historical images
-func GetHistoricalImages(tile db.Tile, dataHistory []db.DataHistory) []PixelMapImage {
+func GetHistoricalImages(network *Network, tile db.Tile, dataHistory []db.DataHistory) []PixelMapImage {
 	imagesAlreadySeen := make(map[string]bool)
 	var historicalImages []PixelMapImage
 
@@ -404,7 +396,7 @@ func GetHistoricalImages(tile db.Tile, dataHistory []db.DataHistory) []PixelMapI
 					BlockNumber: dh.BlockNumber,
 					Date:        dh.TimeStamp,
 					Image:       dh.Image,
-					ImageURL:    fmt.Sprintf("https://pixelmap.art/%d/%d.png", tile.ID, dh.BlockNumber),
+					ImageURL:    fmt.Sprintf("%s/%d/%d.png", network.assetBaseURL(), tile.ID, dh.BlockNumber),
 				})
 			}
 		}
@@ -38,7 +38,7 @@ func TestUpdateTileMetadataForTileZero(t *testing.T) {
 	}
 
 	// Execute
-	err := UpdateTileMetadata(tile, dataHistory, nil, context.Background())
+	err := UpdateTileMetadata(&Mainnet, tile, dataHistory, nil, context.Background())
 
 	// Assert
 	assert.NoError(t, err)
@@ -56,9 +56,12 @@ func TestUpdateTileMetadataForTileZero(t *testing.T) {
 	assert.Equal(t, "https://pixelmap.art/0/latest.png", openseaData["image"])
 
 	attributes := openseaData["attributes"].([]interface{})
-	assert.Len(t, attributes, 3)
-	assert.Contains(t, attributes, map[string]interface{}{"value": "Corner"})
-	assert.Contains(t, attributes, map[string]interface{}{"value": "OG"})
+	assert.Contains(t, attributes, map[string]interface{}{"trait_type": "Location", "value": "Edge"})
+	assert.Contains(t, attributes, map[string]interface{}{"trait_type": "Location", "value": "Corner"})
+	assert.Contains(t, attributes, map[string]interface{}{"trait_type": "Era", "value": "OG"})
+	assert.Contains(t, attributes, map[string]interface{}{"trait_type": "First Updated", "value": "2022"})
+	assert.Contains(t, attributes, map[string]interface{}{"trait_type": "Image Changes", "value": float64(1), "display_type": "number"})
+	assert.Contains(t, attributes, map[string]interface{}{"trait_type": "Dominant Color", "value": "#ffffff"})
 
 	// Cleanup
 	os.Remove("cache/metadata/0.json")
@@ -93,7 +96,7 @@ func TestUpdateTileMetadataForTileCenter(t *testing.T) {
 	}
 
 	// Execute
-	err := UpdateTileMetadata(tile, dataHistory, nil, context.Background())
+	err := UpdateTileMetadata(&Mainnet, tile, dataHistory, nil, context.Background())
 
 	// Assert
 	assert.NoError(t, err)
@@ -111,8 +114,9 @@ func TestUpdateTileMetadataForTileCenter(t *testing.T) {
 	assert.Equal(t, "https://pixelmap.art/blank.png", openseaData["image"])
 
 	attributes := openseaData["attributes"].([]interface{})
-	assert.Len(t, attributes, 2)
-	assert.Contains(t, attributes, map[string]interface{}{"value": "Center"})
+	assert.Contains(t, attributes, map[string]interface{}{"trait_type": "Location", "value": "Center"})
+	assert.Contains(t, attributes, map[string]interface{}{"trait_type": "Era", "value": "OG"})
+	assert.NotContains(t, attributes, map[string]interface{}{"trait_type": "Location", "value": "Edge"})
 
 	// Cleanup
 	// os.Remove("cache/metadata/1985.json")
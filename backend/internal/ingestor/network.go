@@ -0,0 +1,233 @@
+package ingestor
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Network describes one deployment of the PixelMap contracts that the indexer
+// can follow, along with where its data lives. Every profile gets its own
+// Postgres schema and cache directory so a testnet or devnet run can never
+// write into mainnet state.
+type Network struct {
+	Name               string
+	ChainID            int
+	PixelMapAddress    string
+	WrapperAddress     string
+	TimeCapsuleAddress string
+	CreatorAddress     string
+	StartBlock         int64
+	EtherscanURL       string
+	AssetBaseURL       string
+	Schema             string
+	CacheDir           string
+	S3Bucket           string
+	ResolveENS         bool
+	// Marketplaces are the exchange contracts whose transactions make a
+	// transfer a sale, see classifyTransfer.
+	Marketplaces []string
+}
+
+// Mainnet is the original 2016 deployment plus the 2021 wrapper and time
+// capsule.
+var Mainnet = Network{
+	Name:               "mainnet",
+	ChainID:            1,
+	PixelMapAddress:    "0x015a06a433353f8db634df4eddf0c109882a15ab",
+	WrapperAddress:     "0x050dc61dfb867e0fe3cf2948362b6c0f3faf790b",
+	TimeCapsuleAddress: "0x841d6ed6129390af55f015f80c0849535b36f0d6",
+	CreatorAddress:     "0x4f4b7e7edf5ec41235624ce207a6ef352aca7050",
+	StartBlock:         2641527,
+	EtherscanURL:       "https://api.etherscan.io/v2/api",
+	AssetBaseURL:       "https://pixelmap.art",
+	Schema:             "public",
+	CacheDir:           "cache",
+	S3Bucket:           "pixelmap.art",
+	ResolveENS:         true,
+	Marketplaces: []string{
+		"0x7be8076f4ea4a4ad08075c2508e481d6c946d12b", // OpenSea Wyvern v1
+		"0x7f268357a8c2552623316e2562d90e642bb538e5", // OpenSea Wyvern v2
+		"0x00000000006c3852cbef3e08e8df289169ede581", // Seaport 1.1
+		"0x00000000000001ad428e4906ae43d8f9852d0dd6", // Seaport 1.4
+		"0x00000000000000adc04c56bf30ac9d3c0aaf14dc", // Seaport 1.5
+		"0x0000000000000068f116a894984e2db1123eb395", // Seaport 1.6
+		"0x59728544b08ab483533076417fbbb2fd0b17ce3a", // LooksRare
+		"0x74312363e45dcaba76c59ec49a7aa8a65a67eed3", // X2Y2
+		"0x000000000000ad05ccc4f10045630fb830b95127", // Blur
+		"0xb2ecfe4e4d61f8790bbb9de2d1259b9e2410cea5", // Blur v2
+	},
+}
+
+// Sepolia has no canonical deployment, so the contract addresses, creator and
+// start block must be supplied through the environment.
+var Sepolia = Network{
+	Name:         "sepolia",
+	ChainID:      11155111,
+	EtherscanURL: "https://api.etherscan.io/v2/api",
+	AssetBaseURL: "https://sepolia.pixelmap.art",
+	Schema:       "sepolia",
+	CacheDir:     "cache-sepolia",
+	ResolveENS:   true,
+}
+
+// Devnet targets a local chain (anvil/hardhat) fronted by a Blockscout
+// instance, which serves the same Etherscan-style API the ingestor speaks.
+var Devnet = Network{
+	Name:         "devnet",
+	ChainID:      31337,
+	StartBlock:   0,
+	EtherscanURL: "http://localhost:4000/api",
+	AssetBaseURL: "http://localhost:3000/cache-devnet",
+	Schema:       "devnet",
+	CacheDir:     "cache-devnet",
+}
+
+var networks = map[string]Network{
+	Mainnet.Name: Mainnet,
+	Sepolia.Name: Sepolia,
+	Devnet.Name:  Devnet,
+}
+
+var schemaNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// LookupNetwork returns a copy of the named profile.
+func LookupNetwork(name string) (*Network, error) {
+	network, ok := networks[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown network %q", name)
+	}
+	return &network, nil
+}
+
+// NetworkFromEnv selects the profile named by NETWORK (mainnet when unset) and
+// applies any per-field overrides from the environment.
+func NetworkFromEnv() (*Network, error) {
+	name := os.Getenv("NETWORK")
+	if name == "" {
+		name = Mainnet.Name
+	}
+
+	network, err := LookupNetwork(name)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := map[string]*string{
+		"PIXELMAP_ADDRESS":    &network.PixelMapAddress,
+		"WRAPPER_ADDRESS":     &network.WrapperAddress,
+		"TIMECAPSULE_ADDRESS": &network.TimeCapsuleAddress,
+		"CREATOR_ADDRESS":     &network.CreatorAddress,
+		"ETHERSCAN_URL":       &network.EtherscanURL,
+		"ASSET_BASE_URL":      &network.AssetBaseURL,
+		"DATABASE_SCHEMA":     &network.Schema,
+		"CACHE_DIR":           &network.CacheDir,
+		"S3_BUCKET":           &network.S3Bucket,
+	}
+	for key, field := range overrides {
+		if value := os.Getenv(key); value != "" {
+			*field = value
+		}
+	}
+
+	if value := os.Getenv("MARKETPLACE_ADDRESSES"); value != "" {
+		network.Marketplaces = strings.Split(value, ",")
+	}
+	if value := os.Getenv("CHAIN_ID"); value != "" {
+		chainID, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CHAIN_ID %q: %w", value, err)
+		}
+		network.ChainID = chainID
+	}
+	if value := os.Getenv("START_BLOCK"); value != "" {
+		startBlock, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid START_BLOCK %q: %w", value, err)
+		}
+		network.StartBlock = startBlock
+	}
+
+	network.PixelMapAddress = strings.ToLower(network.PixelMapAddress)
+	network.WrapperAddress = strings.ToLower(network.WrapperAddress)
+	network.TimeCapsuleAddress = strings.ToLower(network.TimeCapsuleAddress)
+	network.CreatorAddress = strings.ToLower(network.CreatorAddress)
+	marketplaces := make([]string, len(network.Marketplaces))
+	for n, address := range network.Marketplaces {
+		marketplaces[n] = strings.ToLower(strings.TrimSpace(address))
+	}
+	network.Marketplaces = marketplaces
+
+	if err := network.Validate(); err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+// Validate checks that the profile has everything the ingestor needs.
+func (n *Network) Validate() error {
+	if !common.IsHexAddress(n.PixelMapAddress) {
+		return fmt.Errorf("network %s: PIXELMAP_ADDRESS is not set to a valid address", n.Name)
+	}
+	if n.WrapperAddress != "" && !common.IsHexAddress(n.WrapperAddress) {
+		return fmt.Errorf("network %s: WRAPPER_ADDRESS %q is not a valid address", n.Name, n.WrapperAddress)
+	}
+	if n.TimeCapsuleAddress != "" && !common.IsHexAddress(n.TimeCapsuleAddress) {
+		return fmt.Errorf("network %s: TIMECAPSULE_ADDRESS %q is not a valid address", n.Name, n.TimeCapsuleAddress)
+	}
+	if !common.IsHexAddress(n.CreatorAddress) {
+		return fmt.Errorf("network %s: CREATOR_ADDRESS is not set to a valid address", n.Name)
+	}
+	for _, address := range n.Marketplaces {
+		if !common.IsHexAddress(address) {
+			return fmt.Errorf("network %s: MARKETPLACE_ADDRESSES has invalid address %q", n.Name, address)
+		}
+	}
+	if n.EtherscanURL == "" {
+		return fmt.Errorf("network %s: ETHERSCAN_URL is not set", n.Name)
+	}
+	if !schemaNamePattern.MatchString(n.Schema) {
+		return fmt.Errorf("network %s: invalid database schema %q", n.Name, n.Schema)
+	}
+	if n.CacheDir == "" {
+		return fmt.Errorf("network %s: CACHE_DIR is not set", n.Name)
+	}
+	return nil
+}
+
+// IsPixelMap reports whether address is this network's OG PixelMap contract.
+func (n *Network) IsPixelMap(address string) bool {
+	return n.PixelMapAddress != "" && strings.EqualFold(address, n.PixelMapAddress)
+}
+
+// IsWrapper reports whether address is this network's PixelMapWrapper contract.
+func (n *Network) IsWrapper(address string) bool {
+	return n.WrapperAddress != "" && strings.EqualFold(address, n.WrapperAddress)
+}
+
+// IsMarketplace reports whether address is one of the network's known
+// marketplace contracts.
+func (n *Network) IsMarketplace(address string) bool {
+	for _, marketplace := range n.Marketplaces {
+		if strings.EqualFold(address, marketplace) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTimeCapsule reports whether address is this network's PixelMapTimeCapsule
+// contract.
+func (n *Network) IsTimeCapsule(address string) bool {
+	return n.TimeCapsuleAddress != "" && strings.EqualFold(address, n.TimeCapsuleAddress)
+}
+
+// assetBaseURL is the public URL rendered images and generated JSON are
+// served from, without a trailing slash.
+func (n *Network) assetBaseURL() string {
+	return strings.TrimRight(n.AssetBaseURL, "/")
+}
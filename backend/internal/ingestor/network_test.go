@@ -0,0 +1,71 @@
+package ingestor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkFromEnvDefaultsToMainnet(t *testing.T) {
+	t.Setenv("NETWORK", "")
+
+	network, err := NetworkFromEnv()
+	require.NoError(t, err)
+
+	assert.Equal(t, "mainnet", network.Name)
+	assert.Equal(t, 1, network.ChainID)
+	assert.Equal(t, int64(2641527), network.StartBlock)
+	assert.Equal(t, "public", network.Schema)
+	assert.Equal(t, "cache", network.CacheDir)
+}
+
+func TestNetworkFromEnvRequiresTestnetAddresses(t *testing.T) {
+	t.Setenv("NETWORK", "sepolia")
+
+	_, err := NetworkFromEnv()
+	assert.ErrorContains(t, err, "PIXELMAP_ADDRESS")
+}
+
+func TestNetworkFromEnvOverrides(t *testing.T) {
+	t.Setenv("NETWORK", "devnet")
+	t.Setenv("PIXELMAP_ADDRESS", "0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	t.Setenv("WRAPPER_ADDRESS", "0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+	t.Setenv("TIMECAPSULE_ADDRESS", "0x9fE46736679d2D9a65F0992F2272dE9f3c7fa6e0")
+	t.Setenv("CREATOR_ADDRESS", "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	t.Setenv("START_BLOCK", "12")
+	t.Setenv("MARKETPLACE_ADDRESSES", "0xDc64a140Aa3E981100a9becA4E685f962f0cF6C9, 0x5FC8d32690cc91D4c39d9d3abcBD16989F875707")
+
+	network, err := NetworkFromEnv()
+	require.NoError(t, err)
+
+	assert.Equal(t, 31337, network.ChainID)
+	assert.Equal(t, int64(12), network.StartBlock)
+	assert.Equal(t, "devnet", network.Schema)
+	assert.Equal(t, "0x5fbdb2315678afecb367f032d93f642f64180aa3", network.PixelMapAddress)
+	assert.Equal(t, "0x9fe46736679d2d9a65f0992f2272de9f3c7fa6e0", network.TimeCapsuleAddress)
+	assert.Equal(t, []string{"0xdc64a140aa3e981100a9beca4e685f962f0cf6c9", "0x5fc8d32690cc91d4c39d9d3abcbd16989f875707"}, network.Marketplaces)
+	assert.Empty(t, network.S3Bucket)
+	assert.False(t, network.ResolveENS)
+}
+
+func TestNetworkFromEnvRejectsUnknownProfile(t *testing.T) {
+	t.Setenv("NETWORK", "goerli")
+
+	_, err := NetworkFromEnv()
+	assert.ErrorContains(t, err, "unknown network")
+}
+
+func TestNetworkAddressMatchingIgnoresCase(t *testing.T) {
+	assert.True(t, Mainnet.IsPixelMap("0x015A06a433353f8db634dF4eDdF0C109882A15AB"))
+	assert.True(t, Mainnet.IsWrapper("0x050dc61dFB867E0fE3Cf2948362b6c0F3fAF790b"))
+	assert.False(t, Mainnet.IsWrapper("0x015A06a433353f8db634dF4eDdF0C109882A15AB"))
+	assert.True(t, Mainnet.IsMarketplace("0x00000000006c3852cbEf3e08E8dF289169EdE581"))
+	assert.False(t, Mainnet.IsMarketplace(Mainnet.WrapperAddress))
+	assert.True(t, Mainnet.IsTimeCapsule("0x841D6ED6129390aF55F015f80c0849535B36f0D6"))
+	assert.False(t, Mainnet.IsTimeCapsule("0x050dc61dFB867E0fE3Cf2948362b6c0F3fAF790b"))
+
+	var devnet = Devnet
+	assert.False(t, devnet.IsWrapper(""))
+	assert.False(t, devnet.IsTimeCapsule(""))
+}
@@ -0,0 +1,82 @@
+package ingestor
+
+import (
+	"database/sql"
+	"math/big"
+
+	"go.uber.org/zap"
+	utils "pixelmap.io/backend/internal/utils"
+)
+
+// buyTile only succeeds when msg.value equals the contract's price, which it
+// then sends on with owner.send: to the tile's owner, or to the creator for a
+// tile nobody has bought yet. The value of the call is what the buyer paid,
+// and the internal transactions Etherscan reports for it show who got the
+// ETH. The index's own asking price can be wrong where it missed a step, so
+// a purchase keeps both and flags when they differ.
+
+// salePayment returns what a buyTile paid and the change recording where the
+// ETH went. payee is who the contract pays for the tile: its owner on the
+// original contract, which is the wrapper for a tile it listed on unwrap.
+// Payouts are left NULL when the call carries no internal transactions.
+func (i *Ingestor) salePayment(call *Call, tileID int32, askingPrice, payee string) (string, UpdatePurchasePayment) {
+	paid := askingPrice
+	if value, ok := new(big.Int).SetString(call.Tx.Value, 10); ok {
+		paid = value.String()
+	}
+
+	payment := UpdatePurchasePayment{
+		TileID:        tileID,
+		Tx:            call.Tx.Hash,
+		AskingPrice:   sql.NullString{String: askingPrice, Valid: askingPrice != ""},
+		PriceMismatch: askingPrice != "" && paid != askingPrice,
+	}
+	if payment.PriceMismatch {
+		i.logger.Warn("Sale price differs from the recorded asking price",
+			zap.Int32("location", tileID),
+			zap.String("paid", paid),
+			zap.String("asking", askingPrice),
+			zap.String("tx", call.Tx.Hash))
+	}
+
+	if len(call.Tx.Internal) == 0 {
+		return paid, payment
+	}
+	sellerPayout, creatorFee := new(big.Int), new(big.Int)
+	for _, itx := range call.Tx.Internal {
+		if itx.IsError == "1" || utils.NormalizeAddress(itx.From) != i.network.PixelMapAddress {
+			continue
+		}
+		value, ok := new(big.Int).SetString(itx.Value, 10)
+		if !ok {
+			i.logger.Warn("Invalid internal transaction value",
+				zap.String("value", itx.Value),
+				zap.String("tx", call.Tx.Hash))
+			continue
+		}
+		switch to := utils.NormalizeAddress(itx.To); to {
+		case i.network.CreatorAddress:
+			creatorFee.Add(creatorFee, value)
+		case payee:
+			sellerPayout.Add(sellerPayout, value)
+		default:
+			i.logger.Warn("Sale paid an unexpected recipient",
+				zap.Int32("location", tileID),
+				zap.String("to", to),
+				zap.String("payee", payee),
+				zap.String("value", value.String()),
+				zap.String("tx", call.Tx.Hash))
+		}
+	}
+	payment.SellerPayout = sql.NullString{String: sellerPayout.String(), Valid: true}
+	payment.CreatorFee = sql.NullString{String: creatorFee.String(), Valid: true}
+
+	if forwarded := new(big.Int).Add(sellerPayout, creatorFee); forwarded.String() != paid {
+		i.logger.Warn("Sale payouts do not add up to the price paid",
+			zap.Int32("location", tileID),
+			zap.String("paid", paid),
+			zap.String("forwarded", forwarded.String()),
+			zap.String("tx", call.Tx.Hash))
+	}
+	return paid, payment
+}
@@ -0,0 +1,105 @@
+package ingestor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// Sale hashes in testdata/txlistinternal.json: an unclaimed tile bought from
+// the creator, a resale, and a wrap, which buys the tile through the wrapper.
+const (
+	unclaimedSaleHash = "0x8f0c2a1dbd5e0a8d8b7c1ff7e4a2f6b0c3f4d1e2a3b4c5d6e7f8091a2b3c4d5e"
+	resaleHash        = "0x1d7b3e5a9c2f4e6d8b0a1c3e5f7d9b2a4c6e8f0a1b3d5f7e9c2a4b6d8f0e1c3a"
+	wrapHash          = "0x6c4e2a0b8d6f4a2c0e8b6d4f2a0c8e6b4d2f0a8c6e4b2d0f8a6c4e2b0d8f6a4c"
+	resaleSeller      = "0x2a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b"
+)
+
+func fixtureServer(t *testing.T, action, path string) *httptest.Server {
+	body, err := os.ReadFile(path)
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, action, r.URL.Query().Get("action"))
+		assert.Equal(t, Mainnet.PixelMapAddress, r.URL.Query().Get("address"))
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSalePaymentFromInternalTransactions(t *testing.T) {
+	network := Mainnet
+	network.EtherscanURL = fixtureServer(t, "txlistinternal", "testdata/txlistinternal.json").URL + "/api"
+	client := NewEtherscanClientForNetwork("test_api_key", &network, zap.NewNop())
+
+	internal, err := client.GetInternalTransactions(context.Background(), 2646321, 13087413)
+	require.NoError(t, err)
+	require.Len(t, internal, 4)
+
+	transactions := []EtherscanTransaction{
+		{Hash: unclaimedSaleHash, To: network.PixelMapAddress, Value: "2000000000000000000"},
+		{Hash: resaleHash, To: network.PixelMapAddress, Value: "500000000000000000"},
+		{Hash: wrapHash, To: network.WrapperAddress, Value: "1000000000000000000"},
+		{Hash: wrapHash, To: network.WrapperAddress, Value: "0", LogIndex: "12"},
+	}
+	attachInternalTransactions(transactions, internal)
+	assert.Len(t, transactions[0].Internal, 1)
+	assert.Len(t, transactions[1].Internal, 1)
+	assert.Len(t, transactions[2].Internal, 2)
+	assert.Empty(t, transactions[3].Internal, "events are left without the call's transfers")
+
+	i := &Ingestor{logger: zap.NewNop(), network: &network}
+
+	t.Run("unclaimed tile", func(t *testing.T) {
+		paid, payment := i.salePayment(&Call{Tx: &transactions[0]}, 5, unclaimedPriceWei, network.CreatorAddress)
+		assert.Equal(t, "2000000000000000000", paid)
+		assert.Equal(t, "2000000000000000000", payment.CreatorFee.String)
+		assert.Equal(t, "0", payment.SellerPayout.String)
+		assert.False(t, payment.PriceMismatch)
+	})
+
+	t.Run("resale at a stale asking price", func(t *testing.T) {
+		paid, payment := i.salePayment(&Call{Tx: &transactions[1]}, 7, "1000000000000000000", resaleSeller)
+		assert.Equal(t, "500000000000000000", paid, "the price is what was paid")
+		assert.Equal(t, "1000000000000000000", payment.AskingPrice.String)
+		assert.Equal(t, "500000000000000000", payment.SellerPayout.String)
+		assert.Equal(t, "0", payment.CreatorFee.String)
+		assert.True(t, payment.PriceMismatch)
+	})
+
+	t.Run("wrapper payments are not payouts", func(t *testing.T) {
+		// The wrapper paying the original contract is not from it, so only
+		// what the contract forwarded to the owner counts.
+		_, payment := i.salePayment(&Call{Tx: &transactions[2]}, 9, "1000000000000000000", "0x5e8f7a6b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f")
+		assert.Equal(t, "1000000000000000000", payment.SellerPayout.String)
+		assert.False(t, payment.PriceMismatch)
+	})
+
+	t.Run("no internal transactions", func(t *testing.T) {
+		paid, payment := i.salePayment(&Call{Tx: &EtherscanTransaction{Hash: "0x01", Value: "3000000000000000000"}}, 3, "3000000000000000000", resaleSeller)
+		assert.Equal(t, "3000000000000000000", paid)
+		assert.False(t, payment.SellerPayout.Valid, "unknown until the transfers are fetched")
+		assert.False(t, payment.CreatorFee.Valid)
+	})
+}
+
+func TestGetInternalTransactionsEmpty(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"0","message":"No transactions found","result":[]}`))
+	}))
+	defer server.Close()
+
+	network := Mainnet
+	network.EtherscanURL = server.URL + "/api"
+	client := NewEtherscanClientForNetwork("test_api_key", &network, zap.NewNop())
+
+	internal, err := client.GetInternalTransactions(context.Background(), 1, 2)
+	require.NoError(t, err)
+	assert.Empty(t, internal)
+}
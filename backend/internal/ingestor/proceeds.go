@@ -0,0 +1,122 @@
+package ingestor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"go.uber.org/zap"
+	db "pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/proceeds"
+)
+
+// Unwrapping does not hand a tile back: the wrapper lists it on the original
+// contract at the unwrapper's sale price and keeps a pendingLocationSales
+// entry for them. Whoever buys the tile through buyTile pays the wrapper,
+// and the unwrapper collects the ETH with withdrawETH. The wrapper_pending_sales
+// view follows that entry; these helpers build the change recording each step.
+
+// wrapperListing records the listing unwrap makes. A later unwrap of the
+// same tile replaces it, as it does on chain.
+func (i *Ingestor) wrapperListing(call *Call, tileID int32, salePrice *big.Int) InsertWrapperProceeds {
+	return InsertWrapperProceeds{
+		TimeStamp:   call.TimeStamp,
+		BlockNumber: call.BlockNumber,
+		Tx:          call.Tx.Hash,
+		LogIndex:    call.TransactionIndex,
+		Event:       proceeds.Listed,
+		Seller:      call.Tx.From,
+		Amount:      salePrice.String(),
+		TileID:      tileID,
+	}
+}
+
+// openWrapperListing returns the wrapper's listing of a tile if buying the
+// tile now buys it from the wrapper, or nil.
+func (i *Ingestor) openWrapperListing(ctx context.Context, tileID int32) (*db.WrapperPendingSale, error) {
+	listing, err := i.queries.GetWrapperPendingSale(ctx, tileID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wrapper listing: %w", err)
+	}
+	// A listing at 0 cannot be bought; one already sold left the wrapper.
+	if listing.SoldTx.Valid || listing.SalePrice == "0" {
+		return nil, nil
+	}
+	return &listing, nil
+}
+
+// wrapperSale records the buyTile that sold a wrapper listing. The purchase
+// itself is moved to the wrapper market by handleBuyTile.
+func (i *Ingestor) wrapperSale(listing *db.WrapperPendingSale, call *Call) InsertWrapperProceeds {
+	i.logger.Info("Wrapper listing sold",
+		zap.Int32("location", listing.TileID),
+		zap.String("seller", listing.Seller),
+		zap.String("buyer", call.Tx.From),
+		zap.String("amount", listing.SalePrice),
+		zap.String("tx", call.Tx.Hash))
+	return InsertWrapperProceeds{
+		TimeStamp:   call.TimeStamp,
+		BlockNumber: call.BlockNumber,
+		Tx:          call.Tx.Hash,
+		LogIndex:    call.TransactionIndex,
+		Event:       proceeds.Sold,
+		Seller:      listing.Seller,
+		Buyer:       sql.NullString{String: call.Tx.From, Valid: true},
+		Amount:      listing.SalePrice,
+		TileID:      listing.TileID,
+	}
+}
+
+// handleWithdrawETH records withdrawETH paying out the proceeds of a tile's
+// last wrapper sale.
+func (i *Ingestor) handleWithdrawETH(ctx context.Context, call *Call) (*ChangeSet, error) {
+	args, err := decode[wrapperLocationArgs](call)
+	if err != nil {
+		return nil, err
+	}
+	tileID := int32(args.Location.Int64())
+	tx := call.Tx
+
+	pending, err := i.queries.GetWrapperPendingSale(ctx, tileID)
+	if errors.Is(err, sql.ErrNoRows) {
+		i.logger.Warn("withdrawETH without a wrapper listing",
+			zap.Int32("location", tileID),
+			zap.String("tx", tx.Hash))
+		return &ChangeSet{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wrapper listing: %w", err)
+	}
+	// The contract reverts these, so a successful call means the index
+	// missed a step.
+	if !pending.SoldTx.Valid || pending.WithdrawnTx.Valid || pending.Seller != tx.From {
+		i.logger.Warn("withdrawETH does not match the recorded wrapper sale",
+			zap.Int32("location", tileID),
+			zap.String("seller", pending.Seller),
+			zap.String("from", tx.From),
+			zap.Bool("sold", pending.SoldTx.Valid),
+			zap.Bool("withdrawn", pending.WithdrawnTx.Valid),
+			zap.String("tx", tx.Hash))
+	}
+
+	i.logger.Info("Wrapper proceeds withdrawn",
+		zap.Int32("location", tileID),
+		zap.String("seller", tx.From),
+		zap.String("amount", pending.SalePrice),
+		zap.String("tx", tx.Hash))
+	return (&ChangeSet{}).Add(InsertWrapperProceeds{
+		TimeStamp:   call.TimeStamp,
+		BlockNumber: call.BlockNumber,
+		Tx:          tx.Hash,
+		LogIndex:    call.TransactionIndex,
+		Event:       proceeds.Withdrawn,
+		Seller:      tx.From,
+		Amount:      pending.SalePrice,
+		TileID:      tileID,
+	}), nil
+}
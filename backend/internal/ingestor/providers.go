@@ -0,0 +1,56 @@
+package ingestor
+
+import (
+	"os"
+	"strconv"
+
+	"pixelmap.io/backend/internal/ratebudget"
+)
+
+const (
+	// Etherscan's free tier allows 3 req/sec. 300ms (~3.3/sec) sat just over
+	// the cap and periodically tripped "NOTOK / Max calls per sec"; 2/sec per
+	// key stays comfortably under even with burst alignment.
+	etherscanRatePerSecond = 2
+	// Free tier daily allowance per key.
+	etherscanDailyQuota = 100000
+	// Blockscout's public instances allow more, but they are shared.
+	blockscoutRatePerSecond = 5
+)
+
+// EtherscanProviders returns the API budget for network: its Etherscan-style
+// endpoint with every key in apiKeys (comma separated), plus a
+// Blockscout-compatible fallback when BLOCKSCOUT_URL is set. The fallback does
+// not take module=proxy calls, which Blockscout does not serve.
+//
+// ETHERSCAN_DAILY_QUOTA overrides the per-key daily quota (0 for unlimited);
+// BLOCKSCOUT_API_KEYS supplies optional Blockscout keys.
+func EtherscanProviders(network *Network, apiKeys string) []ratebudget.Provider {
+	dailyQuota := etherscanDailyQuota
+	if value := os.Getenv("ETHERSCAN_DAILY_QUOTA"); value != "" {
+		if quota, err := strconv.Atoi(value); err == nil && quota >= 0 {
+			dailyQuota = quota
+		}
+	}
+
+	providers := []ratebudget.Provider{{
+		Name:          ratebudget.Host(network.EtherscanURL),
+		BaseURL:       network.EtherscanURL,
+		Keys:          ratebudget.SplitKeys(apiKeys),
+		RatePerSecond: etherscanRatePerSecond,
+		DailyQuota:    dailyQuota,
+		ChainID:       network.ChainID,
+		Proxy:         true,
+	}}
+
+	if blockscoutURL := os.Getenv("BLOCKSCOUT_URL"); blockscoutURL != "" && blockscoutURL != network.EtherscanURL {
+		providers = append(providers, ratebudget.Provider{
+			Name:          ratebudget.Host(blockscoutURL),
+			BaseURL:       blockscoutURL,
+			Keys:          ratebudget.SplitKeys(os.Getenv("BLOCKSCOUT_API_KEYS")),
+			RatePerSecond: blockscoutRatePerSecond,
+		})
+	}
+
+	return providers
+}
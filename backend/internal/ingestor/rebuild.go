@@ -0,0 +1,430 @@
+package ingestor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	db "pixelmap.io/backend/internal/db"
+)
+
+// rebuiltTable is a table the ingestor derives from the chain, compared
+// before and after a rebuild.
+type rebuiltTable struct {
+	name string
+	// serial tables number their rows, which a rebuild renumbers, so the
+	// id is left out of the comparison.
+	serial bool
+	// history tables record each row's transaction in tx.
+	history bool
+}
+
+// rebuiltTables are the tables Rebuild recomputes. tiles is reset to the
+// unclaimed state rather than truncated, since tile_repairs and render_jobs
+// refer to it. time_capsule_transfers is kept: capsule Transfer events were
+// not archived before migration 016, and replaying the newer ones leaves
+// their rows as they are.
+var rebuiltTables = []rebuiltTable{
+	{name: "tiles"},
+	{name: "data_histories", serial: true, history: true},
+	{name: "purchase_histories", serial: true, history: true},
+	{name: "transfer_histories", serial: true, history: true},
+	{name: "wrapping_histories", serial: true, history: true},
+	{name: "wrapper_proceeds_histories", serial: true, history: true},
+	{name: "time_capsule_mints", serial: true, history: true},
+	{name: "time_capsule_transfers", serial: true, history: true},
+	{name: "time_capsule_metadata_changes", serial: true, history: true},
+	{name: "wrapper_approvals", serial: true, history: true},
+	{name: "wrapper_operator_approvals", serial: true, history: true},
+	{name: "contract_admin_changes", serial: true, history: true},
+	{name: "tile_update_inputs", history: true},
+	{name: "current_state"},
+}
+
+// rebuildCarryOvers copy what ingestion looked up on the network, and the
+// archive can't tell, from the old state into the rebuilt one: ENS names of
+// owners and updaters, OpenSea prices, and the type of transfers whose
+// transaction went to a contract that isn't followed.
+var rebuildCarryOvers = []string{
+	`UPDATE tiles t
+SET ens = o.ens, opensea_price = o.opensea_price
+FROM rebuild_old_tiles o
+WHERE o.id = t.id AND o.owner = t.owner`,
+	`UPDATE data_histories d
+SET updated_by = o.updated_by
+FROM rebuild_old_data_histories o
+WHERE o.tile_id = d.tile_id AND o.tx = d.tx`,
+	`UPDATE transfer_histories t
+SET transfer_type = o.transfer_type
+FROM rebuild_old_transfer_histories o
+WHERE t.transfer_type IS NULL AND o.tile_id = t.tile_id AND o.tx = t.tx`,
+}
+
+const (
+	rebuildPageSize    = 1000
+	rebuildDiffSamples = 5
+)
+
+// ErrRebuildLosesHistory is returned by Rebuild when committing would drop
+// history rows the archive has nothing to replay from.
+var ErrRebuildLosesHistory = errors.New("rebuild drops history the archive can't reproduce")
+
+// RebuildOptions controls what Rebuild may commit.
+type RebuildOptions struct {
+	// AllowLoss commits a rebuild even when it drops history rows whose
+	// transaction it produced nothing for, such as the rows whose archived
+	// call or event was overwritten before migration 016.
+	AllowLoss bool
+}
+
+// TableDiff is how one table differs after a rebuild: the rows only the old
+// state had and the rows only the rebuilt state has, with a few of each.
+// Lost counts the removed rows of a history table whose transaction has no
+// row at all after the rebuild, which the archive could not reproduce.
+type TableDiff struct {
+	Table       string            `json:"table"`
+	Before      int               `json:"before"`
+	After       int               `json:"after"`
+	Removed     int               `json:"removed"`
+	Added       int               `json:"added"`
+	Lost        int               `json:"lost"`
+	RemovedRows []json.RawMessage `json:"removed_rows,omitempty"`
+	AddedRows   []json.RawMessage `json:"added_rows,omitempty"`
+}
+
+// RebuildReport summarizes a rebuild.
+type RebuildReport struct {
+	Transactions int         `json:"transactions"`
+	Skipped      int         `json:"skipped"`
+	LastBlock    int64       `json:"last_block"`
+	Tables       []TableDiff `json:"tables"`
+	Committed    bool        `json:"committed"`
+}
+
+// Changed reports whether any table differs from before the rebuild.
+func (r *RebuildReport) Changed() bool {
+	for _, table := range r.Tables {
+		if table.Removed > 0 || table.Added > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Lost counts the history rows the rebuild drops without reproducing.
+func (r *RebuildReport) Lost() int {
+	lost := 0
+	for _, table := range r.Tables {
+		lost += table.Lost
+	}
+	return lost
+}
+
+// WriteText prints the sample rows of each table that differs, removed ones
+// with - and added ones with +, a line per table and a summary.
+func (r *RebuildReport) WriteText(w io.Writer) error {
+	changed := 0
+	for _, table := range r.Tables {
+		if table.Removed == 0 && table.Added == 0 {
+			continue
+		}
+		changed++
+		for _, row := range table.RemovedRows {
+			if _, err := fmt.Fprintf(w, "- %s %s\n", table.Table, row); err != nil {
+				return err
+			}
+		}
+		for _, row := range table.AddedRows {
+			if _, err := fmt.Fprintf(w, "+ %s %s\n", table.Table, row); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s: %d rows before, %d after, %d removed, %d added",
+			table.Table, table.Before, table.After, table.Removed, table.Added); err != nil {
+			return err
+		}
+		if table.Lost > 0 {
+			if _, err := fmt.Fprintf(w, ", %d not in the archive", table.Lost); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "replayed %d transactions (%d skipped) up to block %d: %d of %d tables differ\n",
+		r.Transactions, r.Skipped, r.LastBlock, changed, len(r.Tables))
+	return err
+}
+
+// Rebuild recomputes the tables the ingestor derives from the chain out of
+// pixel_map_transaction and pixel_map_internal_transaction alone. It clears
+// them, replays every archived call and event through the handlers in chain
+// order without Etherscan, an Ethereum client or notifications, and compares
+// the result with what was there before. Everything runs in one transaction,
+// holding the ingestion lock so a running ingestor waits:
+// confirm is given the report and the rebuilt state is committed only when it
+// returns true, and, unless opts allow it, nothing is lost. The ingestion
+// cursor is kept where it was, and the replayed tiles are queued for
+// rendering.
+func Rebuild(ctx context.Context, logger *zap.Logger, conn *sql.DB, network *Network, opts RebuildOptions, confirm func(*RebuildReport) (bool, error)) (*RebuildReport, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin rebuild: %w", err)
+	}
+	defer tx.Rollback()
+
+	i := newIngestor(logger, db.New(tx), nil, network)
+
+	// Ingestion takes the lock shared for each call it applies, so it waits
+	// until the rebuild commits or rolls back.
+	if err := i.queries.LockIngestion(ctx); err != nil {
+		return nil, fmt.Errorf("failed to lock ingestion: %w", err)
+	}
+
+	for _, table := range rebuiltTables {
+		if _, err := tx.ExecContext(ctx, "CREATE TEMP TABLE rebuild_old_"+table.name+" ON COMMIT DROP AS SELECT * FROM "+table.name); err != nil {
+			return nil, fmt.Errorf("failed to snapshot %s: %w", table.name, err)
+		}
+	}
+	lastProcessedBlock, err := i.queries.GetLastProcessedBlock(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get last processed block: %w", err)
+	}
+
+	if err := i.queries.TruncateDerivedTables(ctx); err != nil {
+		return nil, fmt.Errorf("failed to truncate derived tables: %w", err)
+	}
+	reset, err := i.queries.ResetTiles(ctx, db.ResetTilesParams{
+		Price: unclaimedPriceWei,
+		Owner: network.CreatorAddress,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reset tiles: %w", err)
+	}
+	if reset == 0 {
+		if err := i.initializeTiles(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &RebuildReport{}
+	if err := i.replayArchive(ctx, report); err != nil {
+		return report, err
+	}
+
+	for _, statement := range rebuildCarryOvers {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return report, fmt.Errorf("failed to carry over network lookups: %w", err)
+		}
+	}
+	if lastProcessedBlock > 0 {
+		if err := i.updateLastProcessedBlock(ctx, lastProcessedBlock); err != nil {
+			return report, err
+		}
+	}
+
+	for _, table := range rebuiltTables {
+		diff, err := diffRebuiltTable(ctx, tx, table)
+		if err != nil {
+			return report, err
+		}
+		report.Tables = append(report.Tables, diff)
+	}
+
+	ok, err := confirm(report)
+	if err != nil || !ok {
+		return report, err
+	}
+	if lost := report.Lost(); lost > 0 && !opts.AllowLoss {
+		return report, fmt.Errorf("%w: %d rows", ErrRebuildLosesHistory, lost)
+	}
+	if err := tx.Commit(); err != nil {
+		return report, fmt.Errorf("failed to commit rebuild: %w", err)
+	}
+	report.Committed = true
+	return report, nil
+}
+
+// replayArchive applies the archived transactions page by page in chain
+// order, as ingestion applied them.
+func (i *Ingestor) replayArchive(ctx context.Context, report *RebuildReport) error {
+	after := db.ListArchivedTransactionsParams{AfterBlock: -1, PageSize: rebuildPageSize}
+	// Calls by hash, for the Transfer events they emitted. An event comes
+	// right after its call, so only the current block's are kept.
+	calls := make(map[string]EtherscanTransaction)
+	var block int64
+	for {
+		rows, err := i.queries.ListArchivedTransactions(ctx, after)
+		if err != nil {
+			return fmt.Errorf("failed to list archived transactions: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		transactions := make([]EtherscanTransaction, len(rows))
+		var hashes []string
+		for n, row := range rows {
+			transactions[n] = archivedTransaction(row)
+			if row.LogIndex < 0 {
+				hashes = append(hashes, row.Hash)
+			}
+		}
+		internal, err := i.queries.ListArchivedInternalTransactions(ctx, hashes)
+		if err != nil {
+			return fmt.Errorf("failed to list archived internal transactions: %w", err)
+		}
+		byHash := make(map[string][]EtherscanInternalTransaction)
+		for _, itx := range internal {
+			byHash[itx.Hash] = append(byHash[itx.Hash], archivedInternalTransaction(itx))
+		}
+
+		for n := range transactions {
+			tx := &transactions[n]
+			if rows[n].BlockNumber != block {
+				block = rows[n].BlockNumber
+				clear(calls)
+			}
+			if tx.LogIndex == "" {
+				tx.Internal = byHash[tx.Hash]
+				calls[strings.ToLower(tx.Hash)] = *tx
+			} else if call, ok := calls[strings.ToLower(tx.Hash)]; ok {
+				tx.Outer = &call
+			}
+		}
+
+		last := rows[len(rows)-1]
+		skipped, err := i.applyTransactions(ctx, transactions, last.BlockNumber)
+		if err != nil {
+			return err
+		}
+		report.Transactions += len(rows)
+		report.Skipped += skipped
+		report.LastBlock = last.BlockNumber
+		after.AfterBlock, after.AfterIndex, after.AfterLogIndex = last.BlockNumber, last.TransactionIndex, last.LogIndex
+
+		i.logger.Info("Replayed archived transactions",
+			zap.Int("transactions", report.Transactions),
+			zap.Int64("block", last.BlockNumber))
+	}
+}
+
+// archivedTransaction turns an archived row back into the transaction
+// Etherscan returned, so replaying it takes the path ingesting it took.
+func archivedTransaction(row db.PixelMapTransaction) EtherscanTransaction {
+	tx := EtherscanTransaction{
+		BlockNumber:       strconv.FormatInt(row.BlockNumber, 10),
+		TimeStamp:         archivedUnix(row.TimeStamp),
+		Hash:              row.Hash,
+		Nonce:             strconv.FormatInt(row.Nonce, 10),
+		BlockHash:         row.BlockHash,
+		TransactionIndex:  strconv.FormatInt(int64(row.TransactionIndex), 10),
+		From:              row.From,
+		To:                row.To,
+		Value:             row.Value,
+		Gas:               strconv.FormatInt(row.Gas, 10),
+		GasPrice:          strconv.FormatInt(row.GasPrice, 10),
+		IsError:           etherscanFlag(row.IsError),
+		TxreceiptStatus:   etherscanFlag(row.TxreceiptStatus.Bool),
+		Input:             row.Input,
+		ContractAddress:   row.ContractAddress,
+		CumulativeGasUsed: strconv.FormatInt(row.CumulativeGasUsed, 10),
+		GasUsed:           strconv.FormatInt(row.GasUsed, 10),
+		Confirmations:     strconv.FormatInt(row.Confirmations, 10),
+	}
+	if row.LogIndex >= 0 {
+		// getLogs reports the transaction index in hex.
+		tx.TransactionIndex = "0x" + strconv.FormatInt(int64(row.TransactionIndex), 16)
+		tx.LogIndex = strconv.FormatInt(int64(row.LogIndex), 10)
+	}
+	return tx
+}
+
+func archivedInternalTransaction(row db.PixelMapInternalTransaction) EtherscanInternalTransaction {
+	return EtherscanInternalTransaction{
+		BlockNumber: strconv.FormatInt(row.BlockNumber, 10),
+		TimeStamp:   archivedUnix(row.TimeStamp),
+		Hash:        row.Hash,
+		From:        row.From,
+		To:          row.To,
+		Value:       row.Value,
+		TraceID:     row.TraceID,
+		IsError:     etherscanFlag(row.IsError),
+	}
+}
+
+// archivedUnix reads back a time_stamp written from time.Unix: the column has
+// no zone, so Postgres kept the local wall clock.
+func archivedUnix(t time.Time) string {
+	local := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local)
+	return strconv.FormatInt(local.Unix(), 10)
+}
+
+// etherscanFlag formats a boolean as Etherscan does.
+func etherscanFlag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// diffRebuiltTable compares a table with its snapshot from before the
+// rebuild.
+func diffRebuiltTable(ctx context.Context, tx *sql.Tx, table rebuiltTable) (TableDiff, error) {
+	diff := TableDiff{Table: table.name}
+	old := "rebuild_old_" + table.name
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT (SELECT COUNT(*) FROM %s), (SELECT COUNT(*) FROM %s)", old, table.name)).
+		Scan(&diff.Before, &diff.After); err != nil {
+		return diff, fmt.Errorf("failed to count %s: %w", table.name, err)
+	}
+
+	row := "to_jsonb(t)"
+	if table.serial {
+		row += " - 'id'"
+	}
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT 'removed', r FROM (SELECT %[1]s AS r FROM %[2]s t EXCEPT ALL SELECT %[1]s FROM %[3]s t) d
+UNION ALL
+SELECT 'added', r FROM (SELECT %[1]s AS r FROM %[3]s t EXCEPT ALL SELECT %[1]s FROM %[2]s t) d
+ORDER BY 1 DESC, 2`, row, old, table.name))
+	if err != nil {
+		return diff, fmt.Errorf("failed to compare %s: %w", table.name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var change string
+		var contents []byte
+		if err := rows.Scan(&change, &contents); err != nil {
+			return diff, fmt.Errorf("failed to compare %s: %w", table.name, err)
+		}
+		if change == "removed" {
+			diff.Removed++
+			if len(diff.RemovedRows) < rebuildDiffSamples {
+				diff.RemovedRows = append(diff.RemovedRows, json.RawMessage(contents))
+			}
+		} else {
+			diff.Added++
+			if len(diff.AddedRows) < rebuildDiffSamples {
+				diff.AddedRows = append(diff.AddedRows, json.RawMessage(contents))
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return diff, fmt.Errorf("failed to compare %s: %w", table.name, err)
+	}
+
+	if table.history && diff.Removed > 0 {
+		if err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM (SELECT %[1]s AS r FROM %[2]s t EXCEPT ALL SELECT %[1]s FROM %[3]s t) d
+WHERE NOT EXISTS (SELECT 1 FROM %[3]s n WHERE n.tx = d.r->>'tx')`, row, old, table.name)).Scan(&diff.Lost); err != nil {
+			return diff, fmt.Errorf("failed to count lost %s: %w", table.name, err)
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,190 @@
+package ingestor
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "pixelmap.io/backend/internal/db"
+)
+
+func TestArchivedTransactionReplaysAsFetched(t *testing.T) {
+	row := db.PixelMapTransaction{
+		BlockNumber:      12,
+		TimeStamp:        time.Unix(1700000000, 0),
+		Hash:             "0x01",
+		Nonce:            7,
+		TransactionIndex: 26,
+		From:             alice,
+		Value:            "0",
+		TxreceiptStatus:  sql.NullBool{Bool: true, Valid: true},
+		LogIndex:         7,
+	}
+
+	event := archivedTransaction(row)
+	assert.Equal(t, "0x1a", event.TransactionIndex, "as getLogs reports it")
+	assert.Equal(t, "7", event.LogIndex)
+	assert.Equal(t, "1700000000", event.TimeStamp)
+	assert.Equal(t, "0", event.IsError)
+	assert.Equal(t, "1", event.TxreceiptStatus)
+	assert.Equal(t, [3]int64{12, 26, 7}, transactionOrderKey(event))
+
+	row.LogIndex = -1
+	call := archivedTransaction(row)
+	assert.Equal(t, "26", call.TransactionIndex)
+	assert.Empty(t, call.LogIndex)
+	assert.Equal(t, [3]int64{12, 26, -1}, transactionOrderKey(call))
+}
+
+func TestHarnessRebuildMatchesIngest(t *testing.T) {
+	h := newIngestHarness(t)
+	const carol = "0x90f79bf6eb2c4f870365e785982e1f101e93b906"
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 9, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 9, Image: redTile, URL: "https://example.com", Price: ether(1)},
+		scriptStep{Method: "wrap", From: alice, Location: 9, Value: ether(1)},
+		scriptStep{Method: "approve", From: alice, To: bob, Location: 9},
+		scriptStep{Method: "transfer", From: alice, To: bob, Location: 9},
+		scriptStep{Method: "unwrap", From: bob, Location: 9, Price: ether(3)},
+		scriptStep{Method: "buyTile", From: carol, Location: 9, Value: ether(3)},
+		scriptStep{Method: "withdrawETH", From: bob, Location: 9},
+		scriptStep{Method: "mintCapsules", From: alice, Location: 2},
+		scriptStep{Method: "transferCapsule", From: alice, To: bob, Location: 1},
+	)
+	h.Sync()
+	before := h.Tile(9)
+	lastProcessed, err := h.queries.GetLastProcessedBlock(h.ctx)
+	require.NoError(t, err)
+
+	report, err := Rebuild(h.ctx, h.ingestor.logger, h.conn, h.network, RebuildOptions{}, func(*RebuildReport) (bool, error) {
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.True(t, report.Committed)
+	for _, table := range report.Tables {
+		assert.Zero(t, table.Removed+table.Added, "%s: -%s +%s", table.Table, table.RemovedRows, table.AddedRows)
+	}
+	assert.False(t, report.Changed())
+	assert.Positive(t, report.Transactions)
+
+	require.Equal(t, before, h.Tile(9))
+	require.True(t, strings.EqualFold(carol, before.Owner), "owner = %s", before.Owner)
+	last, err := h.queries.GetLastProcessedBlock(h.ctx)
+	require.NoError(t, err)
+	require.Equal(t, lastProcessed, last, "ingestion resumes where it stopped")
+}
+
+func TestHarnessRebuildReportsDifferences(t *testing.T) {
+	h := newIngestHarness(t)
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 7, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 7, Image: redTile},
+		scriptStep{Method: "buyTile", From: bob, Location: 8, Value: ether(2)},
+	)
+	h.Sync()
+
+	_, err := h.conn.ExecContext(h.ctx, "UPDATE tiles SET url = 'https://stale.example' WHERE id = 7")
+	require.NoError(t, err)
+	_, err = h.conn.ExecContext(h.ctx, "DELETE FROM purchase_histories WHERE tile_id = 8")
+	require.NoError(t, err)
+
+	diffs := func(report *RebuildReport) map[string][2]int {
+		changed := map[string][2]int{}
+		for _, table := range report.Tables {
+			if table.Removed > 0 || table.Added > 0 {
+				changed[table.Table] = [2]int{table.Removed, table.Added}
+			}
+		}
+		return changed
+	}
+
+	report, err := Rebuild(h.ctx, h.ingestor.logger, h.conn, h.network, RebuildOptions{}, func(*RebuildReport) (bool, error) {
+		return false, nil
+	})
+	require.NoError(t, err)
+	require.False(t, report.Committed)
+	require.Equal(t, map[string][2]int{"tiles": {1, 1}, "purchase_histories": {0, 1}}, diffs(report))
+	require.Contains(t, string(report.Tables[0].RemovedRows[0]), "https://stale.example")
+	require.Equal(t, "https://stale.example", h.Tile(7).Url, "declining rolls the rebuild back")
+	require.Zero(t, h.Rows("purchase_histories", 8))
+
+	report, err = Rebuild(h.ctx, h.ingestor.logger, h.conn, h.network, RebuildOptions{}, func(*RebuildReport) (bool, error) {
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.True(t, report.Committed)
+	require.Empty(t, h.Tile(7).Url)
+	require.Equal(t, 1, h.Rows("purchase_histories", 8))
+
+	var text strings.Builder
+	require.NoError(t, report.WriteText(&text))
+	require.Contains(t, text.String(), "purchase_histories: 1 rows before, 2 after, 0 removed, 1 added")
+}
+
+func TestHarnessRebuildRefusesToLoseHistory(t *testing.T) {
+	h := newIngestHarness(t)
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 7, Value: ether(2)},
+		scriptStep{Method: "buyTile", From: bob, Location: 8, Value: ether(2)},
+	)
+	h.Sync()
+
+	// As when an event overwrote its call's archive row before migration 016.
+	purchases, err := h.queries.GetPurchaseHistoryByTileId(h.ctx, 8)
+	require.NoError(t, err)
+	require.Len(t, purchases, 1)
+	_, err = h.conn.ExecContext(h.ctx, "DELETE FROM pixel_map_transaction WHERE hash = $1", purchases[0].Tx)
+	require.NoError(t, err)
+
+	rebuild := func(opts RebuildOptions) (*RebuildReport, error) {
+		return Rebuild(h.ctx, h.ingestor.logger, h.conn, h.network, opts, func(*RebuildReport) (bool, error) {
+			return true, nil
+		})
+	}
+
+	report, err := rebuild(RebuildOptions{})
+	require.ErrorIs(t, err, ErrRebuildLosesHistory)
+	require.False(t, report.Committed)
+	require.Equal(t, 1, report.Lost())
+	require.Equal(t, 1, h.Rows("purchase_histories", 8), "the purchase is kept")
+
+	var text strings.Builder
+	require.NoError(t, report.WriteText(&text))
+	require.Contains(t, text.String(), "purchase_histories: 2 rows before, 1 after, 1 removed, 0 added, 1 not in the archive")
+
+	report, err = rebuild(RebuildOptions{AllowLoss: true})
+	require.NoError(t, err)
+	require.True(t, report.Committed)
+	require.Zero(t, h.Rows("purchase_histories", 8))
+}
+
+func TestHarnessRebuildLocksOutIngestion(t *testing.T) {
+	h := newIngestHarness(t)
+
+	h.chain.Replay(scriptStep{Method: "buyTile", From: alice, Location: 7, Value: ether(2)})
+	h.Sync()
+	h.chain.Replay(scriptStep{Method: "buyTile", From: bob, Location: 8, Value: ether(2)})
+
+	ingested := make(chan error, 1)
+	report, err := Rebuild(h.ctx, h.ingestor.logger, h.conn, h.network, RebuildOptions{}, func(*RebuildReport) (bool, error) {
+		go func() { ingested <- h.ingestor.IngestTransactions(h.ctx) }()
+		select {
+		case err := <-ingested:
+			return false, fmt.Errorf("ingested during the rebuild: %v", err)
+		case <-time.After(500 * time.Millisecond):
+			return true, nil
+		}
+	})
+	require.NoError(t, err)
+	require.True(t, report.Committed)
+
+	require.NoError(t, <-ingested, "ingestion goes on once the rebuild commits")
+	require.Equal(t, 1, h.Rows("purchase_histories", 8))
+}
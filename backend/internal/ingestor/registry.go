@@ -0,0 +1,197 @@
+package ingestor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	pixelmap "pixelmap.io/backend/internal/contracts/pixelmap"
+	pixelmapWrapper "pixelmap.io/backend/internal/contracts/pixelmapWrapper"
+	"pixelmap.io/backend/internal/contracts/timecapsule"
+)
+
+// Call is a contract call, or a Transfer event converted to one, decoded
+// against its contract's ABI.
+type Call struct {
+	Tx          *EtherscanTransaction
+	Contract    string // the registered contract's name
+	Method      *abi.Method
+	Args        []interface{}
+	TimeStamp   time.Time
+	BlockNumber int64
+	// TransactionIndex is the call's position in its block, which the
+	// history tables store as their log_index.
+	TransactionIndex int32
+}
+
+// IsEvent reports whether the call was converted from a Transfer event.
+func (c *Call) IsEvent() bool {
+	return c.Tx.LogIndex != ""
+}
+
+// Handler turns one method's calls into the changes they make. Handlers may
+// read the index to work out those changes but never write it; the returned
+// ChangeSet is applied by processTransaction.
+type Handler func(ctx context.Context, call *Call) (*ChangeSet, error)
+
+// decode copies a call's arguments into a struct whose fields are tagged
+// with the ABI argument names, or hold them in order.
+func decode[T any](call *Call) (T, error) {
+	var args T
+	if err := call.Method.Inputs.Copy(&args, call.Args); err != nil {
+		return args, fmt.Errorf("failed to decode %s arguments: %w", call.Method.Name, err)
+	}
+	return args, nil
+}
+
+// contract is one deployment the registry knows the ABI of.
+type contract struct {
+	name    string
+	abi     *abi.ABI
+	address string
+}
+
+type handlerKey struct {
+	address  string
+	selector [4]byte
+}
+
+// Registry maps a contract address and method selector to the handler for
+// it. Addresses are matched case-insensitively.
+type Registry struct {
+	contracts map[string]*contract
+	handlers  map[handlerKey]Handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		contracts: make(map[string]*contract),
+		handlers:  make(map[handlerKey]Handler),
+	}
+}
+
+// AddContract registers a deployment under name. An empty address is
+// ignored, so optional contracts can be added unconditionally.
+func (r *Registry) AddContract(name, address string, contractABI *abi.ABI) {
+	if address == "" {
+		return
+	}
+	address = strings.ToLower(address)
+	r.contracts[address] = &contract{name: name, abi: contractABI, address: address}
+}
+
+// Handle registers handler for the named methods of the contract at address.
+// Overloads are named as in go-ethereum: safeTransferFrom, safeTransferFrom0.
+func (r *Registry) Handle(address string, handler Handler, methods ...string) error {
+	if address == "" {
+		return nil
+	}
+	c, ok := r.contracts[strings.ToLower(address)]
+	if !ok {
+		return fmt.Errorf("no contract registered at %s", address)
+	}
+	for _, name := range methods {
+		method, ok := c.abi.Methods[name]
+		if !ok {
+			return fmt.Errorf("%s has no method %s", c.name, name)
+		}
+		r.handlers[handlerKey{address: c.address, selector: [4]byte(method.ID)}] = handler
+	}
+	return nil
+}
+
+// Contract returns the name of the contract registered at address, or "".
+func (r *Registry) Contract(address string) string {
+	if c, ok := r.contracts[strings.ToLower(address)]; ok {
+		return c.name
+	}
+	return ""
+}
+
+// Lookup decodes input against the contract at address and returns the call's
+// method, arguments and handler. The handler is nil when the selector is
+// unknown to the ABI or has no handler registered; method is then nil too if
+// the ABI does not know it.
+func (r *Registry) Lookup(address, input string) (*abi.Method, []interface{}, Handler, error) {
+	c, ok := r.contracts[strings.ToLower(address)]
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("no contract registered at %s", address)
+	}
+	data := common.FromHex(input)
+	if len(data) < 4 {
+		return nil, nil, nil, fmt.Errorf("input too short to contain a selector")
+	}
+
+	method, err := c.abi.MethodById(data[:4])
+	if err != nil {
+		return nil, nil, nil, nil
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return method, nil, nil, fmt.Errorf("failed to unpack %s inputs: %w", method.Name, err)
+	}
+	return method, args, r.handlers[handlerKey{address: c.address, selector: [4]byte(data[:4])}], nil
+}
+
+// Contract names, used in logs and the unhandled calls metric.
+const (
+	contractPixelMap    = "pixelmap"
+	contractWrapper     = "wrapper"
+	contractTimeCapsule = "timecapsule"
+)
+
+// newRegistry registers the network's contracts and the ingestor's handlers
+// for them.
+func (i *Ingestor) newRegistry() (*Registry, error) {
+	pixelMapABI, err := pixelmap.PixelMapMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	wrapperABI, err := pixelmapWrapper.PixelMapWrapperMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	capsuleABI, err := timecapsule.PixelMapTimeCapsuleMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	r := NewRegistry()
+	r.AddContract(contractPixelMap, i.network.PixelMapAddress, pixelMapABI)
+	r.AddContract(contractWrapper, i.network.WrapperAddress, wrapperABI)
+	r.AddContract(contractTimeCapsule, i.network.TimeCapsuleAddress, capsuleABI)
+
+	pixelMap, wrapper, capsule := i.network.PixelMapAddress, i.network.WrapperAddress, i.network.TimeCapsuleAddress
+	for _, h := range []struct {
+		address string
+		handler Handler
+		methods []string
+	}{
+		{pixelMap, i.handleBuyTile, []string{"buyTile"}},
+		{pixelMap, i.handleSetTile, []string{"setTile"}},
+		{pixelMap, i.handleNothing, []string{"getTile"}},
+
+		{wrapper, i.handleSetTileData, []string{"setTileData"}},
+		{wrapper, i.handleWrap, []string{"wrap"}},
+		{wrapper, i.handleUnwrap, []string{"unwrap"}},
+		{wrapper, i.handleWithdrawETH, []string{"withdrawETH"}},
+		{wrapper, i.handleTileTransfer, []string{"transferFrom", "safeTransferFrom", "safeTransferFrom0"}},
+		{wrapper, i.handleApprove, []string{"approve"}},
+		{wrapper, i.handleSetApprovalForAll, []string{"setApprovalForAll"}},
+		{wrapper, i.handleAdminChange, []string{"setBaseTokenURI", "setBasecontractURI", "setTokenExtension", "transferOwnership", "renounceOwnership"}},
+
+		{capsule, i.handleTimeCapsuleMint, []string{"MultiMintOwner"}},
+		{capsule, i.handleTimeCapsuleMetadata, []string{"setBaseTokenURI", "setContractURI", "lockMetadata"}},
+		{capsule, i.handleTimeCapsuleTransfer, []string{"transferFrom", "safeTransferFrom", "safeTransferFrom0"}},
+		{capsule, i.handleAdminChange, []string{"transferOwnership", "renounceOwnership"}},
+		{capsule, i.handleNothing, []string{"approve", "setApprovalForAll"}},
+	} {
+		if err := r.Handle(h.address, h.handler, h.methods...); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
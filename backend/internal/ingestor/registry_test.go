@@ -0,0 +1,207 @@
+package ingestor
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	pixelmapWrapper "pixelmap.io/backend/internal/contracts/pixelmapWrapper"
+	"pixelmap.io/backend/internal/contracts/timecapsule"
+)
+
+// registryCall looks up tx the way processTransaction does and returns the
+// Call its handler would get.
+func registryCall(t *testing.T, r *Registry, tx *EtherscanTransaction) (*Call, Handler) {
+	method, args, handler, err := r.Lookup(tx.To, tx.Input)
+	require.NoError(t, err)
+	require.NotNil(t, handler)
+	return &Call{Tx: tx, Contract: r.Contract(tx.To), Method: method, Args: args, TransactionIndex: 3}, handler
+}
+
+func packInput(t *testing.T, contractABI *abi.ABI, method string, args ...interface{}) string {
+	input, err := contractABI.Pack(method, args...)
+	require.NoError(t, err)
+	return "0x" + common.Bytes2Hex(input)
+}
+
+func TestRegistryDispatch(t *testing.T) {
+	network := Mainnet
+	i := &Ingestor{logger: zap.NewNop(), network: &network}
+	r, err := i.newRegistry()
+	require.NoError(t, err)
+
+	assert.Equal(t, contractPixelMap, r.Contract(strings.ToUpper(network.PixelMapAddress)))
+	assert.Equal(t, contractWrapper, r.Contract(network.WrapperAddress))
+	assert.Equal(t, contractTimeCapsule, r.Contract(network.TimeCapsuleAddress))
+	assert.Equal(t, "", r.Contract("0x0000000000000000000000000000000000000001"))
+
+	wrapperABI, err := pixelmapWrapper.PixelMapWrapperMetaData.GetAbi()
+	require.NoError(t, err)
+
+	// A selector the ABI does not know has neither method nor handler.
+	method, _, handler, err := r.Lookup(network.WrapperAddress, "0xdeadbeef")
+	require.NoError(t, err)
+	assert.Nil(t, method)
+	assert.Nil(t, handler)
+
+	_, _, _, err = r.Lookup("0x0000000000000000000000000000000000000001", "0xdeadbeef")
+	assert.Error(t, err)
+
+	t.Run("wrap", func(t *testing.T) {
+		call, handler := registryCall(t, r, &EtherscanTransaction{
+			Hash:  "0x01",
+			From:  alice,
+			To:    strings.ToUpper(network.WrapperAddress),
+			Input: packInput(t, wrapperABI, "wrap", big.NewInt(42)),
+		})
+		assert.Equal(t, "wrap", call.Method.Name)
+
+		changes, err := handler(context.Background(), call)
+		require.NoError(t, err)
+		require.Len(t, changes.Changes, 3)
+		assert.Equal(t, InsertWrapping{TileID: 42, Wrapped: true, Tx: "0x01", UpdatedBy: alice, LogIndex: 3}, changes.Changes[0])
+		assert.Equal(t, UpdateWrapped{ID: 42, Wrapped: true}, changes.Changes[1])
+		assert.Equal(t, UpdateTilePrice{ID: 42, Price: "0"}, changes.Changes[2])
+	})
+
+	t.Run("unwrap", func(t *testing.T) {
+		call, handler := registryCall(t, r, &EtherscanTransaction{
+			Hash:  "0x02",
+			From:  alice,
+			To:    network.WrapperAddress,
+			Input: packInput(t, wrapperABI, "unwrap", big.NewInt(42), ether(3)),
+		})
+
+		changes, err := handler(context.Background(), call)
+		require.NoError(t, err)
+		require.Len(t, changes.Changes, 4)
+		assert.Equal(t, UpdateWrapped{ID: 42, Wrapped: false}, changes.Changes[1])
+		assert.Equal(t, UpdateTilePrice{ID: 42, Price: ether(3).String()}, changes.Changes[2])
+	})
+
+	t.Run("wrapper transfer", func(t *testing.T) {
+		call, handler := registryCall(t, r, &EtherscanTransaction{
+			Hash:  "0x03",
+			From:  alice,
+			To:    network.WrapperAddress,
+			Input: packInput(t, wrapperABI, "safeTransferFrom", common.HexToAddress(alice), common.HexToAddress(bob), big.NewInt(42)),
+		})
+		assert.Equal(t, "safeTransferFrom", call.Method.Name)
+
+		changes, err := handler(context.Background(), call)
+		require.NoError(t, err)
+		require.Len(t, changes.Changes, 2)
+		transfer := changes.Changes[0].(InsertTransfer)
+		assert.Equal(t, alice, transfer.TransferredFrom)
+		assert.Equal(t, bob, transfer.TransferredTo)
+		assert.Equal(t, UpdateTileOwner{ID: 42, Owner: bob}, changes.Changes[1])
+	})
+
+	t.Run("approvals", func(t *testing.T) {
+		call, handler := registryCall(t, r, &EtherscanTransaction{
+			Hash:  "0x07",
+			From:  alice,
+			To:    network.WrapperAddress,
+			Input: packInput(t, wrapperABI, "approve", common.HexToAddress(bob), big.NewInt(42)),
+		})
+		changes, err := handler(context.Background(), call)
+		require.NoError(t, err)
+		assert.Equal(t, []Change{InsertWrapperApproval{Tx: "0x07", LogIndex: 3, TileID: 42, Approved: bob, ApprovedBy: alice}}, changes.Changes)
+
+		call, handler = registryCall(t, r, &EtherscanTransaction{
+			Hash:  "0x08",
+			From:  alice,
+			To:    network.WrapperAddress,
+			Input: packInput(t, wrapperABI, "setApprovalForAll", common.HexToAddress(bob), false),
+		})
+		changes, err = handler(context.Background(), call)
+		require.NoError(t, err)
+		assert.Equal(t, []Change{InsertWrapperOperatorApproval{Tx: "0x08", LogIndex: 3, Owner: alice, Operator: bob, Approved: false}}, changes.Changes)
+	})
+
+	t.Run("admin changes", func(t *testing.T) {
+		call, handler := registryCall(t, r, &EtherscanTransaction{
+			Hash:  "0x09",
+			From:  alice,
+			To:    network.WrapperAddress,
+			Input: packInput(t, wrapperABI, "setTokenExtension", ".json"),
+		})
+		changes, err := handler(context.Background(), call)
+		require.NoError(t, err)
+		change := changes.Changes[0].(InsertContractAdminChange)
+		assert.Equal(t, contractWrapper, change.Contract)
+		assert.Equal(t, "setTokenExtension", change.Action)
+		assert.Equal(t, ".json", change.Value.String)
+		assert.Equal(t, alice, change.ChangedBy)
+
+		call, handler = registryCall(t, r, &EtherscanTransaction{
+			Hash:  "0x0a",
+			From:  alice,
+			To:    network.WrapperAddress,
+			Input: packInput(t, wrapperABI, "transferOwnership", common.HexToAddress(strings.ToUpper(bob))),
+		})
+		changes, err = handler(context.Background(), call)
+		require.NoError(t, err)
+		assert.Equal(t, bob, changes.Changes[0].(InsertContractAdminChange).Value.String, "new owners are normalized")
+	})
+
+	capsuleABI, err := timecapsule.PixelMapTimeCapsuleMetaData.GetAbi()
+	require.NoError(t, err)
+
+	t.Run("time capsule ownership", func(t *testing.T) {
+		call, handler := registryCall(t, r, &EtherscanTransaction{Hash: "0x0b", From: alice, To: network.TimeCapsuleAddress, Input: packInput(t, capsuleABI, "renounceOwnership")})
+		changes, err := handler(context.Background(), call)
+		require.NoError(t, err)
+		change := changes.Changes[0].(InsertContractAdminChange)
+		assert.Equal(t, contractTimeCapsule, change.Contract)
+		assert.Equal(t, "renounceOwnership", change.Action)
+		assert.False(t, change.Value.Valid)
+	})
+
+	t.Run("time capsule transfer event", func(t *testing.T) {
+		transfer := packInput(t, capsuleABI, "safeTransferFrom", common.HexToAddress(alice), common.HexToAddress(bob), big.NewInt(7))
+
+		call, handler := registryCall(t, r, &EtherscanTransaction{Hash: "0x04", To: network.TimeCapsuleAddress, Input: transfer})
+		changes, err := handler(context.Background(), call)
+		require.NoError(t, err)
+		assert.Empty(t, changes.Changes, "direct calls are left to their events")
+
+		call, handler = registryCall(t, r, &EtherscanTransaction{Hash: "0x04", To: network.TimeCapsuleAddress, Input: transfer, LogIndex: "5"})
+		changes, err = handler(context.Background(), call)
+		require.NoError(t, err)
+		require.Len(t, changes.Changes, 1)
+		assert.Equal(t, int32(5), changes.Changes[0].(InsertTimeCapsuleTransfer).LogIndex)
+	})
+
+	t.Run("time capsule metadata", func(t *testing.T) {
+		call, handler := registryCall(t, r, &EtherscanTransaction{Hash: "0x05", From: alice, To: network.TimeCapsuleAddress, Input: packInput(t, capsuleABI, "lockMetadata")})
+		changes, err := handler(context.Background(), call)
+		require.NoError(t, err)
+		change := changes.Changes[0].(InsertTimeCapsuleMetadataChange)
+		assert.Equal(t, "lockMetadata", change.Action)
+		assert.False(t, change.Value.Valid)
+
+		call, handler = registryCall(t, r, &EtherscanTransaction{Hash: "0x06", From: alice, To: network.TimeCapsuleAddress, Input: packInput(t, capsuleABI, "setBaseTokenURI", "ipfs://cid/")})
+		changes, err = handler(context.Background(), call)
+		require.NoError(t, err)
+		change = changes.Changes[0].(InsertTimeCapsuleMetadataChange)
+		assert.Equal(t, "ipfs://cid/", change.Value.String)
+	})
+}
+
+func TestRegistryHandleUnknownMethod(t *testing.T) {
+	wrapperABI, err := pixelmapWrapper.PixelMapWrapperMetaData.GetAbi()
+	require.NoError(t, err)
+
+	r := NewRegistry()
+	r.AddContract(contractWrapper, "0xABC", wrapperABI)
+	assert.Error(t, r.Handle("0xabc", nil, "buyTile"))
+	assert.Error(t, r.Handle("0xdef", nil, "wrap"))
+	assert.NoError(t, r.Handle("", nil, "wrap"), "optional contracts are skipped")
+}
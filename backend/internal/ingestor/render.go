@@ -0,0 +1,307 @@
+package ingestor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	db "pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/metrics"
+	utils "pixelmap.io/backend/internal/utils"
+)
+
+const (
+	defaultRenderWorkers = 4
+	// Attempts before a render job is left as failed. Retries back off from
+	// renderRetryBase up to renderRetryMax.
+	maxRenderAttempts = 5
+	renderRetryBase   = 10 * time.Second
+	renderRetryMax    = 10 * time.Minute
+)
+
+// Rendering is driven by the render_jobs table. Ingestion enqueues a job for
+// every tile whose image changes; a tile updated again before its job runs
+// keeps the one pending job with a later block. A pool of renderWorkers claims
+// jobs, each rendering one tile's block images, latest.png and metadata.
+// Completed jobs wake the map worker, which regenerates tilemap.png and
+// tiledata.json once updates have been quiet for mapDebounce (or at most
+// every mapMaxDelay while they keep coming).
+
+// renderWorker claims and renders jobs until ctx is cancelled, waiting for
+// renderSignal or the poll interval when the queue is empty.
+func (i *Ingestor) renderWorker(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// A claim that commits must reach runRenderJob, so it isn't cancelled.
+		job, err := i.queries.ClaimRenderJob(context.WithoutCancel(ctx))
+		if errors.Is(err, sql.ErrNoRows) {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-i.renderSignal:
+			case <-time.After(i.renderPollInterval):
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to claim render job: %w", err)
+		}
+
+		// Wake another worker in case more jobs are waiting.
+		i.signalNewData()
+		i.runRenderJob(context.WithoutCancel(ctx), job)
+	}
+}
+
+// runRenderJob renders the job's tile and records the outcome. It returns the
+// render error, if any, after the job has been scheduled for retry or marked
+// failed.
+func (i *Ingestor) runRenderJob(ctx context.Context, job db.RenderJob) error {
+	timer := prometheus.NewTimer(metrics.RenderJobDuration)
+	renderErr := i.renderTile(ctx, job.TileID, job.BlockNumber)
+	timer.ObserveDuration()
+
+	var err error
+	switch {
+	case renderErr == nil:
+		metrics.RenderAttempts.WithLabelValues("ok").Inc()
+		err = i.queries.CompleteRenderJob(ctx, job.ID)
+		i.signalMap()
+	case job.Attempts >= maxRenderAttempts:
+		metrics.RenderAttempts.WithLabelValues("failed").Inc()
+		i.logger.Error("Render job failed, giving up",
+			zap.Int32("tileID", job.TileID),
+			zap.Int64("block", job.BlockNumber),
+			zap.Int32("attempts", job.Attempts),
+			zap.Error(renderErr))
+		err = i.queries.FailRenderJob(ctx, db.FailRenderJobParams{ID: job.ID, LastError: renderErr.Error()})
+	default:
+		metrics.RenderAttempts.WithLabelValues("retry").Inc()
+		delay := renderRetryDelay(job.Attempts)
+		i.logger.Warn("Render job failed, retrying",
+			zap.Int32("tileID", job.TileID),
+			zap.Int64("block", job.BlockNumber),
+			zap.Int32("attempts", job.Attempts),
+			zap.Duration("delay", delay),
+			zap.Error(renderErr))
+		err = i.queries.RetryRenderJob(ctx, db.RetryRenderJobParams{
+			ID:           job.ID,
+			LastError:    renderErr.Error(),
+			DelaySeconds: delay.Seconds(),
+		})
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			// A job for the tile was enqueued since the retry checked for
+			// one; it covers this attempt.
+			err = i.queries.SupersedeRenderJob(ctx, db.SupersedeRenderJobParams{ID: job.ID, LastError: renderErr.Error()})
+		}
+	}
+	if err != nil {
+		i.logger.Error("Failed to record render job result", zap.Int32("jobID", job.ID), zap.Error(err))
+	}
+
+	i.updateRenderQueueMetrics(ctx)
+	return renderErr
+}
+
+func renderRetryDelay(attempts int32) time.Duration {
+	delay := renderRetryBase
+	for n := int32(1); n < attempts && delay < renderRetryMax; n++ {
+		delay *= 2
+	}
+	if delay > renderRetryMax {
+		delay = renderRetryMax
+	}
+	return delay
+}
+
+// renderTile renders the tile's image history up to block: any missing
+// per-block images, latest.png from the newest image at or before block, and
+// the tile's metadata JSON. Images and URLs too long for the index are taken
+// whole from tile_update_inputs.
+func (i *Ingestor) renderTile(ctx context.Context, tileID int32, block int64) error {
+	history, err := i.queries.GetDataHistoryByTileId(ctx, tileID)
+	if err != nil {
+		return fmt.Errorf("failed to get data history: %w", err)
+	}
+	tile, err := i.queries.GetTileById(ctx, tileID)
+	if err != nil {
+		return fmt.Errorf("failed to get tile data: %w", err)
+	}
+	if err := PreferTileInputs(ctx, i.queries, &tile, history); err != nil {
+		return err
+	}
+
+	var latest *db.DataHistory
+	for n := range history {
+		row := &history[n]
+		if row.BlockNumber <= block && (latest == nil || row.ID > latest.ID) {
+			latest = row
+		}
+	}
+
+	location := big.NewInt(int64(tileID))
+	for _, row := range history {
+		if latest == nil || row.BlockNumber > block || row.ID == latest.ID {
+			continue
+		}
+		path := filepath.Join(i.network.CacheDir, location.String(), fmt.Sprintf("%d.png", row.BlockNumber))
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := utils.RenderImage(row.Image, imageSize, imageSize, path); err != nil {
+			// Same as renderAndSaveImage: an undecodable historical image
+			// shouldn't block the tile.
+			i.logger.Error("Failed to render block image", zap.Error(err), zap.String("path", path))
+		}
+	}
+
+	if latest != nil {
+		if err := i.renderAndSaveImage(location, latest.Image, latest.BlockNumber); err != nil {
+			return fmt.Errorf("failed to render and save image: %w", err)
+		}
+	}
+
+	if err := UpdateTileMetadata(i.network, tile, history, i.queries, ctx); err != nil {
+		return fmt.Errorf("failed to update metadata: %w", err)
+	}
+	return nil
+}
+
+// mapWorker regenerates the full map and tiledata.json after renders, once
+// they have been quiet for mapDebounce. Renders finished before shutdown are
+// still reflected: Run stops the render workers before this one.
+func (i *Ingestor) mapWorker(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			select {
+			case <-i.mapSignal:
+				i.regenerateMapLogged(context.WithoutCancel(ctx))
+			default:
+			}
+			return ctx.Err()
+		case <-i.mapSignal:
+		}
+
+		quiet := time.NewTimer(i.mapDebounce)
+		deadline := time.NewTimer(i.mapMaxDelay)
+	debounce:
+		for {
+			select {
+			case <-i.mapSignal:
+				if !quiet.Stop() {
+					<-quiet.C
+				}
+				quiet.Reset(i.mapDebounce)
+			case <-quiet.C:
+				break debounce
+			case <-deadline.C:
+				break debounce
+			case <-ctx.Done():
+				break debounce
+			}
+		}
+		quiet.Stop()
+		deadline.Stop()
+
+		i.regenerateMapLogged(context.WithoutCancel(ctx))
+	}
+}
+
+func (i *Ingestor) regenerateMapLogged(ctx context.Context) {
+	if err := i.regenerateMap(ctx); err != nil {
+		i.logger.Error("Failed to regenerate map", zap.Error(err))
+	}
+}
+
+// regenerateMap redraws tilemap.png and tiledata.json from the database and
+// requests an S3 sync.
+func (i *Ingestor) regenerateMap(ctx context.Context) error {
+	timer := prometheus.NewTimer(metrics.MapRegenerationDuration)
+	defer timer.ObserveDuration()
+
+	tiles, err := i.getLatestTileImages(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest tile images: %w", err)
+	}
+	utils.RenderFullMap(tiles, filepath.Join(i.network.CacheDir, "tilemap.png"))
+
+	return i.updateTileDataAndSync(ctx)
+}
+
+// drainRenderQueue renders every job that is due, one at a time, and then
+// regenerates the map if anything was rendered. It returns the number of jobs
+// completed and the first render error.
+func (i *Ingestor) drainRenderQueue(ctx context.Context) (int, error) {
+	var firstErr error
+	rendered := 0
+	for ctx.Err() == nil {
+		job, err := i.queries.ClaimRenderJob(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+		if err != nil {
+			return rendered, fmt.Errorf("failed to claim render job: %w", err)
+		}
+
+		if err := i.runRenderJob(ctx, job); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		rendered++
+	}
+
+	if rendered > 0 {
+		// Drop the wake-up meant for mapWorker; the map is current.
+		select {
+		case <-i.mapSignal:
+		default:
+		}
+		if err := i.regenerateMap(ctx); err != nil {
+			return rendered, err
+		}
+	}
+	return rendered, firstErr
+}
+
+func (i *Ingestor) signalMap() {
+	select {
+	case i.mapSignal <- struct{}{}:
+	default:
+	}
+}
+
+func (i *Ingestor) updateRenderQueueMetrics(ctx context.Context) {
+	counts, err := i.queries.CountRenderJobsByStatus(ctx)
+	if err != nil {
+		i.logger.Warn("Failed to count render jobs", zap.Error(err))
+		return
+	}
+
+	depth := 0.0
+	metrics.RenderJobs.Reset()
+	for _, c := range counts {
+		metrics.RenderJobs.WithLabelValues(c.Status).Set(float64(c.Count))
+		if c.Status == "pending" || c.Status == "running" {
+			depth += float64(c.Count)
+		}
+	}
+	metrics.RenderQueueDepth.Set(depth)
+}
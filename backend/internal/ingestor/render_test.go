@@ -0,0 +1,152 @@
+package ingestor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "pixelmap.io/backend/internal/db"
+)
+
+func TestRenderRetryDelay(t *testing.T) {
+	assert.Equal(t, 10*time.Second, renderRetryDelay(1))
+	assert.Equal(t, 20*time.Second, renderRetryDelay(2))
+	assert.Equal(t, 80*time.Second, renderRetryDelay(4))
+	assert.Equal(t, renderRetryMax, renderRetryDelay(20))
+}
+
+func (h *ingestHarness) RenderJobs(tileID int32) []db.RenderJob {
+	h.t.Helper()
+	rows, err := h.conn.QueryContext(h.ctx, `SELECT id, block_number, status, attempts, last_error FROM render_jobs WHERE tile_id = $1 ORDER BY id`, tileID)
+	require.NoError(h.t, err)
+	defer rows.Close()
+
+	var jobs []db.RenderJob
+	for rows.Next() {
+		job := db.RenderJob{TileID: tileID}
+		require.NoError(h.t, rows.Scan(&job.ID, &job.BlockNumber, &job.Status, &job.Attempts, &job.LastError))
+		jobs = append(jobs, job)
+	}
+	require.NoError(h.t, rows.Err())
+	return jobs
+}
+
+func TestRenderJobsCollapsePerTile(t *testing.T) {
+	h := newIngestHarness(t)
+
+	h.chain.Replay(scriptStep{Method: "buyTile", From: alice, Location: 5, Value: ether(2)})
+	var blocks []int64
+	for _, color := range []string{"f00", "0f0", "00f"} {
+		h.chain.Replay(scriptStep{Method: "setTile", From: alice, Location: 5, Image: strings.Repeat(color, 256)})
+		blocks = append(blocks, h.chain.head)
+	}
+	require.NoError(t, h.ingestor.IngestTransactions(h.ctx))
+
+	jobs := h.RenderJobs(5)
+	require.Len(t, jobs, 1, "three updates collapse into one job")
+	assert.Equal(t, "pending", jobs[0].Status)
+	assert.Equal(t, blocks[2], jobs[0].BlockNumber)
+
+	rendered, err := h.ingestor.drainRenderQueue(h.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rendered)
+
+	for _, block := range blocks {
+		h.RequireFile("5", fmt.Sprintf("%d.png", block))
+	}
+	h.RequireFile("5", "latest.png")
+	h.RequireFile("tilemap.png")
+	h.RequireFile("tiledata.json")
+	assert.Equal(t, "done", h.RenderJobs(5)[0].Status)
+
+	// A later update gets a new job rather than reopening the finished one.
+	h.chain.Replay(scriptStep{Method: "setTile", From: alice, Location: 5, Image: redTile})
+	require.NoError(t, h.ingestor.IngestTransactions(h.ctx))
+	jobs = h.RenderJobs(5)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "pending", jobs[1].Status)
+}
+
+func TestRenderJobsRetryThenStayFailed(t *testing.T) {
+	h := newIngestHarness(t)
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 6, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 6, Image: redTile},
+	)
+	require.NoError(t, h.ingestor.IngestTransactions(h.ctx))
+
+	// A file where the tile's directory should be makes every render fail.
+	blocker := filepath.Join(h.network.CacheDir, "6")
+	require.NoError(t, os.WriteFile(blocker, nil, 0644))
+
+	_, err := h.ingestor.drainRenderQueue(h.ctx)
+	require.Error(t, err)
+
+	jobs := h.RenderJobs(6)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "pending", jobs[0].Status, "failed attempts are retried")
+	assert.Equal(t, int32(1), jobs[0].Attempts)
+	assert.NotEmpty(t, jobs[0].LastError)
+
+	rendered, err := h.ingestor.drainRenderQueue(h.ctx)
+	require.NoError(t, err)
+	assert.Zero(t, rendered, "retries wait for their backoff")
+
+	_, err = h.conn.ExecContext(h.ctx, `UPDATE render_jobs SET run_after = NOW(), attempts = $1 WHERE tile_id = 6`, maxRenderAttempts-1)
+	require.NoError(t, err)
+	_, err = h.ingestor.drainRenderQueue(h.ctx)
+	require.Error(t, err)
+
+	jobs = h.RenderJobs(6)
+	assert.Equal(t, "failed", jobs[0].Status)
+	assert.NotEmpty(t, jobs[0].LastError)
+
+	require.NoError(t, os.Remove(blocker))
+	requeued, err := h.queries.RequeueFailedRenderJobs(h.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), requeued)
+
+	rendered, err = h.ingestor.drainRenderQueue(h.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rendered)
+	h.RequireFile("6", "latest.png")
+}
+
+func TestRenderJobRetryYieldsToConcurrentEnqueue(t *testing.T) {
+	h := newIngestHarness(t)
+
+	h.chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 6, Value: ether(2)},
+		scriptStep{Method: "setTile", From: alice, Location: 6, Image: redTile},
+	)
+	require.NoError(t, h.ingestor.IngestTransactions(h.ctx))
+	require.NoError(t, os.WriteFile(filepath.Join(h.network.CacheDir, "6"), nil, 0644))
+
+	job, err := h.queries.ClaimRenderJob(h.ctx)
+	require.NoError(t, err)
+
+	// Ingestion enqueues a job for the tile while the failed render is
+	// being put back; the retry waits on its uncommitted pending row.
+	enqueue, err := h.conn.BeginTx(h.ctx, nil)
+	require.NoError(t, err)
+	defer enqueue.Rollback()
+	require.NoError(t, db.New(enqueue).EnqueueRenderJob(h.ctx, db.EnqueueRenderJobParams{TileID: 6, BlockNumber: job.BlockNumber + 1}))
+
+	done := make(chan error, 1)
+	go func() { done <- h.ingestor.runRenderJob(h.ctx, job) }()
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, enqueue.Commit())
+	require.Error(t, <-done)
+
+	jobs := h.RenderJobs(6)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "superseded", jobs[0].Status, "not left running")
+	assert.NotEmpty(t, jobs[0].LastError)
+	assert.Equal(t, "pending", jobs[1].Status)
+}
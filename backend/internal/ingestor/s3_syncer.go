@@ -14,6 +14,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"go.uber.org/zap"
+	"pixelmap.io/backend/internal/metrics"
 )
 
 type S3Syncer struct {
@@ -25,7 +26,7 @@ type S3Syncer struct {
 	fileHashes map[string]string
 }
 
-func NewS3Syncer(logger *zap.Logger, cacheDir string) (*S3Syncer, error) {
+func NewS3Syncer(logger *zap.Logger, cacheDir, bucketName string) (*S3Syncer, error) {
 	cfg, err := config.LoadDefaultConfig(context.TODO())
 	if err != nil {
 		return nil, err
@@ -35,22 +36,29 @@ func NewS3Syncer(logger *zap.Logger, cacheDir string) (*S3Syncer, error) {
 
 	return &S3Syncer{
 		client:     client,
-		bucketName: "pixelmap.art",
+		bucketName: bucketName,
 		cacheDir:   cacheDir,
 		logger:     logger,
 		fileHashes: make(map[string]string),
 	}, nil
 }
 
-// Modify SyncWithS3 to accept a context
+// SyncWithS3 uploads files whose contents changed since the last sync.
+// Cancelling ctx stops the walk between files; an upload in progress is
+// finished so the bucket never holds a partial object.
 func (s *S3Syncer) SyncWithS3(ctx context.Context) error {
 	s.syncMu.Lock()
 	defer s.syncMu.Unlock()
 
+	stop := ctx
+	ctx = context.WithoutCancel(ctx)
 	err := filepath.Walk(s.cacheDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if err := stop.Err(); err != nil {
+			return err
+		}
 
 		if info.IsDir() {
 			return nil
@@ -80,7 +88,7 @@ func (s *S3Syncer) SyncWithS3(ctx context.Context) error {
 		return nil
 	})
 
-	if err != nil {
+	if err != nil && stop.Err() == nil {
 		s.logger.Error("Error walking through cache directory", zap.Error(err))
 	}
 
@@ -91,21 +99,33 @@ func (s *S3Syncer) SyncWithS3(ctx context.Context) error {
 func (s *S3Syncer) uploadToS3(ctx context.Context, filePath, s3Key string) error {
 	file, err := os.Open(filePath)
 	if err != nil {
+		metrics.S3Uploads.WithLabelValues("error").Inc()
 		return err
 	}
 	defer file.Close()
 
+	info, err := file.Stat()
+	if err != nil {
+		metrics.S3Uploads.WithLabelValues("error").Inc()
+		return err
+	}
+
 	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket: aws.String(s.bucketName),
 		Key:    aws.String(s3Key),
 		Body:   file,
 	})
 
-	if err == nil {
-		s.logger.Info("File uploaded to S3", zap.String("key", s3Key))
+	if err != nil {
+		metrics.S3Uploads.WithLabelValues("error").Inc()
+		return err
 	}
 
-	return err
+	metrics.S3Uploads.WithLabelValues("ok").Inc()
+	metrics.S3UploadBytes.Add(float64(info.Size()))
+	s.logger.Info("File uploaded to S3", zap.String("key", s3Key))
+
+	return nil
 }
 
 func (s *S3Syncer) calculateMD5(filePath string) (string, error) {
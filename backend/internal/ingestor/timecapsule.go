@@ -0,0 +1,96 @@
+package ingestor
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+	"strconv"
+
+	"go.uber.org/zap"
+	utils "pixelmap.io/backend/internal/utils"
+)
+
+// PixelMapTimeCapsule is a separate ERC-721 with no tiles behind it, so its
+// calls and Transfer events go to their own tables instead of the tile
+// histories. Holders come from the Transfer events, which GetTransactions
+// turns into synthetic safeTransferFrom calls carrying a LogIndex; a direct
+// transfer call is left to the event it emitted.
+
+// handleTimeCapsuleMint records MultiMintOwner. The tokens themselves are
+// recorded from the Transfer events the mint emits.
+func (i *Ingestor) handleTimeCapsuleMint(ctx context.Context, call *Call) (*ChangeSet, error) {
+	args, err := decode[struct {
+		MintAmount *big.Int `abi:"mintAmount"`
+	}](call)
+	if err != nil {
+		return nil, err
+	}
+
+	i.logger.Info("Time capsules minted",
+		zap.String("amount", args.MintAmount.String()),
+		zap.String("tx", call.Tx.Hash))
+	return (&ChangeSet{}).Add(InsertTimeCapsuleMint{
+		TimeStamp:   call.TimeStamp,
+		BlockNumber: call.BlockNumber,
+		Tx:          call.Tx.Hash,
+		LogIndex:    call.TransactionIndex,
+		MintedBy:    call.Tx.From,
+		Amount:      int32(args.MintAmount.Int64()),
+	}), nil
+}
+
+// handleTimeCapsuleMetadata records setBaseTokenURI, setContractURI and
+// lockMetadata, which takes no URI.
+func (i *Ingestor) handleTimeCapsuleMetadata(ctx context.Context, call *Call) (*ChangeSet, error) {
+	var value sql.NullString
+	if len(call.Args) > 0 {
+		args, err := decode[struct{ URI string }](call)
+		if err != nil {
+			return nil, err
+		}
+		value = sql.NullString{String: args.URI, Valid: true}
+	}
+
+	i.logger.Info("Time capsule metadata changed",
+		zap.String("action", call.Method.Name),
+		zap.String("value", value.String),
+		zap.String("tx", call.Tx.Hash))
+	return (&ChangeSet{}).Add(InsertTimeCapsuleMetadataChange{
+		TimeStamp:   call.TimeStamp,
+		BlockNumber: call.BlockNumber,
+		Tx:          call.Tx.Hash,
+		LogIndex:    call.TransactionIndex,
+		Action:      call.Method.Name,
+		Value:       value,
+		UpdatedBy:   call.Tx.From,
+	}), nil
+}
+
+// handleTimeCapsuleTransfer records a Transfer event of a time capsule token,
+// a mint when it comes from the zero address.
+func (i *Ingestor) handleTimeCapsuleTransfer(ctx context.Context, call *Call) (*ChangeSet, error) {
+	if !call.IsEvent() {
+		return &ChangeSet{}, nil
+	}
+	args, err := decode[transferArgs](call)
+	if err != nil {
+		return nil, err
+	}
+	logIndex, _ := strconv.Atoi(call.Tx.LogIndex)
+
+	transfer := InsertTimeCapsuleTransfer{
+		TimeStamp:       call.TimeStamp,
+		BlockNumber:     call.BlockNumber,
+		Tx:              call.Tx.Hash,
+		LogIndex:        int32(logIndex),
+		TokenID:         int32(args.TokenID.Int64()),
+		TransferredFrom: utils.NormalizeAddress(args.From.Hex()),
+		TransferredTo:   utils.NormalizeAddress(args.To.Hex()),
+	}
+	i.logger.Info("Time capsule transferred",
+		zap.Int32("token", transfer.TokenID),
+		zap.String("from", transfer.TransferredFrom),
+		zap.String("to", transfer.TransferredTo),
+		zap.String("tx", call.Tx.Hash))
+	return &ChangeSet{Changes: []Change{transfer}}, nil
+}
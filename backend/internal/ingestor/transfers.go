@@ -0,0 +1,110 @@
+package ingestor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+
+	"go.uber.org/zap"
+	db "pixelmap.io/backend/internal/db"
+)
+
+// Transfer types stored in transfer_histories.transfer_type.
+const (
+	transferWrap   = "wrap"
+	transferUnwrap = "unwrap"
+	transferMint   = "mint"
+	transferBurn   = "burn"
+	transferSale   = "sale"
+	transferGift   = "gift"
+)
+
+const zeroAddress = "0x0000000000000000000000000000000000000000"
+
+// classifyTransfer labels a transfer of contract's token from one address to
+// another. The wrapper only mints and burns to wrap and unwrap a tile. Any
+// other transfer is a sale when the transaction it was made in, tx, went to a
+// known marketplace or sent ETH, and a gift otherwise. It returns "" for a
+// transfer between owners whose transaction is unknown.
+func classifyTransfer(network *Network, contract, from, to string, tx *EtherscanTransaction) string {
+	switch {
+	case from == zeroAddress && network.IsWrapper(contract):
+		return transferWrap
+	case from == zeroAddress:
+		return transferMint
+	case to == zeroAddress && network.IsWrapper(contract):
+		return transferUnwrap
+	case to == zeroAddress:
+		return transferBurn
+	case tx == nil:
+		return ""
+	}
+	if network.IsMarketplace(tx.To) {
+		return transferSale
+	}
+	if value, ok := new(big.Int).SetString(tx.Value, 10); ok && value.Sign() > 0 {
+		return transferSale
+	}
+	return transferGift
+}
+
+// transferContext returns the transaction a transfer call was made in: the
+// call itself, or for a Transfer event the transaction that emitted it.
+func transferContext(call *Call) *EtherscanTransaction {
+	if call.IsEvent() {
+		return call.Tx.Outer
+	}
+	return call.Tx
+}
+
+const classifyTransfersPageSize = 500
+
+// ClassifyTransfers labels the transfers recorded before they were
+// classified, or whose transaction was not known when they were ingested. The
+// transaction of each is looked up on Etherscan. It returns how many
+// transfers were classified.
+func (i *Ingestor) ClassifyTransfers(ctx context.Context) (int, error) {
+	var afterID int32
+	classified := 0
+	for {
+		transfers, err := i.queries.ListUnclassifiedTransfers(ctx, db.ListUnclassifiedTransfersParams{
+			AfterID:  afterID,
+			PageSize: classifyTransfersPageSize,
+		})
+		if err != nil {
+			return classified, fmt.Errorf("failed to list unclassified transfers: %w", err)
+		}
+		if len(transfers) == 0 {
+			return classified, nil
+		}
+
+		txs := make(map[string]*EtherscanTransaction)
+		for _, transfer := range transfers {
+			afterID = transfer.ID
+			tx, ok := txs[transfer.Tx]
+			if !ok {
+				if tx, err = i.etherscanClient.GetTransactionByHash(ctx, transfer.Tx); err != nil {
+					return classified, fmt.Errorf("failed to look up transfer %d: %w", transfer.ID, err)
+				}
+				txs[transfer.Tx] = tx
+			}
+
+			contract := i.network.PixelMapAddress
+			if transfer.IsWrapperContract {
+				contract = i.network.WrapperAddress
+			}
+			transferType := classifyTransfer(i.network, contract, transfer.TransferredFrom, transfer.TransferredTo, tx)
+			if err := i.queries.UpdateTransferType(ctx, db.UpdateTransferTypeParams{
+				ID:           transfer.ID,
+				TransferType: sql.NullString{String: transferType, Valid: true},
+			}); err != nil {
+				return classified, fmt.Errorf("failed to classify transfer %d: %w", transfer.ID, err)
+			}
+			classified++
+		}
+		i.logger.Info("Classified transfers",
+			zap.Int("classified", classified),
+			zap.Int32("lastID", afterID))
+	}
+}
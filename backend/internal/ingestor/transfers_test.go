@@ -0,0 +1,64 @@
+package ingestor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestClassifyTransfer(t *testing.T) {
+	network := Mainnet
+	seaport := "0x00000000006c3852cbef3e08e8df289169ede581"
+
+	for _, tc := range []struct {
+		name     string
+		contract string
+		from, to string
+		tx       *EtherscanTransaction
+		want     string
+	}{
+		{"wrap", network.WrapperAddress, zeroAddress, alice, nil, transferWrap},
+		{"unwrap", network.WrapperAddress, alice, zeroAddress, nil, transferUnwrap},
+		{"mint", network.TimeCapsuleAddress, zeroAddress, alice, nil, transferMint},
+		{"burn", network.TimeCapsuleAddress, alice, zeroAddress, nil, transferBurn},
+		{"marketplace sale", network.WrapperAddress, alice, bob, &EtherscanTransaction{To: seaport, Value: "0"}, transferSale},
+		{"paid through another contract", network.WrapperAddress, alice, bob, &EtherscanTransaction{To: transferProxy, Value: "10000000000000000"}, transferSale},
+		{"direct transfer", network.WrapperAddress, alice, bob, &EtherscanTransaction{To: network.WrapperAddress, Value: "0"}, transferGift},
+		{"unknown transaction", network.WrapperAddress, alice, bob, nil, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, classifyTransfer(&network, tc.contract, tc.from, tc.to, tc.tx))
+		})
+	}
+}
+
+func TestGetTransactionsAttachesEventTransactions(t *testing.T) {
+	network := Devnet
+	network.PixelMapAddress = "0x5fbdb2315678afecb367f032d93f642f64180aa3"
+	network.WrapperAddress = "0xe7f1725e7734ce288f8367e1bb143e90bb3f0512"
+	network.Marketplaces = []string{"0x00000000006c3852cbef3e08e8df289169ede581"}
+	network.StartBlock = 1
+
+	chain := newSimulatedChain(t, &network)
+	chain.Replay(
+		scriptStep{Method: "buyTile", From: alice, Location: 9, Value: ether(2)},
+		scriptStep{Method: "wrap", From: alice, Location: 9, Value: ether(1)},
+		scriptStep{Method: "transfer", From: alice, To: bob, Location: 9, Via: network.Marketplaces[0]},
+	)
+	txs, err := chain.Client(zap.NewNop()).GetTransactions(context.Background(), 1, 10)
+	require.NoError(t, err)
+	require.Len(t, txs, 4)
+
+	mint, sale := txs[2], txs[3]
+	require.NotNil(t, mint.Outer, "the wrap call is already known")
+	assert.Equal(t, txs[1].Hash, mint.Outer.Hash)
+	assert.Empty(t, mint.Outer.Internal)
+
+	require.NotNil(t, sale.Outer, "looked up by hash")
+	assert.Equal(t, network.Marketplaces[0], sale.Outer.To)
+	assert.Equal(t, alice, sale.Outer.From)
+	assert.Equal(t, transferSale, classifyTransfer(&network, sale.To, alice, bob, sale.Outer))
+}
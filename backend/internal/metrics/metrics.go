@@ -0,0 +1,180 @@
+// Package metrics defines the indexer's Prometheus metrics and the admin HTTP
+// server that exposes them alongside health checks.
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"pixelmap.io/backend/internal/ratebudget"
+)
+
+const namespace = "pixelmap"
+
+var (
+	LastProcessedBlock = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_processed_block",
+		Help:      "Last block whose transactions have been applied.",
+	})
+	ChainHeadBlock = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "chain_head_block",
+		Help:      "Latest block reported by the explorer API.",
+	})
+	BlockLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "block_lag",
+		Help:      "Blocks between the chain head and the last processed block.",
+	})
+
+	IngestCycles = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ingest_cycles_total",
+		Help:      "Ingestion cycles by result (ok or error).",
+	}, []string{"result"})
+	SkippedTransactions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "skipped_transactions_total",
+		Help:      "Transactions skipped or quarantined instead of applied, by reason.",
+	}, []string{"reason"})
+	UnhandledCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "unhandled_calls_total",
+		Help:      "Calls archived without being applied because no handler is registered for their selector, by contract and selector.",
+	}, []string{"contract", "selector"})
+	MalformedCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "malformed_calls_total",
+		Help:      "Calls archived without being applied because their input does not decode with the contract's ABI, by contract and selector.",
+	}, []string{"contract", "selector"})
+
+	EtherscanRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "etherscan_requests_total",
+		Help:      "Explorer API calls by action and status (ok, empty or error).",
+	}, []string{"action", "status"})
+
+	EtherscanRangeSplits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "etherscan_range_splits_total",
+		Help:      "Block ranges split in half because a call returned as many results as the explorer returns at most, by action.",
+	}, []string{"action"})
+
+	RenderQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "render_queue_depth",
+		Help:      "Render jobs pending or running.",
+	})
+	RenderJobs = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "render_jobs",
+		Help:      "Render jobs by status.",
+	}, []string{"status"})
+	RenderAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "render_attempts_total",
+		Help:      "Render job attempts by result (ok, retry or failed).",
+	}, []string{"result"})
+	RenderJobDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "render_job_duration_seconds",
+		Help:      "Time spent rendering one tile's images and metadata.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	})
+	MapRegenerationDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "map_regeneration_duration_seconds",
+		Help:      "Time spent rendering the full map and tiledata.json.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	})
+
+	S3UploadBytes = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "s3_upload_bytes_total",
+		Help:      "Bytes uploaded to S3.",
+	})
+	S3Uploads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "s3_uploads_total",
+		Help:      "S3 uploads by result (ok or error).",
+	}, []string{"result"})
+)
+
+var blocks struct {
+	sync.Mutex
+	head, processed int64
+}
+
+// SetChainHead records the latest block and updates the lag.
+func SetChainHead(block int64) {
+	blocks.Lock()
+	defer blocks.Unlock()
+	blocks.head = block
+	ChainHeadBlock.Set(float64(block))
+	updateLag()
+}
+
+// SetLastProcessedBlock records ingestion progress and updates the lag.
+func SetLastProcessedBlock(block int64) {
+	blocks.Lock()
+	defer blocks.Unlock()
+	blocks.processed = block
+	LastProcessedBlock.Set(float64(block))
+	updateLag()
+}
+
+func updateLag() {
+	// Without a head there is nothing to lag behind yet.
+	if blocks.head == 0 {
+		return
+	}
+	lag := blocks.head - blocks.processed
+	if lag < 0 {
+		lag = 0
+	}
+	BlockLag.Set(float64(lag))
+}
+
+// RateBudgetCollector exports per-key counters from a rate budget scheduler.
+type RateBudgetCollector struct {
+	usage func() []ratebudget.Usage
+
+	requests    *prometheus.Desc
+	rateLimited *prometheus.Desc
+	failures    *prometheus.Desc
+	usedToday   *prometheus.Desc
+	quota       *prometheus.Desc
+}
+
+// NewRateBudgetCollector returns a collector that reads usage on every scrape.
+func NewRateBudgetCollector(usage func() []ratebudget.Usage) *RateBudgetCollector {
+	labels := []string{"provider", "key"}
+	return &RateBudgetCollector{
+		usage:       usage,
+		requests:    prometheus.NewDesc(namespace+"_api_key_requests_total", "Successful explorer API calls per key.", labels, nil),
+		rateLimited: prometheus.NewDesc(namespace+"_api_key_rate_limited_total", "Explorer API calls rejected as rate limited per key.", labels, nil),
+		failures:    prometheus.NewDesc(namespace+"_api_key_failures_total", "Explorer API calls that failed per key.", labels, nil),
+		usedToday:   prometheus.NewDesc(namespace+"_api_key_used_today", "Calls made with the key during the current UTC day.", labels, nil),
+		quota:       prometheus.NewDesc(namespace+"_api_key_daily_quota", "Daily call quota for the key; zero is unlimited.", labels, nil),
+	}
+}
+
+func (c *RateBudgetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.requests
+	ch <- c.rateLimited
+	ch <- c.failures
+	ch <- c.usedToday
+	ch <- c.quota
+}
+
+func (c *RateBudgetCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, u := range c.usage() {
+		ch <- prometheus.MustNewConstMetric(c.requests, prometheus.CounterValue, float64(u.Requests), u.Provider, u.Key)
+		ch <- prometheus.MustNewConstMetric(c.rateLimited, prometheus.CounterValue, float64(u.RateLimited), u.Provider, u.Key)
+		ch <- prometheus.MustNewConstMetric(c.failures, prometheus.CounterValue, float64(u.Failures), u.Provider, u.Key)
+		ch <- prometheus.MustNewConstMetric(c.usedToday, prometheus.GaugeValue, float64(u.UsedToday), u.Provider, u.Key)
+		ch <- prometheus.MustNewConstMetric(c.quota, prometheus.GaugeValue, float64(u.DailyQuota), u.Provider, u.Key)
+	}
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pixelmap.io/backend/internal/ratebudget"
+)
+
+func TestBlockLag(t *testing.T) {
+	SetLastProcessedBlock(90)
+	SetChainHead(100)
+	assert.Equal(t, float64(10), testutil.ToFloat64(BlockLag))
+
+	SetLastProcessedBlock(100)
+	assert.Equal(t, float64(0), testutil.ToFloat64(BlockLag))
+	assert.Equal(t, float64(100), testutil.ToFloat64(LastProcessedBlock))
+}
+
+func TestHealthDetectsStalls(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	h := NewHealth(time.Minute)
+	h.now = func() time.Time { return now }
+	h.started = now
+
+	assert.NoError(t, h.Live())
+	assert.Error(t, h.Ready(context.Background()), "not ready before the first cycle")
+
+	h.Progress(42)
+	assert.NoError(t, h.Ready(context.Background()))
+
+	now = now.Add(2 * time.Minute)
+	assert.ErrorContains(t, h.Live(), "stalled")
+	assert.Error(t, h.Ready(context.Background()))
+
+	h.Progress(0)
+	assert.NoError(t, h.Live())
+	assert.Equal(t, int64(42), h.lastBlock.Load(), "a cycle without new blocks keeps the last block")
+}
+
+func TestHealthReadinessChecks(t *testing.T) {
+	h := NewHealth(time.Minute)
+	h.Progress(1)
+
+	h.AddReadinessCheck("database", func(context.Context) error { return errors.New("connection refused") })
+	assert.EqualError(t, h.Ready(context.Background()), "database: connection refused")
+
+	h.AddReadinessCheck("database", func(context.Context) error { return nil })
+	assert.NoError(t, h.Ready(context.Background()))
+}
+
+func TestServerEndpoints(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewRateBudgetCollector(func() []ratebudget.Usage {
+		return []ratebudget.Usage{{Provider: "api.etherscan.io", Key: "…abcd", Requests: 7, RateLimited: 2, DailyQuota: 100000}}
+	}))
+
+	h := NewHealth(time.Minute)
+	server := httptest.NewServer(NewServer("", h, registry).Handler)
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := get("/metrics")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `pixelmap_api_key_requests_total{key="…abcd",provider="api.etherscan.io"} 7`)
+	assert.Contains(t, body, `pixelmap_api_key_rate_limited_total{key="…abcd",provider="api.etherscan.io"} 2`)
+
+	status, _ = get("/healthz")
+	assert.Equal(t, http.StatusOK, status)
+
+	status, body = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Contains(t, body, "first ingestion cycle")
+
+	h.Progress(12)
+	status, body = get("/readyz")
+	assert.Equal(t, http.StatusOK, status)
+
+	var resp healthResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	assert.Equal(t, "ok", resp.Status)
+	assert.Equal(t, int64(12), resp.LastProcessedBlock)
+}
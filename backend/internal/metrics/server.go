@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultStallAfter is how long ingestion may go without progress before
+// /healthz reports it as stalled. A cycle runs every 30 seconds and a single
+// block window rarely takes more than a few minutes.
+const DefaultStallAfter = 10 * time.Minute
+
+// Health tracks ingestion progress for the /healthz and /readyz endpoints.
+type Health struct {
+	stallAfter time.Duration
+	now        func() time.Time
+	started    time.Time
+
+	lastProgress atomic.Int64 // unix nanoseconds; zero until the first cycle
+	lastBlock    atomic.Int64
+
+	mu     sync.Mutex
+	checks map[string]func(context.Context) error
+}
+
+func NewHealth(stallAfter time.Duration) *Health {
+	if stallAfter <= 0 {
+		stallAfter = DefaultStallAfter
+	}
+	return &Health{
+		stallAfter: stallAfter,
+		now:        time.Now,
+		started:    time.Now(),
+		checks:     make(map[string]func(context.Context) error),
+	}
+}
+
+// Progress records that ingestion completed a block window or a cycle.
+func (h *Health) Progress(block int64) {
+	h.lastProgress.Store(h.now().UnixNano())
+	if block > 0 {
+		h.lastBlock.Store(block)
+	}
+}
+
+// AddReadinessCheck adds a dependency that must be reachable for /readyz.
+func (h *Health) AddReadinessCheck(name string, check func(context.Context) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = check
+}
+
+// Live returns an error when ingestion has made no progress for longer than
+// the stall threshold, counting from startup until the first cycle finishes.
+func (h *Health) Live() error {
+	since := h.started
+	if last := h.lastProgress.Load(); last != 0 {
+		since = time.Unix(0, last)
+	}
+	if idle := h.now().Sub(since); idle > h.stallAfter {
+		return fmt.Errorf("ingestion stalled: no progress for %s", idle.Round(time.Second))
+	}
+	return nil
+}
+
+// Ready returns an error until the first ingestion cycle has finished, while
+// ingestion is stalled, or while a readiness check fails.
+func (h *Health) Ready(ctx context.Context) error {
+	if h.lastProgress.Load() == 0 {
+		return fmt.Errorf("waiting for the first ingestion cycle")
+	}
+	if err := h.Live(); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	checks := h.checks
+	h.mu.Unlock()
+
+	sort.Strings(names)
+	for _, name := range names {
+		if err := checks[name](ctx); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+type healthResponse struct {
+	Status             string `json:"status"`
+	Error              string `json:"error,omitempty"`
+	LastProcessedBlock int64  `json:"last_processed_block,omitempty"`
+	LastProgress       string `json:"last_progress,omitempty"`
+}
+
+func (h *Health) handler(check func(*http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := healthResponse{Status: "ok", LastProcessedBlock: h.lastBlock.Load()}
+		if last := h.lastProgress.Load(); last != 0 {
+			resp.LastProgress = time.Unix(0, last).UTC().Format(time.RFC3339)
+		}
+
+		status := http.StatusOK
+		if err := check(r); err != nil {
+			status = http.StatusServiceUnavailable
+			resp.Status = "unavailable"
+			resp.Error = err.Error()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// NewServer returns the admin server: Prometheus metrics from gatherer on
+// /metrics, liveness on /healthz and readiness on /readyz.
+func NewServer(addr string, health *Health, gatherer prometheus.Gatherer) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	mux.Handle("/healthz", health.handler(func(*http.Request) error { return health.Live() }))
+	mux.Handle("/readyz", health.handler(func(r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		return health.Ready(ctx)
+	}))
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
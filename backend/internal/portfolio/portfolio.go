@@ -0,0 +1,237 @@
+// Package portfolio summarises what one address holds and how it got there.
+// The API serves portfolios on request and the ingestor writes one JSON file
+// per address for the frontend's profile pages; both build them here.
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	db "pixelmap.io/backend/internal/db"
+	utils "pixelmap.io/backend/internal/utils"
+)
+
+type Portfolio struct {
+	Address        string        `json:"address"`
+	Ens            string        `json:"ens"`
+	Tiles          []Tile        `json:"tiles"`
+	WrappedCount   int           `json:"wrapped_count"`
+	UnwrappedCount int           `json:"unwrapped_count"`
+	Acquisitions   []Acquisition `json:"acquisitions"`
+	// TotalSpent is the sum of every purchase the address made, in ETH.
+	TotalSpent string `json:"total_spent"`
+	// TimeCapsules are the PixelMapTimeCapsule tokens the address holds.
+	TimeCapsules []TimeCapsule `json:"time_capsules"`
+}
+
+// Tile prices are in ETH, with the exact wei alongside.
+type Tile struct {
+	ID       int32  `json:"id"`
+	Image    string `json:"image"`
+	URL      string `json:"url"`
+	Price    string `json:"price"`
+	PriceWei string `json:"price_wei"`
+	Wrapped  bool   `json:"wrapped"`
+}
+
+// Acquisition is one purchase or incoming transfer of a tile, including
+// tiles the address has since sold or sent on.
+type Acquisition struct {
+	TileID      int32     `json:"tile_id"`
+	Type        string    `json:"type"`
+	From        string    `json:"from"`
+	Price       string    `json:"price,omitempty"`
+	PriceWei    string    `json:"price_wei,omitempty"`
+	Tx          string    `json:"tx"`
+	BlockNumber int64     `json:"block_number"`
+	Timestamp   time.Time `json:"timestamp"`
+	logIndex    int32
+}
+
+// TimeCapsule is a PixelMapTimeCapsule token and how its holder got it.
+type TimeCapsule struct {
+	TokenID     int32     `json:"token_id"`
+	Tx          string    `json:"tx"`
+	BlockNumber int64     `json:"block_number"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+const (
+	AcquisitionPurchase = "purchase"
+	AcquisitionTransfer = "transfer"
+)
+
+const zeroAddress = "0x0000000000000000000000000000000000000000"
+
+// Load builds the portfolio of a single address.
+func Load(ctx context.Context, queries *db.Queries, address string) (*Portfolio, error) {
+	address = utils.NormalizeAddress(address)
+
+	tiles, err := queries.GetTilesByOwner(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tiles for %s: %w", address, err)
+	}
+	purchases, err := queries.GetPurchasesByBuyer(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purchases for %s: %w", address, err)
+	}
+	transfers, err := queries.GetTransfersByRecipient(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfers for %s: %w", address, err)
+	}
+	capsules, err := queries.GetTimeCapsulesByOwner(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time capsules for %s: %w", address, err)
+	}
+
+	p := Build(address, tiles, purchases, transfers)
+	p.AddTimeCapsules(capsules)
+	return p, nil
+}
+
+// LoadAll builds a portfolio for every address that owns a tile or time
+// capsule or has ever bought or received a tile, keyed by address.
+func LoadAll(ctx context.Context, queries *db.Queries, tiles []db.Tile) (map[string]*Portfolio, error) {
+	purchases, err := queries.ListPurchaseHistories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purchases: %w", err)
+	}
+	transfers, err := queries.ListTransferHistories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfers: %w", err)
+	}
+	capsules, err := queries.ListTimeCapsuleTokens(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list time capsules: %w", err)
+	}
+
+	tilesBy := make(map[string][]db.Tile)
+	for _, tile := range tiles {
+		tilesBy[tile.Owner] = append(tilesBy[tile.Owner], tile)
+	}
+	purchasesBy := make(map[string][]db.PurchaseHistory)
+	for _, purchase := range purchases {
+		purchasesBy[purchase.PurchasedBy] = append(purchasesBy[purchase.PurchasedBy], purchase)
+	}
+	transfersBy := make(map[string][]db.TransferHistory)
+	for _, transfer := range transfers {
+		transfersBy[transfer.TransferredTo] = append(transfersBy[transfer.TransferredTo], transfer)
+	}
+	capsulesBy := make(map[string][]db.TimeCapsuleToken)
+	for _, capsule := range capsules {
+		// Burnt tokens are held by the zero address.
+		if capsule.Owner == zeroAddress {
+			continue
+		}
+		capsulesBy[capsule.Owner] = append(capsulesBy[capsule.Owner], capsule)
+	}
+
+	portfolios := make(map[string]*Portfolio)
+	for address := range tilesBy {
+		portfolios[address] = nil
+	}
+	for address := range purchasesBy {
+		portfolios[address] = nil
+	}
+	for address := range transfersBy {
+		portfolios[address] = nil
+	}
+	for address := range capsulesBy {
+		portfolios[address] = nil
+	}
+	for address := range portfolios {
+		portfolios[address] = Build(address, tilesBy[address], purchasesBy[address], transfersBy[address])
+		portfolios[address].AddTimeCapsules(capsulesBy[address])
+	}
+	return portfolios, nil
+}
+
+// Build assembles a portfolio from rows already selected for address: the
+// tiles it owns, its purchases and the transfers it received.
+func Build(address string, tiles []db.Tile, purchases []db.PurchaseHistory, transfers []db.TransferHistory) *Portfolio {
+	p := &Portfolio{
+		Address:      address,
+		Tiles:        make([]Tile, 0, len(tiles)),
+		Acquisitions: make([]Acquisition, 0, len(purchases)+len(transfers)),
+		TimeCapsules: []TimeCapsule{},
+	}
+
+	for _, tile := range tiles {
+		p.Tiles = append(p.Tiles, Tile{
+			ID:       tile.ID,
+			Image:    tile.Image,
+			URL:      tile.Url,
+			Price:    utils.FormatWei(tile.Price),
+			PriceWei: tile.Price,
+			Wrapped:  tile.Wrapped,
+		})
+		if tile.Wrapped {
+			p.WrappedCount++
+		} else {
+			p.UnwrappedCount++
+		}
+		if p.Ens == "" {
+			p.Ens = tile.Ens
+		}
+	}
+	sort.Slice(p.Tiles, func(a, b int) bool { return p.Tiles[a].ID < p.Tiles[b].ID })
+
+	spent := new(big.Rat)
+	for _, purchase := range purchases {
+		if price, ok := utils.WeiToEth(purchase.Price); ok {
+			spent.Add(spent, price)
+		}
+		p.Acquisitions = append(p.Acquisitions, Acquisition{
+			TileID:      purchase.TileID,
+			Type:        AcquisitionPurchase,
+			From:        purchase.SoldBy,
+			Price:       utils.FormatWei(purchase.Price),
+			PriceWei:    purchase.Price,
+			Tx:          purchase.Tx,
+			BlockNumber: purchase.BlockNumber,
+			Timestamp:   purchase.TimeStamp,
+			logIndex:    purchase.LogIndex,
+		})
+	}
+	p.TotalSpent = utils.FormatEth(spent)
+
+	for _, transfer := range transfers {
+		p.Acquisitions = append(p.Acquisitions, Acquisition{
+			TileID:      transfer.TileID,
+			Type:        AcquisitionTransfer,
+			From:        transfer.TransferredFrom,
+			Tx:          transfer.Tx,
+			BlockNumber: transfer.BlockNumber,
+			Timestamp:   transfer.TimeStamp,
+			logIndex:    transfer.LogIndex,
+		})
+	}
+
+	// Newest first, the order the history tables are shown in elsewhere.
+	sort.SliceStable(p.Acquisitions, func(a, b int) bool {
+		x, y := p.Acquisitions[a], p.Acquisitions[b]
+		if x.BlockNumber != y.BlockNumber {
+			return x.BlockNumber > y.BlockNumber
+		}
+		return x.logIndex > y.logIndex
+	})
+
+	return p
+}
+
+// AddTimeCapsules adds the time capsule tokens the address holds, as returned
+// by the time_capsule_tokens view.
+func (p *Portfolio) AddTimeCapsules(tokens []db.TimeCapsuleToken) {
+	for _, token := range tokens {
+		p.TimeCapsules = append(p.TimeCapsules, TimeCapsule{
+			TokenID:     token.TokenID,
+			Tx:          token.AcquiredTx,
+			BlockNumber: token.AcquiredBlock,
+			Timestamp:   token.AcquiredAt,
+		})
+	}
+	sort.Slice(p.TimeCapsules, func(a, b int) bool { return p.TimeCapsules[a].TokenID < p.TimeCapsules[b].TokenID })
+}
@@ -0,0 +1,79 @@
+package portfolio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "pixelmap.io/backend/internal/db"
+)
+
+const (
+	alice   = "0x70997970c51812dc3a010c7d01b50e0d17dc79c8"
+	bob     = "0x3c44cdddb6a900fa2b585dd299e03d12fa4293bc"
+	creator = "0x4f4b7e7edf5ec41235624ce207a6ef352aca7050"
+)
+
+func TestBuild(t *testing.T) {
+	at := time.Date(2021, 12, 13, 0, 0, 0, 0, time.UTC)
+	tiles := []db.Tile{
+		{ID: 9, Owner: alice, Wrapped: true, Ens: "alice.eth"},
+		{ID: 2, Owner: alice, Price: "1500000000000000000"},
+	}
+	purchases := []db.PurchaseHistory{
+		{TileID: 2, SoldBy: creator, PurchasedBy: alice, Price: "2000000000000000000", Tx: "0xa", BlockNumber: 100, TimeStamp: at},
+		{TileID: 7, SoldBy: creator, PurchasedBy: alice, Price: "100000000000000000", Tx: "0xb", BlockNumber: 300, TimeStamp: at},
+	}
+	transfers := []db.TransferHistory{
+		{TileID: 9, TransferredFrom: bob, TransferredTo: alice, Tx: "0xc", BlockNumber: 200, LogIndex: 1, TimeStamp: at},
+		{TileID: 9, TransferredFrom: bob, TransferredTo: alice, Tx: "0xd", BlockNumber: 200, LogIndex: 4, TimeStamp: at},
+	}
+
+	p := Build(alice, tiles, purchases, transfers)
+
+	assert.Equal(t, alice, p.Address)
+	assert.Equal(t, "alice.eth", p.Ens)
+	require.Len(t, p.Tiles, 2)
+	assert.Equal(t, int32(2), p.Tiles[0].ID)
+	assert.Equal(t, "1.5", p.Tiles[0].Price)
+	assert.Equal(t, "1500000000000000000", p.Tiles[0].PriceWei)
+	assert.Equal(t, 1, p.WrappedCount)
+	assert.Equal(t, 1, p.UnwrappedCount)
+	assert.Equal(t, "2.1", p.TotalSpent)
+
+	var order []string
+	for _, a := range p.Acquisitions {
+		order = append(order, a.Tx)
+	}
+	assert.Equal(t, []string{"0xb", "0xd", "0xc", "0xa"}, order, "newest first, including tiles since sold")
+	assert.Equal(t, AcquisitionTransfer, p.Acquisitions[1].Type)
+	assert.Equal(t, bob, p.Acquisitions[1].From)
+	assert.Empty(t, p.Acquisitions[1].Price)
+	assert.Equal(t, AcquisitionPurchase, p.Acquisitions[3].Type)
+	assert.Equal(t, "2", p.Acquisitions[3].Price)
+	assert.Equal(t, "2000000000000000000", p.Acquisitions[3].PriceWei)
+}
+
+func TestBuildEmpty(t *testing.T) {
+	p := Build(bob, nil, nil, nil)
+	assert.Equal(t, "0", p.TotalSpent)
+	assert.NotNil(t, p.Tiles, "encodes as [] rather than null")
+	assert.NotNil(t, p.Acquisitions)
+	assert.NotNil(t, p.TimeCapsules)
+}
+
+func TestAddTimeCapsules(t *testing.T) {
+	at := time.Date(2021, 10, 22, 0, 0, 0, 0, time.UTC)
+	p := Build(alice, nil, nil, nil)
+
+	p.AddTimeCapsules([]db.TimeCapsuleToken{
+		{TokenID: 12, Owner: alice, AcquiredTx: "0xf", AcquiredBlock: 13480000, AcquiredAt: at},
+		{TokenID: 3, Owner: alice, AcquiredTx: "0xe", AcquiredBlock: 13470000, AcquiredAt: at},
+	})
+
+	require.Len(t, p.TimeCapsules, 2)
+	assert.Equal(t, TimeCapsule{TokenID: 3, Tx: "0xe", BlockNumber: 13470000, Timestamp: at}, p.TimeCapsules[0])
+	assert.Equal(t, int32(12), p.TimeCapsules[1].TokenID)
+	assert.Empty(t, p.Tiles, "capsules are not tiles")
+}
@@ -0,0 +1,171 @@
+package prices
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ChainlinkETHUSD is the mainnet ETH/USD price feed proxy.
+var ChainlinkETHUSD = common.HexToAddress("0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419")
+
+// The parts of AggregatorV3Interface and the proxy's phase lookup used here.
+const chainlinkABI = `[
+	{"name":"decimals","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]},
+	{"name":"latestRoundData","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"roundId","type":"uint80"},{"name":"answer","type":"int256"},{"name":"startedAt","type":"uint256"},{"name":"updatedAt","type":"uint256"},{"name":"answeredInRound","type":"uint80"}]},
+	{"name":"getRoundData","type":"function","stateMutability":"view","inputs":[{"name":"_roundId","type":"uint80"}],"outputs":[{"name":"roundId","type":"uint80"},{"name":"answer","type":"int256"},{"name":"startedAt","type":"uint256"},{"name":"updatedAt","type":"uint256"},{"name":"answeredInRound","type":"uint80"}]},
+	{"name":"phaseAggregators","type":"function","stateMutability":"view","inputs":[{"name":"","type":"uint16"}],"outputs":[{"name":"","type":"address"}]},
+	{"name":"latestRound","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+var parsedChainlinkABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(chainlinkABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// Caller runs read-only contract calls; *ethclient.Client is one.
+type Caller interface {
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// ChainlinkSource reads a Chainlink price feed through its proxy over
+// JSON-RPC. It walks rounds back from the latest one and keeps the last
+// answer of each day, so it costs one call per round: fine for keeping up
+// day to day, slow for years of history, which a CSV import covers better.
+type ChainlinkSource struct {
+	Client Caller
+	Feed   common.Address
+}
+
+func (s *ChainlinkSource) Name() string { return "chainlink" }
+
+type round struct {
+	id        *big.Int
+	answer    *big.Int
+	updatedAt time.Time
+}
+
+// A proxy round ID is the phase in the top 16 bits above the round ID of
+// that phase's aggregator.
+const phaseOffset = 64
+
+func (s *ChainlinkSource) Daily(ctx context.Context, from, to time.Time) ([]Price, error) {
+	var decimals uint8
+	if err := s.call(ctx, s.Feed, &decimals, "decimals"); err != nil {
+		return nil, err
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+
+	latest, err := s.round(ctx, "latestRoundData")
+	if err != nil {
+		return nil, err
+	}
+	phase := new(big.Int).Rsh(latest.id, phaseOffset).Uint64()
+	aggregatorRound := new(big.Int).Sub(latest.id, new(big.Int).Lsh(new(big.Int).SetUint64(phase), phaseOffset)).Uint64()
+
+	var prices []Price
+	current := latest
+	for {
+		if !current.updatedAt.IsZero() {
+			day := Day(current.updatedAt)
+			if day.Before(from) {
+				break
+			}
+			// Rounds are visited newest first, so the first answer seen for a
+			// day is its close.
+			if inRange(day, from, to) && (len(prices) == 0 || !prices[len(prices)-1].Day.Equal(day)) {
+				usd := new(big.Rat).SetFrac(current.answer, scale)
+				if usd.Sign() > 0 {
+					prices = append(prices, Price{Day: day, USD: usd.FloatString(8)})
+				}
+			}
+		}
+
+		if aggregatorRound <= 1 {
+			if phase <= 1 {
+				break
+			}
+			phase--
+			if aggregatorRound, err = s.lastRoundOfPhase(ctx, phase); err != nil {
+				return nil, err
+			}
+			if aggregatorRound == 0 {
+				break
+			}
+		} else {
+			aggregatorRound--
+		}
+
+		id := new(big.Int).Lsh(new(big.Int).SetUint64(phase), phaseOffset)
+		id.Add(id, new(big.Int).SetUint64(aggregatorRound))
+		if current, err = s.round(ctx, "getRoundData", id); err != nil {
+			return nil, err
+		}
+	}
+
+	// Oldest first, like the other sources.
+	for a, b := 0, len(prices)-1; a < b; a, b = a+1, b-1 {
+		prices[a], prices[b] = prices[b], prices[a]
+	}
+	return prices, nil
+}
+
+func (s *ChainlinkSource) lastRoundOfPhase(ctx context.Context, phase uint64) (uint64, error) {
+	var aggregator common.Address
+	if err := s.call(ctx, s.Feed, &aggregator, "phaseAggregators", uint16(phase)); err != nil {
+		return 0, err
+	}
+	if aggregator == (common.Address{}) {
+		return 0, nil
+	}
+	var latestRound *big.Int
+	if err := s.call(ctx, aggregator, &latestRound, "latestRound"); err != nil {
+		return 0, err
+	}
+	return latestRound.Uint64(), nil
+}
+
+func (s *ChainlinkSource) round(ctx context.Context, method string, args ...interface{}) (round, error) {
+	out, err := s.callRaw(ctx, s.Feed, method, args...)
+	if err != nil {
+		return round{}, err
+	}
+	r := round{
+		id:     out[0].(*big.Int),
+		answer: out[1].(*big.Int),
+	}
+	// An updatedAt of zero marks a round that never completed.
+	if updatedAt := out[3].(*big.Int); updatedAt.Sign() > 0 {
+		r.updatedAt = time.Unix(updatedAt.Int64(), 0).UTC()
+	}
+	return r, nil
+}
+
+func (s *ChainlinkSource) call(ctx context.Context, to common.Address, result interface{}, method string, args ...interface{}) error {
+	out, err := s.callRaw(ctx, to, method, args...)
+	if err != nil {
+		return err
+	}
+	return parsedChainlinkABI.Methods[method].Outputs.Copy(result, out)
+}
+
+func (s *ChainlinkSource) callRaw(ctx context.Context, to common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	input, err := parsedChainlinkABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	output, err := s.Client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: input}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s on %s: %w", method, to.Hex(), err)
+	}
+	return parsedChainlinkABI.Unpack(method, output)
+}
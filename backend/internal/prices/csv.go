@@ -0,0 +1,117 @@
+package prices
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVSource reads prices from a CSV file with a date and a price per row,
+// such as a daily close export. The date is YYYY-MM-DD, RFC 3339 or Unix
+// seconds. With a header row the price is taken from a column named
+// price_usd, price, close or usd; without one it is the second column.
+type CSVSource struct {
+	Path string
+}
+
+func (s *CSVSource) Name() string { return "csv" }
+
+func (s *CSVSource) Daily(ctx context.Context, from, to time.Time) ([]Price, error) {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readCSV(f, from, to)
+}
+
+func readCSV(r io.Reader, from, to time.Time) ([]Price, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	dateColumn, priceColumn := 0, 1
+	byDay := make(map[time.Time]string)
+	var days []time.Time
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if line == 1 {
+			if _, err := parseDate(record[0]); err != nil {
+				dateColumn, priceColumn = headerColumns(record)
+				if priceColumn < 0 {
+					return nil, errors.New("header has no price column")
+				}
+				continue
+			}
+		}
+		if len(record) <= dateColumn || len(record) <= priceColumn {
+			return nil, fmt.Errorf("line %d: expected a date and a price", line)
+		}
+
+		day, err := parseDate(record[dateColumn])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if !inRange(day, from, to) {
+			continue
+		}
+		usd, err := parseUSD(strings.TrimSpace(record[priceColumn]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if _, seen := byDay[day]; !seen {
+			days = append(days, day)
+		}
+		// A later row for the same day wins, so intraday exports keep the
+		// close.
+		byDay[day] = usd
+	}
+
+	prices := make([]Price, len(days))
+	for n, day := range days {
+		prices[n] = Price{Day: day, USD: byDay[day]}
+	}
+	return prices, nil
+}
+
+func headerColumns(header []string) (date, price int) {
+	date, price = 0, -1
+	for n, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "date", "day", "timestamp", "time":
+			date = n
+		case "price_usd", "price", "close", "usd":
+			if price < 0 {
+				price = n
+			}
+		}
+	}
+	return date, price
+}
+
+func parseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return Day(t), nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return Day(time.Unix(seconds, 0)), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
@@ -0,0 +1,83 @@
+package prices
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPSource reads prices from a JSON endpoint, e.g. a small service in front
+// of a market data API or a local stub. It is called as
+// GET URL?from=YYYY-MM-DD&to=YYYY-MM-DD and answers with
+//
+//	[{"date": "2021-12-13", "price_usd": 3782.51}, ...]
+//
+// where price_usd may be a number or a string.
+type HTTPSource struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *HTTPSource) Name() string { return "http" }
+
+type httpPrice struct {
+	Date     string      `json:"date"`
+	PriceUSD json.Number `json:"price_usd"`
+}
+
+func (s *HTTPSource) Daily(ctx context.Context, from, to time.Time) ([]Price, error) {
+	endpoint, err := url.Parse(s.URL)
+	if err != nil {
+		return nil, err
+	}
+	query := endpoint.Query()
+	query.Set("from", from.Format(time.DateOnly))
+	if !to.IsZero() {
+		query.Set("to", to.Format(time.DateOnly))
+	}
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("price provider returned %s: %s", resp.Status, body)
+	}
+
+	var rows []httpPrice
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("failed to decode prices: %w", err)
+	}
+
+	prices := make([]Price, 0, len(rows))
+	for _, row := range rows {
+		day, err := parseDate(row.Date)
+		if err != nil {
+			return nil, err
+		}
+		if !inRange(day, from, to) {
+			continue
+		}
+		usd, err := parseUSD(row.PriceUSD.String())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", row.Date, err)
+		}
+		prices = append(prices, Price{Day: day, USD: usd})
+	}
+	return prices, nil
+}
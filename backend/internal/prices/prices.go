@@ -0,0 +1,68 @@
+// Package prices loads daily ETH/USD prices into the eth_prices table, which
+// the API and the marketplace stats join onto sales by day. Prices come from
+// a Source: a CSV export, an HTTP provider or a Chainlink price feed.
+package prices
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	db "pixelmap.io/backend/internal/db"
+)
+
+// Price is the ETH/USD price for one UTC day, as a decimal string.
+type Price struct {
+	Day time.Time
+	USD string
+}
+
+// Source provides one price per UTC day between from and to, inclusive. A
+// zero to means up to the latest price available.
+type Source interface {
+	Name() string
+	Daily(ctx context.Context, from, to time.Time) ([]Price, error)
+}
+
+// Import stores the prices source has for from through to, replacing any
+// already stored for those days, and returns how many it stored.
+func Import(ctx context.Context, queries *db.Queries, source Source, from, to time.Time) (int, error) {
+	prices, err := source.Daily(ctx, Day(from), Day(to))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read prices from %s: %w", source.Name(), err)
+	}
+
+	for _, price := range prices {
+		if err := queries.UpsertEthPrice(ctx, db.UpsertEthPriceParams{
+			Day:      price.Day,
+			PriceUsd: price.USD,
+			Source:   source.Name(),
+		}); err != nil {
+			return 0, fmt.Errorf("failed to store price for %s: %w", price.Day.Format(time.DateOnly), err)
+		}
+	}
+	return len(prices), nil
+}
+
+// Day truncates t to the start of its UTC day, the key prices are stored by.
+func Day(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func inRange(day, from, to time.Time) bool {
+	return !day.Before(from) && (to.IsZero() || !day.After(to))
+}
+
+// parseUSD checks that value is a positive decimal and normalises it.
+func parseUSD(value string) (string, error) {
+	amount, ok := new(big.Rat).SetString(value)
+	if !ok || amount.Sign() <= 0 {
+		return "", fmt.Errorf("invalid price %q", value)
+	}
+	return amount.FloatString(8), nil
+}
@@ -0,0 +1,160 @@
+package prices
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCSVSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eth.csv")
+	require.NoError(t, os.WriteFile(path, []byte(`Date,Open,Close
+2021-12-12,4000,4100.5
+2021-12-13,4100.5,3782.51
+2021-12-13T20:00:00Z,3700,3790
+2021-12-14,3790,3860
+`), 0644))
+
+	source := &CSVSource{Path: path}
+	prices, err := source.Daily(context.Background(), date("2021-12-13"), date("2021-12-14"))
+	require.NoError(t, err)
+	assert.Equal(t, []Price{
+		{Day: date("2021-12-13"), USD: "3790.00000000"},
+		{Day: date("2021-12-14"), USD: "3860.00000000"},
+	}, prices, "the close column, the last row of a day, within the range")
+
+	noHeader := filepath.Join(t.TempDir(), "eth.csv")
+	require.NoError(t, os.WriteFile(noHeader, []byte("1639353600,3782.51\n"), 0644))
+	prices, err = (&CSVSource{Path: noHeader}).Daily(context.Background(), time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []Price{{Day: date("2021-12-13"), USD: "3782.51000000"}}, prices)
+
+	bad := filepath.Join(t.TempDir(), "eth.csv")
+	require.NoError(t, os.WriteFile(bad, []byte("date,price\n2021-12-13,-1\n"), 0644))
+	_, err = (&CSVSource{Path: bad}).Daily(context.Background(), time.Time{}, time.Time{})
+	assert.ErrorContains(t, err, `line 2: invalid price "-1"`)
+}
+
+func TestHTTPSource(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`[{"date": "2021-12-13", "price_usd": 3782.51}, {"date": "2021-12-14", "price_usd": "3860"}]`))
+	}))
+	defer server.Close()
+
+	source := &HTTPSource{URL: server.URL + "/eth?currency=usd"}
+	prices, err := source.Daily(context.Background(), date("2021-12-13"), date("2021-12-31"))
+	require.NoError(t, err)
+	assert.Equal(t, "currency=usd&from=2021-12-13&to=2021-12-31", query)
+	assert.Equal(t, []Price{
+		{Day: date("2021-12-13"), USD: "3782.51000000"},
+		{Day: date("2021-12-14"), USD: "3860.00000000"},
+	}, prices)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer failing.Close()
+	_, err = (&HTTPSource{URL: failing.URL}).Daily(context.Background(), date("2021-12-13"), time.Time{})
+	assert.ErrorContains(t, err, "429")
+}
+
+// fakeFeed answers the proxy and aggregator calls ChainlinkSource makes from
+// a fixed set of rounds.
+type fakeFeed struct {
+	aggregators map[uint16]common.Address
+	latest      map[common.Address]int64
+	rounds      map[string][2]int64 // proxy round ID -> answer, updatedAt
+	latestID    *big.Int
+	calls       int
+}
+
+func roundID(phase, round int64) *big.Int {
+	id := new(big.Int).Lsh(big.NewInt(phase), phaseOffset)
+	return id.Add(id, big.NewInt(round))
+}
+
+func (f *fakeFeed) CallContract(ctx context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	f.calls++
+	method, err := parsedChainlinkABI.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	switch method.Name {
+	case "decimals":
+		return method.Outputs.Pack(uint8(8))
+	case "phaseAggregators":
+		return method.Outputs.Pack(f.aggregators[args[0].(uint16)])
+	case "latestRound":
+		return method.Outputs.Pack(big.NewInt(f.latest[*call.To]))
+	case "latestRoundData", "getRoundData":
+		id := f.latestID
+		if method.Name == "getRoundData" {
+			id = args[0].(*big.Int)
+		}
+		r, ok := f.rounds[id.String()]
+		if !ok {
+			return nil, errors.New("execution reverted: No data present")
+		}
+		return method.Outputs.Pack(id, big.NewInt(r[0]), big.NewInt(r[1]), big.NewInt(r[1]), id)
+	}
+	return nil, errors.New("unexpected call " + method.Name)
+}
+
+func TestChainlinkSource(t *testing.T) {
+	dollars := func(usd int64) int64 { return usd * 100_000_000 }
+	at := func(s string) int64 {
+		parsed, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return parsed.Unix()
+	}
+
+	oldAggregator := common.HexToAddress("0x01")
+	feed := &fakeFeed{
+		aggregators: map[uint16]common.Address{1: oldAggregator},
+		latest:      map[common.Address]int64{oldAggregator: 3},
+		rounds: map[string][2]int64{
+			roundID(1, 1).String(): {dollars(3500), at("2021-12-11T12:00:00Z")},
+			roundID(1, 2).String(): {dollars(3600), at("2021-12-12T09:00:00Z")},
+			roundID(1, 3).String(): {dollars(3650), at("2021-12-12T23:00:00Z")},
+			roundID(2, 1).String(): {dollars(3700), at("2021-12-13T01:00:00Z")},
+			roundID(2, 2).String(): {dollars(3782), at("2021-12-13T18:00:00Z")},
+			roundID(2, 3).String(): {dollars(3860), at("2021-12-14T03:00:00Z")},
+		},
+		latestID: roundID(2, 3),
+	}
+
+	source := &ChainlinkSource{Client: feed, Feed: ChainlinkETHUSD}
+	prices, err := source.Daily(context.Background(), date("2021-12-12"), date("2021-12-13"))
+	require.NoError(t, err)
+	assert.Equal(t, []Price{
+		{Day: date("2021-12-12"), USD: "3650.00000000"},
+		{Day: date("2021-12-13"), USD: "3782.00000000"},
+	}, prices, "the last round of each day, across the phase change")
+	assert.Equal(t, 9, feed.calls, "stops at the first round before from")
+}
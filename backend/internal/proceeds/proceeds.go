@@ -0,0 +1,172 @@
+// Package proceeds follows sales made through the wrapper. Unwrapping lists
+// a tile on the original contract from the wrapper at the unwrapper's price;
+// the wrapper receives the ETH when the tile is bought and holds it until
+// the unwrapper withdraws it. The ingestor records each step and the API
+// reports what is owed per tile and per seller.
+package proceeds
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	db "pixelmap.io/backend/internal/db"
+	utils "pixelmap.io/backend/internal/utils"
+)
+
+// Steps of a sale through the wrapper.
+const (
+	Listed    = "listed"
+	Sold      = "sold"
+	Withdrawn = "withdrawn"
+)
+
+// Sale is the wrapper's current listing of a tile. Prices are in ETH, with
+// the exact wei alongside.
+type Sale struct {
+	TileID          int32      `json:"tile_id"`
+	Seller          string     `json:"seller"`
+	SalePrice       string     `json:"sale_price"`
+	SalePriceWei    string     `json:"sale_price_wei"`
+	ListedTx        string     `json:"listed_tx"`
+	ListedAt        time.Time  `json:"listed_at"`
+	Buyer           string     `json:"buyer,omitempty"`
+	SoldTx          string     `json:"sold_tx,omitempty"`
+	SoldAt          *time.Time `json:"sold_at,omitempty"`
+	WithdrawnTx     string     `json:"withdrawn_tx,omitempty"`
+	WithdrawnAt     *time.Time `json:"withdrawn_at,omitempty"`
+	Withdrawable    string     `json:"withdrawable"`
+	WithdrawableWei string     `json:"withdrawable_wei"`
+}
+
+// Event is one recorded step: a listing, a sale or a withdrawal.
+type Event struct {
+	TileID      int32     `json:"tile_id"`
+	Event       string    `json:"event"`
+	Seller      string    `json:"seller"`
+	Buyer       string    `json:"buyer,omitempty"`
+	Amount      string    `json:"amount"`
+	AmountWei   string    `json:"amount_wei"`
+	Tx          string    `json:"tx"`
+	BlockNumber int64     `json:"block_number"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Tile is the wrapper sale state of one tile. Pending is nil for tiles that
+// were never unwrapped.
+type Tile struct {
+	TileID  int32   `json:"tile_id"`
+	Pending *Sale   `json:"pending"`
+	History []Event `json:"history"`
+}
+
+// Seller is everything an address has sold or listed through the wrapper.
+// Withdrawable totals the proceeds it can still collect.
+type Seller struct {
+	Address         string  `json:"address"`
+	Withdrawable    string  `json:"withdrawable"`
+	WithdrawableWei string  `json:"withdrawable_wei"`
+	Sales           []Sale  `json:"sales"`
+	History         []Event `json:"history"`
+}
+
+// LoadTile reads the wrapper sale state of a tile.
+func LoadTile(ctx context.Context, queries *db.Queries, tileID int32) (*Tile, error) {
+	t := &Tile{TileID: tileID}
+
+	pending, err := queries.GetWrapperPendingSale(ctx, tileID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return nil, fmt.Errorf("failed to get wrapper sale of tile %d: %w", tileID, err)
+	default:
+		sale := newSale(pending)
+		t.Pending = &sale
+	}
+
+	history, err := queries.GetWrapperProceedsByTileId(ctx, tileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wrapper proceeds of tile %d: %w", tileID, err)
+	}
+	t.History = newEvents(history)
+	return t, nil
+}
+
+// LoadSeller reads the wrapper sales of an address.
+func LoadSeller(ctx context.Context, queries *db.Queries, address string) (*Seller, error) {
+	address = utils.NormalizeAddress(address)
+
+	sales, err := queries.GetWrapperPendingSalesBySeller(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wrapper sales of %s: %w", address, err)
+	}
+	history, err := queries.GetWrapperProceedsBySeller(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wrapper proceeds of %s: %w", address, err)
+	}
+	return BuildSeller(address, sales, history), nil
+}
+
+// BuildSeller assembles a Seller from rows already selected for address.
+func BuildSeller(address string, sales []db.WrapperPendingSale, history []db.WrapperProceedsHistory) *Seller {
+	s := &Seller{
+		Address: address,
+		Sales:   make([]Sale, 0, len(sales)),
+		History: newEvents(history),
+	}
+
+	withdrawable := new(big.Int)
+	for _, row := range sales {
+		s.Sales = append(s.Sales, newSale(row))
+		if amount, ok := new(big.Int).SetString(row.Withdrawable, 10); ok {
+			withdrawable.Add(withdrawable, amount)
+		}
+	}
+	s.WithdrawableWei = withdrawable.String()
+	s.Withdrawable = utils.FormatWei(s.WithdrawableWei)
+	return s
+}
+
+func newSale(row db.WrapperPendingSale) Sale {
+	sale := Sale{
+		TileID:          row.TileID,
+		Seller:          row.Seller,
+		SalePrice:       utils.FormatWei(row.SalePrice),
+		SalePriceWei:    row.SalePrice,
+		ListedTx:        row.ListedTx,
+		ListedAt:        row.ListedAt,
+		Buyer:           row.Buyer.String,
+		SoldTx:          row.SoldTx.String,
+		WithdrawnTx:     row.WithdrawnTx.String,
+		Withdrawable:    utils.FormatWei(row.Withdrawable),
+		WithdrawableWei: row.Withdrawable,
+	}
+	if row.SoldAt.Valid {
+		sale.SoldAt = &row.SoldAt.Time
+	}
+	if row.WithdrawnAt.Valid {
+		sale.WithdrawnAt = &row.WithdrawnAt.Time
+	}
+	return sale
+}
+
+func newEvents(rows []db.WrapperProceedsHistory) []Event {
+	events := make([]Event, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, Event{
+			TileID:      row.TileID,
+			Event:       row.Event,
+			Seller:      row.Seller,
+			Buyer:       row.Buyer.String,
+			Amount:      utils.FormatWei(row.Amount),
+			AmountWei:   row.Amount,
+			Tx:          row.Tx,
+			BlockNumber: row.BlockNumber,
+			Timestamp:   row.TimeStamp,
+		})
+	}
+	return events
+}
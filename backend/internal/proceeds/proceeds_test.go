@@ -0,0 +1,49 @@
+package proceeds
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "pixelmap.io/backend/internal/db"
+)
+
+const (
+	alice = "0x70997970c51812dc3a010c7d01b50e0d17dc79c8"
+	bob   = "0x3c44cdddb6a900fa2b585dd299e03d12fa4293bc"
+)
+
+func TestBuildSeller(t *testing.T) {
+	at := time.Date(2021, 12, 13, 0, 0, 0, 0, time.UTC)
+	sales := []db.WrapperPendingSale{
+		{TileID: 9, Seller: alice, SalePrice: "1500000000000000000", ListedTx: "0xa", ListedAt: at,
+			Buyer: sql.NullString{String: bob, Valid: true}, SoldTx: sql.NullString{String: "0xb", Valid: true}, SoldAt: sql.NullTime{Time: at, Valid: true},
+			Withdrawable: "1500000000000000000"},
+		{TileID: 4, Seller: alice, SalePrice: "2000000000000000", ListedTx: "0xc", ListedAt: at, Withdrawable: "0"},
+	}
+	history := []db.WrapperProceedsHistory{
+		{TileID: 9, Event: Sold, Seller: alice, Buyer: sql.NullString{String: bob, Valid: true}, Amount: "1500000000000000000", Tx: "0xb", BlockNumber: 12, TimeStamp: at},
+		{TileID: 4, Event: Listed, Seller: alice, Amount: "2000000000000000", Tx: "0xc", BlockNumber: 11, TimeStamp: at},
+	}
+
+	s := BuildSeller(alice, sales, history)
+
+	assert.Equal(t, "1.5", s.Withdrawable, "only sold, unwithdrawn listings are owed")
+	assert.Equal(t, "1500000000000000000", s.WithdrawableWei)
+	require.Len(t, s.Sales, 2)
+	assert.Equal(t, bob, s.Sales[0].Buyer)
+	assert.Equal(t, "0.002", s.Sales[1].SalePrice)
+	assert.Nil(t, s.Sales[1].SoldAt)
+	require.Len(t, s.History, 2)
+	assert.Equal(t, Sold, s.History[0].Event)
+	assert.Equal(t, "1.5", s.History[0].Amount)
+}
+
+func TestBuildSellerEmpty(t *testing.T) {
+	encoded, err := json.Marshal(BuildSeller(bob, nil, nil))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"address": "`+bob+`", "withdrawable": "0", "withdrawable_wei": "0", "sales": [], "history": []}`, string(encoded))
+}
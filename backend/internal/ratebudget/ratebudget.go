@@ -0,0 +1,426 @@
+// Package ratebudget schedules requests to Etherscan-style APIs across every
+// configured provider and API key, keeping each key inside its own rate and
+// daily quota and backing off when a provider says it is rate limited.
+package ratebudget
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// ErrBudgetExhausted is returned when every key has used its daily quota.
+var ErrBudgetExhausted = errors.New("rate budget exhausted: every API key has reached its daily quota")
+
+// ErrNoProvider is returned for a call no configured provider serves.
+var ErrNoProvider = errors.New("no configured provider serves this call")
+
+const (
+	defaultMaxAttempts = 8
+	// Used when a provider reports a per-second limit without Retry-After.
+	defaultBackoff = time.Second
+	// Keys the provider rejects are parked rather than dropped, in case the
+	// rejection was transient.
+	invalidKeyBackoff = time.Hour
+)
+
+// Provider is one Etherscan-compatible API, such as Etherscan itself or a
+// Blockscout instance.
+type Provider struct {
+	Name    string
+	BaseURL string
+	// Keys are used in rotation. A provider with no keys gets one keyless
+	// slot, which Blockscout allows.
+	Keys []string
+	// RatePerSecond is the allowance for each key.
+	RatePerSecond float64
+	// DailyQuota caps calls per key per UTC day; zero means unlimited.
+	DailyQuota int
+	// ChainID is sent as the chainid parameter when non-zero (Etherscan v2).
+	ChainID int
+	// Proxy marks providers that serve module=proxy, Etherscan's JSON-RPC
+	// passthrough. Blockscout's Etherscan-compatible API does not, so proxy
+	// calls only go to providers that set it.
+	Proxy bool
+}
+
+// Usage is a snapshot of the counters for one provider key.
+type Usage struct {
+	Provider    string `json:"provider"`
+	Key         string `json:"key"`
+	Requests    int64  `json:"requests"`
+	RateLimited int64  `json:"rate_limited"`
+	Failures    int64  `json:"failures"`
+	UsedToday   int    `json:"used_today"`
+	DailyQuota  int    `json:"daily_quota"`
+}
+
+type slot struct {
+	provider *Provider
+	key      string
+	limiter  *rate.Limiter
+
+	blockedUntil time.Time
+	day          time.Time
+	usedToday    int
+
+	requests    int64
+	rateLimited int64
+	failures    int64
+}
+
+// Scheduler hands each request to the provider key that can serve it soonest.
+// It is safe for concurrent use; all callers share the same budget.
+type Scheduler struct {
+	logger      *zap.Logger
+	client      *http.Client
+	maxAttempts int
+	now         func() time.Time
+
+	mu    sync.Mutex
+	slots []*slot
+	next  int
+}
+
+func New(logger *zap.Logger, providers []Provider) (*Scheduler, error) {
+	s := &Scheduler{
+		logger:      logger,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		maxAttempts: defaultMaxAttempts,
+		now:         time.Now,
+	}
+
+	for n := range providers {
+		provider := &providers[n]
+		if provider.BaseURL == "" {
+			return nil, fmt.Errorf("provider %q has no base URL", provider.Name)
+		}
+		if provider.RatePerSecond <= 0 {
+			return nil, fmt.Errorf("provider %q needs a positive rate", provider.Name)
+		}
+
+		keys := provider.Keys
+		if len(keys) == 0 {
+			keys = []string{""}
+		}
+		for _, key := range keys {
+			s.slots = append(s.slots, &slot{
+				provider: provider,
+				key:      key,
+				limiter:  rate.NewLimiter(rate.Limit(provider.RatePerSecond), 1),
+			})
+		}
+	}
+
+	if len(s.slots) == 0 {
+		return nil, errors.New("no API providers configured")
+	}
+	return s, nil
+}
+
+// Get performs one API call. params should hold module, action and the call's
+// own arguments; the scheduler adds apikey and chainid for whichever provider
+// serves it. Rate-limited attempts are retried on the next available key. The
+// raw response body is returned for the caller to decode, including
+// non-rate-limit errors such as "No transactions found".
+func (s *Scheduler) Get(ctx context.Context, params map[string]string) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; attempt < s.maxAttempts; attempt++ {
+		sl, delay, err := s.pick(params["module"])
+		if err != nil {
+			return nil, err
+		}
+
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			}
+		}
+		if err := sl.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limiter wait: %w", err)
+		}
+
+		body, retryAfter, err := s.do(ctx, sl, params)
+		if err == nil {
+			return body, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		lastErr = err
+
+		s.logger.Warn("API request failed, trying next key",
+			zap.String("provider", sl.provider.Name),
+			zap.String("key", maskKey(sl.key)),
+			zap.Duration("retryAfter", retryAfter),
+			zap.Error(err))
+	}
+
+	return nil, fmt.Errorf("giving up after %d attempts: %w", s.maxAttempts, lastErr)
+}
+
+// pick reserves one call to module on the slot that serves it and is
+// available soonest, rotating between slots that are equally available.
+func (s *Scheduler) pick(module string) (*slot, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var best *slot
+	var bestIndex int
+	var bestAt time.Time
+	served := false
+	for n := range s.slots {
+		index := (s.next + n) % len(s.slots)
+		sl := s.slots[index]
+		if !sl.provider.serves(module) {
+			continue
+		}
+		served = true
+		sl.rollDay(now)
+		if sl.exhausted() {
+			continue
+		}
+
+		at := now
+		if sl.blockedUntil.After(now) {
+			at = sl.blockedUntil
+		}
+		if best == nil || at.Before(bestAt) {
+			best, bestIndex, bestAt = sl, index, at
+		}
+	}
+
+	if !served {
+		return nil, 0, fmt.Errorf("%w: module=%s", ErrNoProvider, module)
+	}
+	if best == nil {
+		return nil, 0, ErrBudgetExhausted
+	}
+
+	s.next = (bestIndex + 1) % len(s.slots)
+	best.usedToday++
+	return best, bestAt.Sub(now), nil
+}
+
+func (s *Scheduler) do(ctx context.Context, sl *slot, params map[string]string) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sl.provider.BaseURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("creating request: %w", err)
+	}
+
+	q := req.URL.Query()
+	if sl.provider.ChainID != 0 {
+		q.Set("chainid", strconv.Itoa(sl.provider.ChainID))
+	}
+	for k, v := range params {
+		q.Set(k, v)
+	}
+	if sl.key != "" {
+		q.Set("apikey", sl.key)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.recordFailure(sl, 0)
+		return nil, 0, fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		s.recordFailure(sl, 0)
+		return nil, 0, fmt.Errorf("reading response body: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), s.now())
+		s.recordRateLimit(sl, retryAfter)
+		return nil, retryAfter, fmt.Errorf("HTTP %d from %s", resp.StatusCode, sl.provider.Name)
+	}
+	if resp.StatusCode >= 500 {
+		s.recordFailure(sl, 0)
+		return nil, 0, fmt.Errorf("HTTP %d from %s", resp.StatusCode, sl.provider.Name)
+	}
+
+	if message, limited := rateLimitMessage(body); limited {
+		retryAfter := defaultBackoff
+		if strings.Contains(strings.ToLower(message), "daily") {
+			retryAfter = nextDay(s.now()).Sub(s.now())
+		}
+		s.recordRateLimit(sl, retryAfter)
+		return nil, retryAfter, fmt.Errorf("rate limited by %s: %s", sl.provider.Name, message)
+	}
+	if message, invalid := invalidKeyMessage(body); invalid {
+		s.recordFailure(sl, invalidKeyBackoff)
+		return nil, invalidKeyBackoff, fmt.Errorf("key rejected by %s: %s", sl.provider.Name, message)
+	}
+
+	s.mu.Lock()
+	sl.requests++
+	s.mu.Unlock()
+	return body, 0, nil
+}
+
+func (s *Scheduler) recordRateLimit(sl *slot, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sl.rateLimited++
+	sl.block(s.now().Add(retryAfter))
+}
+
+func (s *Scheduler) recordFailure(sl *slot, backoff time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sl.failures++
+	if backoff > 0 {
+		sl.block(s.now().Add(backoff))
+	}
+}
+
+// Usage returns the counters for every provider key. Keys are masked.
+func (s *Scheduler) Usage() []Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	usage := make([]Usage, 0, len(s.slots))
+	for _, sl := range s.slots {
+		sl.rollDay(now)
+		usage = append(usage, Usage{
+			Provider:    sl.provider.Name,
+			Key:         maskKey(sl.key),
+			Requests:    sl.requests,
+			RateLimited: sl.rateLimited,
+			Failures:    sl.failures,
+			UsedToday:   sl.usedToday,
+			DailyQuota:  sl.provider.DailyQuota,
+		})
+	}
+	return usage
+}
+
+func (p *Provider) serves(module string) bool {
+	return module != "proxy" || p.Proxy
+}
+
+func (sl *slot) block(until time.Time) {
+	if until.After(sl.blockedUntil) {
+		sl.blockedUntil = until
+	}
+}
+
+func (sl *slot) rollDay(now time.Time) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	if !day.Equal(sl.day) {
+		sl.day = day
+		sl.usedToday = 0
+	}
+}
+
+func (sl *slot) exhausted() bool {
+	return sl.provider.DailyQuota > 0 && sl.usedToday >= sl.provider.DailyQuota
+}
+
+// rateLimitMessage reports whether body is Etherscan's NOTOK rate-limit
+// response, e.g. "Max calls per sec rate limit reached (3/sec)" or
+// "Max daily rate limit reached".
+func rateLimitMessage(body []byte) (string, bool) {
+	var resp struct {
+		Status  string          `json:"status"`
+		Message string          `json:"message"`
+		Result  json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Status != "0" {
+		return "", false
+	}
+
+	var result string
+	json.Unmarshal(resp.Result, &result)
+	if strings.Contains(strings.ToLower(result), "rate limit") {
+		return result, true
+	}
+	return "", false
+}
+
+func invalidKeyMessage(body []byte) (string, bool) {
+	var resp struct {
+		Status string          `json:"status"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Status != "0" {
+		return "", false
+	}
+
+	var result string
+	json.Unmarshal(resp.Result, &result)
+	if strings.Contains(strings.ToLower(result), "invalid api key") {
+		return result, true
+	}
+	return "", false
+}
+
+// parseRetryAfter accepts both forms of the header: delay-seconds and an HTTP
+// date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return defaultBackoff
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return defaultBackoff
+}
+
+func nextDay(now time.Time) time.Time {
+	return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
+
+func maskKey(key string) string {
+	if key == "" {
+		return "(none)"
+	}
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "…" + key[len(key)-4:]
+}
+
+// SplitKeys parses a comma-separated key list, ignoring blanks.
+func SplitKeys(value string) []string {
+	var keys []string
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Host is a short label for a provider URL, used when a provider is not
+// given a name.
+func Host(baseURL string) string {
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return baseURL
+}
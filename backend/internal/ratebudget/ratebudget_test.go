@@ -0,0 +1,247 @@
+package ratebudget
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recordingServer answers with respond and remembers which key made each call.
+type recordingServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	keys []string
+}
+
+func newRecordingServer(t *testing.T, respond func(key string, w http.ResponseWriter, r *http.Request)) *recordingServer {
+	s := &recordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("apikey")
+		s.mu.Lock()
+		s.keys = append(s.keys, key)
+		s.mu.Unlock()
+		respond(key, w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *recordingServer) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.keys...)
+}
+
+func ok(key string, w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(`{"status":"1","message":"OK","result":[]}`))
+}
+
+func TestNewRejectsIncompleteProviders(t *testing.T) {
+	_, err := New(zap.NewNop(), nil)
+	assert.Error(t, err)
+
+	_, err = New(zap.NewNop(), []Provider{{Name: "a", RatePerSecond: 1}})
+	assert.Error(t, err)
+
+	_, err = New(zap.NewNop(), []Provider{{Name: "a", BaseURL: "http://a"}})
+	assert.Error(t, err)
+}
+
+func TestGetRotatesKeysAndAddsParameters(t *testing.T) {
+	server := newRecordingServer(t, func(key string, w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "11155111", r.URL.Query().Get("chainid"))
+		assert.Equal(t, "account", r.URL.Query().Get("module"))
+		ok(key, w, r)
+	})
+
+	s, err := New(zap.NewNop(), []Provider{{
+		Name:          "etherscan",
+		BaseURL:       server.URL,
+		Keys:          []string{"key-a", "key-b"},
+		RatePerSecond: 1000,
+		ChainID:       11155111,
+	}})
+	require.NoError(t, err)
+
+	for n := 0; n < 4; n++ {
+		_, err := s.Get(context.Background(), map[string]string{"module": "account"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"key-a", "key-b", "key-a", "key-b"}, server.Keys())
+}
+
+func TestGetOmitsChainIDAndKeyWhenUnset(t *testing.T) {
+	server := newRecordingServer(t, func(key string, w http.ResponseWriter, r *http.Request) {
+		assert.False(t, r.URL.Query().Has("chainid"))
+		assert.False(t, r.URL.Query().Has("apikey"))
+		ok(key, w, r)
+	})
+
+	s, err := New(zap.NewNop(), []Provider{{Name: "blockscout", BaseURL: server.URL, RatePerSecond: 1000}})
+	require.NoError(t, err)
+
+	_, err = s.Get(context.Background(), map[string]string{"module": "logs"})
+	require.NoError(t, err)
+	assert.Equal(t, "(none)", s.Usage()[0].Key)
+}
+
+func TestGetSendsProxyCallsOnlyToProvidersThatServeThem(t *testing.T) {
+	etherscan := newRecordingServer(t, ok)
+	blockscout := newRecordingServer(t, func(key string, w http.ResponseWriter, r *http.Request) {
+		assert.NotEqual(t, "proxy", r.URL.Query().Get("module"))
+		ok(key, w, r)
+	})
+
+	s, err := New(zap.NewNop(), []Provider{
+		{Name: "etherscan", BaseURL: etherscan.URL, Keys: []string{"key-a"}, RatePerSecond: 1000, Proxy: true},
+		{Name: "blockscout", BaseURL: blockscout.URL, Keys: []string{"key-b"}, RatePerSecond: 1000},
+	})
+	require.NoError(t, err)
+
+	for n := 0; n < 4; n++ {
+		_, err := s.Get(context.Background(), map[string]string{"module": "proxy", "action": "eth_blockNumber"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"key-a", "key-a", "key-a", "key-a"}, etherscan.Keys())
+	assert.Empty(t, blockscout.Keys())
+
+	// Other calls still rotate across both.
+	for n := 0; n < 2; n++ {
+		_, err := s.Get(context.Background(), map[string]string{"module": "account"})
+		require.NoError(t, err)
+	}
+	assert.Len(t, blockscout.Keys(), 1)
+
+	s, err = New(zap.NewNop(), []Provider{{Name: "blockscout", BaseURL: blockscout.URL, RatePerSecond: 1000}})
+	require.NoError(t, err)
+	_, err = s.Get(context.Background(), map[string]string{"module": "proxy"})
+	assert.ErrorIs(t, err, ErrNoProvider)
+}
+
+func TestGetRetriesRateLimitedCallsOnAnotherKey(t *testing.T) {
+	server := newRecordingServer(t, func(key string, w http.ResponseWriter, r *http.Request) {
+		if key == "limited" {
+			w.Write([]byte(`{"status":"0","message":"NOTOK","result":"Max calls per sec rate limit reached (3/sec)"}`))
+			return
+		}
+		ok(key, w, r)
+	})
+
+	s, err := New(zap.NewNop(), []Provider{{
+		Name:          "etherscan",
+		BaseURL:       server.URL,
+		Keys:          []string{"limited", "fresh"},
+		RatePerSecond: 1000,
+	}})
+	require.NoError(t, err)
+
+	body, err := s.Get(context.Background(), nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status":"1","message":"OK","result":[]}`, string(body))
+	assert.Equal(t, []string{"limited", "fresh"}, server.Keys())
+
+	usage := s.Usage()
+	assert.Equal(t, int64(1), usage[0].RateLimited)
+	assert.Equal(t, int64(0), usage[0].Requests)
+	assert.Equal(t, int64(1), usage[1].Requests)
+
+	// The limited key is parked, so the next call goes straight to the other.
+	_, err = s.Get(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", server.Keys()[2])
+}
+
+func TestGetHonoursRetryAfter(t *testing.T) {
+	var calls int
+	server := newRecordingServer(t, func(key string, w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		ok(key, w, r)
+	})
+
+	s, err := New(zap.NewNop(), []Provider{
+		{Name: "primary", BaseURL: server.URL, Keys: []string{"primary"}, RatePerSecond: 1000},
+		{Name: "fallback", BaseURL: server.URL, Keys: []string{"fallback"}, RatePerSecond: 1000},
+	})
+	require.NoError(t, err)
+
+	for n := 0; n < 3; n++ {
+		_, err := s.Get(context.Background(), nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"primary", "fallback", "fallback", "fallback"}, server.Keys())
+}
+
+func TestGetWaitsForBlockedKeyUntilContextEnds(t *testing.T) {
+	server := newRecordingServer(t, func(key string, w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	s, err := New(zap.NewNop(), []Provider{{Name: "only", BaseURL: server.URL, Keys: []string{"only"}, RatePerSecond: 1000}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = s.Get(ctx, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, server.Keys(), 1)
+}
+
+func TestGetStopsAtDailyQuota(t *testing.T) {
+	server := newRecordingServer(t, ok)
+
+	s, err := New(zap.NewNop(), []Provider{{
+		Name:          "etherscan",
+		BaseURL:       server.URL,
+		Keys:          []string{"key-a", "key-b"},
+		RatePerSecond: 1000,
+		DailyQuota:    1,
+	}})
+	require.NoError(t, err)
+
+	now := time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	for n := 0; n < 2; n++ {
+		_, err := s.Get(context.Background(), nil)
+		require.NoError(t, err)
+	}
+	_, err = s.Get(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrBudgetExhausted)
+
+	now = now.Add(2 * time.Hour)
+	_, err = s.Get(context.Background(), nil)
+	require.NoError(t, err, "quota resets at the start of the UTC day")
+	assert.Equal(t, 1, s.Usage()[0].UsedToday+s.Usage()[1].UsedToday)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 7*time.Second, parseRetryAfter("7", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, defaultBackoff, parseRetryAfter("", now))
+	assert.Equal(t, defaultBackoff, parseRetryAfter("soon", now))
+}
+
+func TestSplitKeysAndMasking(t *testing.T) {
+	assert.Equal(t, []string{"a", "bcdefgh"}, SplitKeys(" a, ,bcdefgh,"))
+	assert.Nil(t, SplitKeys(""))
+
+	assert.Equal(t, "(none)", maskKey(""))
+	assert.Equal(t, "****", maskKey("abc"))
+	assert.Equal(t, "…efgh", maskKey("bcdefgh"))
+}
@@ -0,0 +1,166 @@
+// Package stats summarises the marketplace: sale volume, counts, buyers and
+// prices per day and per month, and the tiles currently listed. The
+// aggregates live in materialized views that the ingestor refreshes after
+// every cycle; the API and cache/stats.json both read them through Load.
+package stats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "pixelmap.io/backend/internal/db"
+	utils "pixelmap.io/backend/internal/utils"
+)
+
+// Markets a sale can go through. MarketAll combines the others.
+const (
+	MarketAll      = "all"
+	MarketOriginal = "original"
+	MarketWrapper  = "wrapper"
+)
+
+var markets = []string{MarketAll, MarketOriginal, MarketWrapper}
+
+type Stats struct {
+	Listings Listings `json:"listings"`
+	// Markets is keyed by MarketAll, MarketOriginal and MarketWrapper; every
+	// key is present even before the first sale.
+	Markets map[string]*Market `json:"markets"`
+}
+
+// Listings describes the tiles for sale on the original contract right now.
+type Listings struct {
+	ListedTiles int    `json:"listed_tiles"`
+	FloorPrice  string `json:"floor_price,omitempty"`
+}
+
+type Market struct {
+	Totals  Aggregate   `json:"totals"`
+	Daily   []Aggregate `json:"daily"`
+	Monthly []Aggregate `json:"monthly"`
+}
+
+// Aggregate summarises the sales in one period, oldest period first. Prices
+// are in ETH; they are empty when there were no sales.
+type Aggregate struct {
+	Period       string `json:"period,omitempty"`
+	Sales        int    `json:"sales"`
+	Volume       string `json:"volume"`
+	UniqueBuyers int    `json:"unique_buyers"`
+	AveragePrice string `json:"average_price,omitempty"`
+	MedianPrice  string `json:"median_price,omitempty"`
+	MinPrice     string `json:"min_price,omitempty"`
+	MaxPrice     string `json:"max_price,omitempty"`
+	// VolumeUSD values each sale at the ETH/USD price of its day. It covers
+	// PricedSales of the Sales; the rest had no price imported.
+	VolumeUSD   string `json:"volume_usd,omitempty"`
+	PricedSales int    `json:"priced_sales"`
+}
+
+// Refresh recomputes the aggregates from the sales recorded so far. Readers
+// keep seeing the previous figures until it finishes.
+func Refresh(ctx context.Context, queries *db.Queries) error {
+	if err := queries.RefreshMarketStatsDaily(ctx); err != nil {
+		return fmt.Errorf("failed to refresh daily market stats: %w", err)
+	}
+	if err := queries.RefreshMarketStatsMonthly(ctx); err != nil {
+		return fmt.Errorf("failed to refresh monthly market stats: %w", err)
+	}
+	if err := queries.RefreshMarketStatsTotals(ctx); err != nil {
+		return fmt.Errorf("failed to refresh market stats totals: %w", err)
+	}
+	return nil
+}
+
+// Load reads the aggregates as of the last Refresh, and the current listings.
+func Load(ctx context.Context, queries *db.Queries) (*Stats, error) {
+	listings, err := queries.GetListingSummary(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get listings: %w", err)
+	}
+	totals, err := queries.ListMarketStatsTotals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list market stats totals: %w", err)
+	}
+	daily, err := queries.ListMarketStatsDaily(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daily market stats: %w", err)
+	}
+	monthly, err := queries.ListMarketStatsMonthly(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list monthly market stats: %w", err)
+	}
+	return Build(listings, totals, daily, monthly), nil
+}
+
+// Build assembles Stats from the rows of the stats views.
+func Build(listings db.GetListingSummaryRow, totals []db.MarketStatsTotal, daily []db.MarketStatsDaily, monthly []db.MarketStatsMonthly) *Stats {
+	s := &Stats{
+		Listings: Listings{ListedTiles: int(listings.ListedTiles)},
+		Markets:  make(map[string]*Market, len(markets)),
+	}
+	if listings.FloorPrice.Valid {
+		s.Listings.FloorPrice = formatPrice(listings.FloorPrice.String)
+	}
+
+	for _, market := range markets {
+		s.Markets[market] = &Market{
+			Totals:  Aggregate{Volume: "0"},
+			Daily:   []Aggregate{},
+			Monthly: []Aggregate{},
+		}
+	}
+
+	for _, row := range totals {
+		if m, ok := s.Markets[row.Market]; ok {
+			m.Totals = aggregate(row)
+		}
+	}
+	for _, row := range daily {
+		if m, ok := s.Markets[row.Market]; ok {
+			a := aggregate(db.MarketStatsTotal{
+				Sales: row.Sales, Volume: row.Volume, UniqueBuyers: row.UniqueBuyers,
+				AveragePrice: row.AveragePrice, MedianPrice: row.MedianPrice, MinPrice: row.MinPrice, MaxPrice: row.MaxPrice,
+				VolumeUsd: row.VolumeUsd, PricedSales: row.PricedSales,
+			})
+			a.Period = row.Period.Format(time.DateOnly)
+			m.Daily = append(m.Daily, a)
+		}
+	}
+	for _, row := range monthly {
+		if m, ok := s.Markets[row.Market]; ok {
+			a := aggregate(db.MarketStatsTotal{
+				Sales: row.Sales, Volume: row.Volume, UniqueBuyers: row.UniqueBuyers,
+				AveragePrice: row.AveragePrice, MedianPrice: row.MedianPrice, MinPrice: row.MinPrice, MaxPrice: row.MaxPrice,
+				VolumeUsd: row.VolumeUsd, PricedSales: row.PricedSales,
+			})
+			a.Period = row.Period.Format("2006-01")
+			m.Monthly = append(m.Monthly, a)
+		}
+	}
+
+	return s
+}
+
+func aggregate(row db.MarketStatsTotal) Aggregate {
+	a := Aggregate{
+		Sales:        int(row.Sales),
+		Volume:       formatPrice(row.Volume),
+		UniqueBuyers: int(row.UniqueBuyers),
+		AveragePrice: formatPrice(row.AveragePrice),
+		MedianPrice:  formatPrice(row.MedianPrice),
+		MinPrice:     formatPrice(row.MinPrice),
+		MaxPrice:     formatPrice(row.MaxPrice),
+		PricedSales:  int(row.PricedSales),
+	}
+	if row.VolumeUsd.Valid {
+		a.VolumeUSD = row.VolumeUsd.String
+	}
+	return a
+}
+
+// formatPrice prints a wei amount from the views as ETH.
+func formatPrice(value string) string {
+	return utils.FormatWei(value)
+}
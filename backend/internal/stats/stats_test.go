@@ -0,0 +1,65 @@
+package stats
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "pixelmap.io/backend/internal/db"
+)
+
+func TestBuild(t *testing.T) {
+	day := time.Date(2021, 12, 13, 0, 0, 0, 0, time.UTC)
+	listings := db.GetListingSummaryRow{ListedTiles: 12, FloorPrice: sql.NullString{String: "250000000000000000", Valid: true}}
+	totals := []db.MarketStatsTotal{
+		{Market: MarketAll, Sales: 3, Volume: "5500000000000000000", UniqueBuyers: 2, AveragePrice: "1833333333333333333", MedianPrice: "2000000000000000000", MinPrice: "500000000000000000", MaxPrice: "3000000000000000000"},
+		{Market: MarketOriginal, Sales: 3, Volume: "5500000000000000000", UniqueBuyers: 2, AveragePrice: "1833333333333333333", MedianPrice: "2000000000000000000", MinPrice: "500000000000000000", MaxPrice: "3000000000000000000"},
+	}
+	daily := []db.MarketStatsDaily{
+		{Period: day, Market: MarketAll, Sales: 1, Volume: "500000000000000000", UniqueBuyers: 1, AveragePrice: "500000000000000000", MedianPrice: "500000000000000000", MinPrice: "500000000000000000", MaxPrice: "500000000000000000"},
+		{Period: day, Market: MarketOriginal, Sales: 1, Volume: "500000000000000000", UniqueBuyers: 1, AveragePrice: "500000000000000000", MedianPrice: "500000000000000000", MinPrice: "500000000000000000", MaxPrice: "500000000000000000"},
+		{Period: day.AddDate(0, 0, 1), Market: MarketAll, Sales: 2, Volume: "5000000000000000000", UniqueBuyers: 2, AveragePrice: "2500000000000000000", MedianPrice: "2500000000000000000", MinPrice: "2000000000000000000", MaxPrice: "3000000000000000000",
+			VolumeUsd: sql.NullString{String: "7565.02", Valid: true}, PricedSales: 1},
+	}
+	monthly := []db.MarketStatsMonthly{
+		{Period: day.AddDate(0, 0, -12), Market: MarketAll, Sales: 3, Volume: "5500000000000000000", UniqueBuyers: 2, AveragePrice: "1833333333333333333", MedianPrice: "2000000000000000000", MinPrice: "500000000000000000", MaxPrice: "3000000000000000000"},
+	}
+
+	s := Build(listings, totals, daily, monthly)
+
+	assert.Equal(t, Listings{ListedTiles: 12, FloorPrice: "0.25"}, s.Listings)
+	require.Len(t, s.Markets, 3)
+
+	all := s.Markets[MarketAll]
+	assert.Equal(t, Aggregate{Sales: 3, Volume: "5.5", UniqueBuyers: 2, AveragePrice: "1.833333333333333333", MedianPrice: "2", MinPrice: "0.5", MaxPrice: "3"}, all.Totals)
+	require.Len(t, all.Daily, 2)
+	assert.Equal(t, "2021-12-13", all.Daily[0].Period)
+	assert.Equal(t, "2021-12-14", all.Daily[1].Period)
+	assert.Equal(t, "2.5", all.Daily[1].MedianPrice)
+	assert.Equal(t, "7565.02", all.Daily[1].VolumeUSD)
+	assert.Equal(t, 1, all.Daily[1].PricedSales)
+	assert.Empty(t, all.Daily[0].VolumeUSD, "no price imported for the day")
+	require.Len(t, all.Monthly, 1)
+	assert.Equal(t, "2021-12", all.Monthly[0].Period)
+
+	assert.Len(t, s.Markets[MarketOriginal].Daily, 1)
+
+	wrapper := s.Markets[MarketWrapper]
+	assert.Equal(t, Aggregate{Volume: "0"}, wrapper.Totals, "markets without sales are still reported")
+	assert.Empty(t, wrapper.Daily)
+}
+
+func TestBuildEncodesEmptyMarkets(t *testing.T) {
+	s := Build(db.GetListingSummaryRow{}, nil, nil, nil)
+
+	encoded, err := json.Marshal(s.Markets[MarketWrapper])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"totals": {"sales": 0, "volume": "0", "unique_buyers": 0, "priced_sales": 0}, "daily": [], "monthly": []}`, string(encoded))
+
+	encoded, err = json.Marshal(s.Listings)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"listed_tiles": 0}`, string(encoded))
+}
@@ -0,0 +1,380 @@
+// Package traits turns a rule file into the OpenSea attributes of each tile
+// and scores how rare those attributes are across the collection. Rules match
+// tiles by id or by region of the grid, or derive a value from the tile's
+// image and history. The default rules are embedded; TRAIT_RULES points the
+// ingestor at a replacement in YAML or JSON.
+package traits
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	db "pixelmap.io/backend/internal/db"
+	utils "pixelmap.io/backend/internal/utils"
+)
+
+// The grid is 81 tiles wide. Tile 3969 sits alone on row 49.
+const (
+	Columns = 81
+	MaxTile = 3969
+)
+
+// Values a rule can derive from a tile.
+const (
+	FirstUpdateYear = "first_update_year"
+	ImageChanges    = "image_changes"
+	TimesSold       = "times_sold"
+	DominantColor   = "dominant_color"
+	ColorCount      = "color_count"
+	NeverUpdated    = "never_updated"
+)
+
+//go:embed default.yaml
+var defaultRules []byte
+
+// Region is an inclusive block of rows and columns. An empty bound spans the
+// whole grid in that direction.
+type Region struct {
+	Rows    []int `yaml:"rows"`
+	Columns []int `yaml:"columns"`
+}
+
+// Rule assigns one trait. Exactly one of Tiles, Regions or Derive selects
+// the tiles; Value is required unless Derive supplies it.
+type Rule struct {
+	TraitType   string   `yaml:"trait_type"`
+	Value       string   `yaml:"value"`
+	DisplayType string   `yaml:"display_type"`
+	Tiles       []int32  `yaml:"tiles"`
+	Regions     []Region `yaml:"regions"`
+	Derive      string   `yaml:"derive"`
+}
+
+// Rules is a parsed rule file.
+type Rules struct {
+	Traits []Rule `yaml:"traits"`
+}
+
+// Attribute is one entry of a token's OpenSea attributes. Value is a string,
+// or a number for numeric traits.
+type Attribute struct {
+	TraitType   string      `json:"trait_type"`
+	Value       interface{} `json:"value"`
+	DisplayType string      `json:"display_type,omitempty"`
+}
+
+// Tile is what the rules are evaluated against.
+type Tile struct {
+	ID          int32
+	Image       string
+	DataHistory []db.DataHistory
+	Purchases   []db.PurchaseHistory
+}
+
+// Default returns the embedded rules.
+func Default() *Rules {
+	rules, err := Parse(defaultRules)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default trait rules: %v", err))
+	}
+	return rules
+}
+
+// Load reads a rule file. JSON parses as YAML, so either works.
+func Load(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trait rules: %w", err)
+	}
+	rules, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// Parse decodes and validates rules.
+func Parse(data []byte) (*Rules, error) {
+	var rules Rules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse trait rules: %w", err)
+	}
+	for i, rule := range rules.Traits {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("trait rule %d (%s): %w", i+1, rule.TraitType, err)
+		}
+	}
+	return &rules, nil
+}
+
+func (r Rule) validate() error {
+	if r.TraitType == "" {
+		return fmt.Errorf("trait_type is required")
+	}
+
+	selectors := 0
+	if len(r.Tiles) > 0 {
+		selectors++
+	}
+	if len(r.Regions) > 0 {
+		selectors++
+	}
+	if r.Derive != "" {
+		selectors++
+	}
+	if selectors != 1 {
+		return fmt.Errorf("exactly one of tiles, regions or derive is required")
+	}
+
+	for _, id := range r.Tiles {
+		if id < 0 || id > MaxTile {
+			return fmt.Errorf("tile %d is outside the map", id)
+		}
+	}
+	for _, region := range r.Regions {
+		for _, bounds := range [][]int{region.Rows, region.Columns} {
+			if len(bounds) != 0 && (len(bounds) != 2 || bounds[0] > bounds[1]) {
+				return fmt.Errorf("region bounds must be [first, last], got %v", bounds)
+			}
+		}
+	}
+
+	switch r.Derive {
+	case "", NeverUpdated:
+		if r.Value == "" {
+			return fmt.Errorf("value is required")
+		}
+	case FirstUpdateYear, ImageChanges, TimesSold, DominantColor, ColorCount:
+	default:
+		return fmt.Errorf("unknown derive %q", r.Derive)
+	}
+	return nil
+}
+
+// Evaluate returns the attributes of a tile in rule order.
+func (r *Rules) Evaluate(tile Tile) []Attribute {
+	attributes := []Attribute{}
+	var pixels map[string]int
+
+	for _, rule := range r.Traits {
+		var value interface{}
+		switch {
+		case len(rule.Tiles) > 0:
+			if !containsTile(rule.Tiles, tile.ID) {
+				continue
+			}
+			value = rule.Value
+		case len(rule.Regions) > 0:
+			if !inRegions(rule.Regions, tile.ID) {
+				continue
+			}
+			value = rule.Value
+		default:
+			if pixels == nil && (rule.Derive == DominantColor || rule.Derive == ColorCount) {
+				pixels = colorCounts(tile.Image)
+			}
+			var ok bool
+			if value, ok = derive(rule, tile, pixels); !ok {
+				continue
+			}
+		}
+		attributes = append(attributes, Attribute{
+			TraitType:   rule.TraitType,
+			Value:       value,
+			DisplayType: rule.DisplayType,
+		})
+	}
+	return attributes
+}
+
+func derive(rule Rule, tile Tile, pixels map[string]int) (interface{}, bool) {
+	switch rule.Derive {
+	case FirstUpdateYear:
+		if len(tile.DataHistory) == 0 {
+			return nil, false
+		}
+		return strconv.Itoa(tile.DataHistory[0].TimeStamp.Year()), true
+	case ImageChanges:
+		return imageChanges(tile.DataHistory), true
+	case TimesSold:
+		return len(tile.Purchases), true
+	case DominantColor:
+		if len(pixels) == 0 {
+			return nil, false
+		}
+		return dominantColor(pixels), true
+	case ColorCount:
+		if len(pixels) == 0 {
+			return nil, false
+		}
+		return len(pixels), true
+	case NeverUpdated:
+		return rule.Value, len(tile.DataHistory) == 0
+	}
+	return nil, false
+}
+
+func containsTile(ids []int32, id int32) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func inRegions(regions []Region, id int32) bool {
+	row, column := int(id)/Columns, int(id)%Columns
+	for _, region := range regions {
+		if within(region.Rows, row) && within(region.Columns, column) {
+			return true
+		}
+	}
+	return false
+}
+
+func within(bounds []int, n int) bool {
+	return len(bounds) == 0 || (n >= bounds[0] && n <= bounds[1])
+}
+
+// imageChanges counts the updates that set a different image from the one
+// before, ignoring updates that only changed the URL or price.
+func imageChanges(history []db.DataHistory) int {
+	changes, previous := 0, ""
+	for _, h := range history {
+		if h.Image != "" && h.Image != previous {
+			changes++
+			previous = h.Image
+		}
+	}
+	return changes
+}
+
+// colorCounts counts the pixels of each colour in a tile image, or returns
+// nil when the image does not decode to a full tile.
+func colorCounts(image string) map[string]int {
+	pixels, err := utils.DecompressTileCode(image)
+	if err != nil || len(pixels) < 768 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for i := 0; i+3 <= 768; i += 3 {
+		counts[strings.ToLower(pixels[i:i+3])]++
+	}
+	return counts
+}
+
+// dominantColor returns the most common colour as #rrggbb. Ties go to the
+// lowest colour so the result does not depend on map order.
+func dominantColor(counts map[string]int) string {
+	best, bestCount := "", 0
+	for color, count := range counts {
+		if count > bestCount || (count == bestCount && color < best) {
+			best, bestCount = color, count
+		}
+	}
+	var expanded strings.Builder
+	expanded.WriteByte('#')
+	for _, c := range best {
+		expanded.WriteRune(c)
+		expanded.WriteRune(c)
+	}
+	return expanded.String()
+}
+
+// Rarity is a tile's rarity across the collection. Score sums, over the
+// tile's traits, the number of tiles divided by the number sharing that
+// trait; Rank 1 is the rarest tile.
+type Rarity struct {
+	Score float64 `json:"score"`
+	Rank  int     `json:"rank"`
+}
+
+// TraitCount is how many tiles share one trait value.
+type TraitCount struct {
+	TraitType string      `json:"trait_type"`
+	Value     interface{} `json:"value"`
+	Count     int         `json:"count"`
+	Score     float64     `json:"score"`
+}
+
+// Collection is the rarity of every tile and the counts behind it.
+type Collection struct {
+	Tiles  int              `json:"tiles"`
+	Traits []TraitCount     `json:"traits"`
+	Rarity map[int32]Rarity `json:"rarity"`
+}
+
+type traitKey struct {
+	traitType string
+	value     string
+}
+
+func keyOf(a Attribute) traitKey {
+	return traitKey{a.TraitType, fmt.Sprint(a.Value)}
+}
+
+// Score computes rarity across the given tiles. Ties share the better rank.
+func Score(attributes map[int32][]Attribute) *Collection {
+	counts := make(map[traitKey]int)
+	values := make(map[traitKey]interface{})
+	for _, attrs := range attributes {
+		for _, a := range attrs {
+			key := keyOf(a)
+			counts[key]++
+			values[key] = a.Value
+		}
+	}
+
+	total := float64(len(attributes))
+	c := &Collection{
+		Tiles:  len(attributes),
+		Traits: make([]TraitCount, 0, len(counts)),
+		Rarity: make(map[int32]Rarity, len(attributes)),
+	}
+	for key, count := range counts {
+		c.Traits = append(c.Traits, TraitCount{
+			TraitType: key.traitType,
+			Value:     values[key],
+			Count:     count,
+			Score:     total / float64(count),
+		})
+	}
+	sort.Slice(c.Traits, func(i, j int) bool {
+		if c.Traits[i].TraitType != c.Traits[j].TraitType {
+			return c.Traits[i].TraitType < c.Traits[j].TraitType
+		}
+		return fmt.Sprint(c.Traits[i].Value) < fmt.Sprint(c.Traits[j].Value)
+	})
+
+	ids := make([]int32, 0, len(attributes))
+	for id, attrs := range attributes {
+		score := 0.0
+		for _, a := range attrs {
+			score += total / float64(counts[keyOf(a)])
+		}
+		c.Rarity[id] = Rarity{Score: score}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		si, sj := c.Rarity[ids[i]].Score, c.Rarity[ids[j]].Score
+		if si != sj {
+			return si > sj
+		}
+		return ids[i] < ids[j]
+	})
+	for i, id := range ids {
+		r := c.Rarity[id]
+		r.Rank = i + 1
+		if i > 0 && c.Rarity[ids[i-1]].Score == r.Score {
+			r.Rank = c.Rarity[ids[i-1]].Rank
+		}
+		c.Rarity[id] = r
+	}
+	return c
+}
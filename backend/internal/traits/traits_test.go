@@ -0,0 +1,126 @@
+package traits
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "pixelmap.io/backend/internal/db"
+)
+
+func values(attributes []Attribute, traitType string) []interface{} {
+	var found []interface{}
+	for _, a := range attributes {
+		if a.TraitType == traitType {
+			found = append(found, a.Value)
+		}
+	}
+	return found
+}
+
+func TestDefaultRulesMatchTheMap(t *testing.T) {
+	rules := Default()
+	location := func(id int32) []interface{} {
+		return values(rules.Evaluate(Tile{ID: id}), "Location")
+	}
+
+	// Corners of the centre block and tiles just outside it.
+	for _, id := range []int32{1574, 1584, 1985, 2384, 2394} {
+		assert.Equal(t, []interface{}{"Center"}, location(id), "tile %d", id)
+	}
+	for _, id := range []int32{1573, 1585, 1493, 2475, 1000, 2500} {
+		assert.Empty(t, location(id), "tile %d", id)
+	}
+
+	for _, id := range []int32{40, 81, 161, 1701, 1781, 3900, 3969} {
+		assert.Equal(t, []interface{}{"Edge"}, location(id), "tile %d", id)
+	}
+	for _, id := range []int32{0, 80, 3888, 3968} {
+		assert.Equal(t, []interface{}{"Edge", "Corner"}, location(id), "tile %d", id)
+	}
+
+	assert.Equal(t, []interface{}{"Invisible"}, values(rules.Evaluate(Tile{ID: 3969}), "Special"))
+	assert.Equal(t, []interface{}{"Genesis"}, values(rules.Evaluate(Tile{ID: 1984}), "Special"))
+	assert.Equal(t, []interface{}{"OG"}, values(rules.Evaluate(Tile{ID: 1984}), "Era"))
+	assert.Empty(t, values(rules.Evaluate(Tile{ID: 1000}), "Era"))
+}
+
+func TestEvaluateDerivedTraits(t *testing.T) {
+	red := strings.Repeat("f00", 200) + strings.Repeat("00f", 56)
+	tile := Tile{
+		ID:    1000,
+		Image: red,
+		DataHistory: []db.DataHistory{
+			{TimeStamp: time.Date(2017, 3, 1, 0, 0, 0, 0, time.UTC), Image: "abc"},
+			{TimeStamp: time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC), Image: "abc", Url: "https://example.com"},
+			{TimeStamp: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), Image: red},
+		},
+		Purchases: []db.PurchaseHistory{{ID: 1}, {ID: 2}},
+	}
+
+	attributes := Default().Evaluate(tile)
+
+	assert.Contains(t, attributes, Attribute{TraitType: "First Updated", Value: "2017"})
+	assert.Contains(t, attributes, Attribute{TraitType: "Image Changes", Value: 2, DisplayType: "number"})
+	assert.Contains(t, attributes, Attribute{TraitType: "Times Sold", Value: 2, DisplayType: "number"})
+	assert.Contains(t, attributes, Attribute{TraitType: "Dominant Color", Value: "#ff0000"})
+	assert.Contains(t, attributes, Attribute{TraitType: "Colors", Value: 2, DisplayType: "number"})
+	assert.Empty(t, values(attributes, "Status"))
+
+	encoded, err := json.Marshal(attributes[len(attributes)-1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"trait_type": "Colors", "value": 2, "display_type": "number"}`, string(encoded))
+}
+
+func TestEvaluateNeverUpdated(t *testing.T) {
+	attributes := Default().Evaluate(Tile{ID: 1000, Image: "notset"})
+
+	assert.Equal(t, []Attribute{
+		{TraitType: "Image Changes", Value: 0, DisplayType: "number"},
+		{TraitType: "Times Sold", Value: 0, DisplayType: "number"},
+		{TraitType: "Status", Value: "Never Updated"},
+	}, attributes, "no year or colours without history or a decodable image")
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := Parse([]byte(`{"traits": [{"trait_type": "Row", "value": "Top", "regions": [{"rows": [0, 0]}]}]}`))
+	require.NoError(t, err, "JSON rule files are accepted")
+	assert.Equal(t, []Attribute{{TraitType: "Row", Value: "Top"}}, rules.Evaluate(Tile{ID: 5}))
+	assert.Empty(t, rules.Evaluate(Tile{ID: 81}))
+
+	for name, file := range map[string]string{
+		"no selector":    "traits: [{trait_type: A, value: B}]",
+		"two selectors":  "traits: [{trait_type: A, value: B, tiles: [1], derive: times_sold}]",
+		"no value":       "traits: [{trait_type: A, tiles: [1]}]",
+		"unknown derive": "traits: [{trait_type: A, derive: luck}]",
+		"off the map":    "traits: [{trait_type: A, value: B, tiles: [3970]}]",
+		"bad region":     "traits: [{trait_type: A, value: B, regions: [{rows: [5, 2]}]}]",
+	} {
+		_, err := Parse([]byte(file))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestScore(t *testing.T) {
+	common := Attribute{TraitType: "Location", Value: "Edge"}
+	rare := Attribute{TraitType: "Special", Value: "Genesis"}
+	collection := Score(map[int32][]Attribute{
+		1: {common},
+		2: {common},
+		3: {common, rare},
+		4: {},
+	})
+
+	assert.Equal(t, 4, collection.Tiles)
+	assert.Equal(t, Rarity{Score: 4.0/3 + 4, Rank: 1}, collection.Rarity[3])
+	assert.Equal(t, Rarity{Score: 4.0 / 3, Rank: 2}, collection.Rarity[1])
+	assert.Equal(t, Rarity{Score: 4.0 / 3, Rank: 2}, collection.Rarity[2], "ties share a rank")
+	assert.Equal(t, Rarity{Score: 0, Rank: 4}, collection.Rarity[4])
+	assert.Equal(t, []TraitCount{
+		{TraitType: "Location", Value: "Edge", Count: 3, Score: 4.0 / 3},
+		{TraitType: "Special", Value: "Genesis", Count: 1, Score: 4},
+	}, collection.Traits)
+}
@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NormalizeAddress returns the form addresses are stored in: lowercase hex
+// with the 0x prefix. Anything that is not a hex address, such as an ENS name
+// recorded in place of one, is returned unchanged.
+func NormalizeAddress(address string) string {
+	address = strings.TrimSpace(address)
+	if !common.IsHexAddress(address) {
+		return address
+	}
+	return strings.ToLower(common.HexToAddress(address).Hex())
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"math/big"
+	"strings"
+)
+
+var weiPerEther = new(big.Rat).SetInt(big.NewInt(1e18))
+
+// FormatEth prints an exact ETH amount to at most 18 decimal places, the
+// precision of wei, without trailing zeros.
+func FormatEth(amount *big.Rat) string {
+	return strings.TrimRight(strings.TrimRight(amount.FloatString(18), "0"), ".")
+}
+
+// FormatWei prints a stored wei amount, such as "2000000000000000000", as
+// ETH ("2"). Values that are not a number are returned unchanged.
+func FormatWei(wei string) string {
+	amount, ok := WeiToEth(wei)
+	if !ok {
+		return wei
+	}
+	return FormatEth(amount)
+}
+
+// WeiToEth converts a stored wei amount to an exact ETH amount.
+func WeiToEth(wei string) (*big.Rat, bool) {
+	amount, ok := new(big.Rat).SetString(wei)
+	if !ok {
+		return nil, false
+	}
+	return amount.Quo(amount, weiPerEther), true
+}
+
+// ParseEther converts an ETH amount such as "2.00" or "0.05" to wei. It
+// fails for amounts that are not a whole number of wei.
+func ParseEther(value string) (*big.Int, bool) {
+	amount, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, false
+	}
+	amount.Mul(amount, weiPerEther)
+	if !amount.IsInt() {
+		return nil, false
+	}
+	return amount.Num(), true
+}
@@ -12,14 +12,12 @@ import (
 )
 
 func RenderImage(tileImageData string, sizeX, sizeY int, outputPath string) error {
-	// First try to decompress the tile image data
-	decompressedImage, err := DecompressTileCode(tileImageData)
+	img, err := DecodeTileImage(tileImageData)
 	if err != nil {
-		return fmt.Errorf("failed to decompress tile image data: %w", err)
+		return err
 	}
-
-	if len(decompressedImage) < 768 {
-		fmt.Printf("decompressed tile image data is too short: %d bytes", len(decompressedImage))
+	if img == nil {
+		fmt.Printf("decompressed tile image data is too short")
 		return nil
 	}
 
@@ -27,17 +25,6 @@ func RenderImage(tileImageData string, sizeX, sizeY int, outputPath string) erro
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
-
-	for i := 0; i < 256; i++ {
-		x, y := i%16, i/16
-		hexStr := decompressedImage[i*3 : i*3+3]
-		r := parseHexChar(hexStr[0])
-		g := parseHexChar(hexStr[1])
-		b := parseHexChar(hexStr[2])
-		img.Set(x, y, color.RGBA{r, g, b, 255})
-	}
-
 	resizedImg := image.NewRGBA(image.Rect(0, 0, sizeX, sizeY))
 	draw.NearestNeighbor.Scale(resizedImg, resizedImg.Bounds(), img, img.Bounds(), draw.Over, nil)
 
@@ -54,6 +41,59 @@ func RenderImage(tileImageData string, sizeX, sizeY int, outputPath string) erro
 	return nil
 }
 
+// DecodeTileImage decodes tile image data into its 16x16 pixels. It returns
+// nil when the data is too short to hold a whole tile.
+func DecodeTileImage(tileImageData string) (*image.RGBA, error) {
+	// First try to decompress the tile image data
+	decompressedImage, err := DecompressTileCode(tileImageData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress tile image data: %w", err)
+	}
+
+	if len(decompressedImage) < 768 {
+		return nil, nil
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+
+	for i := 0; i < 256; i++ {
+		x, y := i%16, i/16
+		hexStr := decompressedImage[i*3 : i*3+3]
+		r := parseHexChar(hexStr[0])
+		g := parseHexChar(hexStr[1])
+		b := parseHexChar(hexStr[2])
+		img.Set(x, y, color.RGBA{r, g, b, 255})
+	}
+	return img, nil
+}
+
+// DiffTileImages compares two tiles' image data pixel by pixel. It returns
+// how many of the 256 pixels differ and the smallest rectangle holding them,
+// which is empty when none do. Data that does not decode to a whole tile
+// counts as blank, so drawing on a blank tile changes every pixel.
+func DiffTileImages(before, after string) (int, image.Rectangle) {
+	from, _ := DecodeTileImage(before)
+	to, _ := DecodeTileImage(after)
+	switch {
+	case from == nil && to == nil:
+		return 0, image.Rectangle{}
+	case from == nil || to == nil:
+		return 256, image.Rect(0, 0, 16, 16)
+	}
+
+	changed := 0
+	var bounds image.Rectangle
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			if from.RGBAAt(x, y) != to.RGBAAt(x, y) {
+				changed++
+				bounds = bounds.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return changed, bounds
+}
+
 func parseHexChar(c byte) uint8 {
 	switch {
 	case c >= '0' && c <= '9':
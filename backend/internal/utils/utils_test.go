@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"image"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -156,4 +158,67 @@ func TestImageProperties(t *testing.T) {
 			assert.Equal(t, tc.colorDepth, props.ColorDepth)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestNormalizeAddress(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"0x015A06a433353f8db634dF4eDdF0C109882A15AB", "0x015a06a433353f8db634df4eddf0c109882a15ab"},
+		{"0x015a06a433353f8db634df4eddf0c109882a15ab", "0x015a06a433353f8db634df4eddf0c109882a15ab"},
+		{" 015A06A433353F8DB634DF4EDDF0C109882A15AB ", "0x015a06a433353f8db634df4eddf0c109882a15ab"},
+		{"pixelmap.eth", "pixelmap.eth"},
+		{"", ""},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, NormalizeAddress(tc.input), tc.input)
+	}
+}
+
+func TestFormatWei(t *testing.T) {
+	assert.Equal(t, "2", FormatWei("2000000000000000000"))
+	assert.Equal(t, "0.002", FormatWei("2000000000000000"))
+	assert.Equal(t, "0.000000000000000001", FormatWei("1"))
+	assert.Equal(t, "1000000", FormatWei("1000000000000000000000000"))
+	assert.Equal(t, "0", FormatWei("0"))
+	assert.Equal(t, "", FormatWei(""))
+}
+
+func TestParseEther(t *testing.T) {
+	wei, ok := ParseEther("2.00")
+	assert.True(t, ok)
+	assert.Equal(t, "2000000000000000000", wei.String())
+
+	wei, ok = ParseEther("0.002")
+	assert.True(t, ok)
+	assert.Equal(t, "2000000000000000", wei.String())
+
+	_, ok = ParseEther("0.0000000000000000001")
+	assert.False(t, ok, "below one wei")
+	_, ok = ParseEther("two")
+	assert.False(t, ok)
+}
+
+func TestDiffTileImages(t *testing.T) {
+	black := strings.Repeat("000", 256)
+	// Two pixels changed: (3, 1) and (10, 4).
+	drawn := []byte(black)
+	copy(drawn[(1*16+3)*3:], "f00")
+	copy(drawn[(4*16+10)*3:], "0f0")
+
+	changed, bounds := DiffTileImages(black, string(drawn))
+	assert.Equal(t, 2, changed)
+	assert.Equal(t, image.Rect(3, 1, 11, 5), bounds)
+
+	changed, bounds = DiffTileImages(black, black)
+	assert.Equal(t, 0, changed)
+	assert.True(t, bounds.Empty())
+
+	changed, bounds = DiffTileImages("", black)
+	assert.Equal(t, 256, changed, "a blank tile differs everywhere")
+	assert.Equal(t, image.Rect(0, 0, 16, 16), bounds)
+
+	changed, _ = DiffTileImages("", "")
+	assert.Equal(t, 0, changed)
+}
@@ -0,0 +1,301 @@
+// Package verifier reconciles the indexed tile state in Postgres against what
+// the PixelMap contracts report on chain.
+package verifier
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+	pixelmap "pixelmap.io/backend/internal/contracts/pixelmap"
+	pixelmapWrapper "pixelmap.io/backend/internal/contracts/pixelmapWrapper"
+	db "pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/ingestor"
+)
+
+const tileCount = 3970
+
+// Unclaimed tiles report a zero owner and price on chain, but buyTile sells
+// them for 2 ETH on behalf of the creator, which is how the ingestor seeds them.
+var unclaimedPrice = new(big.Int).Mul(big.NewInt(2), big.NewInt(1e18))
+
+// ChainTile is a tile as the contracts report it, normalized to the form the
+// ingestor stores.
+type ChainTile struct {
+	ID      int32
+	Owner   string
+	Image   string
+	URL     string
+	Price   *big.Int
+	Wrapped bool
+}
+
+// Mismatch is one field of one tile that differs between Postgres and the
+// chain.
+type Mismatch struct {
+	TileID   int32  `json:"tile_id"`
+	Field    string `json:"field"`
+	Database string `json:"database"`
+	Chain    string `json:"chain"`
+}
+
+// Report summarizes a verification run.
+type Report struct {
+	Block      int64      `json:"block"`
+	Checked    int        `json:"checked"`
+	Mismatches []Mismatch `json:"mismatches"`
+	Repaired   int        `json:"repaired"`
+}
+
+// WriteText prints the report as one line per mismatch followed by a summary.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, m := range r.Mismatches {
+		if _, err := fmt.Fprintf(w, "tile %d %s: db=%q chain=%q\n", m.TileID, m.Field, m.Database, m.Chain); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "checked %d tiles at block %d: %d mismatches, %d tiles repaired\n",
+		r.Checked, r.Block, len(r.Mismatches), r.Repaired)
+	return err
+}
+
+// Options controls a verification run.
+type Options struct {
+	// Block pins every contract call. When nil the last block the ingestor
+	// processed is used, so the database and chain describe the same moment.
+	Block *big.Int
+	// Locations restricts the run to specific tiles; nil checks all of them.
+	Locations []int32
+	// Repair writes the chain's values over mismatched tiles and records
+	// each corrected field in tile_repairs.
+	Repair bool
+}
+
+type Verifier struct {
+	logger   *zap.Logger
+	conn     *sql.DB
+	queries  *db.Queries
+	network  *ingestor.Network
+	pixelMap *pixelmap.PixelMapCallerRaw
+	wrapper  *pixelmapWrapper.PixelMapWrapperCaller
+}
+
+func New(logger *zap.Logger, conn *sql.DB, network *ingestor.Network, caller bind.ContractCaller) (*Verifier, error) {
+	pixelMapCaller, err := pixelmap.NewPixelMapCaller(common.HexToAddress(network.PixelMapAddress), caller)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind PixelMap: %w", err)
+	}
+
+	v := &Verifier{
+		logger:   logger,
+		conn:     conn,
+		queries:  db.New(conn),
+		network:  network,
+		pixelMap: &pixelmap.PixelMapCallerRaw{Contract: pixelMapCaller},
+	}
+
+	if network.WrapperAddress != "" {
+		v.wrapper, err = pixelmapWrapper.NewPixelMapWrapperCaller(common.HexToAddress(network.WrapperAddress), caller)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bind PixelMapWrapper: %w", err)
+		}
+	}
+
+	return v, nil
+}
+
+// Verify compares every requested tile against the chain and, with
+// opts.Repair, corrects the ones that differ.
+func (v *Verifier) Verify(ctx context.Context, opts Options) (*Report, error) {
+	block := opts.Block
+	if block == nil {
+		lastProcessed, err := v.queries.GetLastProcessedBlock(ctx)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get last processed block: %w", err)
+		}
+		if lastProcessed == 0 {
+			return nil, fmt.Errorf("nothing has been ingested yet")
+		}
+		block = big.NewInt(lastProcessed)
+	}
+
+	locations := opts.Locations
+	if locations == nil {
+		locations = make([]int32, tileCount)
+		for id := range locations {
+			locations[id] = int32(id)
+		}
+	}
+
+	report := &Report{Block: block.Int64(), Mismatches: []Mismatch{}}
+	for n, id := range locations {
+		if n > 0 && n%500 == 0 {
+			v.logger.Info("Verification progress",
+				zap.Int("checked", n),
+				zap.Int("total", len(locations)),
+				zap.Int("mismatches", len(report.Mismatches)))
+		}
+
+		stored, err := v.queries.GetTileById(ctx, id)
+		if err == sql.ErrNoRows {
+			report.Mismatches = append(report.Mismatches, Mismatch{TileID: id, Field: "tile", Database: "missing", Chain: "present"})
+			report.Checked++
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tile %d: %w", id, err)
+		}
+
+		onChain, err := v.ChainTile(ctx, block, id)
+		if err != nil {
+			return nil, err
+		}
+
+		mismatches := Compare(stored, onChain)
+		report.Checked++
+		if len(mismatches) == 0 {
+			continue
+		}
+		report.Mismatches = append(report.Mismatches, mismatches...)
+
+		if opts.Repair {
+			if err := v.repair(ctx, onChain, mismatches, block.Int64()); err != nil {
+				return nil, err
+			}
+			report.Repaired++
+		}
+	}
+
+	v.logger.Info("Verification finished",
+		zap.Int64("block", report.Block),
+		zap.Int("checked", report.Checked),
+		zap.Int("mismatches", len(report.Mismatches)),
+		zap.Int("repaired", report.Repaired))
+
+	return report, nil
+}
+
+// ChainTile reads one tile with getTile and, when the wrapper holds it,
+// resolves the real owner with ownerOf. An unwrapped tile stays with the
+// wrapper, listed until it sells, but its token is burned and ownerOf
+// reverts; it is reported as an unwrapped tile owned by the wrapper.
+func (v *Verifier) ChainTile(ctx context.Context, block *big.Int, id int32) (ChainTile, error) {
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: block}
+	location := big.NewInt(int64(id))
+
+	// getTile is not marked constant in the 2016 ABI, so abigen only emits a
+	// transactor for it; call it through the raw binding instead.
+	var out []interface{}
+	if err := v.pixelMap.Call(opts, &out, "getTile", location); err != nil {
+		return ChainTile{}, fmt.Errorf("getTile(%d) failed: %w", id, err)
+	}
+
+	owner := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	tile := ChainTile{
+		ID:    id,
+		Owner: strings.ToLower(owner.Hex()),
+		Image: *abi.ConvertType(out[1], new(string)).(*string),
+		URL:   *abi.ConvertType(out[2], new(string)).(*string),
+		Price: *abi.ConvertType(out[3], new(*big.Int)).(**big.Int),
+	}
+
+	switch {
+	case owner == (common.Address{}):
+		tile.Owner = strings.ToLower(v.network.CreatorAddress)
+		tile.Price = unclaimedPrice
+	case v.wrapper != nil && v.network.IsWrapper(owner.Hex()):
+		holder, err := v.wrapper.OwnerOf(opts, location)
+		switch {
+		case err != nil && isRevert(err):
+		case err != nil:
+			return ChainTile{}, fmt.Errorf("ownerOf(%d) failed: %w", id, err)
+		default:
+			tile.Owner = strings.ToLower(holder.Hex())
+			tile.Wrapped = true
+		}
+	}
+
+	return tile, nil
+}
+
+// Compare lists the fields where the stored tile disagrees with the chain.
+// Owners are compared case-insensitively, prices in wei, and images and URLs
+// in the truncated form the ingestor stores them in.
+func Compare(stored db.Tile, onChain ChainTile) []Mismatch {
+	var mismatches []Mismatch
+	add := func(field, database, chain string) {
+		mismatches = append(mismatches, Mismatch{TileID: stored.ID, Field: field, Database: database, Chain: chain})
+	}
+
+	if !strings.EqualFold(stored.Owner, onChain.Owner) {
+		add("owner", stored.Owner, onChain.Owner)
+	}
+	image, url := ingestor.StoredTileText(onChain.Image, onChain.URL)
+	if stored.Image != image {
+		add("image", stored.Image, image)
+	}
+	if stored.Url != url {
+		add("url", stored.Url, url)
+	}
+	if wei, ok := new(big.Int).SetString(stored.Price, 10); !ok || wei.Cmp(onChain.Price) != 0 {
+		add("price", stored.Price, onChain.Price.String())
+	}
+	if stored.Wrapped != onChain.Wrapped {
+		add("wrapped", fmt.Sprint(stored.Wrapped), fmt.Sprint(onChain.Wrapped))
+	}
+
+	return mismatches
+}
+
+func (v *Verifier) repair(ctx context.Context, onChain ChainTile, mismatches []Mismatch, block int64) error {
+	tx, err := v.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := v.queries.WithTx(tx)
+
+	for _, m := range mismatches {
+		if _, err := q.InsertTileRepair(ctx, db.InsertTileRepairParams{
+			TileID:      m.TileID,
+			Field:       m.Field,
+			OldValue:    m.Database,
+			NewValue:    m.Chain,
+			BlockNumber: block,
+		}); err != nil {
+			return fmt.Errorf("failed to record repair of tile %d: %w", m.TileID, err)
+		}
+	}
+
+	image, url := ingestor.StoredTileText(onChain.Image, onChain.URL)
+	if err := q.RepairTile(ctx, db.RepairTileParams{
+		ID:      onChain.ID,
+		Image:   image,
+		Price:   onChain.Price.String(),
+		Url:     url,
+		Owner:   onChain.Owner,
+		Wrapped: onChain.Wrapped,
+	}); err != nil {
+		return fmt.Errorf("failed to repair tile %d: %w", onChain.ID, err)
+	}
+
+	v.logger.Info("Repaired tile",
+		zap.Int32("tile", onChain.ID),
+		zap.Int("fields", len(mismatches)))
+
+	return tx.Commit()
+}
+
+// isRevert reports whether a call failed because the contract reverted, as
+// opposed to the node being unreachable.
+func isRevert(err error) bool {
+	return strings.Contains(err.Error(), "execution reverted")
+}
@@ -0,0 +1,235 @@
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	pixelmap "pixelmap.io/backend/internal/contracts/pixelmap"
+	pixelmapWrapper "pixelmap.io/backend/internal/contracts/pixelmapWrapper"
+	db "pixelmap.io/backend/internal/db"
+	"pixelmap.io/backend/internal/db/dbtest"
+	"pixelmap.io/backend/internal/ingestor"
+)
+
+var testNetwork = ingestor.Network{
+	Name:            "test",
+	PixelMapAddress: "0x5fbdb2315678afecb367f032d93f642f64180aa3",
+	WrapperAddress:  "0xe7f1725e7734ce288f8367e1bb143e90bb3f0512",
+	CreatorAddress:  "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266",
+}
+
+const (
+	alice = "0x70997970c51812dc3a010c7d01b50e0d17dc79c8"
+	bob   = "0x3c44cdddb6a900fa2b585dd299e03d12fa4293bc"
+)
+
+type simulatedTile struct {
+	owner string
+	image string
+	url   string
+	price *big.Int
+}
+
+// simulatedBackend answers getTile and ownerOf from in-memory contract state,
+// encoding results with the same ABIs the bindings decode them with.
+type simulatedBackend struct {
+	tiles   map[int64]simulatedTile
+	holders map[int64]string
+	blocks  []*big.Int
+}
+
+func newSimulatedBackend() *simulatedBackend {
+	return &simulatedBackend{tiles: map[int64]simulatedTile{}, holders: map[int64]string{}}
+}
+
+func (b *simulatedBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x60}, nil
+}
+
+func (b *simulatedBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	b.blocks = append(b.blocks, blockNumber)
+
+	pixelMapABI, _ := pixelmap.PixelMapMetaData.GetAbi()
+	wrapperABI, _ := pixelmapWrapper.PixelMapWrapperMetaData.GetAbi()
+
+	switch {
+	case testNetwork.IsPixelMap(call.To.Hex()):
+		method, err := pixelMapABI.MethodById(call.Data)
+		if err != nil || method.Name != "getTile" {
+			return nil, fmt.Errorf("unexpected PixelMap call")
+		}
+		args, err := method.Inputs.Unpack(call.Data[4:])
+		if err != nil {
+			return nil, err
+		}
+		tile, ok := b.tiles[args[0].(*big.Int).Int64()]
+		if !ok {
+			tile = simulatedTile{price: new(big.Int)}
+		}
+		return method.Outputs.Pack(common.HexToAddress(tile.owner), tile.image, tile.url, tile.price)
+
+	case testNetwork.IsWrapper(call.To.Hex()):
+		method, err := wrapperABI.MethodById(call.Data)
+		if err != nil || method.Name != "ownerOf" {
+			return nil, fmt.Errorf("unexpected wrapper call")
+		}
+		args, err := method.Inputs.Unpack(call.Data[4:])
+		if err != nil {
+			return nil, err
+		}
+		holder, ok := b.holders[args[0].(*big.Int).Int64()]
+		if !ok {
+			return nil, fmt.Errorf("execution reverted: ERC721: invalid token ID")
+		}
+		return method.Outputs.Pack(common.HexToAddress(holder))
+	}
+
+	return nil, fmt.Errorf("unexpected call to %s", call.To.Hex())
+}
+
+func ether(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), big.NewInt(1e18))
+}
+
+func TestChainTileNormalizesUnclaimedAndWrappedTiles(t *testing.T) {
+	backend := newSimulatedBackend()
+	backend.tiles[5] = simulatedTile{owner: testNetwork.WrapperAddress, image: "abc", price: new(big.Int)}
+	backend.holders[5] = bob
+
+	v, err := New(zap.NewNop(), nil, &testNetwork, backend)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	block := big.NewInt(100)
+
+	unclaimed, err := v.ChainTile(ctx, block, 0)
+	require.NoError(t, err)
+	assert.Equal(t, testNetwork.CreatorAddress, unclaimed.Owner)
+	assert.Equal(t, ether(2), unclaimed.Price)
+	assert.False(t, unclaimed.Wrapped)
+
+	wrapped, err := v.ChainTile(ctx, block, 5)
+	require.NoError(t, err)
+	assert.Equal(t, bob, wrapped.Owner)
+	assert.True(t, wrapped.Wrapped)
+	assert.Equal(t, "abc", wrapped.Image)
+
+	for _, b := range backend.blocks {
+		assert.Equal(t, block, b)
+	}
+}
+
+func TestChainTileTreatsBurnedTokenAsUnwrapped(t *testing.T) {
+	backend := newSimulatedBackend()
+	backend.tiles[6] = simulatedTile{owner: testNetwork.WrapperAddress, price: ether(3)}
+
+	v, err := New(zap.NewNop(), nil, &testNetwork, backend)
+	require.NoError(t, err)
+
+	listed, err := v.ChainTile(context.Background(), big.NewInt(100), 6)
+	require.NoError(t, err, "ownerOf reverts once the token is burned")
+	assert.Equal(t, testNetwork.WrapperAddress, listed.Owner)
+	assert.False(t, listed.Wrapped)
+	assert.Equal(t, ether(3), listed.Price)
+}
+
+func TestCompare(t *testing.T) {
+	longImage := strings.Repeat("f", 1000)
+	stored := db.Tile{ID: 3, Owner: strings.ToUpper(alice[:2]) + alice[2:], Image: longImage[:800], Url: "https://a", Price: "2000000000000000000"}
+	onChain := ChainTile{ID: 3, Owner: alice, Image: longImage, URL: "https://a", Price: ether(2)}
+
+	assert.Empty(t, Compare(stored, onChain), "case and truncation are not mismatches")
+
+	longURL := "https://example.com/" + strings.Repeat("a", 300)
+	accented := strings.Repeat("é", 900)
+	assert.Empty(t, Compare(
+		db.Tile{ID: 3, Owner: alice, Image: strings.Repeat("é", 800), Url: longURL[:255], Price: "2000000000000000000"},
+		ChainTile{ID: 3, Owner: alice, Image: accented, URL: longURL, Price: ether(2)},
+	), "URLs and images are cut by characters as VARCHAR stores them")
+
+	onChain.Owner = bob
+	onChain.Price = new(big.Int)
+	onChain.Wrapped = true
+	onChain.URL = "https://b"
+
+	mismatches := Compare(stored, onChain)
+	fields := make([]string, len(mismatches))
+	for i, m := range mismatches {
+		fields[i] = m.Field
+	}
+	assert.Equal(t, []string{"owner", "url", "price", "wrapped"}, fields)
+	assert.Equal(t, Mismatch{TileID: 3, Field: "price", Database: "2000000000000000000", Chain: "0"}, mismatches[2])
+}
+
+func TestReportWriteText(t *testing.T) {
+	report := Report{Block: 10, Checked: 2, Mismatches: []Mismatch{{TileID: 1, Field: "url", Database: "", Chain: "https://a"}}}
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteText(&out))
+	assert.Equal(t, "tile 1 url: db=\"\" chain=\"https://a\"\nchecked 2 tiles at block 10: 1 mismatches, 0 tiles repaired\n", out.String())
+}
+
+func TestVerifyRepairsAndAudits(t *testing.T) {
+	conn := dbtest.Open(t)
+	queries := db.New(conn)
+	ctx := context.Background()
+
+	for id := int32(0); id < 3; id++ {
+		_, err := queries.InsertTile(ctx, db.InsertTileParams{ID: id, Price: "2000000000000000000", Owner: testNetwork.CreatorAddress})
+		require.NoError(t, err)
+	}
+	require.NoError(t, queries.UpdateLastProcessedBlock(ctx, 42))
+
+	backend := newSimulatedBackend()
+	backend.tiles[1] = simulatedTile{owner: alice, image: "fff", url: "https://alice", price: ether(1)}
+	backend.tiles[2] = simulatedTile{owner: testNetwork.WrapperAddress, price: new(big.Int)}
+	backend.holders[2] = bob
+
+	v, err := New(zap.NewNop(), conn, &testNetwork, backend)
+	require.NoError(t, err)
+
+	report, err := v.Verify(ctx, Options{Locations: []int32{0, 1, 2}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), report.Block)
+	assert.Equal(t, 3, report.Checked)
+	assert.NotEmpty(t, report.Mismatches)
+	assert.Zero(t, report.Repaired)
+
+	tile, err := queries.GetTileById(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, testNetwork.CreatorAddress, tile.Owner, "dry run must not write")
+
+	report, err = v.Verify(ctx, Options{Locations: []int32{0, 1, 2}, Repair: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Repaired)
+
+	tile, err = queries.GetTileById(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, alice, tile.Owner)
+	assert.Equal(t, "fff", tile.Image)
+	assert.Equal(t, "https://alice", tile.Url)
+	assert.Equal(t, "1000000000000000000", tile.Price)
+
+	tile, err = queries.GetTileById(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, bob, tile.Owner)
+	assert.True(t, tile.Wrapped)
+
+	repairs, err := queries.GetTileRepairsByTileId(ctx, 2)
+	require.NoError(t, err)
+	require.NotEmpty(t, repairs)
+	assert.Equal(t, int64(42), repairs[0].BlockNumber)
+
+	report, err = v.Verify(ctx, Options{Locations: []int32{0, 1, 2}})
+	require.NoError(t, err)
+	assert.Empty(t, report.Mismatches)
+}
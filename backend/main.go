@@ -3,18 +3,25 @@ package main
 //go:generate sh -c "cd internal/db && sqlc generate"
 //go:generate sh -c "abigen --abi ../contracts/PixelMap.abi -pkg pixelmap --type PixelMap --out internal/contracts/pixelmap/pixelmap.go"
 //go:generate sh -c "abigen --abi ../contracts/PixelMapWrapper.abi -pkg contracts --type PixelMapWrapper --out internal/contracts/pixelmapWrapper/pixelmap_wrapper.go"
+//go:generate sh -c "abigen --abi ../contracts/PixelMapTimeCapsule.abi -pkg timecapsule --type PixelMapTimeCapsule --out internal/contracts/timecapsule/pixelmap_time_capsule.go"
 
 import (
 	"context"
-	"database/sql"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
 	prettyconsole "github.com/thessem/zap-prettyconsole"
 	"go.uber.org/zap"
+	"pixelmap.io/backend/internal/db"
 	"pixelmap.io/backend/internal/ingestor"
+	"pixelmap.io/backend/internal/metrics"
 )
 
 func main() {
@@ -25,16 +32,56 @@ func main() {
 
 	defer logger.Sync()
 
-	db, err := sql.Open("postgres", os.Getenv("DATABASE_URL"))
+	network, err := ingestor.NetworkFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid network configuration", zap.Error(err))
+	}
+	logger.Info("Using network profile",
+		zap.String("network", network.Name),
+		zap.Int("chainId", network.ChainID),
+		zap.String("schema", network.Schema))
+
+	conn, err := db.Open(os.Getenv("DATABASE_URL"), network.Schema)
 	if err != nil {
 		logger.Fatal("Failed to connect to database", zap.Error(err))
 	}
-	defer db.Close()
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := db.Migrate(ctx, conn); err != nil {
+		logger.Fatal("Failed to migrate database", zap.Error(err))
+	}
 
-	ingester := ingestor.NewIngestor(logger, db, os.Getenv("ETHERSCAN_API_KEY"))
+	ingester := ingestor.NewIngestor(logger, conn, os.Getenv("ETHERSCAN_API_KEY"), network)
+	defer ingester.Close()
+
+	health := ingester.Health()
+	health.AddReadinessCheck("database", conn.PingContext)
+	prometheus.MustRegister(metrics.NewRateBudgetCollector(ingester.EtherscanUsage))
+
+	adminAddr := os.Getenv("ADMIN_ADDR")
+	if adminAddr == "" {
+		adminAddr = ":9090"
+	}
+	adminServer := metrics.NewServer(adminAddr, health, prometheus.DefaultGatherer)
+	go func() {
+		logger.Info("Admin server listening", zap.String("addr", adminAddr))
+		if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Admin server stopped", zap.Error(err))
+		}
+	}()
+
+	// Run returns once SIGINT/SIGTERM has been received and every worker has
+	// finished what it was doing.
+	if err := ingester.Run(ctx); err != nil {
+		logger.Error("Ingestor stopped", zap.Error(err))
+	}
 
-	ctx := context.Background()
-	if err := ingester.StartContinuousIngestion(ctx); err != nil {
-		log.Fatalf("Continuous ingestion stopped: %v", err)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := adminServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Failed to shut down admin server", zap.Error(err))
 	}
 }
@@ -0,0 +1,11 @@
+#!/bin/bash
+
+# Load environment variables from .env file
+if [ -f .env ]; then
+  export $(cat .env | grep -v '^#' | xargs)
+fi
+
+# Import daily ETH/USD prices, e.g.
+#   ./prices.sh -source csv -file eth-usd.csv
+#   ./prices.sh -source chainlink
+go run cmd/prices/main.go "$@"
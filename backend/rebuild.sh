@@ -0,0 +1,14 @@
+#!/bin/bash
+
+# Load environment variables from .env file
+if [ -f .env ]; then
+  export $(cat .env | grep -v '^#' | xargs)
+fi
+
+# Recompute tiles and the history tables from the transaction archive, offline.
+# Prints the differences and asks before committing; pass --yes to commit
+# without asking, --dry-run to only compare, --json for a machine-readable
+# report. History rows the archive can't reproduce, as on databases migrated
+# through 016, are only dropped with --allow-loss, even with --yes. A running
+# ingestor waits until the rebuild commits or rolls back.
+go run cmd/rebuild/main.go "$@"
@@ -0,0 +1,11 @@
+#!/bin/bash
+
+# Load environment variables from .env file
+if [ -f .env ]; then
+  export $(cat .env | grep -v '^#' | xargs)
+fi
+
+# Compare every tile in the database against the chain.
+# Pass --repair to write corrections (recorded in tile_repairs), --json for a
+# machine-readable report.
+go run cmd/verify/main.go "$@"